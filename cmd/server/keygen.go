@@ -0,0 +1,44 @@
+package main
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"log"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// runKeygen generates an Ed25519 client key pair for authenticating
+// X-Session-Key handshakes and prints the private key plus the
+// authorized_keys line an operator should add to their -authorized-keys file.
+func runKeygen() {
+	pubKey, privKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		log.Fatalf("Failed to generate key: %v", err)
+	}
+
+	privKeyBytes, err := ssh.MarshalPrivateKey(privKey, "ssh-mcp-client")
+	if err != nil {
+		log.Fatalf("Failed to marshal private key: %v", err)
+	}
+
+	sshPubKey, err := ssh.NewPublicKey(pubKey)
+	if err != nil {
+		log.Fatalf("Failed to create SSH public key: %v", err)
+	}
+
+	authorizedLine := fmt.Sprintf("%s %s ssh-mcp-client",
+		sshPubKey.Type(),
+		base64.StdEncoding.EncodeToString(sshPubKey.Marshal()))
+
+	fmt.Println("# Private key (save this; it is not stored anywhere):")
+	fmt.Print(string(pem.EncodeToMemory(privKeyBytes)))
+	fmt.Println()
+	fmt.Println("# Add this line to the server's -authorized-keys file:")
+	fmt.Println(authorizedLine)
+	fmt.Println()
+	fmt.Printf("# Fingerprint: %s\n", ssh.FingerprintSHA256(sshPubKey))
+}
@@ -4,30 +4,53 @@ package main
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/json"
 	"flag"
+	"fmt"
 	"log"
 	"net/http"
 	"os"
 	"os/signal"
+	"runtime/debug"
+	"strconv"
 	"sync"
 	"syscall"
 	"time"
 
+	"ssh-mcp/internal/audit"
+	"ssh-mcp/internal/auth"
+	"ssh-mcp/internal/metrics"
 	"ssh-mcp/internal/ssh"
 	"ssh-mcp/internal/tools"
 
 	"github.com/google/uuid"
+	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
 const (
-	serverName    = "ssh-mcp"
-	
+	serverName = "ssh-mcp"
+
 	// Defaults
-	defaultMode  = "http"
-	defaultPort  = "8000"
-	defaultDebug = "false"
-	defaultGlobal = "false"
+	defaultMode     = "http"
+	defaultPort     = "8000"
+	defaultDebug    = "false"
+	defaultGlobal   = "false"
+	defaultStore    = "memory"
+	defaultRedisURL = "redis://localhost:6379/0"
+
+	// Pool capacity defaults (0 = unlimited, the pool's historical behavior)
+	defaultMaxHeaderSessions                     = 0
+	defaultMaxManagers                           = 0
+	defaultMaxPerRemoteConcurrency               = 0
+	defaultIdleTTL                 time.Duration = 0 // 0 = ssh.Pool's built-in default (5m)
+
+	// Per-header-key limits (0 = unlimited, same convention)
+	defaultHeaderMaxConcurrent int     = 0
+	defaultHeaderRatePerSecond float64 = 0
+	defaultHeaderBurst         int     = 0
 )
 
 // UUIDv7SessionManager generates time-ordered UUIDv7 session IDs
@@ -73,12 +96,12 @@ func (m *UUIDv7SessionManager) Validate(sessionID string) (bool, error) {
 	if _, err := uuid.Parse(sessionID); err != nil {
 		return false, err
 	}
-	
+
 	// Check if terminated
 	m.mu.RLock()
 	_, isTerminated := m.terminated[sessionID]
 	m.mu.RUnlock()
-	
+
 	return isTerminated, nil
 }
 
@@ -87,11 +110,11 @@ func (m *UUIDv7SessionManager) Terminate(sessionID string) (bool, error) {
 	if _, err := uuid.Parse(sessionID); err != nil {
 		return false, err
 	}
-	
+
 	m.mu.Lock()
 	m.terminated[sessionID] = time.Now()
 	m.mu.Unlock()
-	
+
 	log.Printf("[SESSION] Terminated: %s", sessionID)
 	return false, nil // isNotAllowed=false (we allow termination)
 }
@@ -100,8 +123,13 @@ func (m *UUIDv7SessionManager) Terminate(sessionID string) (bool, error) {
 var commitSHA = "dev"
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "keygen" {
+		runKeygen()
+		return
+	}
+
 	// Configuration Precedence: Flag > Env > Default
-	
+
 	// Helper to get env with fallback
 	getEnv := func(key, fallback string) string {
 		if value, exists := os.LookupEnv(key); exists {
@@ -110,17 +138,72 @@ func main() {
 		return fallback
 	}
 
+	// Helper to get an int env with fallback, ignoring an unparseable value.
+	getEnvInt := func(key string, fallback int) int {
+		if value, exists := os.LookupEnv(key); exists {
+			if n, err := strconv.Atoi(value); err == nil {
+				return n
+			}
+		}
+		return fallback
+	}
+
+	// Helper to get a time.Duration env with fallback, ignoring an
+	// unparseable value.
+	getEnvDuration := func(key string, fallback time.Duration) time.Duration {
+		if value, exists := os.LookupEnv(key); exists {
+			if d, err := time.ParseDuration(value); err == nil {
+				return d
+			}
+		}
+		return fallback
+	}
+
+	// Helper to get a float64 env with fallback, ignoring an unparseable value.
+	getEnvFloat := func(key string, fallback float64) float64 {
+		if value, exists := os.LookupEnv(key); exists {
+			if f, err := strconv.ParseFloat(value, 64); err == nil {
+				return f
+			}
+		}
+		return fallback
+	}
+
 	// Initialize flags with Env/Default values
 	modeEnv := getEnv("SSH_MCP_MODE", defaultMode)
 	portEnv := getEnv("PORT", defaultPort)
 	debugEnv := getEnv("SSH_MCP_DEBUG", defaultDebug) == "true"
 	globalEnv := getEnv("SSH_MCP_GLOBAL", "false") == "true"
+	storeEnv := getEnv("SSH_MCP_STORE", defaultStore)
+	redisURLEnv := getEnv("SSH_MCP_REDIS_URL", defaultRedisURL)
+	authorizedKeysEnv := getEnv("SSH_MCP_AUTHORIZED_KEYS", "")
+	hmacSecretEnv := getEnv("SSH_MCP_HMAC_SECRET", "")
+	auditLogEnv := getEnv("SSH_MCP_AUDIT_LOG", "")
+	maxHeaderSessionsEnv := getEnvInt("SSH_MCP_MAX_HEADER_SESSIONS", defaultMaxHeaderSessions)
+	maxManagersEnv := getEnvInt("SSH_MCP_MAX_MANAGERS", defaultMaxManagers)
+	maxPerRemoteConcurrencyEnv := getEnvInt("SSH_MCP_MAX_PER_REMOTE_CONCURRENCY", defaultMaxPerRemoteConcurrency)
+	idleTTLEnv := getEnvDuration("SSH_MCP_IDLE_TTL", defaultIdleTTL)
+	headerMaxConcurrentEnv := getEnvInt("SSH_MCP_HEADER_MAX_CONCURRENT", defaultHeaderMaxConcurrent)
+	headerRatePerSecondEnv := getEnvFloat("SSH_MCP_HEADER_RATE_PER_SECOND", defaultHeaderRatePerSecond)
+	headerBurstEnv := getEnvInt("SSH_MCP_HEADER_BURST", defaultHeaderBurst)
 
 	// Define flags (overrides envs)
 	mode := flag.String("mode", modeEnv, "Transport mode: stdio or http")
 	port := flag.String("port", portEnv, "HTTP server port (http mode only)")
 	debug := flag.Bool("debug", debugEnv, "Enable debug logging")
 	globalState := flag.Bool("global", globalEnv, "Use single shared SSH manager for all sessions")
+	store := flag.String("store", storeEnv, "Session state backend: memory or redis")
+	redisURL := flag.String("redis-url", redisURLEnv, "Redis URL (redis mode only), e.g. redis://localhost:6379/0")
+	authorizedKeysPath := flag.String("authorized-keys", authorizedKeysEnv, "Path to an authorized_keys file; enables signed X-Session-Key authentication (http mode only)")
+	hmacSecret := flag.String("hmac-secret", hmacSecretEnv, "Secret used to derive pool keys from authenticated fingerprints (required for multi-instance fleets sharing -authorized-keys)")
+	auditLogPath := flag.String("audit-log", auditLogEnv, "Path to write a rotating JSON-lines audit log of tool invocations (disabled if empty)")
+	maxHeaderSessions := flag.Int("max-header-sessions", maxHeaderSessionsEnv, "Max concurrent X-Session-Key sessions before LRU eviction kicks in (0 = unlimited)")
+	maxManagers := flag.Int("max-managers", maxManagersEnv, "Max concurrent per-MCP-session Managers before LRU eviction kicks in (0 = unlimited)")
+	maxPerRemoteConcurrency := flag.Int("max-per-remote-concurrency", maxPerRemoteConcurrencyEnv, "Max concurrent in-flight requests per pooled Manager (0 = unlimited)")
+	idleTTL := flag.Duration("idle-ttl", idleTTLEnv, "How long a not-in-use session/manager may sit untouched before the background reaper closes it (0 = 5m default)")
+	headerMaxConcurrent := flag.Int("header-max-concurrent", headerMaxConcurrentEnv, "Max concurrent in-flight requests a single X-Session-Key may hold (0 = unlimited)")
+	headerRatePerSecond := flag.Float64("header-rate-per-second", headerRatePerSecondEnv, "Sustained requests/sec allowed per X-Session-Key before TouchHeaderCtx returns ErrRateLimited (0 = unlimited)")
+	headerBurst := flag.Int("header-burst", headerBurstEnv, "Burst size for -header-rate-per-second (0 defaults to 1 when rate limiting is enabled)")
 	flag.Parse()
 
 	// Configure logging
@@ -129,11 +212,24 @@ func main() {
 	} else {
 		log.SetFlags(log.LstdFlags)
 	}
-	
-	log.Printf("Starting %s (commit=%s, mode=%s, port=%s, global=%v)", serverName, commitSHA, *mode, *port, *globalState)
+
+	log.Printf("Starting %s (commit=%s, mode=%s, port=%s, global=%v, store=%s)", serverName, commitSHA, *mode, *port, *globalState, *store)
 
 	// Initialize SSH Pool
-	pool := ssh.NewPool(*globalState)
+	pool := ssh.NewPoolWithOptions(*globalState, newPoolStore(*store, *redisURL), ssh.PoolOptions{
+		MaxHeaderSessions:       *maxHeaderSessions,
+		MaxManagers:             *maxManagers,
+		MaxPerRemoteConcurrency: *maxPerRemoteConcurrency,
+		IdleTTL:                 *idleTTL,
+		HeaderLimits: ssh.HeaderLimits{
+			MaxConcurrent: *headerMaxConcurrent,
+			RatePerSecond: *headerRatePerSecond,
+			Burst:         *headerBurst,
+		},
+	})
+
+	auditLogger := newAuditLogger(*auditLogPath)
+	defer auditLogger.Close()
 
 	// Create MCP Server
 	mcpServer := server.NewMCPServer(
@@ -141,40 +237,188 @@ func main() {
 		commitSHA,
 		server.WithToolCapabilities(true),
 		server.WithRecovery(),
-		server.WithHooks(createSessionHooks(pool)),
+		withPoolPanicMetrics(pool),
+		server.WithHooks(createHooks(pool, auditLogger)),
 	)
 
 	// Register all tools
 	tools.RegisterAll(mcpServer, pool)
 
+	authenticator := newAuthenticator(*authorizedKeysPath, *hmacSecret)
+
 	// Start server
 	switch *mode {
 	case "stdio":
 		runStdio(mcpServer)
 	case "http":
-		runHTTP(mcpServer, *port, pool)
+		runHTTP(mcpServer, *port, pool, authenticator)
 	default:
 		log.Fatalf("Unknown mode: %s. Use 'stdio' or 'http'.", *mode)
 	}
 }
 
-// createSessionHooks sets up session lifecycle hooks.
+// withPoolPanicMetrics wraps every tool call so a panic is fed through
+// pool.RecordPanic - incrementing PoolStats.PanicsTotal/the Prometheus
+// counter and running any configured PanicHandler - before converting it
+// to a tool error result. It sits alongside server.WithRecovery(), which
+// already keeps a panicking handler from crashing the process: almost
+// every tool handler calls Manager methods directly via getManager rather
+// than through Pool.Do, so without this middleware those panics would
+// vanish into WithRecovery's bare error message instead of surfacing in
+// the pool's own panic accounting.
+func withPoolPanicMetrics(pool *ssh.Pool) server.ServerOption {
+	return server.WithToolHandlerMiddleware(func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (result *mcp.CallToolResult, err error) {
+			defer func() {
+				if r := recover(); r != nil {
+					stack := debug.Stack()
+					pool.RecordPanic(request.Params.Name, r, stack)
+					result = mcp.NewToolResultError(fmt.Sprintf("panic recovered in %s tool handler: %v", request.Params.Name, r))
+					err = nil
+				}
+			}()
+			return next(ctx, request)
+		}
+	})
+}
+
+// newAuthenticator builds an Authenticator from -authorized-keys, or returns
+// nil (authentication disabled, legacy honor-system X-Session-Key header) if
+// no authorized keys file was configured.
+func newAuthenticator(authorizedKeysPath, hmacSecret string) *auth.Authenticator {
+	if authorizedKeysPath == "" {
+		return nil
+	}
+
+	keyStore, err := auth.LoadKeyStore(authorizedKeysPath)
+	if err != nil {
+		log.Fatalf("Failed to load authorized keys from %s: %v", authorizedKeysPath, err)
+	}
+
+	secret := []byte(hmacSecret)
+	if len(secret) == 0 {
+		log.Printf("[Auth] WARNING: -hmac-secret not set, generating a random one. Pool keys will not be stable across restarts or shared across a fleet.")
+		secret = make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			log.Fatalf("Failed to generate HMAC secret: %v", err)
+		}
+	}
+
+	log.Printf("[Auth] Loaded authorized keys from %s", authorizedKeysPath)
+	return auth.NewAuthenticator(keyStore, secret)
+}
+
+// newPoolStore builds the PoolStore backend selected by -store. Falls back to
+// MemoryStore (with a logged warning) on an unknown backend or a Redis dial
+// failure, so a misconfiguration degrades to single-process behavior instead
+// of crashing the server.
+func newPoolStore(backend, redisURL string) ssh.PoolStore {
+	switch backend {
+	case "redis":
+		store, err := ssh.NewRedisStore(redisURL)
+		if err != nil {
+			log.Printf("[Pool] Failed to initialize Redis store, falling back to memory: %v", err)
+			return ssh.NewMemoryStore()
+		}
+		log.Printf("[Pool] Using Redis store: %s", redisURL)
+		return store
+	case "memory":
+		return ssh.NewMemoryStore()
+	default:
+		log.Printf("[Pool] Unknown store backend %q, falling back to memory", backend)
+		return ssh.NewMemoryStore()
+	}
+}
+
+// newAuditLogger builds the audit.AuditLogger selected by -audit-log, or a
+// NopAuditLogger (audit logging disabled) if path is empty.
+func newAuditLogger(path string) audit.AuditLogger {
+	if path == "" {
+		return audit.NopAuditLogger{}
+	}
+
+	log.Printf("[Audit] Writing tool invocation log to %s", path)
+	return audit.NewFileAuditLogger(path)
+}
+
+// toolCallStart is stashed between the BeforeCallTool and AfterCallTool/OnError
+// hooks for a given request ID so the audit entry can record call duration.
+type toolCallStart struct {
+	at    time.Time
+	alias string
+}
+
+// createHooks sets up session lifecycle hooks plus per-tool-call metrics and
+// audit logging.
 // IMPORTANT: When X-Session-Key is present, we use header-based pooling instead of session-based.
 // This prevents duplicate managers and ensures connection reuse across MCP session restarts.
-func createSessionHooks(pool *ssh.Pool) *server.Hooks {
+func createHooks(pool *ssh.Pool, auditLogger audit.AuditLogger) *server.Hooks {
 	hooks := &server.Hooks{}
 
+	var calls sync.Map // request id -> *toolCallStart
+
+	hooks.AddBeforeCallTool(func(ctx context.Context, id any, message *mcp.CallToolRequest) {
+		calls.Store(id, &toolCallStart{
+			at:    time.Now(),
+			alias: toolAlias(message),
+		})
+	})
+
+	recordCall := func(ctx context.Context, id any, message *mcp.CallToolRequest, exitCode int) {
+		startVal, ok := calls.LoadAndDelete(id)
+		if !ok {
+			return
+		}
+		start := startVal.(*toolCallStart)
+		outcome := "ok"
+		if exitCode != 0 {
+			outcome = "error"
+		}
+		metrics.CommandsTotal.WithLabelValues(message.Params.Name, start.alias, outcome).Inc()
+
+		keyID, _ := ctx.Value(ssh.SessionKeyContextKey).(string)
+		args, _ := message.Params.Arguments.(map[string]interface{})
+		auditLogger.Log(audit.Entry{
+			Timestamp:  start.at,
+			KeyID:      keyID,
+			Tool:       message.Params.Name,
+			Alias:      start.alias,
+			ArgsDigest: audit.DigestArgs(args),
+			ExitCode:   exitCode,
+			DurationMs: time.Since(start.at).Milliseconds(),
+		})
+	}
+
+	hooks.AddAfterCallTool(func(ctx context.Context, id any, message *mcp.CallToolRequest, result *mcp.CallToolResult) {
+		exitCode := 0
+		if result != nil && result.IsError {
+			exitCode = 1
+		}
+		recordCall(ctx, id, message, exitCode)
+	})
+
+	hooks.AddOnError(func(ctx context.Context, id any, method mcp.MCPMethod, message any, err error) {
+		req, ok := message.(*mcp.CallToolRequest)
+		if !ok {
+			return
+		}
+		recordCall(ctx, id, req, 1)
+	})
+
 	hooks.AddOnRegisterSession(func(ctx context.Context, session server.ClientSession) {
 		sessionID := session.SessionID()
-		
+
 		// Check if this request has X-Session-Key - if so, use header-based pooling
 		if sessionKey, ok := ctx.Value(ssh.SessionKeyContextKey).(string); ok && sessionKey != "" {
 			// Touch the header-based manager to keep it alive
-			pool.TouchHeader(sessionKey)
+			if err := pool.TouchHeader(sessionKey); err != nil {
+				log.Printf("[Session] Rejected: %s (header pool: %s): %v", sessionID, sessionKey, err)
+				return
+			}
 			log.Printf("[Session] Started: %s (using header pool: %s)", sessionID, sessionKey)
 			return // Don't create session-based manager
 		}
-		
+
 		// No header - create session-based manager
 		log.Printf("[Session] Started: %s (session pool)", sessionID)
 		pool.CreateSession(sessionID)
@@ -182,14 +426,14 @@ func createSessionHooks(pool *ssh.Pool) *server.Hooks {
 
 	hooks.AddOnUnregisterSession(func(ctx context.Context, session server.ClientSession) {
 		sessionID := session.SessionID()
-		
+
 		// If using header-based pooling, release active count (managed by timeout)
 		if sessionKey, ok := ctx.Value(ssh.SessionKeyContextKey).(string); ok && sessionKey != "" {
 			pool.ReleaseHeader(sessionKey)
 			log.Printf("[Session] Ended: %s (header pool: %s retained)", sessionID, sessionKey)
 			return
 		}
-		
+
 		log.Printf("[Session] Ended: %s (session pool destroyed)", sessionID)
 		pool.DestroySession(sessionID)
 	})
@@ -197,6 +441,24 @@ func createSessionHooks(pool *ssh.Pool) *server.Hooks {
 	return hooks
 }
 
+// toolAlias extracts the connection alias a tool call was aimed at, for
+// labeling metrics and audit entries. Tools vary between "alias" (connect,
+// disconnect) and "target" (run, info, and most others); empty if neither
+// argument is present.
+func toolAlias(message *mcp.CallToolRequest) string {
+	args, ok := message.Params.Arguments.(map[string]interface{})
+	if !ok {
+		return ""
+	}
+	if alias, ok := args["alias"].(string); ok && alias != "" {
+		return alias
+	}
+	if target, ok := args["target"].(string); ok && target != "" {
+		return target
+	}
+	return ""
+}
+
 // runStdio runs the server in stdio mode.
 func runStdio(s *server.MCPServer) {
 	if err := server.ServeStdio(s); err != nil {
@@ -204,28 +466,48 @@ func runStdio(s *server.MCPServer) {
 	}
 }
 
-const sessionKeyHeader = "X-Session-Key"
+const (
+	sessionKeyHeader    = "X-Session-Key"
+	sessionKeyIDHeader  = "X-Session-Key-Id"
+	sessionKeySigHeader = "X-Session-Key-Sig"
+)
+
+// authContextKey is used for passing the authenticated pool key from
+// authMiddleware to the StreamableHTTPServer's context func.
+type authContextKey string
+
+const authPoolKeyContextKey authContextKey = "auth-pool-key"
 
 // runHTTP runs the server in Streamable HTTP mode with graceful shutdown.
-// 
+//
 // PRODUCTION SECURITY NOTICE:
 // This implementation requires additional security layers for production use:
 // - TLS/HTTPS: Use WithTLSCert() or run behind a reverse proxy with TLS
-// - Authentication: Validate X-Session-Key against authorized keys
-// - Authorization: Implement per-user access controls
+// - Authorization: Implement per-user access controls beyond session binding
 // - Rate Limiting: Add request throttling
 // - Audit Logging: Track all tool invocations with user context
-func runHTTP(s *server.MCPServer, port string, pool *ssh.Pool) {
+//
+// Authentication: when -authorized-keys is set, authMiddleware requires a
+// signed X-Session-Key-Id/X-Session-Key-Sig handshake (see /mcp/auth/nonce)
+// instead of trusting the raw X-Session-Key header.
+func runHTTP(s *server.MCPServer, port string, pool *ssh.Pool, authenticator *auth.Authenticator) {
 	// Use StreamableHTTPServer with UUIDv7 session IDs and security middleware
 	httpSrv := server.NewStreamableHTTPServer(s,
 		// Use time-ordered UUIDv7 for professional session IDs
 		server.WithSessionIdManager(NewUUIDv7SessionManager()),
-		
-		// Extract X-Session-Key for session persistence
+
+		// Extract the session key: from authMiddleware's verified pool key
+		// when authentication is enabled, otherwise the raw header.
 		server.WithHTTPContextFunc(func(ctx context.Context, r *http.Request) context.Context {
+			if authenticator != nil {
+				if poolKey, ok := ctx.Value(authPoolKeyContextKey).(string); ok && poolKey != "" {
+					return context.WithValue(ctx, ssh.SessionKeyContextKey, poolKey)
+				}
+				return ctx
+			}
+
 			sessionKey := r.Header.Get(sessionKeyHeader)
 			if sessionKey != "" {
-				// TODO PRODUCTION: Validate sessionKey against authorized keys here
 				log.Printf("[SECURITY] Session key received: %s from %s", sessionKey, r.RemoteAddr)
 				return context.WithValue(ctx, ssh.SessionKeyContextKey, sessionKey)
 			}
@@ -233,12 +515,19 @@ func runHTTP(s *server.MCPServer, port string, pool *ssh.Pool) {
 			return ctx
 		}),
 	)
-	
+
 	mux := http.NewServeMux()
-	
+
 	// Register the streamable HTTP handler at /mcp
 	// This handles both POST requests and GET (SSE) connections
-	mux.Handle("/mcp", httpSrv)
+	mux.Handle("/mcp", authMiddleware(httpSrv, authenticator))
+
+	if authenticator != nil {
+		mux.HandleFunc("/mcp/auth/nonce", nonceHandler(authenticator))
+	}
+
+	// Expose Prometheus metrics for scraping.
+	mux.Handle("/metrics", promhttp.Handler())
 
 	httpServer := &http.Server{
 		Addr:    ":" + port,
@@ -269,3 +558,59 @@ func runHTTP(s *server.MCPServer, port string, pool *ssh.Pool) {
 
 	log.Println("[HTTP] Server stopped")
 }
+
+// authMiddleware enforces the signed X-Session-Key-Id/X-Session-Key-Sig
+// handshake when authenticator is configured, rejecting requests with an
+// unknown fingerprint or an invalid signature before they reach the MCP
+// server. It's a transparent passthrough when authenticator is nil, so the
+// legacy honor-system header keeps working until -authorized-keys is set.
+func authMiddleware(next http.Handler, authenticator *auth.Authenticator) http.Handler {
+	if authenticator == nil {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		keyID := r.Header.Get(sessionKeyIDHeader)
+		sig := r.Header.Get(sessionKeySigHeader)
+		if keyID == "" || sig == "" {
+			log.Printf("[Auth] Rejected request from %s: missing %s/%s headers", r.RemoteAddr, sessionKeyIDHeader, sessionKeySigHeader)
+			http.Error(w, "missing session key authentication headers", http.StatusUnauthorized)
+			return
+		}
+
+		poolKey, err := authenticator.Verify(keyID, sig)
+		if err != nil {
+			log.Printf("[Auth] Rejected request from %s (key %s): %v", r.RemoteAddr, keyID, err)
+			http.Error(w, "session key authentication failed", http.StatusUnauthorized)
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), authPoolKeyContextKey, poolKey)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// nonceHandler issues a single-use nonce for the fingerprint named by
+// X-Session-Key-Id, for the client to sign with its private key and present
+// back on /mcp via X-Session-Key-Sig.
+func nonceHandler(authenticator *auth.Authenticator) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		keyID := r.Header.Get(sessionKeyIDHeader)
+		if keyID == "" {
+			http.Error(w, "missing "+sessionKeyIDHeader+" header", http.StatusBadRequest)
+			return
+		}
+
+		nonce, err := authenticator.IssueNonce(keyID)
+		if err != nil {
+			log.Printf("[Auth] Rejected nonce request from %s (key %s): %v", r.RemoteAddr, keyID, err)
+			http.Error(w, "unknown fingerprint", http.StatusUnauthorized)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(map[string]string{"nonce": nonce}); err != nil {
+			log.Printf("[Auth] Failed to write nonce response: %v", err)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+// Package audit provides structured, one-line-per-call logging of MCP tool
+// invocations for compliance and incident review.
+package audit
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"log"
+	"sync"
+	"time"
+
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+// Entry is a single audit record, one per tool invocation.
+type Entry struct {
+	Timestamp  time.Time `json:"timestamp"`
+	KeyID      string    `json:"key_id,omitempty"`
+	Tool       string    `json:"tool"`
+	Alias      string    `json:"alias,omitempty"`
+	ArgsDigest string    `json:"args_digest,omitempty"`
+	ExitCode   int       `json:"exit_code"`
+	DurationMs int64     `json:"duration_ms"`
+	RemoteIP   string    `json:"remote_ip,omitempty"`
+}
+
+// AuditLogger records tool invocation entries.
+type AuditLogger interface {
+	Log(entry Entry)
+	Close() error
+}
+
+// NopAuditLogger discards every entry. It's the default until -audit-log is
+// configured, so audit logging stays strictly opt-in.
+type NopAuditLogger struct{}
+
+func (NopAuditLogger) Log(Entry)    {}
+func (NopAuditLogger) Close() error { return nil }
+
+// FileAuditLogger writes one JSON object per line to a rotating log file.
+type FileAuditLogger struct {
+	mu  sync.Mutex
+	out io.WriteCloser
+	enc *json.Encoder
+}
+
+// NewFileAuditLogger creates a FileAuditLogger that rotates path at 100MB,
+// keeping 5 compressed backups for up to 30 days.
+func NewFileAuditLogger(path string) *FileAuditLogger {
+	rotator := &lumberjack.Logger{
+		Filename:   path,
+		MaxSize:    100,
+		MaxBackups: 5,
+		MaxAge:     30,
+		Compress:   true,
+	}
+	return &FileAuditLogger{out: rotator, enc: json.NewEncoder(rotator)}
+}
+
+func (f *FileAuditLogger) Log(entry Entry) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.enc.Encode(entry); err != nil {
+		log.Printf("[Audit] Failed to write audit entry: %v", err)
+	}
+}
+
+func (f *FileAuditLogger) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.out.Close()
+}
+
+// DigestArgs returns a short, stable digest of a tool call's arguments —
+// enough to correlate repeated calls in the audit log without recording
+// potentially sensitive argument values verbatim.
+func DigestArgs(args map[string]interface{}) string {
+	if len(args) == 0 {
+		return ""
+	}
+
+	data, err := json.Marshal(args)
+	if err != nil {
+		return ""
+	}
+
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])[:16]
+}
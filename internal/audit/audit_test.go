@@ -0,0 +1,34 @@
+package audit
+
+import "testing"
+
+func TestDigestArgs(t *testing.T) {
+	if got := DigestArgs(nil); got != "" {
+		t.Errorf("DigestArgs(nil) = %q, want empty", got)
+	}
+	if got := DigestArgs(map[string]interface{}{}); got != "" {
+		t.Errorf("DigestArgs(empty map) = %q, want empty", got)
+	}
+
+	a := DigestArgs(map[string]interface{}{"host": "example.com", "port": 22})
+	b := DigestArgs(map[string]interface{}{"host": "example.com", "port": 22})
+	if a != b {
+		t.Errorf("DigestArgs not stable across calls: %q != %q", a, b)
+	}
+	if len(a) != 16 {
+		t.Errorf("DigestArgs length = %d, want 16", len(a))
+	}
+
+	c := DigestArgs(map[string]interface{}{"host": "other.com", "port": 22})
+	if a == c {
+		t.Errorf("DigestArgs(%q) == DigestArgs(%q), want distinct digests", "example.com", "other.com")
+	}
+}
+
+func TestNopAuditLogger(t *testing.T) {
+	var logger AuditLogger = NopAuditLogger{}
+	logger.Log(Entry{Tool: "run"})
+	if err := logger.Close(); err != nil {
+		t.Errorf("NopAuditLogger.Close() = %v, want nil", err)
+	}
+}
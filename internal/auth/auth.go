@@ -0,0 +1,88 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// Authenticator verifies X-Session-Key-Id / X-Session-Key-Sig handshakes
+// against a KeyStore and derives a stable pool key for authenticated
+// sessions, modeled on how Gitea gates git-over-SSH by public-key fingerprint.
+type Authenticator struct {
+	keys       *KeyStore
+	nonces     *NonceStore
+	hmacSecret []byte
+}
+
+// NewAuthenticator creates an Authenticator that only admits keys from
+// keyStore, deriving pool keys with hmacSecret so the same fingerprint always
+// binds to the same pooled Manager.
+func NewAuthenticator(keyStore *KeyStore, hmacSecret []byte) *Authenticator {
+	return &Authenticator{
+		keys:       keyStore,
+		nonces:     NewNonceStore(),
+		hmacSecret: hmacSecret,
+	}
+}
+
+// IssueNonce generates a fresh nonce for fingerprint, rejecting unknown
+// fingerprints so a client can't probe for valid keys via the nonce endpoint.
+func (a *Authenticator) IssueNonce(fingerprint string) (string, error) {
+	if _, ok := a.keys.Lookup(fingerprint); !ok {
+		log.Printf("[Auth] Rejected nonce request: unknown fingerprint %s", fingerprint)
+		return "", errors.New("unknown fingerprint")
+	}
+	return a.nonces.Issue(fingerprint)
+}
+
+// Verify checks sigB64 as a signature by fingerprint's key over the nonce
+// most recently issued to it, consuming that nonce in the process. On
+// success it returns a pool key stable for this fingerprint so the same
+// client always reuses the same pooled Manager.
+func (a *Authenticator) Verify(fingerprint, sigB64 string) (poolKey string, err error) {
+	pubKey, ok := a.keys.Lookup(fingerprint)
+	if !ok {
+		log.Printf("[Auth] Rejected session: unknown fingerprint %s", fingerprint)
+		return "", errors.New("unknown fingerprint")
+	}
+
+	nonce, ok := a.nonces.Consume(fingerprint)
+	if !ok {
+		log.Printf("[Auth] Rejected session for %s: no outstanding nonce", fingerprint)
+		return "", errors.New("no outstanding nonce (request one from /mcp/auth/nonce)")
+	}
+
+	sigBytes, err := base64.StdEncoding.DecodeString(sigB64)
+	if err != nil {
+		log.Printf("[Auth] Rejected session for %s: malformed signature", fingerprint)
+		return "", fmt.Errorf("malformed signature: %w", err)
+	}
+
+	var sig ssh.Signature
+	if err := ssh.Unmarshal(sigBytes, &sig); err != nil {
+		log.Printf("[Auth] Rejected session for %s: malformed signature", fingerprint)
+		return "", fmt.Errorf("malformed signature: %w", err)
+	}
+
+	if err := pubKey.Verify([]byte(nonce), &sig); err != nil {
+		log.Printf("[Auth] Rejected session for %s: signature verification failed", fingerprint)
+		return "", fmt.Errorf("signature verification failed: %w", err)
+	}
+
+	return a.derivePoolKey(fingerprint), nil
+}
+
+// derivePoolKey maps a verified fingerprint to a stable pool key via HMAC, so
+// the raw fingerprint never doubles as the pool's lookup key.
+func (a *Authenticator) derivePoolKey(fingerprint string) string {
+	mac := hmac.New(sha256.New, a.hmacSecret)
+	mac.Write([]byte(fingerprint))
+	return hex.EncodeToString(mac.Sum(nil))
+}
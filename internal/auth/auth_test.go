@@ -0,0 +1,230 @@
+package auth
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// genSigner returns a freshly generated ed25519 SSH signer and the
+// authorized_keys-format line for its public half.
+func genSigner(t *testing.T) (ssh.Signer, string) {
+	t.Helper()
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("ed25519.GenerateKey: %v", err)
+	}
+	signer, err := ssh.NewSignerFromSigner(priv)
+	if err != nil {
+		t.Fatalf("ssh.NewSignerFromSigner: %v", err)
+	}
+	sshPub, err := ssh.NewPublicKey(pub)
+	if err != nil {
+		t.Fatalf("ssh.NewPublicKey: %v", err)
+	}
+	return signer, string(ssh.MarshalAuthorizedKey(sshPub))
+}
+
+// writeAuthorizedKeys writes lines to a temp authorized_keys file and
+// returns its path.
+func writeAuthorizedKeys(t *testing.T, lines ...string) string {
+	t.Helper()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "authorized_keys")
+	var contents string
+	for _, l := range lines {
+		contents += l
+	}
+	if err := os.WriteFile(path, []byte(contents), 0o600); err != nil {
+		t.Fatalf("failed to write authorized_keys: %v", err)
+	}
+	return path
+}
+
+func TestLoadKeyStoreRoundTrips(t *testing.T) {
+	_, authorizedLine := genSigner(t)
+	path := writeAuthorizedKeys(t, "# a comment\n", "\n", authorizedLine)
+
+	store, err := LoadKeyStore(path)
+	if err != nil {
+		t.Fatalf("LoadKeyStore: %v", err)
+	}
+
+	pubKey, _, _, _, err := ssh.ParseAuthorizedKey([]byte(authorizedLine))
+	if err != nil {
+		t.Fatalf("ssh.ParseAuthorizedKey: %v", err)
+	}
+	fingerprint := ssh.FingerprintSHA256(pubKey)
+
+	got, ok := store.Lookup(fingerprint)
+	if !ok {
+		t.Fatalf("Lookup(%s): key not found after round-trip", fingerprint)
+	}
+	if got.Type() != pubKey.Type() || string(got.Marshal()) != string(pubKey.Marshal()) {
+		t.Fatalf("Lookup returned a different key than was loaded")
+	}
+}
+
+func TestNonceStoreConsumeRejectsReuse(t *testing.T) {
+	store := NewNonceStore()
+	nonce, err := store.Issue("fp-1")
+	if err != nil {
+		t.Fatalf("Issue: %v", err)
+	}
+
+	got, ok := store.Consume("fp-1")
+	if !ok || got != nonce {
+		t.Fatalf("first Consume: got (%q, %v), want (%q, true)", got, ok, nonce)
+	}
+
+	if _, ok := store.Consume("fp-1"); ok {
+		t.Fatal("SECURITY FAIL: Consume accepted a replayed nonce")
+	}
+}
+
+func TestNonceStoreConsumeRejectsExpired(t *testing.T) {
+	store := NewNonceStore()
+	nonce, err := store.Issue("fp-1")
+	if err != nil {
+		t.Fatalf("Issue: %v", err)
+	}
+
+	store.mu.Lock()
+	store.entries["fp-1"] = nonceEntry{value: nonce, expires: time.Now().Add(-time.Second)}
+	store.mu.Unlock()
+
+	if _, ok := store.Consume("fp-1"); ok {
+		t.Fatal("SECURITY FAIL: Consume accepted a nonce past nonceTTL")
+	}
+}
+
+func TestAuthenticatorVerifyRejectsUnknownFingerprint(t *testing.T) {
+	a := NewAuthenticator(&KeyStore{}, []byte("secret"))
+	if _, err := a.Verify("deadbeef", "irrelevant"); err == nil {
+		t.Fatal("SECURITY FAIL: Verify accepted a fingerprint absent from the KeyStore")
+	}
+}
+
+func TestAuthenticatorVerifyRejectsSignatureOverWrongNonce(t *testing.T) {
+	signer, authorizedLine := genSigner(t)
+	path := writeAuthorizedKeys(t, authorizedLine)
+	store, err := LoadKeyStore(path)
+	if err != nil {
+		t.Fatalf("LoadKeyStore: %v", err)
+	}
+
+	pubKey, _, _, _, err := ssh.ParseAuthorizedKey([]byte(authorizedLine))
+	if err != nil {
+		t.Fatalf("ssh.ParseAuthorizedKey: %v", err)
+	}
+	fingerprint := ssh.FingerprintSHA256(pubKey)
+
+	a := NewAuthenticator(store, []byte("secret"))
+	if _, err := a.IssueNonce(fingerprint); err != nil {
+		t.Fatalf("IssueNonce: %v", err)
+	}
+
+	// Sign a nonce that was never issued, rather than the one Verify will
+	// consume, to simulate a replayed or forged signature.
+	sig, err := signer.Sign(rand.Reader, []byte("some-other-nonce"))
+	if err != nil {
+		t.Fatalf("Sign: %v", err)
+	}
+	sigB64 := sshSigToBase64(t, sig)
+
+	if _, err := a.Verify(fingerprint, sigB64); err == nil {
+		t.Fatal("SECURITY FAIL: Verify accepted a signature over the wrong nonce")
+	}
+}
+
+func TestAuthenticatorVerifyRejectsUnregisteredKey(t *testing.T) {
+	// A key that was never added to the KeyStore signing the nonce of a key
+	// that was - the fingerprint in the request must select whose key
+	// verifies the signature, not merely whether any known key would.
+	_, registeredLine := genSigner(t)
+	strangerSigner, _ := genSigner(t)
+
+	path := writeAuthorizedKeys(t, registeredLine)
+	store, err := LoadKeyStore(path)
+	if err != nil {
+		t.Fatalf("LoadKeyStore: %v", err)
+	}
+
+	pubKey, _, _, _, err := ssh.ParseAuthorizedKey([]byte(registeredLine))
+	if err != nil {
+		t.Fatalf("ssh.ParseAuthorizedKey: %v", err)
+	}
+	fingerprint := ssh.FingerprintSHA256(pubKey)
+
+	a := NewAuthenticator(store, []byte("secret"))
+	nonce, err := a.IssueNonce(fingerprint)
+	if err != nil {
+		t.Fatalf("IssueNonce: %v", err)
+	}
+
+	sig, err := strangerSigner.Sign(rand.Reader, []byte(nonce))
+	if err != nil {
+		t.Fatalf("Sign: %v", err)
+	}
+	sigB64 := sshSigToBase64(t, sig)
+
+	if _, err := a.Verify(fingerprint, sigB64); err == nil {
+		t.Fatal("SECURITY FAIL: Verify accepted a signature from a key not in the KeyStore")
+	}
+}
+
+func TestAuthenticatorVerifySucceedsAndDerivesStablePoolKey(t *testing.T) {
+	signer, authorizedLine := genSigner(t)
+	path := writeAuthorizedKeys(t, authorizedLine)
+	store, err := LoadKeyStore(path)
+	if err != nil {
+		t.Fatalf("LoadKeyStore: %v", err)
+	}
+
+	pubKey, _, _, _, err := ssh.ParseAuthorizedKey([]byte(authorizedLine))
+	if err != nil {
+		t.Fatalf("ssh.ParseAuthorizedKey: %v", err)
+	}
+	fingerprint := ssh.FingerprintSHA256(pubKey)
+
+	a := NewAuthenticator(store, []byte("secret"))
+	nonce, err := a.IssueNonce(fingerprint)
+	if err != nil {
+		t.Fatalf("IssueNonce: %v", err)
+	}
+
+	sig, err := signer.Sign(rand.Reader, []byte(nonce))
+	if err != nil {
+		t.Fatalf("Sign: %v", err)
+	}
+	sigB64 := sshSigToBase64(t, sig)
+
+	poolKey1, err := a.Verify(fingerprint, sigB64)
+	if err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	if poolKey1 == "" {
+		t.Fatal("Verify returned an empty pool key on success")
+	}
+
+	// The nonce is single-use, so re-verifying the same signature must fail.
+	if _, err := a.Verify(fingerprint, sigB64); err == nil {
+		t.Fatal("SECURITY FAIL: Verify accepted a replayed nonce/signature pair")
+	}
+
+	if poolKey2 := a.derivePoolKey(fingerprint); poolKey2 != poolKey1 {
+		t.Fatalf("derivePoolKey is not stable for the same fingerprint: %q != %q", poolKey1, poolKey2)
+	}
+}
+
+// sshSigToBase64 marshals sig the way Verify expects sigB64 to be encoded.
+func sshSigToBase64(t *testing.T, sig *ssh.Signature) string {
+	t.Helper()
+	return base64.StdEncoding.EncodeToString(ssh.Marshal(sig))
+}
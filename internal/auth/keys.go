@@ -0,0 +1,63 @@
+// Package auth implements authorized-keys-based authentication for the
+// X-Session-Key session binding used by the HTTP transport.
+package auth
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"sync"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// KeyStore holds the set of public keys allowed to bind a pool session,
+// loaded from an OpenSSH authorized_keys-format file and keyed by their
+// SHA256 fingerprint (the same format `ssh-keygen -lf` prints).
+type KeyStore struct {
+	mu   sync.RWMutex
+	keys map[string]ssh.PublicKey
+}
+
+// LoadKeyStore reads an authorized_keys-format file and returns a KeyStore
+// indexing each entry by its fingerprint. Blank lines and '#' comments are
+// skipped; malformed entries are rejected with the offending line number.
+func LoadKeyStore(path string) (*KeyStore, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open authorized keys file: %w", err)
+	}
+	defer f.Close()
+
+	store := &KeyStore{keys: make(map[string]ssh.PublicKey)}
+
+	scanner := bufio.NewScanner(f)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := scanner.Bytes()
+		if len(line) == 0 || line[0] == '#' {
+			continue
+		}
+
+		pubKey, _, _, _, err := ssh.ParseAuthorizedKey(line)
+		if err != nil {
+			return nil, fmt.Errorf("invalid authorized key on line %d: %w", lineNum, err)
+		}
+
+		store.keys[ssh.FingerprintSHA256(pubKey)] = pubKey
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read authorized keys file: %w", err)
+	}
+
+	return store, nil
+}
+
+// Lookup returns the public key registered under fingerprint, if any.
+func (s *KeyStore) Lookup(fingerprint string) (ssh.PublicKey, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	key, ok := s.keys[fingerprint]
+	return key, ok
+}
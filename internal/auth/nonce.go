@@ -0,0 +1,61 @@
+package auth
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// nonceTTL is how long an issued nonce remains valid for signing.
+const nonceTTL = 30 * time.Second
+
+// nonceEntry is a single outstanding nonce issued for a fingerprint.
+type nonceEntry struct {
+	value   string
+	expires time.Time
+}
+
+// NonceStore tracks one outstanding, single-use nonce per key fingerprint.
+// Issuing a new nonce for a fingerprint replaces any previous one.
+type NonceStore struct {
+	mu      sync.Mutex
+	entries map[string]nonceEntry
+}
+
+// NewNonceStore creates an empty NonceStore.
+func NewNonceStore() *NonceStore {
+	return &NonceStore{entries: make(map[string]nonceEntry)}
+}
+
+// Issue generates and stores a fresh nonce for fingerprint, replacing any
+// nonce previously issued for it.
+func (s *NonceStore) Issue(fingerprint string) (string, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	nonce := base64.RawURLEncoding.EncodeToString(raw)
+
+	s.mu.Lock()
+	s.entries[fingerprint] = nonceEntry{value: nonce, expires: time.Now().Add(nonceTTL)}
+	s.mu.Unlock()
+
+	return nonce, nil
+}
+
+// Consume returns the outstanding nonce for fingerprint and deletes it, so
+// each issued nonce can only be redeemed once. Returns false if no nonce is
+// outstanding or it has expired.
+func (s *NonceStore) Consume(fingerprint string) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.entries[fingerprint]
+	delete(s.entries, fingerprint)
+	if !ok || time.Now().After(entry.expires) {
+		return "", false
+	}
+	return entry.value, true
+}
@@ -0,0 +1,174 @@
+package container
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// containerdRuntime talks to containerd through nerdctl, which mirrors the
+// docker CLI closely enough to reuse the same command shapes. Raw ctr
+// lacks a logs/cp/network surface comparable to docker, so it isn't used
+// at all; detection and every operation require nerdctl.
+type containerdRuntime struct {
+	host      Host
+	namespace string
+}
+
+// defaultContainerdNamespace is where Kubernetes (via CRI) places
+// containers, and what `nerdctl -n k8s.io` / `ctr -n k8s.io` target.
+const defaultContainerdNamespace = "k8s.io"
+
+func newContainerdRuntime(ctx context.Context, host Host) (ContainerRuntime, error) {
+	out, err := host.Execute(ctx, "command -v nerdctl >/dev/null 2>&1 && echo ok || echo missing")
+	if err != nil {
+		return nil, err
+	}
+	if !strings.Contains(out, "ok") {
+		return nil, fmt.Errorf("nerdctl not found on target (ctr alone doesn't expose logs/cp/networks)")
+	}
+
+	return &containerdRuntime{host: host, namespace: defaultContainerdNamespace}, nil
+}
+
+func (r *containerdRuntime) Name() Runtime { return RuntimeContainerd }
+
+func (r *containerdRuntime) Close() error { return nil }
+
+func (r *containerdRuntime) nerdctl(args string) string {
+	return fmt.Sprintf("nerdctl -n %s %s", shellQuote(r.namespace), args)
+}
+
+func (r *containerdRuntime) List(ctx context.Context, all bool) ([]ContainerInfo, error) {
+	flag := ""
+	if all {
+		flag = "-a"
+	}
+	cmd := r.nerdctl(fmt.Sprintf("ps %s --format '{{.ID}}|{{.Image}}|{{.Status}}|{{.Names}}'", flag))
+	out, err := r.host.Execute(ctx, cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	var infos []ContainerInfo
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		if line == "" {
+			continue
+		}
+		fields := strings.SplitN(line, "|", 4)
+		if len(fields) != 4 {
+			continue
+		}
+		infos = append(infos, ContainerInfo{
+			ID:     fields[0],
+			Image:  fields[1],
+			Status: fields[2],
+			Names:  strings.Split(fields[3], ","),
+		})
+	}
+	return infos, nil
+}
+
+func (r *containerdRuntime) Logs(ctx context.Context, containerName string, lines int) (string, error) {
+	cmd := r.nerdctl(fmt.Sprintf("logs --tail %d %s 2>&1", lines, shellQuote(containerName)))
+	return r.host.Execute(ctx, cmd)
+}
+
+func (r *containerdRuntime) Inspect(ctx context.Context, containerName string) (map[string]string, error) {
+	cmd := r.nerdctl(fmt.Sprintf(`inspect --format '{{range $net, $conf := .NetworkSettings.Networks}}{{$net}}:{{$conf.IPAddress}}|{{end}}' %s 2>/dev/null`, shellQuote(containerName)))
+	out, err := r.host.Execute(ctx, cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	networks := make(map[string]string)
+	for _, pair := range strings.Split(strings.TrimSpace(out), "|") {
+		if pair == "" {
+			continue
+		}
+		parts := strings.SplitN(pair, ":", 2)
+		if len(parts) == 2 {
+			networks[parts[0]] = parts[1]
+		}
+	}
+	return networks, nil
+}
+
+func (r *containerdRuntime) Op(ctx context.Context, containerName, action string) error {
+	if action != "start" && action != "stop" && action != "restart" {
+		return fmt.Errorf("invalid action %q: use start, stop, restart", action)
+	}
+	cmd := r.nerdctl(fmt.Sprintf("%s %s 2>&1", shellQuote(action), shellQuote(containerName)))
+	_, err := r.host.Execute(ctx, cmd)
+	return err
+}
+
+func (r *containerdRuntime) CopyFrom(ctx context.Context, containerName, containerPath string) (string, error) {
+	// nerdctl cp writes to a path on the remote host; read it back over the
+	// same Host so the tools layer can push it on to wherever the caller
+	// actually wants it (which may not be this host at all).
+	tmp := fmt.Sprintf("/tmp/nerdctl-cp-%s", randSuffix())
+	cmd := r.nerdctl(fmt.Sprintf("cp %s:%s %s 2>&1", shellQuote(containerName), shellQuote(containerPath), shellQuote(tmp)))
+	if out, err := r.host.Execute(ctx, cmd); err != nil {
+		return "", fmt.Errorf("%w: %s", err, out)
+	}
+	defer r.host.Execute(ctx, "rm -f "+shellQuote(tmp))
+
+	return r.host.Execute(ctx, "cat "+shellQuote(tmp))
+}
+
+func (r *containerdRuntime) CopyTo(ctx context.Context, containerName, containerPath, content string) error {
+	tmp := fmt.Sprintf("/tmp/nerdctl-cp-%s", randSuffix())
+	// content is attacker-reachable (docker_cp_to's file content argument),
+	// so a fixed heredoc delimiter is unsafe: a line in content matching
+	// NERDCTL_CP_EOF would close the heredoc early and let the rest of
+	// content run as shell commands on the target. Base64-encode it instead
+	// so nothing but [A-Za-z0-9+/=] ever reaches the shell unquoted.
+	encoded := base64.StdEncoding.EncodeToString([]byte(content))
+	writeCmd := fmt.Sprintf("echo %s | base64 -d > %s", shellQuote(encoded), shellQuote(tmp))
+	if _, err := r.host.Execute(ctx, writeCmd); err != nil {
+		return err
+	}
+	defer r.host.Execute(ctx, "rm -f "+shellQuote(tmp))
+
+	cmd := r.nerdctl(fmt.Sprintf("cp %s %s:%s 2>&1", shellQuote(tmp), shellQuote(containerName), shellQuote(containerPath)))
+	out, err := r.host.Execute(ctx, cmd)
+	if err != nil {
+		return fmt.Errorf("%w: %s", err, out)
+	}
+	return nil
+}
+
+func (r *containerdRuntime) Networks(ctx context.Context) ([]NetworkInfo, error) {
+	cmd := r.nerdctl("network ls --format '{{.Name}}|{{.Driver}}'")
+	out, err := r.host.Execute(ctx, cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	var infos []NetworkInfo
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		if line == "" {
+			continue
+		}
+		fields := strings.SplitN(line, "|", 2)
+		if len(fields) != 2 {
+			continue
+		}
+		infos = append(infos, NetworkInfo{Name: fields[0], Driver: fields[1]})
+	}
+	return infos, nil
+}
+
+func (r *containerdRuntime) FindByIP(ctx context.Context, ip string) (*ContainerInfo, string, error) {
+	return findByIP(ctx, r, ip)
+}
+
+// randSuffix returns a short non-cryptographic suffix for scratch /tmp
+// paths; a wall-clock nanosecond timestamp is good enough here.
+func randSuffix() string {
+	return strconv.FormatInt(time.Now().UnixNano(), 36)
+}
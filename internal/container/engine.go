@@ -0,0 +1,183 @@
+package container
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+
+	intdocker "ssh-mcp/internal/docker"
+
+	"github.com/docker/docker/api/types"
+	dockercontainer "github.com/docker/docker/api/types/container"
+	dockerclient "github.com/docker/docker/client"
+	"github.com/docker/docker/pkg/stdcopy"
+)
+
+// podmanSocketPath is Podman's default rootful docker-compatible socket.
+// Rootless Podman instead listens on a per-user path
+// (/run/user/<uid>/podman/podman.sock), which this constant doesn't cover;
+// the podman backend only probes this rootful path.
+const podmanSocketPath = "/run/podman/podman.sock"
+
+// engineRuntime implements ContainerRuntime against any Docker-Engine-API-
+// compatible socket. Docker and Podman both speak this API, so one
+// implementation covers both backends - only the socket path and the
+// reported Name differ.
+type engineRuntime struct {
+	name Runtime
+	cli  *dockerclient.Client
+}
+
+func newEngineRuntime(ctx context.Context, host Host, name Runtime, socketPath string) (*engineRuntime, error) {
+	if err := intdocker.ProbeSocket(ctx, host, socketPath); err != nil {
+		return nil, err
+	}
+	cli, err := intdocker.NewClientWithSocket(host, socketPath)
+	if err != nil {
+		return nil, err
+	}
+	return &engineRuntime{name: name, cli: cli}, nil
+}
+
+func newDockerRuntime(ctx context.Context, host Host) (ContainerRuntime, error) {
+	return newEngineRuntime(ctx, host, RuntimeDocker, intdocker.SocketPath)
+}
+
+func newPodmanRuntime(ctx context.Context, host Host) (ContainerRuntime, error) {
+	return newEngineRuntime(ctx, host, RuntimePodman, podmanSocketPath)
+}
+
+func (r *engineRuntime) Name() Runtime { return r.name }
+
+func (r *engineRuntime) Close() error { return r.cli.Close() }
+
+func (r *engineRuntime) List(ctx context.Context, all bool) ([]ContainerInfo, error) {
+	containers, err := r.cli.ContainerList(ctx, types.ContainerListOptions{All: all})
+	if err != nil {
+		return nil, err
+	}
+
+	infos := make([]ContainerInfo, 0, len(containers))
+	for _, c := range containers {
+		infos = append(infos, ContainerInfo{
+			ID:     shortID(c.ID),
+			Image:  c.Image,
+			Status: c.Status,
+			Names:  c.Names,
+		})
+	}
+	return infos, nil
+}
+
+func (r *engineRuntime) Logs(ctx context.Context, containerName string, lines int) (string, error) {
+	reader, err := r.cli.ContainerLogs(ctx, containerName, types.ContainerLogsOptions{
+		ShowStdout: true,
+		ShowStderr: true,
+		Tail:       strconv.Itoa(lines),
+	})
+	if err != nil {
+		return "", err
+	}
+	defer reader.Close()
+
+	var stdout, stderr bytes.Buffer
+	if _, err := stdcopy.StdCopy(&stdout, &stderr, reader); err != nil {
+		return "", err
+	}
+	return stdout.String() + stderr.String(), nil
+}
+
+func (r *engineRuntime) Inspect(ctx context.Context, containerName string) (map[string]string, error) {
+	info, err := r.cli.ContainerInspect(ctx, containerName)
+	if err != nil {
+		return nil, err
+	}
+
+	networks := make(map[string]string, len(info.NetworkSettings.Networks))
+	for name, ep := range info.NetworkSettings.Networks {
+		networks[name] = ep.IPAddress
+	}
+	return networks, nil
+}
+
+func (r *engineRuntime) Op(ctx context.Context, containerName, action string) error {
+	switch action {
+	case "start":
+		return r.cli.ContainerStart(ctx, containerName, types.ContainerStartOptions{})
+	case "stop":
+		return r.cli.ContainerStop(ctx, containerName, dockercontainer.StopOptions{})
+	case "restart":
+		return r.cli.ContainerRestart(ctx, containerName, dockercontainer.StopOptions{})
+	default:
+		return fmt.Errorf("invalid action %q: use start, stop, restart", action)
+	}
+}
+
+func (r *engineRuntime) CopyFrom(ctx context.Context, containerName, containerPath string) (string, error) {
+	reader, _, err := r.cli.CopyFromContainer(ctx, containerName, containerPath)
+	if err != nil {
+		return "", err
+	}
+	defer reader.Close()
+
+	tr := tar.NewReader(reader)
+	if _, err := tr.Next(); err != nil {
+		return "", fmt.Errorf("reading %s:%s: %w", containerName, containerPath, err)
+	}
+	content, err := io.ReadAll(tr)
+	if err != nil {
+		return "", err
+	}
+	return string(content), nil
+}
+
+func (r *engineRuntime) CopyTo(ctx context.Context, containerName, containerPath, content string) error {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	hdr := &tar.Header{Name: path.Base(containerPath), Mode: 0644, Size: int64(len(content))}
+	if err := tw.WriteHeader(hdr); err != nil {
+		return err
+	}
+	if _, err := tw.Write([]byte(content)); err != nil {
+		return err
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+
+	return r.cli.CopyToContainer(ctx, containerName, path.Dir(containerPath), &buf, types.CopyToContainerOptions{})
+}
+
+func (r *engineRuntime) Networks(ctx context.Context) ([]NetworkInfo, error) {
+	networks, err := r.cli.NetworkList(ctx, types.NetworkListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	infos := make([]NetworkInfo, 0, len(networks))
+	for _, n := range networks {
+		names := make([]string, 0, len(n.Containers))
+		for _, ep := range n.Containers {
+			names = append(names, ep.Name)
+		}
+		infos = append(infos, NetworkInfo{Name: n.Name, Driver: n.Driver, Containers: names})
+	}
+	return infos, nil
+}
+
+func (r *engineRuntime) FindByIP(ctx context.Context, ip string) (*ContainerInfo, string, error) {
+	return findByIP(ctx, r, ip)
+}
+
+// shortID truncates a container ID to the 12-character form `docker ps`
+// displays.
+func shortID(id string) string {
+	if len(id) > 12 {
+		return id[:12]
+	}
+	return id
+}
@@ -0,0 +1,128 @@
+// Package container abstracts over container engines (Docker, containerd,
+// Podman) behind a single ContainerRuntime interface, so the MCP tool
+// handlers don't special-case each one. Many production hosts today only
+// expose containerd (Kubernetes nodes) or Podman (RHEL/Fedora), not Docker,
+// so a Docker-only path silently fails on them.
+package container
+
+import (
+	"context"
+	"fmt"
+	"net"
+)
+
+// Runtime identifies which container engine a backend talks to.
+type Runtime string
+
+const (
+	RuntimeDocker     Runtime = "docker"
+	RuntimeContainerd Runtime = "containerd"
+	RuntimePodman     Runtime = "podman"
+)
+
+// ContainerInfo mirrors the subset of container metadata every backend can
+// report, normalized across Docker, containerd, and Podman.
+type ContainerInfo struct {
+	ID     string
+	Image  string
+	Status string
+	Names  []string
+}
+
+// NetworkInfo describes a container network and its attached containers.
+type NetworkInfo struct {
+	Name       string
+	Driver     string
+	Containers []string
+}
+
+// Host is what a ContainerRuntime backend needs from the remote connection:
+// running a shell command, and dialing a local socket on the remote side.
+// The tools layer adapts an ssh.Manager+target pair to this, so this
+// package never needs to import internal/ssh.
+type Host interface {
+	Execute(ctx context.Context, cmd string) (string, error)
+	DialRemote(network, addr string) (net.Conn, error)
+}
+
+// ContainerRuntime is implemented once per backend (Docker, containerd,
+// Podman) so the docker_*/container_* tools can dispatch through a common
+// interface instead of special-casing each engine.
+type ContainerRuntime interface {
+	// Name identifies the backend for error messages and the "runtime"
+	// field in tool output.
+	Name() Runtime
+
+	List(ctx context.Context, all bool) ([]ContainerInfo, error)
+	Logs(ctx context.Context, containerName string, lines int) (string, error)
+	// Inspect returns the container's networks, keyed by network name with
+	// the container's IP address on that network as the value.
+	Inspect(ctx context.Context, containerName string) (map[string]string, error)
+	Op(ctx context.Context, containerName, action string) error
+	// CopyFrom returns the contents of a single file at containerPath.
+	CopyFrom(ctx context.Context, containerName, containerPath string) (string, error)
+	// CopyTo writes content to containerPath inside the container.
+	CopyTo(ctx context.Context, containerName, containerPath, content string) error
+	Networks(ctx context.Context) ([]NetworkInfo, error)
+	// FindByIP returns the container and network name with the given IP,
+	// or an error if none is found.
+	FindByIP(ctx context.Context, ip string) (*ContainerInfo, string, error)
+
+	// Close releases any cached connection (e.g. the Engine API client's
+	// HTTP transport); shell-only backends no-op it.
+	Close() error
+}
+
+// Resolve picks a ContainerRuntime backend for host. If preferred is empty,
+// it auto-detects in order: Docker, Podman, containerd (both Engine-API
+// backends are cheap to probe; containerd's ctr/nerdctl based backend is
+// checked last since it only confirms a CLI is on PATH, not that it works).
+func Resolve(ctx context.Context, host Host, preferred Runtime) (ContainerRuntime, error) {
+	switch preferred {
+	case RuntimeDocker:
+		return newDockerRuntime(ctx, host)
+	case RuntimePodman:
+		return newPodmanRuntime(ctx, host)
+	case RuntimeContainerd:
+		return newContainerdRuntime(ctx, host)
+	case "":
+		// fall through to auto-detect below
+	default:
+		return nil, fmt.Errorf("unknown runtime %q: use docker, containerd, or podman", preferred)
+	}
+
+	if rt, err := newDockerRuntime(ctx, host); err == nil {
+		return rt, nil
+	}
+	if rt, err := newPodmanRuntime(ctx, host); err == nil {
+		return rt, nil
+	}
+	if rt, err := newContainerdRuntime(ctx, host); err == nil {
+		return rt, nil
+	}
+	return nil, fmt.Errorf("no supported container runtime (docker, podman, containerd) found on target")
+}
+
+// findByIP is the shared FindByIP implementation: every backend lists then
+// inspects, so there's no reason to repeat it per engine.
+func findByIP(ctx context.Context, rt ContainerRuntime, ip string) (*ContainerInfo, string, error) {
+	containers, err := rt.List(ctx, true)
+	if err != nil {
+		return nil, "", err
+	}
+
+	for i := range containers {
+		c := &containers[i]
+		networks, err := rt.Inspect(ctx, c.ID)
+		if err != nil {
+			continue
+		}
+		for netName, addr := range networks {
+			if addr == ip {
+				return c, netName, nil
+			}
+		}
+	}
+
+	return nil, "", fmt.Errorf("no container found with IP: %s", ip)
+}
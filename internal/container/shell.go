@@ -0,0 +1,14 @@
+package container
+
+import "strings"
+
+// shellQuote quotes a string for safe shell use in the containerd backend's
+// nerdctl commands (mirrors internal/tools' copy; kept local so this
+// package doesn't depend on internal/tools).
+func shellQuote(s string) string {
+	if s == "" {
+		return "''"
+	}
+	escaped := strings.ReplaceAll(s, "'", "'\"'\"'")
+	return "'" + escaped + "'"
+}
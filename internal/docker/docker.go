@@ -0,0 +1,79 @@
+// Package docker talks to a remote host's Docker Engine API over its UNIX
+// socket, tunneled through an existing SSH connection instead of shelling
+// out to the docker CLI. This avoids locale/format-dependent stdout
+// scraping and gives callers typed errors and structured data.
+//
+// The same Docker Engine API is also what Podman's docker-compatible socket
+// speaks, so internal/container reuses NewClientWithSocket/ProbeSocket
+// against a different socket path rather than duplicating this transport.
+package docker
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/docker/docker/client"
+)
+
+// SocketPath is the default location of the Docker daemon's UNIX socket on
+// the remote host.
+const SocketPath = "/var/run/docker.sock"
+
+// Dialer opens a connection to the remote host on behalf of this package.
+// ssh.Client satisfies this via its existing DialRemote method, so this
+// package never needs to import internal/ssh.
+type Dialer interface {
+	DialRemote(network, addr string) (net.Conn, error)
+}
+
+// NewClient builds a Docker Engine API client whose transport tunnels every
+// request through d to SocketPath, as if the socket were local.
+func NewClient(d Dialer) (*client.Client, error) {
+	return NewClientWithSocket(d, SocketPath)
+}
+
+// NewClientWithSocket is NewClient against an arbitrary UNIX socket path,
+// for Docker-Engine-API-compatible daemons that don't live at SocketPath
+// (e.g. Podman's docker-compatible socket).
+func NewClientWithSocket(d Dialer, socketPath string) (*client.Client, error) {
+	httpClient := &http.Client{
+		Transport: &http.Transport{
+			DialContext: func(_ context.Context, _, _ string) (net.Conn, error) {
+				return d.DialRemote("unix", socketPath)
+			},
+		},
+	}
+
+	return client.NewClientWithOpts(
+		client.WithHTTPClient(httpClient),
+		client.WithHost("unix://"+socketPath),
+		client.WithAPIVersionNegotiation(),
+	)
+}
+
+// Probe reports whether the Docker socket is reachable and answering, so
+// callers can fall back to a shell path when it isn't (socket missing, or
+// the connected user lacks group membership to read/write it).
+func Probe(ctx context.Context, d Dialer) error {
+	return ProbeSocket(ctx, d, SocketPath)
+}
+
+// ProbeSocket is Probe against an arbitrary UNIX socket path.
+func ProbeSocket(ctx context.Context, d Dialer, socketPath string) error {
+	cli, err := NewClientWithSocket(d, socketPath)
+	if err != nil {
+		return err
+	}
+	defer cli.Close()
+
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	if _, err := cli.Ping(ctx); err != nil {
+		return fmt.Errorf("socket %s unreachable: %w", socketPath, err)
+	}
+	return nil
+}
@@ -0,0 +1,142 @@
+// Package hep encodes captured SIP messages as HEP v3 ("EEP") packets and
+// ships them to a Homer/HEPIC collector, so a capture produced by this
+// server can be ingested by tooling teams already run instead of requiring
+// a re-capture at the SBC.
+package hep
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net"
+	"time"
+)
+
+// Chunk type IDs from the HEP v3 wire format (as implemented by
+// sipcapture/Homer's HEPv3 decoder).
+const (
+	chunkIPFamily  = 0x0001
+	chunkIPProtoID = 0x0002
+	chunkIPv4Src   = 0x0003
+	chunkIPv4Dst   = 0x0004
+	chunkIPv6Src   = 0x0005
+	chunkIPv6Dst   = 0x0006
+	chunkSrcPort   = 0x0007
+	chunkDstPort   = 0x0008
+	chunkTimeSec   = 0x0009
+	chunkTimeUsec  = 0x000a
+	chunkProtoType = 0x000b
+	chunkCaptureID = 0x000c
+	chunkAuthKey   = 0x000e
+	chunkPayload   = 0x000f
+)
+
+const (
+	ipFamilyIPv4 = 2
+	ipFamilyIPv6 = 10
+
+	ipProtoUDP = 17
+	ipProtoTCP = 6
+
+	// ProtoTypeSIP is the HEP "protocol type" chunk value for SIP, the only
+	// payload type this package emits.
+	ProtoTypeSIP = 1
+)
+
+// Packet is everything needed to assemble one HEP v3 frame from a captured
+// SIP message: its 5-tuple, capture time, and raw bytes.
+type Packet struct {
+	SrcIP     string
+	SrcPort   int
+	DstIP     string
+	DstPort   int
+	Transport string // "udp" or "tcp"; anything else is treated as udp
+	Timestamp time.Time
+	CaptureID uint32
+	AuthKey   string
+	Payload   []byte
+}
+
+// Encode assembles p into a HEP v3 byte frame: a 6-byte outer header
+// ("HEP3" magic + total length, big-endian) followed by the chunks above,
+// each framed as vendor(2)|type(2)|length(2)|data.
+func Encode(p Packet) ([]byte, error) {
+	srcIP := net.ParseIP(p.SrcIP)
+	dstIP := net.ParseIP(p.DstIP)
+	if srcIP == nil || dstIP == nil {
+		return nil, fmt.Errorf("hep: invalid IP address (src=%q dst=%q)", p.SrcIP, p.DstIP)
+	}
+
+	var body []byte
+	if src4, dst4 := srcIP.To4(), dstIP.To4(); src4 != nil && dst4 != nil {
+		body = appendChunk(body, chunkIPFamily, []byte{ipFamilyIPv4})
+		body = appendChunk(body, chunkIPv4Src, src4)
+		body = appendChunk(body, chunkIPv4Dst, dst4)
+	} else {
+		body = appendChunk(body, chunkIPFamily, []byte{ipFamilyIPv6})
+		body = appendChunk(body, chunkIPv6Src, srcIP.To16())
+		body = appendChunk(body, chunkIPv6Dst, dstIP.To16())
+	}
+
+	ipProto := byte(ipProtoUDP)
+	if p.Transport == "tcp" {
+		ipProto = ipProtoTCP
+	}
+	body = appendChunk(body, chunkIPProtoID, []byte{ipProto})
+	body = appendChunk(body, chunkSrcPort, uint16Bytes(uint16(p.SrcPort)))
+	body = appendChunk(body, chunkDstPort, uint16Bytes(uint16(p.DstPort)))
+	body = appendChunk(body, chunkTimeSec, uint32Bytes(uint32(p.Timestamp.Unix())))
+	body = appendChunk(body, chunkTimeUsec, uint32Bytes(uint32(p.Timestamp.Nanosecond()/1000)))
+	body = appendChunk(body, chunkProtoType, []byte{ProtoTypeSIP})
+	body = appendChunk(body, chunkCaptureID, uint32Bytes(p.CaptureID))
+	if p.AuthKey != "" {
+		body = appendChunk(body, chunkAuthKey, []byte(p.AuthKey))
+	}
+	body = appendChunk(body, chunkPayload, p.Payload)
+
+	total := 6 + len(body)
+	frame := make([]byte, 6, total)
+	copy(frame[0:4], "HEP3")
+	binary.BigEndian.PutUint16(frame[4:6], uint16(total))
+	return append(frame, body...), nil
+}
+
+// appendChunk frames data as vendor(2)=0 (generic, non-vendor-specific
+// chunks) | type(2) | length(2)=6+len(data) | data.
+func appendChunk(dst []byte, chunkType uint16, data []byte) []byte {
+	header := make([]byte, 6)
+	binary.BigEndian.PutUint16(header[2:4], chunkType)
+	binary.BigEndian.PutUint16(header[4:6], uint16(6+len(data)))
+	dst = append(dst, header...)
+	return append(dst, data...)
+}
+
+func uint16Bytes(v uint16) []byte {
+	b := make([]byte, 2)
+	binary.BigEndian.PutUint16(b, v)
+	return b
+}
+
+func uint32Bytes(v uint32) []byte {
+	b := make([]byte, 4)
+	binary.BigEndian.PutUint32(b, v)
+	return b
+}
+
+// Send dials collector ("host:port") over network ("udp" or "tcp") and
+// writes frame, the transport HEP agents normally use to ship captures to
+// a Homer/HEPIC collector.
+func Send(network, collector string, frame []byte) error {
+	if network == "" {
+		network = "udp"
+	}
+	conn, err := net.Dial(network, collector)
+	if err != nil {
+		return fmt.Errorf("hep: dial %s %s: %w", network, collector, err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.Write(frame); err != nil {
+		return fmt.Errorf("hep: write to %s: %w", collector, err)
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package hep
+
+import (
+	"encoding/binary"
+	"testing"
+	"time"
+)
+
+func TestEncodeRoundTripsHeaderAndChunks(t *testing.T) {
+	frame, err := Encode(Packet{
+		SrcIP:     "10.0.0.1",
+		SrcPort:   5060,
+		DstIP:     "10.0.0.2",
+		DstPort:   5060,
+		Transport: "udp",
+		Timestamp: time.Unix(1700000000, 123000),
+		CaptureID: 42,
+		AuthKey:   "secret",
+		Payload:   []byte("INVITE sip:bob@example.com SIP/2.0\r\n"),
+	})
+	if err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+
+	if string(frame[0:4]) != "HEP3" {
+		t.Fatalf("magic = %q, want HEP3", frame[0:4])
+	}
+	total := binary.BigEndian.Uint16(frame[4:6])
+	if int(total) != len(frame) {
+		t.Fatalf("header length = %d, want %d (actual frame length)", total, len(frame))
+	}
+
+	if !containsChunk(t, frame[6:], chunkPayload, []byte("INVITE sip:bob@example.com SIP/2.0\r\n")) {
+		t.Error("payload chunk not found or content mismatch")
+	}
+	if !containsChunk(t, frame[6:], chunkAuthKey, []byte("secret")) {
+		t.Error("auth key chunk not found or content mismatch")
+	}
+}
+
+func TestEncodeRejectsInvalidIP(t *testing.T) {
+	_, err := Encode(Packet{SrcIP: "not-an-ip", DstIP: "10.0.0.2", Payload: []byte("x")})
+	if err == nil {
+		t.Fatal("expected an error for an invalid source IP")
+	}
+}
+
+// containsChunk scans a flat run of vendor(2)|type(2)|length(2)|data chunks
+// for one matching chunkType, asserting its data equals want.
+func containsChunk(t *testing.T, body []byte, chunkType uint16, want []byte) bool {
+	t.Helper()
+	for len(body) >= 6 {
+		typ := binary.BigEndian.Uint16(body[2:4])
+		length := int(binary.BigEndian.Uint16(body[4:6]))
+		if length < 6 || length > len(body) {
+			t.Fatalf("corrupt chunk length %d", length)
+		}
+		data := body[6:length]
+		if typ == chunkType {
+			return string(data) == string(want)
+		}
+		body = body[length:]
+	}
+	return false
+}
@@ -0,0 +1,94 @@
+// Package metrics defines the Prometheus collectors exposed by the SSH MCP
+// server under /metrics, and small helpers for subsystems that don't want a
+// direct dependency on the prometheus client beyond incrementing a counter.
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	// ActiveConnections is the number of currently open SSH connections
+	// across every Manager in the process.
+	ActiveConnections = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "sshmcp_active_connections",
+		Help: "Number of currently active SSH connections.",
+	})
+
+	// CommandsTotal counts tool invocations, labeled by tool name, target
+	// alias, and outcome.
+	CommandsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "sshmcp_commands_total",
+		Help: "Total number of tool invocations.",
+	}, []string{"tool", "alias", "exit_code"})
+
+	// BytesTransferredTotal counts bytes moved over SSH connections, labeled
+	// by alias and direction ("in" or "out").
+	BytesTransferredTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "sshmcp_bytes_transferred_total",
+		Help: "Total bytes transferred over SSH connections.",
+	}, []string{"alias", "direction"})
+
+	// ReconnectsTotal counts reconnect attempts triggered by a connection
+	// error, labeled by alias.
+	ReconnectsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "sshmcp_reconnects_total",
+		Help: "Total number of SSH reconnect attempts.",
+	}, []string{"alias"})
+
+	// PoolManagers is the number of active pooled Managers, labeled by pool
+	// type ("header" or "session").
+	PoolManagers = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "sshmcp_pool_managers",
+		Help: "Number of active pooled SSH Managers.",
+	}, []string{"type"})
+
+	// PoolSessionsTotal counts every Manager ever created by the pool,
+	// labeled by pool type ("header" or "session"). Unlike PoolManagers this
+	// never decreases, so it tracks churn as well as steady-state size.
+	PoolSessionsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "sshmcp_pool_sessions_total",
+		Help: "Total number of pooled SSH Managers ever created.",
+	}, []string{"type"})
+
+	// PoolEvictionsTotal counts LRU evictions performed to keep the pool
+	// within its configured capacity, labeled by pool type.
+	PoolEvictionsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "sshmcp_pool_evictions_total",
+		Help: "Total number of pooled SSH Managers evicted to stay within capacity.",
+	}, []string{"type"})
+
+	// PoolActiveRequests is the number of in-flight tool calls currently
+	// holding a pooled Manager, labeled by pool type.
+	PoolActiveRequests = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "sshmcp_pool_active_requests",
+		Help: "Number of in-flight requests currently using a pooled SSH Manager.",
+	}, []string{"type"})
+
+	// PoolDialDuration observes how long establishing a new SSH connection
+	// takes, labeled by target host, so slow/unreachable hosts stand out.
+	PoolDialDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "sshmcp_pool_dial_duration_seconds",
+		Help:    "Time to establish a new SSH connection, labeled by target host.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"host"})
+
+	// PoolPanicsTotal counts panics fed through Pool.RecordPanic, both those
+	// recovered from a Pool.Do call and those recovered by the server's tool
+	// handler middleware, so an operation panicking (e.g. a bad SFTP op)
+	// shows up as a metric instead of only a log line.
+	PoolPanicsTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "sshmcp_pool_panics_total",
+		Help: "Total number of panics recovered from Pool.Do calls and tool handlers.",
+	})
+)
+
+// AddBytes records n bytes transferred for alias in the given direction
+// ("in" or "out"). No-op if n <= 0.
+func AddBytes(alias, direction string, n int) {
+	if n <= 0 {
+		return
+	}
+	BytesTransferredTotal.WithLabelValues(alias, direction).Add(float64(n))
+}
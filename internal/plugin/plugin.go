@@ -0,0 +1,76 @@
+// Package plugin defines the out-of-process plugin protocol for the
+// validate and edit tools. Plugins are standalone binaries dropped into a
+// plugins/ directory; each is launched as a subprocess over
+// hashicorp/go-plugin's net/rpc transport (chosen over go-plugin's gRPC
+// transport here since the Validator/EditOp surface is small, synchronous,
+// and needs no streaming — net/rpc keeps a plugin to a single file with no
+// protoc step). A crash or hang in a plugin only takes down that
+// subprocess, never the MCP server.
+package plugin
+
+import (
+	hashiplugin "github.com/hashicorp/go-plugin"
+)
+
+// Handshake is the stable handshake both the host and every plugin binary
+// must present before a connection is established. ProtocolVersion must be
+// bumped on any breaking change to the Validator/EditOp RPC wire format;
+// a plugin built against a mismatched version is rejected during launch.
+var Handshake = hashiplugin.HandshakeConfig{
+	ProtocolVersion:  1,
+	MagicCookieKey:   "SSH_MCP_PLUGIN",
+	MagicCookieValue: "ssh-mcp-plugin-v1",
+}
+
+// ValidationResult mirrors tools.ValidationResult. It's redeclared here
+// rather than imported so plugin binaries (and this package) never need to
+// depend on the tools package, which would pull the whole MCP server into
+// every plugin build.
+type ValidationResult struct {
+	Valid    bool
+	FileType string
+	Errors   []string
+}
+
+// Validator is implemented by a plugin that recognizes and checks the
+// syntax of one or more file types not handled by the built-in validators
+// in tools/validate.go.
+type Validator interface {
+	// Detect reports whether this validator handles path, based on its
+	// extension, basename, or any other signal the plugin chooses.
+	Detect(path string) (bool, error)
+	// FileType returns the type name reported in ValidationResult and shown
+	// to the caller (e.g. "nginx-conf", "hcl").
+	FileType() (string, error)
+	// Validate checks content and returns the outcome.
+	Validate(content string) (ValidationResult, error)
+}
+
+// HostOps is the narrow callback surface the host exposes back to an EditOp
+// plugin over go-plugin's MuxBroker, so a plugin can act against the live
+// SSH session without ever holding a reference to the in-process
+// *ssh.Manager.
+type HostOps interface {
+	ReadFile(path, target string) (string, error)
+	WriteFile(path, content, target string) error
+	Execute(cmd, target string) (string, error)
+}
+
+// EditOp is implemented by a plugin that adds a new named operation to the
+// edit tool's "operation" switch.
+type EditOp interface {
+	// Name is the operation name passed as edit's "operation" parameter.
+	Name() (string, error)
+	// Apply runs the operation against path on target using host to reach
+	// the remote file, and returns the edit tool's result text.
+	Apply(path, target string, params map[string]string, host HostOps) (string, error)
+}
+
+// Plugins is the go-plugin plugin map every launched plugin process is
+// served with. A plugin binary registers itself under one or both keys
+// depending on which interface(s) it implements; RegisterAll dispenses
+// whichever of the two are present.
+var Plugins = map[string]hashiplugin.Plugin{
+	"validator": &ValidatorPlugin{},
+	"edit_op":   &EditOpPlugin{},
+}
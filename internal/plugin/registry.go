@@ -0,0 +1,230 @@
+package plugin
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+
+	hashiplugin "github.com/hashicorp/go-plugin"
+)
+
+const (
+	// pluginsProductionDir is the Docker/production location plugin
+	// binaries are discovered from, mirroring ssh.ProductionKeyPath's
+	// production-vs-local split.
+	pluginsProductionDir = "/data/plugins"
+	// pluginsDevDir is the local development location.
+	pluginsDevDir = "./plugins"
+)
+
+// defaultPluginsDir returns the appropriate plugins directory for the
+// current environment: /data/plugins when /data exists (production/Docker),
+// ./plugins otherwise.
+func defaultPluginsDir() string {
+	if stat, err := os.Stat("/data"); err == nil && stat.IsDir() {
+		return pluginsProductionDir
+	}
+	return pluginsDevDir
+}
+
+// registeredValidator pairs a dispensed Validator with the go-plugin client
+// that launched it, so the registry can Kill every subprocess on Close.
+type registeredValidator struct {
+	validator Validator
+	client    *hashiplugin.Client
+}
+
+type registeredEditOp struct {
+	op     EditOp
+	name   string
+	client *hashiplugin.Client
+}
+
+// Registry holds every validator and edit operation discovered from plugin
+// binaries at startup. A nil *Registry (or one with nothing registered)
+// behaves as an empty registry — callers don't need a separate has-plugins
+// check.
+type Registry struct {
+	mu         sync.RWMutex
+	validators []registeredValidator
+	editOps    map[string]registeredEditOp
+}
+
+// defaultRegistry is populated by RegisterAll during server startup and
+// consulted by detectFileType / ValidateContent / the edit tool's operation
+// switch before they fall back to the Go-native built-ins.
+var defaultRegistry = &Registry{editOps: make(map[string]registeredEditOp)}
+
+// Default returns the process-wide plugin registry.
+func Default() *Registry {
+	return defaultRegistry
+}
+
+// RegisterAll discovers every executable in dir (non-recursive, matching
+// the repo's other "drop a file in a directory" conventions like
+// diagnoseRuleStore's JSON file), launches each as a go-plugin subprocess,
+// and registers whichever of Validator / EditOp it dispenses. A plugin that
+// fails to launch or doesn't implement either interface is logged and
+// skipped rather than aborting startup — a bad plugin shouldn't take down
+// the server. If dir is "", the environment-appropriate default
+// (/data/plugins or ./plugins) is used.
+func RegisterAll(dir string) (*Registry, error) {
+	if dir == "" {
+		dir = defaultPluginsDir()
+	}
+
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return defaultRegistry, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read plugins directory %q: %w", dir, err)
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil || info.Mode()&0o111 == 0 {
+			continue
+		}
+
+		path := filepath.Join(dir, entry.Name())
+		if err := defaultRegistry.load(path); err != nil {
+			log.Printf("[Plugin] failed to load %q, skipping: %v", path, err)
+		}
+	}
+
+	return defaultRegistry, nil
+}
+
+// load launches the plugin binary at path and registers whatever interfaces
+// it dispenses.
+func (r *Registry) load(path string) error {
+	client := hashiplugin.NewClient(&hashiplugin.ClientConfig{
+		HandshakeConfig: Handshake,
+		Plugins:         Plugins,
+		Cmd:             exec.Command(path),
+	})
+
+	rpcClient, err := client.Client()
+	if err != nil {
+		client.Kill()
+		return fmt.Errorf("failed to start plugin client: %w", err)
+	}
+
+	registered := false
+
+	if raw, err := rpcClient.Dispense("validator"); err == nil {
+		if v, ok := raw.(Validator); ok {
+			r.mu.Lock()
+			r.validators = append(r.validators, registeredValidator{validator: v, client: client})
+			r.mu.Unlock()
+			registered = true
+			log.Printf("[Plugin] registered validator from %q", path)
+		}
+	}
+
+	if raw, err := rpcClient.Dispense("edit_op"); err == nil {
+		if op, ok := raw.(EditOp); ok {
+			name, err := op.Name()
+			if err != nil || name == "" {
+				log.Printf("[Plugin] %q dispensed an edit_op with no name, skipping: %v", path, err)
+			} else {
+				r.mu.Lock()
+				r.editOps[name] = registeredEditOp{op: op, name: name, client: client}
+				r.mu.Unlock()
+				registered = true
+				log.Printf("[Plugin] registered edit operation %q from %q", name, path)
+			}
+		}
+	}
+
+	if !registered {
+		client.Kill()
+		return fmt.Errorf("plugin dispensed neither a validator nor an edit_op")
+	}
+
+	return nil
+}
+
+// DetectFileType returns the FileType reported by the first registered
+// validator whose Detect matches path, or "" if none match.
+func (r *Registry) DetectFileType(path string) string {
+	if r == nil {
+		return ""
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, rv := range r.validators {
+		ok, err := rv.validator.Detect(path)
+		if err != nil || !ok {
+			continue
+		}
+		ft, err := rv.validator.FileType()
+		if err != nil || ft == "" {
+			continue
+		}
+		return ft
+	}
+	return ""
+}
+
+// Validate runs content through the first registered validator that
+// reports the given fileType, or returns nil if none do.
+func (r *Registry) Validate(content, fileType string) *ValidationResult {
+	if r == nil {
+		return nil
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, rv := range r.validators {
+		ft, err := rv.validator.FileType()
+		if err != nil || ft != fileType {
+			continue
+		}
+		result, err := rv.validator.Validate(content)
+		if err != nil {
+			return &ValidationResult{Valid: false, FileType: fileType, Errors: []string{err.Error()}}
+		}
+		return &result
+	}
+	return nil
+}
+
+// EditOp returns the registered edit operation named name, or nil if none
+// is registered under that name.
+func (r *Registry) EditOp(name string) EditOp {
+	if r == nil {
+		return nil
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if reg, ok := r.editOps[name]; ok {
+		return reg.op
+	}
+	return nil
+}
+
+// Close kills every plugin subprocess this registry launched.
+func (r *Registry) Close() {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, rv := range r.validators {
+		rv.client.Kill()
+	}
+	for _, reg := range r.editOps {
+		reg.client.Kill()
+	}
+}
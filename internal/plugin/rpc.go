@@ -0,0 +1,202 @@
+package plugin
+
+import (
+	"net/rpc"
+
+	hashiplugin "github.com/hashicorp/go-plugin"
+)
+
+// ValidatorPlugin adapts a Validator implementation to go-plugin's net/rpc
+// transport. Impl is only set on the plugin side: a plugin binary sets it to
+// its own Validator and calls hashiplugin.Serve. The host never sets Impl — it
+// only uses ValidatorPlugin.Client to dispense a validatorRPCClient.
+type ValidatorPlugin struct {
+	Impl Validator
+}
+
+func (p *ValidatorPlugin) Server(*hashiplugin.MuxBroker) (interface{}, error) {
+	return &validatorRPCServer{impl: p.Impl}, nil
+}
+
+func (p *ValidatorPlugin) Client(_ *hashiplugin.MuxBroker, c *rpc.Client) (interface{}, error) {
+	return &validatorRPCClient{client: c}, nil
+}
+
+type validatorRPCServer struct {
+	impl Validator
+}
+
+func (s *validatorRPCServer) Detect(path string, resp *bool) error {
+	ok, err := s.impl.Detect(path)
+	*resp = ok
+	return err
+}
+
+func (s *validatorRPCServer) FileType(_ struct{}, resp *string) error {
+	ft, err := s.impl.FileType()
+	*resp = ft
+	return err
+}
+
+func (s *validatorRPCServer) Validate(content string, resp *ValidationResult) error {
+	result, err := s.impl.Validate(content)
+	*resp = result
+	return err
+}
+
+// validatorRPCClient is the host-side stub dispensed by ValidatorPlugin.Client.
+// It satisfies Validator by forwarding every call across the rpc.Client to
+// the plugin subprocess's validatorRPCServer.
+type validatorRPCClient struct {
+	client *rpc.Client
+}
+
+func (c *validatorRPCClient) Detect(path string) (bool, error) {
+	var resp bool
+	err := c.client.Call("Plugin.Detect", path, &resp)
+	return resp, err
+}
+
+func (c *validatorRPCClient) FileType() (string, error) {
+	var resp string
+	err := c.client.Call("Plugin.FileType", struct{}{}, &resp)
+	return resp, err
+}
+
+func (c *validatorRPCClient) Validate(content string) (ValidationResult, error) {
+	var resp ValidationResult
+	err := c.client.Call("Plugin.Validate", content, &resp)
+	return resp, err
+}
+
+// EditOpPlugin adapts an EditOp implementation to go-plugin's net/rpc
+// transport. See ValidatorPlugin for the Impl convention.
+type EditOpPlugin struct {
+	Impl EditOp
+}
+
+func (p *EditOpPlugin) Server(b *hashiplugin.MuxBroker) (interface{}, error) {
+	return &editOpRPCServer{impl: p.Impl, broker: b}, nil
+}
+
+func (p *EditOpPlugin) Client(b *hashiplugin.MuxBroker, c *rpc.Client) (interface{}, error) {
+	return &editOpRPCClient{client: c, broker: b}, nil
+}
+
+type editOpRPCServer struct {
+	impl   EditOp
+	broker *hashiplugin.MuxBroker
+}
+
+func (s *editOpRPCServer) Name(_ struct{}, resp *string) error {
+	name, err := s.impl.Name()
+	*resp = name
+	return err
+}
+
+// editOpApplyArgs is the request envelope for editOpRPCServer.Apply. HostID
+// names the broker stream the plugin must dial to reach a hostOpsRPCClient
+// wrapping the host's HostOps for the duration of this one call.
+type editOpApplyArgs struct {
+	Path   string
+	Target string
+	Params map[string]string
+	HostID uint32
+}
+
+func (s *editOpRPCServer) Apply(args editOpApplyArgs, resp *string) error {
+	conn, err := s.broker.Dial(args.HostID)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	host := &hostOpsRPCClient{client: rpc.NewClient(conn)}
+	out, err := s.impl.Apply(args.Path, args.Target, args.Params, host)
+	*resp = out
+	return err
+}
+
+// editOpRPCClient is the host-side stub dispensed by EditOpPlugin.Client. It
+// satisfies EditOp, and additionally accepts the HostOps the plugin should
+// call back into for this operation.
+type editOpRPCClient struct {
+	client *rpc.Client
+	broker *hashiplugin.MuxBroker
+}
+
+func (c *editOpRPCClient) Name() (string, error) {
+	var resp string
+	err := c.client.Call("Plugin.Name", struct{}{}, &resp)
+	return resp, err
+}
+
+func (c *editOpRPCClient) Apply(path, target string, params map[string]string, host HostOps) (string, error) {
+	hostID := c.broker.NextId()
+	go c.broker.AcceptAndServe(hostID, &hostOpsRPCServer{impl: host})
+
+	var resp string
+	err := c.client.Call("Plugin.Apply", editOpApplyArgs{
+		Path: path, Target: target, Params: params, HostID: hostID,
+	}, &resp)
+	return resp, err
+}
+
+// hostOpsExecuteArgs/hostOpsWriteArgs are the request envelopes for the
+// HostOps callback methods; unlike Detect/Name they take more than one
+// string argument so each needs its own struct.
+type hostOpsExecuteArgs struct {
+	Cmd, Target string
+}
+
+type hostOpsReadArgs struct {
+	Path, Target string
+}
+
+type hostOpsWriteArgs struct {
+	Path, Content, Target string
+}
+
+// hostOpsRPCServer runs in the host process and is what the plugin's
+// hostOpsRPCClient calls back into, over the broker connection Apply set up.
+type hostOpsRPCServer struct {
+	impl HostOps
+}
+
+func (s *hostOpsRPCServer) ReadFile(args hostOpsReadArgs, resp *string) error {
+	content, err := s.impl.ReadFile(args.Path, args.Target)
+	*resp = content
+	return err
+}
+
+func (s *hostOpsRPCServer) WriteFile(args hostOpsWriteArgs, _ *struct{}) error {
+	return s.impl.WriteFile(args.Path, args.Content, args.Target)
+}
+
+func (s *hostOpsRPCServer) Execute(args hostOpsExecuteArgs, resp *string) error {
+	output, err := s.impl.Execute(args.Cmd, args.Target)
+	*resp = output
+	return err
+}
+
+// hostOpsRPCClient runs in the plugin process and satisfies HostOps by
+// calling back across the broker connection to the host's hostOpsRPCServer.
+type hostOpsRPCClient struct {
+	client *rpc.Client
+}
+
+func (c *hostOpsRPCClient) ReadFile(path, target string) (string, error) {
+	var resp string
+	err := c.client.Call("Plugin.ReadFile", hostOpsReadArgs{Path: path, Target: target}, &resp)
+	return resp, err
+}
+
+func (c *hostOpsRPCClient) WriteFile(path, content, target string) error {
+	return c.client.Call("Plugin.WriteFile", hostOpsWriteArgs{Path: path, Content: content, Target: target}, &struct{}{})
+}
+
+func (c *hostOpsRPCClient) Execute(cmd, target string) (string, error) {
+	var resp string
+	err := c.client.Call("Plugin.Execute", hostOpsExecuteArgs{Cmd: cmd, Target: target}, &resp)
+	return resp, err
+}
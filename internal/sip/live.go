@@ -0,0 +1,213 @@
+package sip
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/pcap"
+)
+
+// defaultBPFFilter captures both UDP and TCP SIP signaling on the standard port.
+const defaultBPFFilter = "udp port 5060 or tcp port 5060"
+
+// defaultSnapLen is large enough to capture a full SIP message (with SDP)
+// without truncation in the vast majority of deployments.
+const defaultSnapLen = 65536
+
+// defaultBufferSize is the channel capacity used for Messages()/Calls(),
+// bounding how far a slow consumer can fall behind before LiveCapture starts
+// applying backpressure to the packet read loop.
+const defaultBufferSize = 256
+
+// Option configures a LiveCapture.
+type Option func(*captureConfig)
+
+type captureConfig struct {
+	snapLen    int
+	promisc    bool
+	bufferSize int
+}
+
+// WithSnapLen sets the per-packet capture length (default 65536, enough for
+// a full SIP message with SDP).
+func WithSnapLen(n int) Option {
+	return func(c *captureConfig) { c.snapLen = n }
+}
+
+// WithPromiscuous enables or disables promiscuous mode (default: true).
+func WithPromiscuous(promisc bool) Option {
+	return func(c *captureConfig) { c.promisc = promisc }
+}
+
+// WithBufferSize sets the capacity of the Messages()/Calls() channels
+// (default 256), which bounds how much backpressure a slow consumer applies
+// before LiveCapture's read loop blocks.
+func WithBufferSize(n int) Option {
+	return func(c *captureConfig) { c.bufferSize = n }
+}
+
+// LiveCapture opens a network device and emits parsed SIP messages and call
+// dialog updates as they're captured, for continuous monitoring of a live
+// SIP trunk (as opposed to ParsePCAPBase64/ParsePCAPBytes, which require a
+// fully-buffered PCAP).
+type LiveCapture struct {
+	handle *pcap.Handle
+
+	messages chan Message
+	calls    chan Call
+
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	mu      sync.Mutex
+	dialogs map[string][]Message
+}
+
+// NewLiveCapture opens iface for live SIP capture, applying filter as a BPF
+// expression (default "udp port 5060 or tcp port 5060" if empty). Capture
+// runs in a background goroutine until Close is called; parsed messages and
+// dialog updates are delivered on the channels returned by Messages/Calls.
+func NewLiveCapture(iface string, filter string, opts ...Option) (*LiveCapture, error) {
+	cfg := captureConfig{
+		snapLen:    defaultSnapLen,
+		promisc:    true,
+		bufferSize: defaultBufferSize,
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	if filter == "" {
+		filter = defaultBPFFilter
+	}
+
+	handle, err := pcap.OpenLive(iface, int32(cfg.snapLen), cfg.promisc, pcap.BlockForever)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open interface %s: %w", iface, err)
+	}
+
+	if err := handle.SetBPFFilter(filter); err != nil {
+		handle.Close()
+		return nil, fmt.Errorf("invalid BPF filter %q: %w", filter, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	lc := &LiveCapture{
+		handle:   handle,
+		messages: make(chan Message, cfg.bufferSize),
+		calls:    make(chan Call, cfg.bufferSize),
+		cancel:   cancel,
+		done:     make(chan struct{}),
+		dialogs:  make(map[string][]Message),
+	}
+
+	go lc.run(ctx)
+
+	return lc, nil
+}
+
+// Messages returns the channel of parsed SIP messages as they're captured.
+// Closed once the capture stops.
+func (lc *LiveCapture) Messages() <-chan Message {
+	return lc.messages
+}
+
+// Calls returns the channel of Call summaries, emitted whenever a tracked
+// dialog changes state (a new Call-ID is seen, or it reaches a final
+// response). REGISTER dialogs are excluded, matching ParsePCAPBytes. Closed
+// once the capture stops.
+func (lc *LiveCapture) Calls() <-chan Call {
+	return lc.calls
+}
+
+// Close stops the capture and releases the underlying pcap handle, blocking
+// until the read loop has exited and both channels are closed.
+func (lc *LiveCapture) Close() error {
+	lc.cancel()
+	<-lc.done
+	lc.handle.Close()
+	return nil
+}
+
+// run reads packets from the handle until ctx is canceled or the handle is
+// exhausted/closed, parsing each one with the same extractSIPFromPacket path
+// used by the batch PCAP reader.
+func (lc *LiveCapture) run(ctx context.Context) {
+	defer close(lc.done)
+	defer close(lc.messages)
+	defer close(lc.calls)
+
+	source := gopacket.NewPacketSource(lc.handle, lc.handle.LinkType())
+	packets := source.Packets()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case packet, ok := <-packets:
+			if !ok {
+				return
+			}
+
+			msg := extractSIPFromPacket(packet, packet.Metadata().Timestamp)
+			if msg == nil {
+				continue
+			}
+
+			// Blocking sends apply backpressure to the read loop when a
+			// consumer falls behind, rather than dropping messages.
+			select {
+			case lc.messages <- *msg:
+			case <-ctx.Done():
+				return
+			}
+
+			if call, ok := lc.trackDialog(*msg); ok {
+				select {
+				case lc.calls <- call:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}
+}
+
+// trackDialog folds msg into its Call-ID's running message list and returns
+// an updated Call summary when the dialog changes state (msg is its first
+// message, or its final response), or ok=false for any other message, for
+// REGISTER dialogs, and for messages without a Call-ID (mirroring
+// aggregateCalls). Dialogs are forgotten once
+// they reach a final (non-1xx) response, so a long-running capture doesn't
+// grow memory unbounded.
+func (lc *LiveCapture) trackDialog(msg Message) (Call, bool) {
+	if msg.CallID == "" {
+		return Call{}, false
+	}
+
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+
+	lc.dialogs[msg.CallID] = append(lc.dialogs[msg.CallID], msg)
+	msgs := lc.dialogs[msg.CallID]
+
+	if isRegisterDialog(msgs) {
+		delete(lc.dialogs, msg.CallID)
+		return Call{}, false
+	}
+
+	call := buildCall(msg.CallID, msgs)
+
+	final := call.FinalStatus == "success" || call.FinalStatus == "failed"
+	if final {
+		delete(lc.dialogs, msg.CallID)
+	}
+	if len(msgs) > 1 && !final {
+		return Call{}, false
+	}
+
+	return call, true
+}
@@ -0,0 +1,96 @@
+package sip
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MGCPMessage is a decoded MGCP command or response (RFC 3435), commonly
+// seen alongside SIP on gateways that speak both toward a media gateway
+// controller.
+type MGCPMessage struct {
+	Timestamp     time.Time `json:"timestamp"`
+	SrcIP         string    `json:"src_ip"`
+	SrcPort       int       `json:"src_port"`
+	DstIP         string    `json:"dst_ip"`
+	DstPort       int       `json:"dst_port"`
+	Type          string    `json:"type"` // "command" or "response"
+	Verb          string    `json:"verb,omitempty"`
+	TransactionID string    `json:"transaction_id"`
+	Endpoint      string    `json:"endpoint,omitempty"`
+	ResponseCode  int       `json:"response_code,omitempty"`
+	Comment       string    `json:"comment,omitempty"`
+}
+
+// mgcpVerbs are the command verbs defined by RFC 3435 section 2.3.
+var mgcpVerbs = []string{
+	"CRCX", "MDCX", "DLCX", "RQNT", "NTFY", "AUEP", "AUCX", "EPCF", "RSIP",
+}
+
+type mgcpParser struct{}
+
+func (mgcpParser) Match(payload []byte) bool {
+	line := firstLine(payload)
+	if line == "" {
+		return false
+	}
+	for _, verb := range mgcpVerbs {
+		if strings.HasPrefix(line, verb+" ") {
+			return true
+		}
+	}
+	// Responses are "<3-digit code> <transaction-id> <comment>".
+	parts := strings.Fields(line)
+	if len(parts) >= 2 {
+		if _, err := strconv.Atoi(parts[0]); err == nil && len(parts[0]) == 3 {
+			return true
+		}
+	}
+	return false
+}
+
+func (mgcpParser) Parse(payload []byte, ts time.Time, flow FiveTuple) (any, error) {
+	line := firstLine(payload)
+	parts := strings.Fields(line)
+	if len(parts) < 2 {
+		return nil, fmt.Errorf("mgcp: malformed start line %q", line)
+	}
+
+	msg := &MGCPMessage{
+		Timestamp: ts,
+		SrcIP:     flow.SrcIP,
+		SrcPort:   flow.SrcPort,
+		DstIP:     flow.DstIP,
+		DstPort:   flow.DstPort,
+	}
+
+	if code, err := strconv.Atoi(parts[0]); err == nil && len(parts[0]) == 3 {
+		msg.Type = "response"
+		msg.ResponseCode = code
+		msg.TransactionID = parts[1]
+		if len(parts) > 2 {
+			msg.Comment = strings.Join(parts[2:], " ")
+		}
+		return msg, nil
+	}
+
+	msg.Type = "command"
+	msg.Verb = parts[0]
+	msg.TransactionID = parts[1]
+	if len(parts) > 2 {
+		msg.Endpoint = parts[2]
+	}
+	return msg, nil
+}
+
+// firstLine returns the first line of a text-based payload, trimmed of
+// surrounding whitespace ("" if the payload starts with a line break).
+func firstLine(payload []byte) string {
+	text := string(payload)
+	if idx := strings.IndexAny(text, "\r\n"); idx != -1 {
+		text = text[:idx]
+	}
+	return strings.TrimSpace(text)
+}
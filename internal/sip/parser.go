@@ -19,34 +19,55 @@ import (
 
 // Message represents a parsed SIP message.
 type Message struct {
-	Timestamp   time.Time `json:"timestamp"`
-	Time        string    `json:"time"`
-	SrcIP       string    `json:"src_ip"`
-	SrcPort     int       `json:"src_port"`
-	DstIP       string    `json:"dst_ip"`
-	DstPort     int       `json:"dst_port"`
-	Transport   string    `json:"transport"`
-	Type        string    `json:"type"` // "request" or "response"
-	Method      string    `json:"method,omitempty"`
-	StatusCode  int       `json:"status_code,omitempty"`
-	Reason      string    `json:"reason,omitempty"`
-	CallID      string    `json:"call_id"`
-	FromUser    string    `json:"from_user,omitempty"`
-	ToUser      string    `json:"to_user,omitempty"`
-	FromURI     string    `json:"from_uri,omitempty"`
-	ToURI       string    `json:"to_uri,omitempty"`
-	CSeqNumber  int       `json:"cseq_number,omitempty"`
-	CSeqMethod  string    `json:"cseq_method,omitempty"`
-	Contact     string    `json:"contact,omitempty"`
-	ContentType string    `json:"content_type,omitempty"`
-	HasSDP      bool      `json:"has_sdp"`
-	SDP         *SDP      `json:"sdp,omitempty"`
+	Timestamp  time.Time `json:"timestamp"`
+	Time       string    `json:"time"`
+	SrcIP      string    `json:"src_ip"`
+	SrcPort    int       `json:"src_port"`
+	DstIP      string    `json:"dst_ip"`
+	DstPort    int       `json:"dst_port"`
+	Transport  string    `json:"transport"`
+	Type       string    `json:"type"` // "request" or "response"
+	Method     string    `json:"method,omitempty"`
+	StatusCode int       `json:"status_code,omitempty"`
+	Reason     string    `json:"reason,omitempty"`
+	CallID     string    `json:"call_id"`
+	FromUser   string    `json:"from_user,omitempty"`
+	ToUser     string    `json:"to_user,omitempty"`
+	FromURI    string    `json:"from_uri,omitempty"`
+	ToURI      string    `json:"to_uri,omitempty"`
+	FromTag    string    `json:"from_tag,omitempty"`
+	ToTag      string    `json:"to_tag,omitempty"`
+	// XCID and PChargingVector carry a call identifier some B2BUAs preserve
+	// across legs even when they rewrite Call-ID, letting cross-leg
+	// correlation fall back to them when Call-ID alone doesn't match.
+	XCID            string `json:"x_cid,omitempty"`
+	PChargingVector string `json:"p_charging_vector,omitempty"`
+	CSeqNumber      int    `json:"cseq_number,omitempty"`
+	CSeqMethod      string `json:"cseq_method,omitempty"`
+	Contact         string `json:"contact,omitempty"`
+	ContentType     string `json:"content_type,omitempty"`
+	HasSDP          bool   `json:"has_sdp"`
+	SDP             *SDP   `json:"sdp,omitempty"`
+	// RawPayload holds the original SIP message bytes (start line, headers,
+	// and body) as captured on the wire. Excluded from JSON since it
+	// duplicates the parsed fields above; kept for callers that need to
+	// re-emit the exact message, e.g. HEP export.
+	RawPayload []byte `json:"-"`
 }
 
 // SDP represents parsed SDP content.
 type SDP struct {
-	ConnectionAddr string       `json:"connection_addr,omitempty"`
-	Media          []MediaEntry `json:"media,omitempty"`
+	ConnectionAddr string `json:"connection_addr,omitempty"`
+	// SessionID is the session-id token from the "o=" origin line. A B2BUA
+	// that rewrites Call-ID on each leg commonly preserves the original
+	// SDP offer's o= line unchanged, so this can correlate legs Call-ID
+	// can't.
+	SessionID string       `json:"session_id,omitempty"`
+	Media     []MediaEntry `json:"media,omitempty"`
+	// RTPMap maps RTP payload type to codec name, from "a=rtpmap:<pt> <codec>"
+	// lines. Used to label MediaStream.Codec by the payload type actually
+	// observed on the wire, rather than guessing from Media[].Codecs.
+	RTPMap map[int]string `json:"rtpmap,omitempty"`
 }
 
 // MediaEntry represents an SDP media line.
@@ -72,6 +93,9 @@ type Call struct {
 	FinalStatus  string    `json:"final_status"`
 	ErrorCode    int       `json:"error_code,omitempty"`
 	Messages     []Message `json:"messages,omitempty"`
+	// MediaStreams holds per-RTP-stream QoS stats whose SDP-advertised
+	// endpoint matched an observed RTP flow, populated by computeMediaStreams.
+	MediaStreams []MediaStream `json:"media_streams,omitempty"`
 }
 
 // Registration represents a SIP REGISTER dialog.
@@ -102,7 +126,16 @@ type ParseResult struct {
 	Calls         []Call         `json:"calls"`
 	Registrations []Registration `json:"registrations"`
 	Stats         Stats          `json:"stats"`
-	Error         string         `json:"error,omitempty"`
+	// Flows holds results from the other registered ProtocolParsers (RTP,
+	// RTCP, MGCP, STUN, ...), keyed by parser name, as []any of whatever
+	// type that parser's Parse method returns. SIP itself is reflected in
+	// Messages/Calls/Registrations above, not duplicated here.
+	Flows map[string]any `json:"flows,omitempty"`
+	// MediaStreams holds RTP streams that couldn't be matched to any Call's
+	// SDP-advertised endpoint (e.g. the offer wasn't captured). Matched
+	// streams are attached to their owning Call instead.
+	MediaStreams []MediaStream `json:"media_streams,omitempty"`
+	Error        string        `json:"error,omitempty"`
 }
 
 // SIP methods that identify a SIP request.
@@ -177,12 +210,17 @@ func ParsePCAPBytes(data []byte) (*ParseResult, error) {
 		if msg != nil {
 			result.Messages = append(result.Messages, *msg)
 		}
+
+		if payload, flow, ok := packetFlow(packet); ok {
+			result.addFlow(payload, ci.Timestamp, flow)
+		}
 	}
 
 	// Aggregate into calls and registrations
 	result.aggregateCalls()
 	result.aggregateRegistrations()
 	result.computeStats()
+	result.computeMediaStreams()
 
 	return result, nil
 }
@@ -225,55 +263,58 @@ func parseFromStrings(data []byte) *ParseResult {
 
 // extractSIPFromPacket extracts SIP message from a network packet.
 func extractSIPFromPacket(packet gopacket.Packet, ts time.Time) *Message {
-	// Get IP layer
+	payload, flow, ok := packetFlow(packet)
+	if !ok || !IsSIPPayload(payload) {
+		return nil
+	}
+
+	return parseSIPMessage(payload, ts, flow.SrcIP, flow.DstIP, flow.SrcPort, flow.DstPort, flow.Transport)
+}
+
+// packetFlow extracts the transport-layer payload and five-tuple from an IP
+// packet, shared by every ProtocolParser-driven path (extractSIPFromPacket,
+// ParseResult.addFlow) so they see identical framing.
+func packetFlow(packet gopacket.Packet) (payload []byte, flow FiveTuple, ok bool) {
 	ipLayer := packet.Layer(layers.LayerTypeIPv4)
 	if ipLayer == nil {
 		ipLayer = packet.Layer(layers.LayerTypeIPv6)
 	}
 	if ipLayer == nil {
-		return nil
+		return nil, FiveTuple{}, false
 	}
 
-	var srcIP, dstIP string
 	if ip4, ok := ipLayer.(*layers.IPv4); ok {
-		srcIP = ip4.SrcIP.String()
-		dstIP = ip4.DstIP.String()
+		flow.SrcIP = ip4.SrcIP.String()
+		flow.DstIP = ip4.DstIP.String()
 	} else if ip6, ok := ipLayer.(*layers.IPv6); ok {
-		srcIP = ip6.SrcIP.String()
-		dstIP = ip6.DstIP.String()
+		flow.SrcIP = ip6.SrcIP.String()
+		flow.DstIP = ip6.DstIP.String()
 	}
 
-	var srcPort, dstPort int
-	var transport string
-	var payload []byte
-
-	// Check UDP
 	if udpLayer := packet.Layer(layers.LayerTypeUDP); udpLayer != nil {
 		udp := udpLayer.(*layers.UDP)
-		srcPort = int(udp.SrcPort)
-		dstPort = int(udp.DstPort)
-		transport = "udp"
+		flow.SrcPort = int(udp.SrcPort)
+		flow.DstPort = int(udp.DstPort)
+		flow.Transport = "udp"
 		if appLayer := packet.ApplicationLayer(); appLayer != nil {
 			payload = appLayer.Payload()
 		}
 	}
 
-	// Check TCP
 	if tcpLayer := packet.Layer(layers.LayerTypeTCP); tcpLayer != nil {
 		tcp := tcpLayer.(*layers.TCP)
-		srcPort = int(tcp.SrcPort)
-		dstPort = int(tcp.DstPort)
-		transport = "tcp"
+		flow.SrcPort = int(tcp.SrcPort)
+		flow.DstPort = int(tcp.DstPort)
+		flow.Transport = "tcp"
 		if appLayer := packet.ApplicationLayer(); appLayer != nil {
 			payload = appLayer.Payload()
 		}
 	}
 
-	if payload == nil || !IsSIPPayload(payload) {
-		return nil
+	if payload == nil {
+		return nil, FiveTuple{}, false
 	}
-
-	return parseSIPMessage(payload, ts, srcIP, dstIP, srcPort, dstPort, transport)
+	return payload, flow, true
 }
 
 // parseSIPMessage parses raw SIP message bytes.
@@ -290,13 +331,14 @@ func parseSIPMessage(data []byte, ts time.Time, srcIP, dstIP string, srcPort, ds
 	}
 
 	msg := &Message{
-		Timestamp: ts,
-		Time:      ts.UTC().Format(time.RFC3339),
-		SrcIP:     srcIP,
-		SrcPort:   srcPort,
-		DstIP:     dstIP,
-		DstPort:   dstPort,
-		Transport: transport,
+		Timestamp:  ts,
+		Time:       ts.UTC().Format(time.RFC3339),
+		SrcIP:      srcIP,
+		SrcPort:    srcPort,
+		DstIP:      dstIP,
+		DstPort:    dstPort,
+		Transport:  transport,
+		RawPayload: data,
 	}
 
 	// Parse headers
@@ -312,6 +354,10 @@ func parseSIPMessage(data []byte, ts time.Time, srcIP, dstIP string, srcPort, ds
 	msg.ToURI = extractSIPURI(toHeader)
 	msg.FromUser = extractUserFromURI(msg.FromURI)
 	msg.ToUser = extractUserFromURI(msg.ToURI)
+	msg.FromTag = extractTag(fromHeader)
+	msg.ToTag = extractTag(toHeader)
+	msg.XCID = getHeader(headers, "x-cid")
+	msg.PChargingVector = getHeader(headers, "p-charging-vector")
 
 	// Parse CSeq
 	cseq := getHeader(headers, "cseq")
@@ -395,6 +441,21 @@ func extractSIPURI(value string) string {
 	return match
 }
 
+// extractTag extracts the "tag" parameter from a From/To header value, used
+// to tell apart dialogs that share a Call-ID (e.g. forked or re-INVITEd
+// legs) and, combined with Call-ID, to identify one side of a dialog.
+func extractTag(value string) string {
+	if value == "" {
+		return ""
+	}
+	re := regexp.MustCompile(`;\s*tag=([^;\s]+)`)
+	match := re.FindStringSubmatch(strings.ToLower(value))
+	if match == nil {
+		return ""
+	}
+	return match[1]
+}
+
 // extractUserFromURI extracts user part from SIP URI.
 func extractUserFromURI(uri string) string {
 	if uri == "" {
@@ -423,7 +484,12 @@ func parseSDP(body string) *SDP {
 
 	for _, line := range lines {
 		line = strings.TrimSpace(line)
-		if strings.HasPrefix(line, "c=") {
+		if strings.HasPrefix(line, "o=") {
+			parts := strings.Fields(line[2:])
+			if len(parts) >= 2 {
+				sdp.SessionID = parts[1]
+			}
+		} else if strings.HasPrefix(line, "c=") {
 			parts := strings.Fields(line[2:])
 			if len(parts) >= 3 {
 				sdp.ConnectionAddr = parts[2]
@@ -446,6 +512,12 @@ func parseSDP(body string) *SDP {
 				payloadType := value[:spaceIdx]
 				codec := value[spaceIdx+1:]
 				rtpmap[payloadType] = codec
+				if pt, err := strconv.Atoi(payloadType); err == nil {
+					if sdp.RTPMap == nil {
+						sdp.RTPMap = make(map[int]string)
+					}
+					sdp.RTPMap[pt] = codec
+				}
 			}
 		} else if line == "a=sendrecv" || line == "a=sendonly" || line == "a=recvonly" {
 			if len(sdp.Media) > 0 {
@@ -475,64 +547,73 @@ func (r *ParseResult) aggregateCalls() {
 	}
 
 	for callID, msgs := range callMap {
-		// Skip REGISTER dialogs  
-		isRegister := false
-		for _, m := range msgs {
-			if m.Method == "REGISTER" || m.CSeqMethod == "REGISTER" {
-				isRegister = true
-				break
-			}
-		}
-		if isRegister {
+		if isRegisterDialog(msgs) {
 			continue
 		}
+		r.Calls = append(r.Calls, buildCall(callID, msgs))
+	}
+}
 
-		call := Call{
-			CallID:       callID,
-			MessageCount: len(msgs),
-			Messages:     msgs,
+// isRegisterDialog reports whether msgs belong to a REGISTER dialog, which
+// aggregateCalls excludes from Calls (see aggregateRegistrations instead).
+func isRegisterDialog(msgs []Message) bool {
+	for _, m := range msgs {
+		if m.Method == "REGISTER" || m.CSeqMethod == "REGISTER" {
+			return true
 		}
+	}
+	return false
+}
 
-		if len(msgs) > 0 {
-			call.StartTime = msgs[0].Time
-			call.EndTime = msgs[len(msgs)-1].Time
-			for _, m := range msgs {
-				if call.FromUser == "" && m.FromUser != "" {
-					call.FromUser = m.FromUser
-				}
-				if call.ToUser == "" && m.ToUser != "" {
-					call.ToUser = m.ToUser
-				}
-				if call.FromURI == "" && m.FromURI != "" {
-					call.FromURI = m.FromURI
-				}
-				if call.ToURI == "" && m.ToURI != "" {
-					call.ToURI = m.ToURI
-				}
-				if m.HasSDP {
-					call.HasSDP = true
-				}
-			}
-		}
+// buildCall folds a Call-ID's messages into a Call summary: participants,
+// SDP presence, and final status. Shared by the batch PCAP path
+// (aggregateCalls) and LiveCapture's incremental dialog tracking.
+func buildCall(callID string, msgs []Message) Call {
+	call := Call{
+		CallID:       callID,
+		MessageCount: len(msgs),
+		Messages:     msgs,
+	}
 
-		// Determine final status
-		var finalCode int
+	if len(msgs) > 0 {
+		call.StartTime = msgs[0].Time
+		call.EndTime = msgs[len(msgs)-1].Time
 		for _, m := range msgs {
-			if m.Type == "response" && m.StatusCode >= 200 {
-				finalCode = m.StatusCode
+			if call.FromUser == "" && m.FromUser != "" {
+				call.FromUser = m.FromUser
+			}
+			if call.ToUser == "" && m.ToUser != "" {
+				call.ToUser = m.ToUser
+			}
+			if call.FromURI == "" && m.FromURI != "" {
+				call.FromURI = m.FromURI
+			}
+			if call.ToURI == "" && m.ToURI != "" {
+				call.ToURI = m.ToURI
+			}
+			if m.HasSDP {
+				call.HasSDP = true
 			}
 		}
-		if finalCode >= 200 && finalCode < 300 {
-			call.FinalStatus = "success"
-		} else if finalCode >= 300 {
-			call.FinalStatus = "failed"
-			call.ErrorCode = finalCode
-		} else {
-			call.FinalStatus = "unknown"
-		}
+	}
 
-		r.Calls = append(r.Calls, call)
+	// Determine final status
+	var finalCode int
+	for _, m := range msgs {
+		if m.Type == "response" && m.StatusCode >= 200 {
+			finalCode = m.StatusCode
+		}
 	}
+	if finalCode >= 200 && finalCode < 300 {
+		call.FinalStatus = "success"
+	} else if finalCode >= 300 {
+		call.FinalStatus = "failed"
+		call.ErrorCode = finalCode
+	} else {
+		call.FinalStatus = "unknown"
+	}
+
+	return call
 }
 
 // aggregateRegistrations extracts REGISTER dialogs.
@@ -0,0 +1,117 @@
+package sip
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// FiveTuple identifies a network flow by its endpoints and transport,
+// letting a ProtocolParser correlate packets belonging to the same session.
+type FiveTuple struct {
+	SrcIP     string
+	SrcPort   int
+	DstIP     string
+	DstPort   int
+	Transport string
+}
+
+// ProtocolParser is the extension point for VoIP signaling/media protocols
+// beyond SIP/SDP. Match is cheap and runs on every packet's payload to pick
+// a parser; Parse does the real work and returns a protocol-specific value
+// (e.g. *RTPPacket) that callers type-assert based on the parser name.
+type ProtocolParser interface {
+	// Match reports whether payload looks like this parser's protocol.
+	Match(payload []byte) bool
+	// Parse decodes payload into a protocol-specific result.
+	Parse(payload []byte, ts time.Time, flow FiveTuple) (any, error)
+}
+
+var (
+	registryMu sync.Mutex
+	registry   = make(map[string]ProtocolParser)
+)
+
+// RegisterParser registers a named ProtocolParser, making it available to
+// ParsePCAPBytes/ParsePCAPBase64 (populating ParseResult.Flows) and to
+// callers embedding the sip package for their own packet loops. Registering
+// under a name that's already taken replaces the previous parser.
+func RegisterParser(name string, p ProtocolParser) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry[name] = p
+}
+
+// parsers returns a stable, name-sorted snapshot of the registered parsers
+// so iteration order (and therefore Flows population) is deterministic.
+func parsers() []string {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func init() {
+	RegisterParser("sip", sipParser{})
+	RegisterParser("rtp", rtpParser{})
+	RegisterParser("rtcp", rtcpParser{})
+	RegisterParser("mgcp", mgcpParser{})
+	RegisterParser("stun", stunParser{})
+}
+
+// sipParser adapts the pre-existing SIP parsing functions to ProtocolParser,
+// keeping extractSIPFromPacket/ParsePCAPBytes's hot path as the primary
+// entry point while still exposing SIP through the generic registry for
+// embedders that iterate parsers directly.
+type sipParser struct{}
+
+func (sipParser) Match(payload []byte) bool {
+	return IsSIPPayload(payload)
+}
+
+func (sipParser) Parse(payload []byte, ts time.Time, flow FiveTuple) (any, error) {
+	msg := parseSIPMessage(payload, ts, flow.SrcIP, flow.DstIP, flow.SrcPort, flow.DstPort, flow.Transport)
+	if msg == nil {
+		return nil, fmt.Errorf("payload is not a parseable SIP message")
+	}
+	return msg, nil
+}
+
+// addFlow runs every registered parser (other than the primary SIP one,
+// already reflected in Messages/Calls) against payload and, on a match,
+// appends its result under that parser's name in result.Flows.
+func (r *ParseResult) addFlow(payload []byte, ts time.Time, flow FiveTuple) {
+	for _, name := range parsers() {
+		if name == "sip" {
+			continue
+		}
+
+		registryMu.Lock()
+		p := registry[name]
+		registryMu.Unlock()
+
+		if p == nil || !p.Match(payload) {
+			continue
+		}
+
+		parsed, err := p.Parse(payload, ts, flow)
+		if err != nil || parsed == nil {
+			continue
+		}
+
+		if r.Flows == nil {
+			r.Flows = make(map[string]any)
+		}
+		if existing, ok := r.Flows[name].([]any); ok {
+			r.Flows[name] = append(existing, parsed)
+		} else {
+			r.Flows[name] = []any{parsed}
+		}
+	}
+}
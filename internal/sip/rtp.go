@@ -0,0 +1,136 @@
+package sip
+
+import (
+	"encoding/binary"
+	"fmt"
+	"time"
+)
+
+// RTPPacket is a decoded RTP header, enough to drive jitter/loss analysis
+// when correlated against a SIP dialog's SDP media description.
+type RTPPacket struct {
+	Timestamp      time.Time `json:"timestamp"`
+	SrcIP          string    `json:"src_ip"`
+	SrcPort        int       `json:"src_port"`
+	DstIP          string    `json:"dst_ip"`
+	DstPort        int       `json:"dst_port"`
+	PayloadType    int       `json:"payload_type"`
+	SequenceNumber int       `json:"sequence_number"`
+	RTPTimestamp   uint32    `json:"rtp_timestamp"`
+	SSRC           uint32    `json:"ssrc"`
+	Marker         bool      `json:"marker"`
+}
+
+// rtpParser recognizes RTP media packets. RTP has no magic number, so Match
+// relies on the version/padding/extension bits and a plausible payload type
+// per RFC 3550 section 5.1 - enough to avoid false positives on SIP/STUN/
+// MGCP traffic, whose first byte never carries version 2.
+type rtpParser struct{}
+
+func (rtpParser) Match(payload []byte) bool {
+	if len(payload) < 12 {
+		return false
+	}
+	version := payload[0] >> 6
+	if version != 2 {
+		return false
+	}
+	payloadType := payload[1] & 0x7f
+	// 72-76 are reserved for RTCP; leave those to rtcpParser.
+	return payloadType < 72 || payloadType > 76
+}
+
+func (rtpParser) Parse(payload []byte, ts time.Time, flow FiveTuple) (any, error) {
+	if len(payload) < 12 {
+		return nil, fmt.Errorf("rtp: payload too short")
+	}
+
+	return &RTPPacket{
+		Timestamp:      ts,
+		SrcIP:          flow.SrcIP,
+		SrcPort:        flow.SrcPort,
+		DstIP:          flow.DstIP,
+		DstPort:        flow.DstPort,
+		Marker:         payload[1]&0x80 != 0,
+		PayloadType:    int(payload[1] & 0x7f),
+		SequenceNumber: int(binary.BigEndian.Uint16(payload[2:4])),
+		RTPTimestamp:   binary.BigEndian.Uint32(payload[4:8]),
+		SSRC:           binary.BigEndian.Uint32(payload[8:12]),
+	}, nil
+}
+
+// RTCPPacket is a decoded RTCP sender/receiver report header, used to pull
+// jitter and cumulative packet-loss counters straight from the stream
+// instead of recomputing them from raw RTP sequence numbers.
+type RTCPPacket struct {
+	Timestamp      time.Time `json:"timestamp"`
+	SrcIP          string    `json:"src_ip"`
+	SrcPort        int       `json:"src_port"`
+	DstIP          string    `json:"dst_ip"`
+	DstPort        int       `json:"dst_port"`
+	PacketType     int       `json:"packet_type"`
+	SSRC           uint32    `json:"ssrc"`
+	FractionLost   int       `json:"fraction_lost,omitempty"`
+	CumulativeLost int       `json:"cumulative_lost,omitempty"`
+	Jitter         uint32    `json:"jitter,omitempty"`
+}
+
+const (
+	rtcpTypeSR   = 200
+	rtcpTypeRR   = 201
+	rtcpTypeSDES = 202
+	rtcpTypeBye  = 203
+	rtcpTypeApp  = 204
+)
+
+type rtcpParser struct{}
+
+func (rtcpParser) Match(payload []byte) bool {
+	if len(payload) < 8 {
+		return false
+	}
+	if payload[0]>>6 != 2 {
+		return false
+	}
+	pt := payload[1]
+	return pt >= rtcpTypeSR && pt <= rtcpTypeApp
+}
+
+func (rtcpParser) Parse(payload []byte, ts time.Time, flow FiveTuple) (any, error) {
+	if len(payload) < 8 {
+		return nil, fmt.Errorf("rtcp: payload too short")
+	}
+
+	pkt := &RTCPPacket{
+		Timestamp:  ts,
+		SrcIP:      flow.SrcIP,
+		SrcPort:    flow.SrcPort,
+		DstIP:      flow.DstIP,
+		DstPort:    flow.DstPort,
+		PacketType: int(payload[1]),
+		SSRC:       binary.BigEndian.Uint32(payload[4:8]),
+	}
+
+	// Sender/Receiver reports carry a report block with loss/jitter stats
+	// starting right after the (SR's extra 20-byte sender info, or RR's)
+	// fixed header. Report-count is the low 5 bits of the first byte.
+	reportCount := int(payload[0] & 0x1f)
+	if reportCount == 0 {
+		return pkt, nil
+	}
+
+	blockStart := 8
+	if pkt.PacketType == rtcpTypeSR {
+		blockStart += 20
+	}
+	if len(payload) < blockStart+24 {
+		return pkt, nil
+	}
+
+	block := payload[blockStart : blockStart+24]
+	pkt.FractionLost = int(block[4])
+	pkt.CumulativeLost = int(block[5])<<16 | int(block[6])<<8 | int(block[7])
+	pkt.Jitter = binary.BigEndian.Uint32(block[12:16])
+
+	return pkt, nil
+}
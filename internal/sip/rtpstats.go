@@ -0,0 +1,205 @@
+package sip
+
+import (
+	"math"
+	"sort"
+)
+
+// defaultRTPClockRate is assumed for jitter computation when the codec's
+// real clock rate isn't known. It's correct for the common narrowband audio
+// codecs (G.711, G.729, G.722's RTP-level rate is 8000 despite its 16kHz
+// sampling) and only approximate for wideband/video codecs such as Opus.
+const defaultRTPClockRate = 8000
+
+// MediaStream is a per-RTP-flow QoS summary: jitter and loss computed from
+// the observed packet stream, labeled with the codec negotiated in SDP when
+// the flow's endpoint could be matched to one.
+type MediaStream struct {
+	SSRC        uint32  `json:"ssrc"`
+	SrcIP       string  `json:"src_ip"`
+	SrcPort     int     `json:"src_port"`
+	DstIP       string  `json:"dst_ip"`
+	DstPort     int     `json:"dst_port"`
+	PayloadType int     `json:"payload_type"`
+	Codec       string  `json:"codec,omitempty"`
+	PacketCount int     `json:"packet_count"`
+	PacketsLost int     `json:"packets_lost"`
+	LossPercent float64 `json:"loss_percent"`
+	JitterMs    float64 `json:"jitter_ms"`
+	MOS         float64 `json:"mos"`
+}
+
+// sdpEndpoint records where a Call's SDP advertised it would send/receive
+// media, so an observed RTP flow can be attributed back to that call.
+type sdpEndpoint struct {
+	callID string
+	rtpMap map[int]string
+}
+
+// computeMediaStreams walks the RTP packets accumulated in Flows["rtp"] by
+// the registered rtpParser, groups them into per-5-tuple streams, computes
+// jitter/loss/MOS for each, and attaches the result to the Call whose SDP
+// advertised that endpoint (or to ParseResult.MediaStreams if none matched).
+func (r *ParseResult) computeMediaStreams() {
+	rtpAny, ok := r.Flows["rtp"].([]any)
+	if !ok || len(rtpAny) == 0 {
+		return
+	}
+
+	endpoints := r.sdpEndpoints()
+	callsByID := make(map[string]*Call, len(r.Calls))
+	for i := range r.Calls {
+		callsByID[r.Calls[i].CallID] = &r.Calls[i]
+	}
+
+	for _, packets := range groupRTPByFlow(rtpAny) {
+		stream := buildMediaStream(packets)
+
+		ep, matched := endpoints[flowKey{ip: stream.DstIP, port: stream.DstPort}]
+		if !matched {
+			ep, matched = endpoints[flowKey{ip: stream.SrcIP, port: stream.SrcPort}]
+		}
+		if matched {
+			if codec, ok := ep.rtpMap[stream.PayloadType]; ok {
+				stream.Codec = codec
+			}
+			if call, ok := callsByID[ep.callID]; ok {
+				call.MediaStreams = append(call.MediaStreams, stream)
+				continue
+			}
+		}
+
+		r.MediaStreams = append(r.MediaStreams, stream)
+	}
+}
+
+// sdpEndpoints collects every (connection-addr, media-port) pair advertised
+// in the SDP of the parser's messages, keyed for lookup against an observed
+// RTP flow's source or destination.
+func (r *ParseResult) sdpEndpoints() map[flowKey]sdpEndpoint {
+	endpoints := make(map[flowKey]sdpEndpoint)
+	for _, call := range r.Calls {
+		for _, msg := range call.Messages {
+			if msg.SDP == nil {
+				continue
+			}
+			for _, media := range msg.SDP.Media {
+				if media.Port == 0 {
+					continue
+				}
+				endpoints[flowKey{ip: msg.SDP.ConnectionAddr, port: media.Port}] = sdpEndpoint{
+					callID: call.CallID,
+					rtpMap: msg.SDP.RTPMap,
+				}
+			}
+		}
+	}
+	return endpoints
+}
+
+type flowKey struct {
+	ip   string
+	port int
+}
+
+// rtpFlowKey groups RTP packets belonging to the same stream by their
+// 4-tuple (RTP has no port/proto field of its own to key on beyond the UDP
+// endpoints).
+type rtpFlowKey struct {
+	srcIP   string
+	srcPort int
+	dstIP   string
+	dstPort int
+}
+
+// groupRTPByFlow buckets RTP packets by 4-tuple, preserving the order
+// they were appended to Flows["rtp"] (capture order).
+func groupRTPByFlow(rtpAny []any) map[rtpFlowKey][]*RTPPacket {
+	groups := make(map[rtpFlowKey][]*RTPPacket)
+	for _, v := range rtpAny {
+		pkt, ok := v.(*RTPPacket)
+		if !ok {
+			continue
+		}
+		key := rtpFlowKey{srcIP: pkt.SrcIP, srcPort: pkt.SrcPort, dstIP: pkt.DstIP, dstPort: pkt.DstPort}
+		groups[key] = append(groups[key], pkt)
+	}
+	return groups
+}
+
+// buildMediaStream computes loss, jitter, and MOS for a single RTP flow's
+// packets.
+func buildMediaStream(packets []*RTPPacket) MediaStream {
+	sortRTPPackets(packets)
+
+	first := packets[0]
+	stream := MediaStream{
+		SSRC:        first.SSRC,
+		SrcIP:       first.SrcIP,
+		SrcPort:     first.SrcPort,
+		DstIP:       first.DstIP,
+		DstPort:     first.DstPort,
+		PayloadType: first.PayloadType,
+		PacketCount: len(packets),
+	}
+
+	lost := 0
+	var jitter float64 // RFC 3550 section 6.4.1 running estimate, in RTP timestamp units
+
+	for i := 1; i < len(packets); i++ {
+		prev, cur := packets[i-1], packets[i]
+
+		seqDiff := int16(cur.SequenceNumber - prev.SequenceNumber)
+		if seqDiff > 1 {
+			lost += int(seqDiff) - 1
+		}
+
+		arrivalDelta := cur.Timestamp.Sub(prev.Timestamp).Seconds() * defaultRTPClockRate
+		sentDelta := float64(int32(cur.RTPTimestamp - prev.RTPTimestamp))
+		d := arrivalDelta - sentDelta
+		jitter += (math.Abs(d) - jitter) / 16
+	}
+
+	stream.PacketsLost = lost
+	expected := len(packets) + lost
+	if expected > 0 {
+		stream.LossPercent = float64(lost) / float64(expected) * 100
+	}
+	stream.JitterMs = jitter / defaultRTPClockRate * 1000
+	stream.MOS = estimateMOS(stream.LossPercent, stream.JitterMs)
+
+	return stream
+}
+
+// estimateMOS applies the ITU-T G.107 E-model simplification requested for
+// this analyzer: a fixed equipment-impairment factor (Ie = 0) plus linear
+// loss/jitter penalties, converted from the R-factor to MOS via the
+// standard cubic mapping.
+func estimateMOS(lossPercent, jitterMs float64) float64 {
+	const ie = 0
+	r := 93.2 - ie - 0.18*lossPercent - 0.1*jitterMs
+	if r < 0 {
+		r = 0
+	}
+	if r > 100 {
+		r = 100
+	}
+
+	mos := 1 + 0.035*r + 7e-6*r*(r-60)*(100-r)
+	switch {
+	case mos < 1:
+		return 1
+	case mos > 4.5:
+		return 4.5
+	default:
+		return math.Round(mos*100) / 100
+	}
+}
+
+// sortRTPPackets orders a flow's packets by capture time, since jitter and
+// loss both depend on arrival order.
+func sortRTPPackets(packets []*RTPPacket) {
+	sort.SliceStable(packets, func(i, j int) bool {
+		return packets[i].Timestamp.Before(packets[j].Timestamp)
+	})
+}
@@ -0,0 +1,202 @@
+package sip
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/pcapgo"
+)
+
+// ErrStop can be returned from a WalkPCAP handler (or surfaces from
+// ParsePCAPStream when a ParseOptions bound is hit) to end the walk
+// cleanly; it's never returned to the caller as a failure.
+var ErrStop = errors.New("sip: stop walk")
+
+// ParseOptions bounds and filters a streaming PCAP parse, letting a caller
+// analyze multi-GB captures without loading the whole thing into memory or
+// collecting more than they need.
+type ParseOptions struct {
+	// MaxMessages stops the parse once this many SIP messages have been
+	// collected (0 means unlimited).
+	MaxMessages int
+	// MaxBytes stops the parse once this many packet bytes have been read
+	// (0 means unlimited).
+	MaxBytes int64
+	// SkipSDP drops parsed SDP bodies from collected messages and skips
+	// RTP/media correlation, trading media-stream detail for speed and
+	// memory on signaling-only analysis.
+	SkipSDP bool
+	// CallIDAllowlist, if non-empty, discards messages for any other
+	// Call-ID before they're appended to Messages.
+	CallIDAllowlist []string
+}
+
+func (o ParseOptions) callIDAllowed(callID string) bool {
+	if len(o.CallIDAllowlist) == 0 {
+		return true
+	}
+	for _, id := range o.CallIDAllowlist {
+		if id == callID {
+			return true
+		}
+	}
+	return false
+}
+
+// ParsePCAPStream parses a PCAP from r incrementally, honoring ctx
+// cancellation between packets and opts' memory/early-exit bounds. Unlike
+// ParsePCAPBytes, the capture is never buffered in memory all at once.
+func ParsePCAPStream(ctx context.Context, r io.Reader, opts ParseOptions) (*ParseResult, error) {
+	result := &ParseResult{
+		Messages:      make([]Message, 0),
+		Calls:         make([]Call, 0),
+		Registrations: make([]Registration, 0),
+		Stats: Stats{
+			Methods:       make(map[string]int),
+			ResponseCodes: make(map[int]int),
+		},
+	}
+
+	err := walkPackets(ctx, r, opts, func(packet gopacket.Packet, ts time.Time) error {
+		result.Stats.TotalPackets++
+
+		if msg := extractSIPFromPacket(packet, ts); msg != nil {
+			if opts.SkipSDP {
+				msg.HasSDP = false
+				msg.SDP = nil
+			}
+			if opts.callIDAllowed(msg.CallID) {
+				result.Messages = append(result.Messages, *msg)
+			}
+		}
+
+		if !opts.SkipSDP {
+			if payload, flow, ok := packetFlow(packet); ok {
+				result.addFlow(payload, ts, flow)
+			}
+		}
+
+		if opts.MaxMessages > 0 && len(result.Messages) >= opts.MaxMessages {
+			return ErrStop
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result.aggregateCalls()
+	result.aggregateRegistrations()
+	result.computeStats()
+	if !opts.SkipSDP {
+		result.computeMediaStreams()
+	}
+
+	return result, nil
+}
+
+// WalkPCAP parses a PCAP from r incrementally, invoking handler for every
+// SIP message found instead of accumulating a ParseResult. It's the
+// event-driven counterpart to ParsePCAPStream, for scanning a capture for a
+// call of interest without holding the rest of it in memory; return ErrStop
+// from handler to stop early once it's found.
+func WalkPCAP(ctx context.Context, r io.Reader, handler func(Message) error) error {
+	return walkPackets(ctx, r, ParseOptions{}, func(packet gopacket.Packet, ts time.Time) error {
+		msg := extractSIPFromPacket(packet, ts)
+		if msg == nil {
+			return nil
+		}
+		return handler(*msg)
+	})
+}
+
+// StreamEvent is one decoded packet of interest from WalkPCAPStream, tagged
+// by Kind so a live-capture consumer can apply different delivery policies
+// per protocol (e.g. always deliver SIP signaling, drop RTP under load).
+// Exactly one of SIP/RTP is set, matching Kind.
+type StreamEvent struct {
+	Kind string     `json:"kind"` // "sip" or "rtp"
+	SIP  *Message   `json:"sip,omitempty"`
+	RTP  *RTPPacket `json:"rtp,omitempty"`
+}
+
+// WalkPCAPStream is the live-capture counterpart to WalkPCAP: besides SIP
+// messages, it also surfaces RTP packets, so a streaming consumer (e.g. a
+// tool relaying a live tcpdump pipe to an MCP client) can watch media flow
+// alongside signaling instead of polling a saved file afterwards. opts'
+// MaxBytes bound matters here more than in a file-backed parse, since a
+// live capture never reaches EOF on its own.
+func WalkPCAPStream(ctx context.Context, r io.Reader, opts ParseOptions, handler func(StreamEvent) error) error {
+	var rtp rtpParser
+
+	return walkPackets(ctx, r, opts, func(packet gopacket.Packet, ts time.Time) error {
+		if msg := extractSIPFromPacket(packet, ts); msg != nil {
+			return handler(StreamEvent{Kind: "sip", SIP: msg})
+		}
+
+		payload, flow, ok := packetFlow(packet)
+		if !ok || !rtp.Match(payload) {
+			return nil
+		}
+		parsed, err := rtp.Parse(payload, ts, flow)
+		if err != nil {
+			return nil
+		}
+		pkt, ok := parsed.(*RTPPacket)
+		if !ok {
+			return nil
+		}
+		return handler(StreamEvent{Kind: "rtp", RTP: pkt})
+	})
+}
+
+// walkPackets is the shared packet-reading loop behind ParsePCAPStream and
+// WalkPCAP: it decodes one packet at a time from r, checking ctx between
+// each one, and stops cleanly on io.EOF or ErrStop from fn.
+func walkPackets(ctx context.Context, r io.Reader, opts ParseOptions, fn func(gopacket.Packet, time.Time) error) error {
+	if deadline, ok := ctx.Deadline(); ok {
+		if dl, ok := r.(interface{ SetReadDeadline(time.Time) error }); ok {
+			_ = dl.SetReadDeadline(deadline)
+		}
+	}
+
+	reader, err := pcapgo.NewReader(r)
+	if err != nil {
+		return fmt.Errorf("failed to open pcap stream: %w", err)
+	}
+	linkType := reader.LinkType()
+
+	var bytesRead int64
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+
+		packetData, ci, err := reader.ReadPacketData()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return fmt.Errorf("failed to read packet: %w", err)
+		}
+
+		bytesRead += int64(len(packetData))
+		if opts.MaxBytes > 0 && bytesRead > opts.MaxBytes {
+			return nil
+		}
+
+		packet := gopacket.NewPacket(packetData, linkType, gopacket.Default)
+		if err := fn(packet, ci.Timestamp); err != nil {
+			if errors.Is(err, ErrStop) {
+				return nil
+			}
+			return err
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package sip
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"time"
+)
+
+// stunMagicCookie is the fixed value defined by RFC 5389 section 6 that
+// distinguishes STUN from other binary protocols sharing a port (and from
+// RFC 3489 "classic" STUN, which this parser doesn't attempt to decode).
+const stunMagicCookie = 0x2112A442
+
+// STUNMessage is a decoded STUN header (RFC 5389), commonly seen alongside
+// SIP for NAT traversal (ICE connectivity checks ahead of RTP flows).
+type STUNMessage struct {
+	Timestamp     time.Time `json:"timestamp"`
+	SrcIP         string    `json:"src_ip"`
+	SrcPort       int       `json:"src_port"`
+	DstIP         string    `json:"dst_ip"`
+	DstPort       int       `json:"dst_port"`
+	MessageType   uint16    `json:"message_type"`
+	Class         string    `json:"class"`
+	Method        string    `json:"method"`
+	Length        int       `json:"length"`
+	TransactionID string    `json:"transaction_id"`
+}
+
+type stunParser struct{}
+
+func (stunParser) Match(payload []byte) bool {
+	if len(payload) < 20 {
+		return false
+	}
+	// Top two bits of the message type must be 0 (RFC 5389 section 6).
+	if payload[0]&0xc0 != 0 {
+		return false
+	}
+	return binary.BigEndian.Uint32(payload[4:8]) == stunMagicCookie
+}
+
+func (stunParser) Parse(payload []byte, ts time.Time, flow FiveTuple) (any, error) {
+	if len(payload) < 20 {
+		return nil, fmt.Errorf("stun: payload too short")
+	}
+
+	msgType := binary.BigEndian.Uint16(payload[0:2])
+
+	return &STUNMessage{
+		Timestamp:     ts,
+		SrcIP:         flow.SrcIP,
+		SrcPort:       flow.SrcPort,
+		DstIP:         flow.DstIP,
+		DstPort:       flow.DstPort,
+		MessageType:   msgType,
+		Class:         stunClass(msgType),
+		Method:        stunMethod(msgType),
+		Length:        int(binary.BigEndian.Uint16(payload[2:4])),
+		TransactionID: hex.EncodeToString(payload[8:20]),
+	}, nil
+}
+
+// stunClass decodes the message class from the C1/C0 bits scattered across
+// the type field per RFC 5389 section 6.
+func stunClass(msgType uint16) string {
+	c := ((msgType & 0x0100) >> 7) | ((msgType & 0x0010) >> 4)
+	switch c {
+	case 0b00:
+		return "request"
+	case 0b01:
+		return "indication"
+	case 0b10:
+		return "success_response"
+	default:
+		return "error_response"
+	}
+}
+
+// stunMethod decodes the well-known STUN method from the remaining type
+// bits; unrecognized methods return a numeric placeholder.
+func stunMethod(msgType uint16) string {
+	method := msgType & 0x3eef
+	switch method {
+	case 0x0001:
+		return "binding"
+	default:
+		return fmt.Sprintf("0x%03x", method)
+	}
+}
@@ -0,0 +1,39 @@
+package ssh
+
+import (
+	"math/rand"
+	"time"
+)
+
+// ReconnectPolicy controls how Manager.Run retries a lost connection:
+// exponential backoff between attempts, capped at MaxDelay, with random
+// jitter to avoid many clients retrying in lockstep.
+type ReconnectPolicy struct {
+	MaxRetries int
+	BaseDelay  time.Duration
+	MaxDelay   time.Duration
+}
+
+// DefaultReconnectPolicy is used when a connection doesn't request a
+// custom policy via ConnectOptions.
+var DefaultReconnectPolicy = ReconnectPolicy{
+	MaxRetries: 3,
+	BaseDelay:  500 * time.Millisecond,
+	MaxDelay:   10 * time.Second,
+}
+
+// delay returns the backoff delay before attempt n (0-indexed), doubling
+// BaseDelay each attempt up to MaxDelay and adding up to +/-25% jitter.
+func (p ReconnectPolicy) delay(attempt int) time.Duration {
+	d := p.BaseDelay << attempt
+	if d <= 0 || d > p.MaxDelay {
+		d = p.MaxDelay
+	}
+
+	jitter := time.Duration(rand.Int63n(int64(d)/2+1)) - d/4
+	d += jitter
+	if d < 0 {
+		d = 0
+	}
+	return d
+}
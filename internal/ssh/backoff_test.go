@@ -0,0 +1,28 @@
+package ssh
+
+import "testing"
+
+func TestReconnectPolicyDelayCapped(t *testing.T) {
+	policy := ReconnectPolicy{
+		MaxRetries: 5,
+		BaseDelay:  100,
+		MaxDelay:   1000,
+	}
+
+	for attempt := 0; attempt < 10; attempt++ {
+		d := policy.delay(attempt)
+		if d < 0 {
+			t.Fatalf("attempt %d: delay went negative: %v", attempt, d)
+		}
+		if d > policy.MaxDelay+policy.MaxDelay/4 {
+			t.Errorf("attempt %d: delay %v exceeds MaxDelay %v plus jitter headroom", attempt, d, policy.MaxDelay)
+		}
+	}
+}
+
+func TestReconnectPolicyDelayZeroMaxDelay(t *testing.T) {
+	policy := ReconnectPolicy{MaxRetries: 1, BaseDelay: 0, MaxDelay: 0}
+	if d := policy.delay(0); d != 0 {
+		t.Errorf("expected 0 delay with zero MaxDelay, got %v", d)
+	}
+}
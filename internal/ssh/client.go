@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io"
 	"log"
+	"net"
 	"strings"
 	"sync"
 	"time"
@@ -16,12 +17,53 @@ import (
 
 // Client represents a single SSH connection with state tracking.
 type Client struct {
-	alias string
-	conn  *ssh.Client
-	sftp  *sftp.Client
-	cwd   string
-	mu    sync.Mutex
-	creds Credentials
+	alias  string
+	conn   *ssh.Client
+	sftp   *sftp.Client
+	cwd    string
+	mu     sync.Mutex
+	creds  Credentials
+	caps   Capabilities
+	health HealthStatus
+
+	// jumpClient is the via-host connection this Client was dialed
+	// through, if any. The background keepalive loop reconnects through
+	// it directly since it runs without access to the Manager's alias map.
+	jumpClient    *Client
+	stopKeepalive chan struct{}
+	keepaliveOnce sync.Once
+	closeOnce     sync.Once
+
+	uploadMu sync.Mutex
+	uploads  map[string]*uploadSession
+}
+
+// HealthStatus is the result of the most recent keepalive probe, tracked
+// separately from IsConnected (which only reports whether conn is
+// currently non-nil) so a caller can distinguish a connection that's
+// technically open from one that's actually answering keepalives.
+type HealthStatus struct {
+	Healthy   bool
+	Latency   time.Duration
+	CheckedAt time.Time
+}
+
+// Capabilities is the result of probing a freshly connected client for
+// what it can do: its identity and whether it can escalate via sudo
+// without a password, so tools and Run's auto-escalation can reason about
+// privileges before running something destructive.
+type Capabilities struct {
+	User              string
+	UID               string
+	CanSudo           bool
+	SudoNeedsPassword bool
+}
+
+// uploadSession tracks a resumable multi-call upload's progress: the
+// destination path and the next offset WriteChunk should write at.
+type uploadSession struct {
+	path       string
+	nextOffset int64
 }
 
 // Credentials holds SSH connection parameters.
@@ -32,23 +74,122 @@ type Credentials struct {
 	Password   string
 	PrivateKey ssh.Signer
 	Via        string
+	Reconnect  ReconnectPolicy
+
+	HostKeyMode  HostKeyMode
+	HostKeyStore *HostKeyStore
+
+	// Escalate is "sudo" to transparently prefix Run commands with
+	// "sudo -n" when the connection isn't already root, or "" to run
+	// commands as the connected user.
+	Escalate string
+
+	// KeepaliveInterval is how often the keepalive loop sends a
+	// keepalive@openssh.com request; defaults to 30s when zero.
+	KeepaliveInterval time.Duration
+	// KeepaliveCountMax is how many consecutive keepalive failures trigger
+	// a reconnect; defaults to 3 when zero.
+	KeepaliveCountMax int
+	// AutoReconnect starts the background keepalive loop, which
+	// reconnects (reusing the original jump client) after
+	// KeepaliveCountMax consecutive keepalive failures. Without it, a
+	// silent NAT/firewall timeout isn't noticed until the next command
+	// runs and fails.
+	AutoReconnect bool
 }
 
 // NewClient creates a new SSH client.
 func NewClient(alias string, creds Credentials, jumpClient *Client) (*Client, error) {
 	client := &Client{
-		alias: alias,
-		creds: creds,
-		cwd:   "",
+		alias:         alias,
+		creds:         creds,
+		cwd:           "",
+		uploads:       make(map[string]*uploadSession),
+		jumpClient:    jumpClient,
+		stopKeepalive: make(chan struct{}),
 	}
 
 	if err := client.connect(jumpClient); err != nil {
 		return nil, err
 	}
 
+	if creds.AutoReconnect {
+		client.keepaliveOnce.Do(func() { go client.keepaliveLoop() })
+	}
+
 	return client, nil
 }
 
+// keepaliveLoop periodically probes the connection with a
+// keepalive@openssh.com global request, reconnecting through the original
+// jump client after KeepaliveCountMax consecutive failures. This catches
+// silent NAT/firewall drops that neither IsConnected nor a failed Run would
+// surface until the next command happened to run.
+func (c *Client) keepaliveLoop() {
+	interval := c.creds.KeepaliveInterval
+	if interval <= 0 {
+		interval = 30 * time.Second
+	}
+	maxFailures := c.creds.KeepaliveCountMax
+	if maxFailures <= 0 {
+		maxFailures = 3
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var failures int
+	for {
+		select {
+		case <-c.stopKeepalive:
+			return
+		case <-ticker.C:
+			c.mu.Lock()
+			conn := c.conn
+			c.mu.Unlock()
+			if conn == nil {
+				continue
+			}
+
+			start := time.Now()
+			_, _, err := conn.SendRequest("keepalive@openssh.com", true, nil)
+			latency := time.Since(start)
+
+			c.mu.Lock()
+			c.health = HealthStatus{Healthy: err == nil, Latency: latency, CheckedAt: start}
+			c.mu.Unlock()
+
+			if err == nil {
+				failures = 0
+				continue
+			}
+
+			failures++
+			log.Printf("[SSH] Keepalive failed for %s (%d/%d): %v", c.alias, failures, maxFailures, err)
+			if failures >= maxFailures {
+				failures = 0
+				if !c.creds.AutoReconnect {
+					continue
+				}
+				log.Printf("[SSH] Keepalive threshold reached for %s, reconnecting...", c.alias)
+				if err := c.Reconnect(c.jumpClient); err != nil {
+					log.Printf("[SSH] Keepalive-triggered reconnect failed for %s: %v", c.alias, err)
+				}
+			}
+		}
+	}
+}
+
+// IsHealthy returns the result of the most recent keepalive probe. Unlike
+// IsConnected, which only checks whether conn is non-nil, this reflects
+// whether the connection is actually answering keepalives and how long the
+// last probe took.
+func (c *Client) IsHealthy() HealthStatus {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.health
+}
+
 // connect establishes the SSH connection.
 func (c *Client) connect(jumpClient *Client) error {
 	c.mu.Lock()
@@ -59,9 +200,18 @@ func (c *Client) connect(jumpClient *Client) error {
 		c.conn = nil
 	}
 
+	hostKeyCallback := ssh.InsecureIgnoreHostKey()
+	if c.creds.HostKeyStore != nil {
+		cb, err := c.creds.HostKeyStore.Callback(c.creds.HostKeyMode)
+		if err != nil {
+			return fmt.Errorf("host key policy: %w", err)
+		}
+		hostKeyCallback = cb
+	}
+
 	config := &ssh.ClientConfig{
 		User:            c.creds.Username,
-		HostKeyCallback: ssh.InsecureIgnoreHostKey(),
+		HostKeyCallback: hostKeyCallback,
 		Timeout:         30 * time.Second,
 	}
 
@@ -117,10 +267,56 @@ func (c *Client) connect(jumpClient *Client) error {
 		c.cwd = strings.TrimSpace(output)
 	}
 
+	caps, err := c.probeCapabilities()
+	if err != nil {
+		log.Printf("[SSH] Warning: failed to probe capabilities: %v", err)
+	} else {
+		c.caps = caps
+	}
+
 	log.Printf("[SSH] Connected to %s@%s (alias: %s)", c.creds.Username, c.creds.Host, c.alias)
 	return nil
 }
 
+// probeCapabilities runs whoami, id -u, and a passwordless sudo check
+// immediately after auth, so callers can reason about privileges before
+// running destructive commands.
+func (c *Client) probeCapabilities() (Capabilities, error) {
+	whoamiOut, err := c.runRaw("whoami")
+	if err != nil {
+		return Capabilities{}, fmt.Errorf("whoami: %w", err)
+	}
+
+	uidOut, err := c.runRaw("id -u")
+	if err != nil {
+		return Capabilities{}, fmt.Errorf("id -u: %w", err)
+	}
+
+	caps := Capabilities{
+		User: strings.TrimSpace(whoamiOut),
+		UID:  strings.TrimSpace(uidOut),
+	}
+
+	sudoOut, sudoErr := c.runRaw("sudo -n true")
+	switch {
+	case sudoErr == nil:
+		caps.CanSudo = true
+	case strings.Contains(strings.ToLower(sudoOut), "password"):
+		caps.SudoNeedsPassword = true
+	}
+
+	return caps, nil
+}
+
+// wrapEscalation prefixes cmd with "sudo -n" when the connection's
+// Escalate policy is "sudo" and the connected user isn't already root.
+func (c *Client) wrapEscalation(cmd string) string {
+	if c.creds.Escalate == "sudo" && c.caps.UID != "" && c.caps.UID != "0" {
+		return "sudo -n " + cmd
+	}
+	return cmd
+}
+
 // runRaw executes a command without CWD handling.
 func (c *Client) runRaw(cmd string) (string, error) {
 	session, err := c.conn.NewSession()
@@ -145,7 +341,7 @@ func (c *Client) Run(ctx context.Context, cmd string) (*RunResult, error) {
 	delimiter := fmt.Sprintf("___MCP_PWD_%d___", time.Now().UnixNano())
 	wrappedCmd := fmt.Sprintf(
 		`cd %q && %s; __EXIT__=$?; echo ""; echo "%s"; pwd; exit $__EXIT__`,
-		c.cwd, cmd, delimiter,
+		c.cwd, c.wrapEscalation(cmd), delimiter,
 	)
 
 	session, err := c.conn.NewSession()
@@ -216,6 +412,164 @@ type RunResult struct {
 	CWD      string
 }
 
+// DialRemote dials addr from the remote side of the connection, for use by
+// local port forwards and the SOCKS5 proxy.
+func (c *Client) DialRemote(network, addr string) (net.Conn, error) {
+	c.mu.Lock()
+	conn := c.conn
+	c.mu.Unlock()
+
+	if conn == nil {
+		return nil, errors.New("not connected")
+	}
+	return conn.Dial(network, addr)
+}
+
+// ListenRemote asks the remote SSH server to listen on addr, for use by
+// remote port forwards (ssh -R).
+func (c *Client) ListenRemote(addr string) (net.Listener, error) {
+	c.mu.Lock()
+	conn := c.conn
+	c.mu.Unlock()
+
+	if conn == nil {
+		return nil, errors.New("not connected")
+	}
+	return conn.Listen("tcp", addr)
+}
+
+// BeginUpload registers a new resumable upload token for path, letting
+// WriteChunk calls append sequential chunks across multiple MCP tool calls
+// and resume from wherever the last successful chunk left off.
+func (c *Client) BeginUpload(path string) string {
+	token := fmt.Sprintf("up-%s-%d", c.alias, time.Now().UnixNano())
+
+	c.uploadMu.Lock()
+	c.uploads[token] = &uploadSession{path: path}
+	c.uploadMu.Unlock()
+
+	return token
+}
+
+// NextChunk returns the destination path and next expected write offset for
+// token. ok is false if the token is unknown (never issued, or already ended).
+func (c *Client) NextChunk(token string) (path string, offset int64, ok bool) {
+	c.uploadMu.Lock()
+	defer c.uploadMu.Unlock()
+
+	sess, ok := c.uploads[token]
+	if !ok {
+		return "", 0, false
+	}
+	return sess.path, sess.nextOffset, true
+}
+
+// AdvanceUpload records that n more bytes were written for token.
+func (c *Client) AdvanceUpload(token string, n int64) {
+	c.uploadMu.Lock()
+	defer c.uploadMu.Unlock()
+
+	if sess, ok := c.uploads[token]; ok {
+		sess.nextOffset += n
+	}
+}
+
+// EndUpload discards token's resumable state once the upload is complete (or
+// abandoned).
+func (c *Client) EndUpload(token string) {
+	c.uploadMu.Lock()
+	delete(c.uploads, token)
+	c.uploadMu.Unlock()
+}
+
+// NewShell opens an interactive PTY-backed session and starts the remote
+// login shell. The caller owns the returned *ssh.Session and must Close it
+// when done (ShellSession/CloseShell does this); stdin/stdout are returned
+// separately since Session's Close doesn't flush or close its pipes.
+func (c *Client) NewShell(cols, rows int, term string, modeOverrides ssh.TerminalModes) (*ssh.Session, io.WriteCloser, io.Reader, error) {
+	c.mu.Lock()
+	conn := c.conn
+	c.mu.Unlock()
+
+	if conn == nil {
+		return nil, nil, nil, errors.New("not connected")
+	}
+
+	session, err := conn.NewSession()
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to create session: %w", err)
+	}
+
+	if term == "" {
+		term = "xterm"
+	}
+
+	modes := ssh.TerminalModes{
+		ssh.ECHO:          1,
+		ssh.TTY_OP_ISPEED: 14400,
+		ssh.TTY_OP_OSPEED: 14400,
+	}
+	for opcode, arg := range modeOverrides {
+		modes[opcode] = arg
+	}
+	if err := session.RequestPty(term, rows, cols, modes); err != nil {
+		session.Close()
+		return nil, nil, nil, fmt.Errorf("failed to request pty: %w", err)
+	}
+
+	stdin, err := session.StdinPipe()
+	if err != nil {
+		session.Close()
+		return nil, nil, nil, fmt.Errorf("failed to open stdin pipe: %w", err)
+	}
+
+	stdout, err := session.StdoutPipe()
+	if err != nil {
+		session.Close()
+		return nil, nil, nil, fmt.Errorf("failed to open stdout pipe: %w", err)
+	}
+
+	if err := session.Shell(); err != nil {
+		session.Close()
+		return nil, nil, nil, fmt.Errorf("failed to start shell: %w", err)
+	}
+
+	return session, stdin, stdout, nil
+}
+
+// StreamCommand starts cmd in a plain (non-PTY, no cd/CWD wrapping) session
+// and returns its live stdout, for incrementally reading a long-running
+// producer's binary output (e.g. a packet capture tool writing to stdout)
+// instead of buffering the whole thing like Run does. The caller owns the
+// returned *ssh.Session and must Close it to terminate the remote command.
+func (c *Client) StreamCommand(cmd string) (*ssh.Session, io.Reader, error) {
+	c.mu.Lock()
+	conn := c.conn
+	c.mu.Unlock()
+
+	if conn == nil {
+		return nil, nil, errors.New("not connected")
+	}
+
+	session, err := conn.NewSession()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create session: %w", err)
+	}
+
+	stdout, err := session.StdoutPipe()
+	if err != nil {
+		session.Close()
+		return nil, nil, fmt.Errorf("failed to open stdout pipe: %w", err)
+	}
+
+	if err := session.Start(cmd); err != nil {
+		session.Close()
+		return nil, nil, fmt.Errorf("failed to start command: %w", err)
+	}
+
+	return session, stdout, nil
+}
+
 // SFTP returns the SFTP client.
 func (c *Client) SFTP() (*sftp.Client, error) {
 	c.mu.Lock()
@@ -240,6 +594,10 @@ func (c *Client) SFTP() (*sftp.Client, error) {
 
 // Close closes the connection.
 func (c *Client) Close() error {
+	if c.stopKeepalive != nil {
+		c.closeOnce.Do(func() { close(c.stopKeepalive) })
+	}
+
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
@@ -269,6 +627,13 @@ func (c *Client) CWD() string {
 	return c.cwd
 }
 
+// Capabilities returns the privilege probe captured at connect time.
+func (c *Client) Capabilities() Capabilities {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.caps
+}
+
 // Reconnect attempts to reconnect.
 func (c *Client) Reconnect(jumpClient *Client) error {
 	log.Printf("[SSH] Reconnecting %s...", c.alias)
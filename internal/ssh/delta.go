@@ -0,0 +1,293 @@
+package ssh
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path"
+
+	"github.com/pkg/sftp"
+)
+
+// deltaBlockSize is the fixed block size used to chunk both sides of a delta
+// sync. rsync itself scales this with file size; a fixed 4KB block keeps the
+// signature map small and the implementation simple, at the cost of some
+// efficiency on very large files.
+const deltaBlockSize = 4096
+
+// deltaAdlerMod is the modulus used by the weak rolling checksum, matching
+// the one rsync's own Adler-32-derived algorithm uses.
+const deltaAdlerMod = 65521
+
+// blockSignature is one destination block's weak + strong checksum, keyed by
+// weak checksum for O(1) candidate lookup while scanning the source.
+type blockSignature struct {
+	index  int
+	strong [sha256.Size]byte
+}
+
+// weakChecksum computes the Adler-32-style rolling checksum rsync uses: two
+// running sums over the window, folded into a single map key by
+// combineWeak.
+func weakChecksum(block []byte) (sum1, sum2 uint32) {
+	n := uint32(len(block))
+	for i, b := range block {
+		sum1 += uint32(b)
+		sum2 += (n - uint32(i)) * uint32(b)
+	}
+	return sum1 % deltaAdlerMod, sum2 % deltaAdlerMod
+}
+
+// combineWeak folds the two Adler sums into the single uint32 used as the
+// signature map key.
+func combineWeak(sum1, sum2 uint32) uint32 {
+	return sum2<<16 | sum1
+}
+
+// buildDeltaSignatures walks destPath on destSFTP in deltaBlockSize blocks,
+// returning one blockSignature per block keyed by weak checksum for lookup.
+// This runs against the destination, which is assumed to already hold most
+// of the data the source will be diffed against.
+func buildDeltaSignatures(destSFTP *sftp.Client, destPath string) (map[uint32][]blockSignature, error) {
+	f, err := destSFTP.Open(destPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open destination %q for signature scan: %w", destPath, err)
+	}
+	defer f.Close()
+
+	sigs := make(map[uint32][]blockSignature)
+	buf := make([]byte, deltaBlockSize)
+	for index := 0; ; index++ {
+		n, err := io.ReadFull(f, buf)
+		if n > 0 {
+			block := buf[:n]
+			sum1, sum2 := weakChecksum(block)
+			weak := combineWeak(sum1, sum2)
+			sigs[weak] = append(sigs[weak], blockSignature{index: index, strong: sha256.Sum256(block)})
+		}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read destination %q at block %d: %w", destPath, index, err)
+		}
+	}
+	return sigs, nil
+}
+
+// deltaOp is one instruction in the delta produced by diffing sourcePath
+// against the destination's signatures: either copy an existing destination
+// block verbatim (copyBlock >= 0), or emit literal bytes the destination
+// doesn't have (copyBlock == -1).
+type deltaOp struct {
+	copyBlock int
+	literal   []byte
+}
+
+// buildDeltaOps slides a deltaBlockSize window byte-by-byte over sourcePath,
+// matching against sigs the way rsync's sender does: whenever the window's
+// weak checksum collides with a signature AND the strong hash confirms it,
+// emit a copy instruction and jump the window forward by a full block;
+// otherwise emit the current byte as literal data and slide forward by one.
+func buildDeltaOps(srcSFTP *sftp.Client, srcPath string, sigs map[uint32][]blockSignature) ([]deltaOp, int64, error) {
+	f, err := srcSFTP.Open(srcPath)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to open source %q for delta scan: %w", srcPath, err)
+	}
+	defer f.Close()
+
+	srcInfo, err := srcSFTP.Stat(srcPath)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to stat source %q: %w", srcPath, err)
+	}
+
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to read source %q: %w", srcPath, err)
+	}
+
+	var ops []deltaOp
+	var literal []byte
+	flushLiteral := func() {
+		if len(literal) > 0 {
+			ops = append(ops, deltaOp{copyBlock: -1, literal: literal})
+			literal = nil
+		}
+	}
+
+	for i := 0; i < len(data); {
+		end := i + deltaBlockSize
+		if end > len(data) {
+			end = len(data)
+		}
+		window := data[i:end]
+
+		if len(window) == deltaBlockSize {
+			sum1, sum2 := weakChecksum(window)
+			if candidates, ok := sigs[combineWeak(sum1, sum2)]; ok {
+				strong := sha256.Sum256(window)
+				matched := -1
+				for _, c := range candidates {
+					if c.strong == strong {
+						matched = c.index
+						break
+					}
+				}
+				if matched >= 0 {
+					flushLiteral()
+					ops = append(ops, deltaOp{copyBlock: matched})
+					i += deltaBlockSize
+					continue
+				}
+			}
+		}
+
+		literal = append(literal, data[i])
+		i++
+	}
+	flushLiteral()
+
+	return ops, srcInfo.Size(), nil
+}
+
+// replayDeltaOps reconstructs the new file at destPath+syncTempSuffix by
+// replaying ops: a copy instruction reads its block from the destination's
+// existing, unmodified content, and a literal instruction is written
+// through as-is. The temp file is renamed over destPath only once every op
+// has been applied, so a crash mid-reconstruction can't corrupt destPath.
+func replayDeltaOps(destSFTP *sftp.Client, destPath string, ops []deltaOp, total int64, onProgress func(transferred, total int64)) (int64, error) {
+	if err := sftpMkdirParents(destSFTP, path.Dir(destPath)); err != nil {
+		return 0, fmt.Errorf("failed to create destination directory: %w", err)
+	}
+
+	var oldFile *sftp.File
+	if existing, err := destSFTP.Open(destPath); err == nil {
+		oldFile = existing
+		defer oldFile.Close()
+	}
+
+	tempPath := destPath + syncTempSuffix
+	newFile, err := destSFTP.OpenFile(tempPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open destination temp file %q: %w", tempPath, err)
+	}
+	defer newFile.Close()
+
+	var transferred int64
+	readBuf := make([]byte, deltaBlockSize)
+	for _, op := range ops {
+		if op.copyBlock >= 0 {
+			if oldFile == nil {
+				return transferred, fmt.Errorf("copy instruction for block %d but destination has no existing file", op.copyBlock)
+			}
+			if _, err := oldFile.Seek(int64(op.copyBlock)*deltaBlockSize, io.SeekStart); err != nil {
+				return transferred, fmt.Errorf("failed to seek existing destination to block %d: %w", op.copyBlock, err)
+			}
+			n, err := io.ReadFull(oldFile, readBuf)
+			if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+				return transferred, fmt.Errorf("failed to read existing destination block %d: %w", op.copyBlock, err)
+			}
+			if _, err := newFile.Write(readBuf[:n]); err != nil {
+				return transferred, fmt.Errorf("failed to write copied block %d: %w", op.copyBlock, err)
+			}
+			transferred += int64(n)
+		} else {
+			if _, err := newFile.Write(op.literal); err != nil {
+				return transferred, fmt.Errorf("failed to write literal bytes: %w", err)
+			}
+			transferred += int64(len(op.literal))
+		}
+		if onProgress != nil {
+			onProgress(transferred, total)
+		}
+	}
+
+	if err := newFile.Sync(); err != nil {
+		log.Printf("[Sync] fsync not supported by destination, skipping: %v", err)
+	}
+	if err := newFile.Close(); err != nil {
+		return transferred, fmt.Errorf("failed to close destination temp file: %w", err)
+	}
+	if oldFile != nil {
+		oldFile.Close()
+		oldFile = nil
+	}
+
+	if err := destSFTP.PosixRename(tempPath, destPath); err != nil {
+		_ = destSFTP.Remove(destPath)
+		if err := destSFTP.Rename(tempPath, destPath); err != nil {
+			return transferred, fmt.Errorf("failed to rename %q to %q: %w", tempPath, destPath, err)
+		}
+	}
+
+	return transferred, nil
+}
+
+// SyncFileDelta transfers only the blocks of sourcePath that differ from the
+// destination's current content: it builds a signature map from destPath's
+// existing blocks (empty if destPath doesn't exist yet, so every byte falls
+// back to a literal, same as a full copy), diffs sourcePath against it, and
+// replays the resulting copy/literal instructions into a new destination
+// file before renaming it over destPath.
+func (m *Manager) SyncFileDelta(ctx context.Context, sourcePath, sourceTarget, destPath, destTarget string, onProgress func(transferred, total int64)) (int64, error) {
+	srcAlias, err := m.resolveTarget(sourceTarget)
+	if err != nil {
+		return 0, fmt.Errorf("source: %w", err)
+	}
+	dstAlias, err := m.resolveTarget(destTarget)
+	if err != nil {
+		return 0, fmt.Errorf("destination: %w", err)
+	}
+
+	resolvedSrc := m.resolvePath(sourcePath, srcAlias)
+	resolvedDst := m.resolvePath(destPath, dstAlias)
+
+	srcClient, err := m.clientForAlias(srcAlias)
+	if err != nil {
+		return 0, fmt.Errorf("source: %w", err)
+	}
+	dstClient, err := m.clientForAlias(dstAlias)
+	if err != nil {
+		return 0, fmt.Errorf("destination: %w", err)
+	}
+
+	srcSFTP, err := srcClient.SFTP()
+	if err != nil {
+		return 0, fmt.Errorf("source: %w", err)
+	}
+	dstSFTP, err := dstClient.SFTP()
+	if err != nil {
+		return 0, fmt.Errorf("destination: %w", err)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	sigs := make(map[uint32][]blockSignature)
+	if _, err := dstSFTP.Stat(resolvedDst); err == nil {
+		sigs, err = buildDeltaSignatures(dstSFTP, resolvedDst)
+		if err != nil {
+			return 0, fmt.Errorf("destination: %w", err)
+		}
+	}
+
+	ops, total, err := buildDeltaOps(srcSFTP, resolvedSrc, sigs)
+	if err != nil {
+		return 0, fmt.Errorf("source: %w", err)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	transferred, err := replayDeltaOps(dstSFTP, resolvedDst, ops, total, onProgress)
+	if err != nil {
+		return transferred, fmt.Errorf("destination: %w", err)
+	}
+
+	return transferred, nil
+}
@@ -0,0 +1,252 @@
+package ssh
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// HostKeyMode selects how Client.connect verifies the remote host key.
+type HostKeyMode string
+
+const (
+	// HostKeyStrict verifies the remote key against known_hosts and
+	// rejects both unknown and mismatched keys.
+	HostKeyStrict HostKeyMode = "strict"
+	// HostKeyTOFU ("trust on first use") pins whatever key is presented on
+	// the first connection to a host and rejects any later mismatch.
+	HostKeyTOFU HostKeyMode = "tofu"
+	// HostKeyInsecure skips verification entirely. Must be requested
+	// explicitly; it is never the default.
+	HostKeyInsecure HostKeyMode = "insecure"
+)
+
+// DefaultHostKeyMode is used when ConnectOptions.HostKeyMode is empty.
+const DefaultHostKeyMode = HostKeyTOFU
+
+// HostKeyMismatchError means the remote host presented a key that doesn't
+// match the pinned/known entry for its address. This can mean the host key
+// was legitimately rotated, or that the connection is being intercepted;
+// callers should surface it distinctly from a generic dial error rather
+// than retrying silently.
+type HostKeyMismatchError struct {
+	Host            string
+	GotFingerprint  string
+	WantFingerprint string
+}
+
+func (e *HostKeyMismatchError) Error() string {
+	return fmt.Sprintf("host key mismatch for %s: known_hosts has %s, server presented %s (possible MITM, or the host key was rotated)",
+		e.Host, e.WantFingerprint, e.GotFingerprint)
+}
+
+// HostKeyEntry is one known_hosts record, exposed to the known_hosts tool.
+type HostKeyEntry struct {
+	Host        string
+	Fingerprint string
+	KeyType     string
+}
+
+// HostKeyStore manages a known_hosts file alongside the system SSH key,
+// implementing strict verification and trust-on-first-use pinning.
+type HostKeyStore struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewHostKeyStore returns a store backed by a known_hosts file in the same
+// directory as keyPath (the KeyManager's private key).
+func NewHostKeyStore(keyPath string) *HostKeyStore {
+	return &HostKeyStore{path: filepath.Join(filepath.Dir(keyPath), "known_hosts")}
+}
+
+// Callback returns an ssh.HostKeyCallback implementing mode against this
+// store's known_hosts file.
+func (s *HostKeyStore) Callback(mode HostKeyMode) (ssh.HostKeyCallback, error) {
+	switch mode {
+	case HostKeyInsecure:
+		return ssh.InsecureIgnoreHostKey(), nil
+	case HostKeyStrict:
+		return s.strictCallback()
+	case HostKeyTOFU, "":
+		return s.tofuCallback()
+	default:
+		return nil, fmt.Errorf("unknown host key mode %q", mode)
+	}
+}
+
+func (s *HostKeyStore) ensureFile() error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(s.path, os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+// mismatchOrErr turns a knownhosts lookup error into a HostKeyMismatchError
+// when the host is known under a different key, or wraps it otherwise.
+func mismatchOrErr(hostname string, key ssh.PublicKey, err error) error {
+	var keyErr *knownhosts.KeyError
+	if errors.As(err, &keyErr) && len(keyErr.Want) > 0 {
+		return &HostKeyMismatchError{
+			Host:            hostname,
+			GotFingerprint:  ssh.FingerprintSHA256(key),
+			WantFingerprint: ssh.FingerprintSHA256(keyErr.Want[0].Key),
+		}
+	}
+	return fmt.Errorf("host key rejected for %s: %w", hostname, err)
+}
+
+func (s *HostKeyStore) strictCallback() (ssh.HostKeyCallback, error) {
+	if err := s.ensureFile(); err != nil {
+		return nil, fmt.Errorf("failed to prepare known_hosts: %w", err)
+	}
+	cb, err := knownhosts.New(s.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load known_hosts: %w", err)
+	}
+
+	return func(hostname string, remote net.Addr, key ssh.PublicKey) error {
+		if err := cb(hostname, remote, key); err != nil {
+			return mismatchOrErr(hostname, key, err)
+		}
+		return nil
+	}, nil
+}
+
+func (s *HostKeyStore) tofuCallback() (ssh.HostKeyCallback, error) {
+	if err := s.ensureFile(); err != nil {
+		return nil, fmt.Errorf("failed to prepare known_hosts: %w", err)
+	}
+
+	return func(hostname string, remote net.Addr, key ssh.PublicKey) error {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		cb, err := knownhosts.New(s.path)
+		if err != nil {
+			return fmt.Errorf("failed to load known_hosts: %w", err)
+		}
+
+		err = cb(hostname, remote, key)
+		if err == nil {
+			return nil
+		}
+
+		var keyErr *knownhosts.KeyError
+		if errors.As(err, &keyErr) && len(keyErr.Want) > 0 {
+			return mismatchOrErr(hostname, key, err)
+		}
+
+		// Unknown host: pin the presented key.
+		return s.pin(hostname, key)
+	}, nil
+}
+
+// pin appends key as the known_hosts entry for hostname.
+func (s *HostKeyStore) pin(hostname string, key ssh.PublicKey) error {
+	f, err := os.OpenFile(s.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open known_hosts for writing: %w", err)
+	}
+	defer f.Close()
+
+	line := knownhosts.Line([]string{knownhosts.Normalize(hostname)}, key)
+	if _, err := f.WriteString(line + "\n"); err != nil {
+		return fmt.Errorf("failed to write known_hosts entry: %w", err)
+	}
+
+	log.Printf("[SSH] Trust-on-first-use: pinned host key for %s (%s)", hostname, ssh.FingerprintSHA256(key))
+	return nil
+}
+
+// List returns every pinned known_hosts entry.
+func (s *HostKeyStore) List() ([]HostKeyEntry, error) {
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read known_hosts: %w", err)
+	}
+
+	var entries []HostKeyEntry
+	remaining := data
+	for len(remaining) > 0 {
+		_, hosts, pubKey, _, rest, err := ssh.ParseKnownHosts(remaining)
+		if err != nil {
+			break
+		}
+		remaining = rest
+		for _, h := range hosts {
+			entries = append(entries, HostKeyEntry{
+				Host:        h,
+				Fingerprint: ssh.FingerprintSHA256(pubKey),
+				KeyType:     pubKey.Type(),
+			})
+		}
+	}
+	return entries, nil
+}
+
+// Remove deletes every known_hosts entry for host, rewriting the file, and
+// returns how many entries were removed.
+func (s *HostKeyStore) Remove(host string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to read known_hosts: %w", err)
+	}
+
+	normalized := knownhosts.Normalize(host)
+	lines := strings.Split(string(data), "\n")
+	kept := make([]string, 0, len(lines))
+	removed := 0
+
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" {
+			continue
+		}
+		fields := strings.Fields(trimmed)
+		if len(fields) > 0 && hostFieldMatches(fields[0], normalized) {
+			removed++
+			continue
+		}
+		kept = append(kept, line)
+	}
+
+	if removed == 0 {
+		return 0, nil
+	}
+
+	if err := os.WriteFile(s.path, []byte(strings.Join(kept, "\n")+"\n"), 0600); err != nil {
+		return 0, fmt.Errorf("failed to rewrite known_hosts: %w", err)
+	}
+	return removed, nil
+}
+
+func hostFieldMatches(hostsField, normalized string) bool {
+	for _, h := range strings.Split(hostsField, ",") {
+		if h == normalized {
+			return true
+		}
+	}
+	return false
+}
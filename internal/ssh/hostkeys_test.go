@@ -0,0 +1,103 @@
+package ssh
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"net"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/crypto/ssh"
+)
+
+func testSigner(t *testing.T) ssh.Signer {
+	t.Helper()
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate test key: %v", err)
+	}
+	signer, err := ssh.NewSignerFromKey(priv)
+	if err != nil {
+		t.Fatalf("failed to wrap test key: %v", err)
+	}
+	return signer
+}
+
+func TestHostKeyStoreTOFUPinsThenAccepts(t *testing.T) {
+	dir := t.TempDir()
+	store := NewHostKeyStore(filepath.Join(dir, "id_ed25519"))
+	signer := testSigner(t)
+	addr := &net.TCPAddr{IP: net.ParseIP("127.0.0.1"), Port: 22}
+
+	cb, err := store.Callback(HostKeyTOFU)
+	if err != nil {
+		t.Fatalf("Callback: %v", err)
+	}
+
+	if err := cb("example.test:22", addr, signer.PublicKey()); err != nil {
+		t.Fatalf("first connection should pin the key, got error: %v", err)
+	}
+
+	if err := cb("example.test:22", addr, signer.PublicKey()); err != nil {
+		t.Fatalf("second connection with the same key should succeed, got: %v", err)
+	}
+
+	other := testSigner(t)
+	err = cb("example.test:22", addr, other.PublicKey())
+	if err == nil {
+		t.Fatal("expected mismatch error for a different key, got nil")
+	}
+	if _, ok := err.(*HostKeyMismatchError); !ok {
+		t.Fatalf("expected *HostKeyMismatchError, got %T: %v", err, err)
+	}
+}
+
+func TestHostKeyStoreStrictRejectsUnknown(t *testing.T) {
+	dir := t.TempDir()
+	store := NewHostKeyStore(filepath.Join(dir, "id_ed25519"))
+	signer := testSigner(t)
+	addr := &net.TCPAddr{IP: net.ParseIP("127.0.0.1"), Port: 22}
+
+	cb, err := store.Callback(HostKeyStrict)
+	if err != nil {
+		t.Fatalf("Callback: %v", err)
+	}
+
+	if err := cb("example.test:22", addr, signer.PublicKey()); err == nil {
+		t.Fatal("expected strict mode to reject an unknown host, got nil")
+	}
+}
+
+func TestHostKeyStoreListAndRemove(t *testing.T) {
+	dir := t.TempDir()
+	store := NewHostKeyStore(filepath.Join(dir, "id_ed25519"))
+	signer := testSigner(t)
+	addr := &net.TCPAddr{IP: net.ParseIP("127.0.0.1"), Port: 22}
+
+	cb, _ := store.Callback(HostKeyTOFU)
+	if err := cb("example.test:22", addr, signer.PublicKey()); err != nil {
+		t.Fatalf("pin: %v", err)
+	}
+
+	entries, err := store.List()
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	// knownhosts normalizes away the default SSH port (22).
+	if len(entries) != 1 || entries[0].Host != "example.test" {
+		t.Fatalf("expected one entry for example.test, got %+v", entries)
+	}
+
+	removed, err := store.Remove("example.test:22")
+	if err != nil {
+		t.Fatalf("Remove: %v", err)
+	}
+	if removed != 1 {
+		t.Fatalf("expected 1 entry removed, got %d", removed)
+	}
+
+	entries, _ = store.List()
+	if len(entries) != 0 {
+		t.Fatalf("expected no entries after removal, got %+v", entries)
+	}
+}
@@ -135,6 +135,11 @@ func (km *KeyManager) LoadPrivateKey() (ssh.Signer, error) {
 	return signer, nil
 }
 
+// Path returns the resolved private key path (after env-based defaulting).
+func (km *KeyManager) Path() string {
+	return km.keyPath
+}
+
 // GetPublicKey returns the public key string.
 func (km *KeyManager) GetPublicKey() (string, error) {
 	pubKeyBytes, err := os.ReadFile(km.keyPath + ".pub")
@@ -10,6 +10,9 @@ import (
 	"path/filepath"
 	"strings"
 	"sync"
+	"time"
+
+	"ssh-mcp/internal/metrics"
 
 	"golang.org/x/crypto/ssh"
 )
@@ -19,21 +22,49 @@ type Manager struct {
 	connections map[string]*Client
 	primary     string
 	keyManager  *KeyManager
+	hostKeys    *HostKeyStore
 	mu          sync.RWMutex
 	aliasLocks  map[string]*sync.Mutex
+	tunnels     map[string]*Tunnel
+	shells      map[string]*ShellSession
+	shellsMu    sync.Mutex
+
+	// containerRuntimes caches one resolved container runtime backend
+	// (Docker/Podman Engine API, or containerd via nerdctl) per alias, set
+	// by the tools layer after a successful resolve. It's held as
+	// io.Closer rather than container.ContainerRuntime so this package
+	// doesn't need to import internal/container; Close tears these down
+	// alongside the connections they run over.
+	containerRuntimes map[string]io.Closer
+
+	// rootDir bounds the local filesystem paths validatePath will accept
+	// (e.g. transfer destinations), independent of the remote paths
+	// resolvePath deals with. "/" imposes no real restriction.
+	rootDir string
 }
 
-// NewManager creates a new SSH connection manager.
-func NewManager(keyPath string) *Manager {
+// NewManager creates a new SSH connection manager. rootDir is the local
+// filesystem root validatePath confines paths to; pass "/" for no
+// restriction.
+func NewManager(keyPath, rootDir string) *Manager {
+	if rootDir == "" {
+		rootDir = "/"
+	}
+
 	mgr := &Manager{
-		connections: make(map[string]*Client),
-		keyManager:  NewKeyManager(keyPath),
-		aliasLocks:  make(map[string]*sync.Mutex),
+		connections:       make(map[string]*Client),
+		keyManager:        NewKeyManager(keyPath),
+		aliasLocks:        make(map[string]*sync.Mutex),
+		tunnels:           make(map[string]*Tunnel),
+		shells:            make(map[string]*ShellSession),
+		containerRuntimes: make(map[string]io.Closer),
+		rootDir:           filepath.Clean(rootDir),
 	}
 
 	if err := mgr.keyManager.EnsureKey(); err != nil {
 		log.Printf("[Manager] Warning: %v", err)
 	}
+	mgr.hostKeys = NewHostKeyStore(mgr.keyManager.Path())
 
 	return mgr
 }
@@ -87,6 +118,33 @@ type ConnectOptions struct {
 	PrivateKeyPath string
 	Alias          string
 	Via            string
+
+	// ReconnectMaxRetries, ReconnectBaseDelayMs and ReconnectMaxDelayMs
+	// override DefaultReconnectPolicy for this connection when non-zero.
+	ReconnectMaxRetries  int
+	ReconnectBaseDelayMs int
+	ReconnectMaxDelayMs  int
+
+	// HostKeyMode selects host key verification ("strict", "tofu",
+	// "insecure"); defaults to DefaultHostKeyMode when empty.
+	HostKeyMode HostKeyMode
+
+	// RequireRoot fails the connection unless the remote user is root or
+	// passwordless-sudo capable.
+	RequireRoot bool
+	// Escalate is "sudo" to transparently prefix Run commands with
+	// "sudo -n" when not already root, or "" to run as the connected user.
+	Escalate string
+
+	// KeepaliveIntervalSec and KeepaliveCountMax configure the background
+	// keepalive loop that AutoReconnect starts; zero uses Client's
+	// defaults (30s interval, 3 consecutive failures).
+	KeepaliveIntervalSec int
+	KeepaliveCountMax    int
+	// AutoReconnect starts a background keepalive loop that reconnects
+	// after repeated keepalive failures, so a long-lived pooled connection
+	// survives a silent NAT/firewall timeout between tool calls.
+	AutoReconnect bool
 }
 
 // Connect establishes an SSH connection and returns the alias.
@@ -139,12 +197,35 @@ func (m *Manager) Connect(ctx context.Context, opts ConnectOptions) (alias strin
 		}
 	}()
 
+	hostKeyMode := opts.HostKeyMode
+	if hostKeyMode == "" {
+		hostKeyMode = DefaultHostKeyMode
+	}
+
 	creds := Credentials{
-		Host:     opts.Host,
-		Port:     opts.Port,
-		Username: opts.Username,
-		Password: opts.Password,
-		Via:      opts.Via,
+		Host:              opts.Host,
+		Port:              opts.Port,
+		Username:          opts.Username,
+		Password:          opts.Password,
+		Via:               opts.Via,
+		Reconnect:         DefaultReconnectPolicy,
+		HostKeyMode:       hostKeyMode,
+		HostKeyStore:      m.hostKeys,
+		Escalate:          opts.Escalate,
+		KeepaliveCountMax: opts.KeepaliveCountMax,
+		AutoReconnect:     opts.AutoReconnect,
+	}
+	if opts.KeepaliveIntervalSec > 0 {
+		creds.KeepaliveInterval = time.Duration(opts.KeepaliveIntervalSec) * time.Second
+	}
+	if opts.ReconnectMaxRetries > 0 {
+		creds.Reconnect.MaxRetries = opts.ReconnectMaxRetries
+	}
+	if opts.ReconnectBaseDelayMs > 0 {
+		creds.Reconnect.BaseDelay = time.Duration(opts.ReconnectBaseDelayMs) * time.Millisecond
+	}
+	if opts.ReconnectMaxDelayMs > 0 {
+		creds.Reconnect.MaxDelay = time.Duration(opts.ReconnectMaxDelayMs) * time.Millisecond
 	}
 
 	if opts.PrivateKeyPath != "" {
@@ -176,11 +257,21 @@ func (m *Manager) Connect(ctx context.Context, opts ConnectOptions) (alias strin
 		}
 	}
 
+	dialStart := time.Now()
 	client, err := NewClient(opts.Alias, creds, jumpClient)
+	metrics.PoolDialDuration.WithLabelValues(opts.Host).Observe(time.Since(dialStart).Seconds())
 	if err != nil {
 		return "", err
 	}
 
+	if opts.RequireRoot {
+		caps := client.Capabilities()
+		if caps.UID != "0" && !caps.CanSudo {
+			client.Close()
+			return "", fmt.Errorf("connection requires root or passwordless sudo, but %s@%s has neither (uid=%s)", opts.Username, opts.Host, caps.UID)
+		}
+	}
+
 	m.mu.Lock()
 	m.connections[opts.Alias] = client
 	if m.primary == "" {
@@ -188,16 +279,18 @@ func (m *Manager) Connect(ctx context.Context, opts ConnectOptions) (alias strin
 	}
 	m.mu.Unlock()
 
+	metrics.ActiveConnections.Inc()
+
 	return opts.Alias, nil
 }
 
 // Disconnect closes one or all connections.
 func (m *Manager) Disconnect(alias string) (string, error) {
 	m.mu.Lock()
-	defer m.mu.Unlock()
 
 	if alias == "" {
 		count := 0
+		var staleTunnels []*Tunnel
 		for a, client := range m.connections {
 			if client != nil {
 				client.Close()
@@ -205,20 +298,50 @@ func (m *Manager) Disconnect(alias string) (string, error) {
 			}
 			delete(m.connections, a)
 		}
+		for a, dc := range m.containerRuntimes {
+			dc.Close()
+			delete(m.containerRuntimes, a)
+		}
+		for id, t := range m.tunnels {
+			staleTunnels = append(staleTunnels, t)
+			delete(m.tunnels, id)
+		}
 		m.primary = ""
+		m.mu.Unlock()
+
+		for _, t := range staleTunnels {
+			t.close()
+		}
+		m.closeAllShells()
+		metrics.ActiveConnections.Sub(float64(count))
 		return fmt.Sprintf("Disconnected all (%d) connections", count), nil
 	}
 
 	client, ok := m.connections[alias]
 	if !ok {
+		m.mu.Unlock()
 		return "", fmt.Errorf("no connection with alias '%s'", alias)
 	}
 
 	if client != nil {
 		client.Close()
+		metrics.ActiveConnections.Dec()
 	}
 	delete(m.connections, alias)
 
+	if dc, ok := m.containerRuntimes[alias]; ok {
+		dc.Close()
+		delete(m.containerRuntimes, alias)
+	}
+
+	var staleTunnels []*Tunnel
+	for id, t := range m.tunnels {
+		if t.Alias == alias {
+			staleTunnels = append(staleTunnels, t)
+			delete(m.tunnels, id)
+		}
+	}
+
 	if m.primary == alias {
 		m.primary = ""
 		for a, c := range m.connections {
@@ -228,6 +351,12 @@ func (m *Manager) Disconnect(alias string) (string, error) {
 			}
 		}
 	}
+	m.mu.Unlock()
+
+	for _, t := range staleTunnels {
+		t.close()
+	}
+	m.closeShellsForAlias(alias)
 
 	return fmt.Sprintf("Disconnected '%s'", alias), nil
 }
@@ -276,9 +405,9 @@ func (m *Manager) Run(ctx context.Context, cmd, target string) (*RunResult, erro
 	result, err := client.Run(ctx, cmd)
 	if err != nil {
 		if isConnectionError(err) {
-			log.Printf("[Manager] Connection lost for %s, reconnecting...", alias)
-			if reconnErr := client.Reconnect(m.getJumpClient(client.creds.Via)); reconnErr != nil {
-				return nil, fmt.Errorf("reconnect failed: %w", reconnErr)
+			m.closeTunnelsForAlias(alias)
+			if reconnErr := m.reconnectWithBackoff(ctx, client, alias); reconnErr != nil {
+				return nil, reconnErr
 			}
 			return client.Run(ctx, cmd)
 		}
@@ -288,6 +417,36 @@ func (m *Manager) Run(ctx context.Context, cmd, target string) (*RunResult, erro
 	return result, nil
 }
 
+// reconnectWithBackoff retries client.Reconnect according to its
+// ReconnectPolicy, doubling the delay between attempts (with jitter) until
+// it succeeds, MaxRetries is exhausted, or ctx is cancelled.
+func (m *Manager) reconnectWithBackoff(ctx context.Context, client *Client, alias string) error {
+	policy := client.creds.Reconnect
+
+	var lastErr error
+	for attempt := 0; attempt <= policy.MaxRetries; attempt++ {
+		if attempt > 0 {
+			d := policy.delay(attempt - 1)
+			log.Printf("[Manager] Reconnect attempt %d/%d for %s in %s", attempt+1, policy.MaxRetries+1, alias, d)
+			select {
+			case <-time.After(d):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		} else {
+			log.Printf("[Manager] Connection lost for %s, reconnecting...", alias)
+		}
+
+		metrics.ReconnectsTotal.WithLabelValues(alias).Inc()
+		lastErr = client.Reconnect(m.getJumpClient(client.creds.Via))
+		if lastErr == nil {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("reconnect failed after %d attempts: %w", policy.MaxRetries+1, lastErr)
+}
+
 // getJumpClient returns the jump client.
 func (m *Manager) getJumpClient(via string) *Client {
 	if via == "" {
@@ -346,6 +505,46 @@ func (m *Manager) Execute(ctx context.Context, cmd, target string) (string, erro
 	return outputStr, nil
 }
 
+// sessionReader wraps a live ssh.Session's stdout, closing the session
+// (which terminates the remote command) when the caller is done reading.
+type sessionReader struct {
+	io.Reader
+	session *ssh.Session
+}
+
+func (s *sessionReader) Close() error {
+	return s.session.Close()
+}
+
+// StreamCommand runs cmd against target without waiting for it to finish,
+// returning its live stdout as an io.ReadCloser. Closing it terminates the
+// remote command. Used for long-running producers whose output should be
+// consumed as it arrives rather than buffered until exit (e.g. sip_tail_sip
+// tailing a live tcpdump capture).
+func (m *Manager) StreamCommand(ctx context.Context, cmd, target string) (io.ReadCloser, error) {
+	alias, err := m.resolveTarget(target)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := m.clientForAlias(alias)
+	if err != nil {
+		return nil, err
+	}
+
+	session, stdout, err := client.StreamCommand(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	go func() {
+		<-ctx.Done()
+		session.Close()
+	}()
+
+	return &sessionReader{Reader: stdout, session: session}, nil
+}
+
 // resolvePath resolves a path to an absolute path using the connection's CWD.
 // No path restrictions — the connected user's OS permissions are the only boundary.
 func (m *Manager) resolvePath(path, alias string) string {
@@ -365,6 +564,72 @@ func (m *Manager) resolvePath(path, alias string) string {
 	return filepath.Clean(path)
 }
 
+// validatePath resolves path to an absolute path (relative to alias's CWD,
+// same as resolvePath) and confirms it stays within the Manager's rootDir.
+// Unlike resolvePath, which deliberately leaves remote paths unrestricted
+// since the remote OS's own permissions are the boundary, validatePath
+// guards local filesystem paths — e.g. transfer destinations — where a
+// crafted argument could otherwise escape the intended directory.
+//
+// Symlinks are resolved on the parent directory (the leaf itself may not
+// exist yet, e.g. a file about to be created) and re-checked for
+// containment, so a symlink planted inside rootDir that points outside it
+// can't be used to escape.
+func (m *Manager) validatePath(path, alias string) (string, error) {
+	if strings.IndexByte(path, 0) >= 0 {
+		return "", fmt.Errorf("invalid path: contains NUL byte")
+	}
+
+	m.mu.RLock()
+	client := m.connections[alias]
+	m.mu.RUnlock()
+
+	cwd := "/"
+	if client != nil {
+		cwd = client.CWD()
+	}
+
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(cwd, path)
+	}
+
+	return validateLocalPath(filepath.Clean(path), m.rootDir)
+}
+
+// validateLocalPath confines an already-absolute local path to root,
+// re-checking containment on the symlink-resolved parent directory the
+// same way validatePath does. It's split out so callers without a live
+// Manager/alias — Client.Get/Put's per-file transfer paths, in
+// particular, which run below the Manager layer — can still enforce the
+// rootDir boundary validatePath documents.
+func validateLocalPath(cleanPath, root string) (string, error) {
+	if root == "" || root == "/" {
+		return cleanPath, nil
+	}
+
+	if err := pathContained(cleanPath, root); err != nil {
+		return "", err
+	}
+
+	if resolvedParent, err := filepath.EvalSymlinks(filepath.Dir(cleanPath)); err == nil {
+		resolved := filepath.Join(resolvedParent, filepath.Base(cleanPath))
+		if err := pathContained(resolved, root); err != nil {
+			return "", err
+		}
+	}
+
+	return cleanPath, nil
+}
+
+// pathContained returns an error unless path is root or a descendant of it.
+func pathContained(path, root string) error {
+	rel, err := filepath.Rel(root, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return fmt.Errorf("path %q escapes allowed root %q", path, root)
+	}
+	return nil
+}
+
 // IsRoot checks whether the connection for the given target alias is logged in as root.
 func (m *Manager) IsRoot(target string) bool {
 	alias, err := m.resolveTarget(target)
@@ -391,6 +656,93 @@ func (m *Manager) SudoPrefix(target string) string {
 	return "sudo "
 }
 
+// Capabilities returns the privilege probe (identity, sudo ability)
+// captured for target when it connected.
+func (m *Manager) Capabilities(target string) (Capabilities, error) {
+	alias, err := m.resolveTarget(target)
+	if err != nil {
+		return Capabilities{}, err
+	}
+
+	m.mu.RLock()
+	client := m.connections[alias]
+	m.mu.RUnlock()
+
+	if client == nil {
+		return Capabilities{}, fmt.Errorf("connection '%s' not found", alias)
+	}
+	return client.Capabilities(), nil
+}
+
+// Health returns the result of target's most recent keepalive probe. Unlike
+// IsConnected, this reflects whether the connection is actually answering
+// keepalives, not just whether it hasn't been closed.
+func (m *Manager) Health(target string) (HealthStatus, error) {
+	alias, err := m.resolveTarget(target)
+	if err != nil {
+		return HealthStatus{}, err
+	}
+
+	m.mu.RLock()
+	client := m.connections[alias]
+	m.mu.RUnlock()
+
+	if client == nil {
+		return HealthStatus{}, fmt.Errorf("connection '%s' not found", alias)
+	}
+	return client.IsHealthy(), nil
+}
+
+// Client returns the underlying SSH client for target's alias, so other
+// packages (e.g. internal/docker) can tunnel their own connections over it
+// without this package needing to know about them.
+func (m *Manager) Client(target string) (*Client, error) {
+	alias, err := m.resolveTarget(target)
+	if err != nil {
+		return nil, err
+	}
+
+	m.mu.RLock()
+	client := m.connections[alias]
+	m.mu.RUnlock()
+
+	if client == nil {
+		return nil, fmt.Errorf("connection '%s' not found", alias)
+	}
+	return client, nil
+}
+
+// ContainerRuntime returns the cached container runtime backend for
+// target's alias, if one has been resolved and cached via
+// SetContainerRuntime.
+func (m *Manager) ContainerRuntime(target string) (io.Closer, bool) {
+	alias, err := m.resolveTarget(target)
+	if err != nil {
+		return nil, false
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	dc, ok := m.containerRuntimes[alias]
+	return dc, ok
+}
+
+// SetContainerRuntime caches a container runtime backend for target's
+// alias, so subsequent docker_*/container_* tool calls reuse the same
+// resolved backend instead of re-probing it. Close and Disconnect tear it
+// down along with the SSH connection it tunnels over.
+func (m *Manager) SetContainerRuntime(target string, c io.Closer) error {
+	alias, err := m.resolveTarget(target)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.containerRuntimes[alias] = c
+	return nil
+}
+
 // ReadFile reads a file.
 func (m *Manager) ReadFile(ctx context.Context, path, target string) (string, error) {
 	alias, err := m.resolveTarget(target)
@@ -520,6 +872,17 @@ func (m *Manager) GetPublicKey() (string, error) {
 	return m.keyManager.GetPublicKey()
 }
 
+// ListHostKeys returns every pinned known_hosts entry.
+func (m *Manager) ListHostKeys() ([]HostKeyEntry, error) {
+	return m.hostKeys.List()
+}
+
+// RemoveHostKey deletes every known_hosts entry for host, e.g. after a
+// legitimate host key rotation, and returns how many entries were removed.
+func (m *Manager) RemoveHostKey(host string) (int, error) {
+	return m.hostKeys.Remove(host)
+}
+
 // ListConnections returns all active connection aliases.
 func (m *Manager) ListConnections() []string {
 	m.mu.RLock()
@@ -532,16 +895,34 @@ func (m *Manager) ListConnections() []string {
 	return aliases
 }
 
-// Close closes all connections.
+// Close closes all connections, tunnels, and shell sessions.
 func (m *Manager) Close() {
 	m.mu.Lock()
-	defer m.mu.Unlock()
 
+	var closed int
 	for _, client := range m.connections {
 		if client != nil {
 			client.Close()
+			closed++
 		}
 	}
 	m.connections = make(map[string]*Client)
 	m.primary = ""
+
+	for _, dc := range m.containerRuntimes {
+		dc.Close()
+	}
+	m.containerRuntimes = make(map[string]io.Closer)
+
+	tunnels := m.tunnels
+	m.tunnels = make(map[string]*Tunnel)
+	m.mu.Unlock()
+
+	metrics.ActiveConnections.Sub(float64(closed))
+
+	for _, t := range tunnels {
+		t.close()
+	}
+
+	m.closeAllShells()
 }
@@ -1,10 +1,18 @@
 package ssh
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"log"
+	"runtime/debug"
 	"sync"
 	"sync/atomic"
 	"time"
+
+	"ssh-mcp/internal/metrics"
+
+	"golang.org/x/time/rate"
 )
 
 // ContextKey is used for storing values in context.
@@ -22,11 +30,95 @@ const (
 	maxCleanupInterval = 60 * time.Second
 )
 
+// PoolOptions configures the capacity limits enforced by a Pool. A zero
+// value for any field means "unlimited", preserving the pool's original
+// unbounded behavior.
+type PoolOptions struct {
+	// MaxHeaderSessions caps the number of distinct X-Session-Key entries
+	// held in headerCache. Once full, GetByHeader/TouchHeader evict the
+	// least-recently-used idle entry to make room.
+	MaxHeaderSessions int
+
+	// MaxManagers caps the number of distinct per-MCP-session Managers held
+	// in managers. Once full, CreateSession evicts the least-recently-used
+	// idle entry to make room.
+	MaxManagers int
+
+	// MaxPerRemoteConcurrency caps the number of concurrent in-flight
+	// requests a single pooled Manager (header- or session-keyed) may serve
+	// at once. Acquiring beyond this limit fails with an error instead of
+	// blocking, so a single noisy client can't starve the others.
+	MaxPerRemoteConcurrency int
+
+	// IdleTTL overrides how long a not-in-use entry may sit untouched before
+	// the background reaper closes and removes it. Zero means defaultTimeout.
+	IdleTTL time.Duration
+
+	// HeaderLimits caps how hard a single X-Session-Key header may drive its
+	// pooled Manager, on top of MaxPerRemoteConcurrency, so one API key
+	// can't monopolize SSH connections shared by the pool. Zero value means
+	// unlimited. Enforced by TouchHeaderCtx, and so also by TouchHeader,
+	// which wraps it.
+	HeaderLimits HeaderLimits
+}
+
+// HeaderLimits configures the per-header-key concurrency cap and request
+// rate TouchHeaderCtx enforces. A zero value for any field means
+// "unlimited", matching PoolOptions' convention.
+type HeaderLimits struct {
+	// MaxConcurrent caps the number of concurrent in-flight requests a
+	// single header key's entry may hold at once.
+	MaxConcurrent int
+
+	// RatePerSecond caps the sustained rate of TouchHeaderCtx calls allowed
+	// for a single header key, enforced by a token-bucket rate.Limiter
+	// lazily created per entry.
+	RatePerSecond float64
+
+	// Burst is the token bucket's burst size. Zero defaults to 1 when
+	// RatePerSecond is set.
+	Burst int
+}
+
+// ErrBusy is returned by TouchHeaderCtx when a header key's entry is already
+// at HeaderLimits.MaxConcurrent.
+var ErrBusy = errors.New("ssh: header session is at its concurrency limit")
+
+// ErrRateLimited is returned by TouchHeaderCtx when a header key's entry has
+// exceeded HeaderLimits.RatePerSecond.
+var ErrRateLimited = errors.New("ssh: header session exceeded its rate limit")
+
 // sessionEntry tracks a manager and its last access time.
 type sessionEntry struct {
 	manager      *Manager
 	lastAccessed atomic.Int64
 	activeReqs   atomic.Int32 // Number of in-flight requests
+
+	// limiterMu guards the lazy creation of limiter below, so concurrent
+	// first callers don't race to allocate two limiters for the same entry.
+	limiterMu sync.Mutex
+	limiter   *rate.Limiter
+}
+
+// limiterFor lazily creates and returns the entry's rate limiter the first
+// time HeaderLimits.RatePerSecond is configured, so entries for pools
+// without rate limiting never allocate one. Returns nil if limits leaves
+// RatePerSecond unset (unlimited).
+func (e *sessionEntry) limiterFor(limits HeaderLimits) *rate.Limiter {
+	if limits.RatePerSecond <= 0 {
+		return nil
+	}
+
+	e.limiterMu.Lock()
+	defer e.limiterMu.Unlock()
+	if e.limiter == nil {
+		burst := limits.Burst
+		if burst <= 0 {
+			burst = 1
+		}
+		e.limiter = rate.NewLimiter(rate.Limit(limits.RatePerSecond), burst)
+	}
+	return e.limiter
 }
 
 func (e *sessionEntry) touch() {
@@ -50,6 +142,17 @@ func (e *sessionEntry) inUse() bool {
 	return e.activeReqs.Load() > 0
 }
 
+// tryAcquire increments the active-request count and returns true, unless
+// max is positive and the count is already at max, in which case it leaves
+// the entry untouched and returns false.
+func (e *sessionEntry) tryAcquire(max int) bool {
+	if max > 0 && int(e.activeReqs.Load()) >= max {
+		return false
+	}
+	e.acquire()
+	return true
+}
+
 // Pool manages SSH Managers for multiple MCP sessions.
 // Supports three modes:
 // 1. Global: Single shared manager (-global flag)
@@ -57,7 +160,7 @@ func (e *sessionEntry) inUse() bool {
 // 3. Session-based: Per MCP session ID (default)
 type Pool struct {
 	// Per-session managers (keyed by session ID)
-	managers   map[string]*Manager
+	managers   map[string]*sessionEntry
 	managersMu sync.RWMutex
 
 	// Header-based cache (keyed by X-Session-Key header)
@@ -72,17 +175,80 @@ type Pool struct {
 	timeout      time.Duration
 	nextInterval time.Duration
 	stopCleanup  chan struct{}
+
+	// store persists header-keyed Manager state so sessions survive process
+	// restarts and can be shared across a fleet (see PoolStore).
+	store PoolStore
+
+	// opts holds the capacity limits enforced on managers/headerCache.
+	opts PoolOptions
+
+	// panicHandler is called with the key, recovered value, and stack trace
+	// whenever Do recovers a panic from fn, in addition to logging and the
+	// PanicError returned to the caller. nil means "just log it".
+	panicHandler PanicHandler
+
+	// panicsTotal mirrors metrics.PoolPanicsTotal for PoolStats, so the
+	// pool_stats tool can surface it without scraping /metrics.
+	panicsTotal atomic.Int64
 }
 
-// NewPool creates a new session pool.
+// PanicHandler is called by Pool.Do with the resolution key, the recovered
+// panic value, and a stack trace, whenever fn panics. Set via
+// Pool.SetPanicHandler to plug in custom alerting; the default behavior is a
+// log.Printf.
+type PanicHandler func(key string, v any, stack []byte)
+
+// PanicError wraps a panic recovered from a Pool.Do call, so a bad operation
+// (e.g. a buggy SFTP transfer) surfaces as a regular error instead of taking
+// down the process.
+type PanicError struct {
+	Key   string
+	Value any
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("ssh: recovered panic in Do(%s): %v", e.Key, e.Value)
+}
+
+// SetPanicHandler installs the handler Do calls when fn panics. Pass nil to
+// fall back to the default log.Printf behavior.
+func (p *Pool) SetPanicHandler(h PanicHandler) {
+	p.panicHandler = h
+}
+
+// NewPool creates a new session pool backed by an in-process MemoryStore,
+// with no capacity limits.
 func NewPool(globalMode bool) *Pool {
+	return NewPoolWithStore(globalMode, NewMemoryStore())
+}
+
+// NewPoolWithStore creates a new session pool backed by the given PoolStore,
+// e.g. a RedisStore for sharing sessions across a horizontally-scaled fleet,
+// with no capacity limits.
+func NewPoolWithStore(globalMode bool, store PoolStore) *Pool {
+	return NewPoolWithOptions(globalMode, store, PoolOptions{})
+}
+
+// NewPoolWithOptions creates a new session pool backed by the given
+// PoolStore, enforcing opts' capacity limits. A zero-value PoolOptions
+// leaves the pool unbounded, matching NewPool/NewPoolWithStore.
+func NewPoolWithOptions(globalMode bool, store PoolStore, opts PoolOptions) *Pool {
+	timeout := defaultTimeout
+	if opts.IdleTTL > 0 {
+		timeout = opts.IdleTTL
+	}
+
 	pool := &Pool{
-		managers:     make(map[string]*Manager),
+		managers:     make(map[string]*sessionEntry),
 		headerCache:  make(map[string]*sessionEntry),
 		globalMode:   globalMode,
-		timeout:      defaultTimeout,
+		timeout:      timeout,
 		nextInterval: 30 * time.Second,
 		stopCleanup:  make(chan struct{}),
+		store:        store,
+		opts:         opts,
 	}
 
 	if globalMode {
@@ -91,7 +257,7 @@ func NewPool(globalMode bool) *Pool {
 	} else {
 		// Start cleanup goroutine
 		go pool.cleanupLoop()
-		log.Printf("[Pool] Started with %v session timeout", pool.timeout)
+		log.Printf("[Pool] Started with %v session timeout, limits=%+v", pool.timeout, opts)
 	}
 
 	return pool
@@ -104,9 +270,129 @@ func (p *Pool) Get(sessionID string) *Manager {
 	}
 
 	p.managersMu.RLock()
-	mgr := p.managers[sessionID]
+	entry := p.managers[sessionID]
 	p.managersMu.RUnlock()
-	return mgr
+	if entry == nil {
+		return nil
+	}
+	entry.touch()
+	return entry.manager
+}
+
+// Do resolves the Manager for key (an existing session-ID-keyed entry takes
+// priority; otherwise key is treated as a header key and lazily
+// TouchHeader'd into existence), acquires it for the duration of fn, and
+// guarantees the acquire is released afterward - even if fn panics. A
+// panicking fn is recovered and returned as a *PanicError instead of
+// crashing the process, so callers don't have to get acquire/release
+// bookkeeping right by hand for every operation (e.g. a bad SFTP op).
+func (p *Pool) Do(ctx context.Context, key string, fn func(*Manager) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if p.globalMode {
+		return p.invoke(key, p.global, fn)
+	}
+
+	p.managersMu.RLock()
+	entry, isSession := p.managers[key]
+	p.managersMu.RUnlock()
+	if isSession {
+		entry.acquire()
+		defer entry.release()
+		return p.invoke(key, entry.manager, fn)
+	}
+
+	if err := p.TouchHeaderCtx(ctx, key); err != nil {
+		return err
+	}
+	defer p.ReleaseHeader(key)
+
+	mgr := p.GetByHeader(key)
+	if mgr == nil {
+		return fmt.Errorf("ssh: Do: no manager for key %q", key)
+	}
+	return p.invoke(key, mgr, fn)
+}
+
+// invoke calls fn, recovering and converting a panic into a *PanicError
+// instead of letting it propagate and take down the process.
+func (p *Pool) invoke(key string, mgr *Manager, fn func(*Manager) error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			stack := debug.Stack()
+			p.RecordPanic(key, r, stack)
+			err = &PanicError{Key: key, Value: r, Stack: stack}
+		}
+	}()
+	return fn(mgr)
+}
+
+// RecordPanic logs a panic recovered from key, increments panicsTotal and
+// metrics.PoolPanicsTotal, and invokes the configured PanicHandler if set.
+// Do calls it for panics it recovers itself; it's also exported so a
+// recover placed elsewhere - e.g. the MCP tool-dispatch middleware in
+// cmd/server/main.go, which wraps getManager-based handlers that call
+// Manager methods directly rather than through Do - can feed the same
+// pool-wide panic accounting instead of the panic only ever reaching
+// PoolStats.PanicsTotal for the few callers that happen to use Do.
+func (p *Pool) RecordPanic(key string, r any, stack []byte) {
+	p.panicsTotal.Add(1)
+	metrics.PoolPanicsTotal.Inc()
+	if p.panicHandler != nil {
+		p.panicHandler(key, r, stack)
+	} else {
+		log.Printf("[Pool] recovered panic (%s): %v\n%s", key, r, stack)
+	}
+}
+
+// evictLRULocked scans entries for the least-recently-used idle (not
+// inUse()) entry and removes it, returning the evicted Manager so the
+// caller can Close it outside the lock. Callers must hold the map's write
+// lock. Returns ok=false if every entry is pinned (inUse()), meaning the
+// map is already at capacity with no room to make.
+func evictLRULocked(entries map[string]*sessionEntry) (mgr *Manager, ok bool) {
+	var oldestKey string
+	var oldestEntry *sessionEntry
+
+	for key, entry := range entries {
+		if entry.inUse() {
+			continue
+		}
+		if oldestEntry == nil || entry.lastAccessed.Load() < oldestEntry.lastAccessed.Load() {
+			oldestKey, oldestEntry = key, entry
+		}
+	}
+
+	if oldestEntry == nil {
+		return nil, false
+	}
+
+	delete(entries, oldestKey)
+	return oldestEntry.manager, true
+}
+
+// ensureCapacityLocked evicts the least-recently-used idle entry from
+// entries if adding one more would exceed max (max<=0 means unlimited), so
+// the caller can insert a new entry afterward. Callers must hold the
+// corresponding map's write lock. Returns the evicted Manager, to be Closed
+// by the caller after unlocking, or an error if the pool is full and every
+// entry is pinned (inUse()).
+func ensureCapacityLocked(entries map[string]*sessionEntry, max int, poolType string) (evicted *Manager, err error) {
+	if max <= 0 || len(entries) < max {
+		return nil, nil
+	}
+
+	mgr, ok := evictLRULocked(entries)
+	if !ok {
+		return nil, fmt.Errorf("%s pool is at capacity (%d) and every session is in use", poolType, max)
+	}
+
+	metrics.PoolEvictionsTotal.WithLabelValues(poolType).Inc()
+	metrics.PoolManagers.WithLabelValues(poolType).Dec()
+	log.Printf("[Pool] Evicted LRU idle %s entry to stay within capacity %d", poolType, max)
+	return mgr, nil
 }
 
 // GetByHeader returns a Manager for the given header key.
@@ -148,14 +434,43 @@ func (p *Pool) GetByHeader(headerKey string) *Manager {
 	}
 
 	// Create new (shouldn't happen if TouchHeader was called first in session hook)
-	log.Printf("[Pool] WARNING: Created manager via GetByHeader for header: %s (TouchHeader should create first)", headerKey)
-	mgr := NewManager("", "/")
+	evicted, capErr := ensureCapacityLocked(p.headerCache, p.opts.MaxHeaderSessions, "header")
+	if capErr != nil {
+		log.Printf("[Pool] GetByHeader: %v", capErr)
+		return nil
+	}
+	if evicted != nil {
+		evicted.Close()
+	}
+
+	mgr := p.rehydrateOrCreate(headerKey)
 	entry = &sessionEntry{manager: mgr}
 	entry.touch()
 	p.headerCache[headerKey] = entry
 	return mgr
 }
 
+// rehydrateOrCreate looks up persisted state for headerKey in the store and
+// reconnects it into a fresh Manager, falling back to a blank Manager on a
+// cache miss or rehydration error. Callers must hold headerCacheMu.
+func (p *Pool) rehydrateOrCreate(headerKey string) *Manager {
+	metrics.PoolManagers.WithLabelValues("header").Inc()
+	metrics.PoolSessionsTotal.WithLabelValues("header").Inc()
+
+	if p.store != nil {
+		state, err := p.store.Load(context.Background(), headerKey)
+		if err != nil {
+			log.Printf("[Pool] Failed to load stored state for header %s: %v", headerKey, err)
+		} else if state != nil {
+			log.Printf("[Pool] Rehydrating manager for header: %s (%d connections)", headerKey, len(state.Connections))
+			return RehydrateManager("", *state)
+		}
+	}
+
+	log.Printf("[Pool] Created new manager for header: %s", headerKey)
+	return NewManager("", "/")
+}
+
 // ReleaseHeader decrements the active request count for a header session.
 // Called by session hooks on session end to allow cleanup when idle.
 func (p *Pool) ReleaseHeader(headerKey string) {
@@ -169,17 +484,42 @@ func (p *Pool) ReleaseHeader(headerKey string) {
 
 	if entry != nil {
 		entry.release()
+		metrics.PoolActiveRequests.WithLabelValues("header").Dec()
 		log.Printf("[Pool] Released session for header: %s (active=%d)", headerKey, entry.activeReqs.Load())
 	}
+
+	if p.store != nil {
+		if err := p.store.Touch(context.Background(), headerKey, p.timeout); err != nil {
+			log.Printf("[Pool] Failed to refresh TTL for header %s: %v", headerKey, err)
+		}
+	}
 }
 
 // TouchHeader creates or updates a header-based session.
+// Deprecated: context-less wrapper around TouchHeaderCtx kept for
+// back-compat with existing direct callers; it runs with
+// context.Background() and enforces PoolOptions.HeaderLimits the same way.
+// Prefer TouchHeaderCtx.
+func (p *Pool) TouchHeader(headerKey string) error {
+	return p.TouchHeaderCtx(context.Background(), headerKey)
+}
+
+// TouchHeaderCtx creates or updates a header-based session.
 // If the session doesn't exist, creates it.
 // Acquires the active request count (balanced by ReleaseHeader on session end).
-// Called by session hooks on session start.
-func (p *Pool) TouchHeader(headerKey string) {
+// Called by session hooks on session start. Returns an error, logged by the
+// caller, if any of the following reject the acquire: ctx is already done,
+// MaxPerRemoteConcurrency, HeaderLimits.MaxConcurrent (ErrBusy), or
+// HeaderLimits.RatePerSecond (ErrRateLimited) - or the pool is full with
+// every entry pinned. Except when the pool is full, a later GetByHeader
+// still resolves the session to a Manager (creating one if none exists
+// yet), just without a tracked active slot.
+func (p *Pool) TouchHeaderCtx(ctx context.Context, headerKey string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
 	if p.globalMode || headerKey == "" {
-		return
+		return nil
 	}
 
 	// Fast path: entry exists
@@ -188,9 +528,7 @@ func (p *Pool) TouchHeader(headerKey string) {
 	p.headerCacheMu.RUnlock()
 
 	if entry != nil {
-		entry.acquire() // Acquire for this session
-		log.Printf("[Pool] Acquired session for header: %s (active=%d)", headerKey, entry.activeReqs.Load())
-		return
+		return p.acquireHeaderEntry(headerKey, entry, "")
 	}
 
 	// Slow path: create if not exists
@@ -199,20 +537,90 @@ func (p *Pool) TouchHeader(headerKey string) {
 
 	// Double-check after acquiring lock
 	if entry = p.headerCache[headerKey]; entry != nil {
-		entry.acquire()
-		log.Printf("[Pool] Acquired session for header: %s (after lock, active=%d)", headerKey, entry.activeReqs.Load())
-		return
+		return p.acquireHeaderEntry(headerKey, entry, "after lock, ")
 	}
 
-	// Create new manager with active count = 1
-	log.Printf("[Pool] Created new manager for header: %s", headerKey)
-	mgr := NewManager("", "/")
+	evicted, capErr := ensureCapacityLocked(p.headerCache, p.opts.MaxHeaderSessions, "header")
+	if capErr != nil {
+		return capErr
+	}
+	if evicted != nil {
+		evicted.Close()
+	}
+
+	// Create new manager with active count = 1, rehydrating from the store
+	// if another process (or an earlier instance of this one) persisted state.
+	mgr := p.rehydrateOrCreate(headerKey)
 	entry = &sessionEntry{manager: mgr}
+	// The creating call counts against the rate limit like any later one.
+	if limiter := entry.limiterFor(p.opts.HeaderLimits); limiter != nil {
+		limiter.Allow()
+	}
 	entry.acquire() // Start with active=1 for this session
+	metrics.PoolActiveRequests.WithLabelValues("header").Inc()
 	p.headerCache[headerKey] = entry
+	return nil
 }
 
-// CreateSession creates a new Manager for the session.
+// acquireHeaderEntry enforces HeaderLimits.MaxConcurrent, HeaderLimits.RatePerSecond,
+// and MaxPerRemoteConcurrency for an already-existing header entry, acquiring
+// it (incrementing activeReqs) only if all three allow it. logPrefix is
+// spliced into the success log line to distinguish the fast path from the
+// post-lock double-check, mirroring the old TouchHeader's log messages.
+func (p *Pool) acquireHeaderEntry(headerKey string, entry *sessionEntry, logPrefix string) error {
+	limits := p.opts.HeaderLimits
+
+	if limits.MaxConcurrent > 0 && int(entry.activeReqs.Load()) >= limits.MaxConcurrent {
+		return fmt.Errorf("%w: header %s (limit %d)", ErrBusy, headerKey, limits.MaxConcurrent)
+	}
+	if limiter := entry.limiterFor(limits); limiter != nil && !limiter.Allow() {
+		return fmt.Errorf("%w: header %s (%.2f/s, burst %d)", ErrRateLimited, headerKey, limits.RatePerSecond, limits.Burst)
+	}
+
+	if !entry.tryAcquire(p.opts.MaxPerRemoteConcurrency) {
+		return fmt.Errorf("header session %s is at its concurrency limit (%d)", headerKey, p.opts.MaxPerRemoteConcurrency)
+	}
+	metrics.PoolActiveRequests.WithLabelValues("header").Inc()
+	log.Printf("[Pool] Acquired session for header: %s (%sactive=%d)", headerKey, logPrefix, entry.activeReqs.Load())
+	return nil
+}
+
+// PersistHeader snapshots the Manager for headerKey and saves it to the
+// store, so another process (or this one after a restart) can rehydrate the
+// same connection set. No-op if headerKey has no cached entry or no store is
+// configured. Call after mutating a header-keyed Manager's connections
+// (Connect/Disconnect) so the store stays in sync.
+func (p *Pool) PersistHeader(headerKey string) {
+	if p.globalMode || headerKey == "" || p.store == nil {
+		return
+	}
+
+	p.headerCacheMu.RLock()
+	entry := p.headerCache[headerKey]
+	p.headerCacheMu.RUnlock()
+
+	if entry == nil {
+		return
+	}
+
+	state := entry.manager.Snapshot()
+	if err := p.store.Save(context.Background(), headerKey, state, p.timeout); err != nil {
+		log.Printf("[Pool] Failed to persist state for header %s: %v", headerKey, err)
+	}
+}
+
+// PersistFromContext persists the header-keyed Manager state for the session
+// key carried in ctx, if any. Convenience wrapper for tool handlers that only
+// have a context.Context, not the raw header key.
+func (p *Pool) PersistFromContext(ctx context.Context) {
+	headerKey, _ := ctx.Value(SessionKeyContextKey).(string)
+	p.PersistHeader(headerKey)
+}
+
+// CreateSession creates a new Manager for the session, evicting the
+// least-recently-used idle session Manager first if MaxManagers is already
+// reached. Logs and leaves sessionID unmapped (falling back to no manager)
+// if every existing entry is pinned.
 func (p *Pool) CreateSession(sessionID string) {
 	if p.globalMode {
 		return
@@ -225,7 +633,20 @@ func (p *Pool) CreateSession(sessionID string) {
 		return
 	}
 
-	p.managers[sessionID] = NewManager("", "/")
+	evicted, err := ensureCapacityLocked(p.managers, p.opts.MaxManagers, "session")
+	if err != nil {
+		log.Printf("[Pool] CreateSession %s: %v", sessionID, err)
+		return
+	}
+	if evicted != nil {
+		evicted.Close()
+	}
+
+	entry := &sessionEntry{manager: NewManager("", "/")}
+	entry.touch()
+	p.managers[sessionID] = entry
+	metrics.PoolManagers.WithLabelValues("session").Inc()
+	metrics.PoolSessionsTotal.WithLabelValues("session").Inc()
 	log.Printf("[Pool] Created manager for session %s", sessionID)
 }
 
@@ -238,17 +659,19 @@ func (p *Pool) DestroySession(sessionID string) {
 	p.managersMu.Lock()
 	defer p.managersMu.Unlock()
 
-	mgr, exists := p.managers[sessionID]
+	entry, exists := p.managers[sessionID]
 	if !exists {
 		return
 	}
 
-	mgr.Close()
+	entry.manager.Close()
 	delete(p.managers, sessionID)
+	metrics.PoolManagers.WithLabelValues("session").Dec()
 	log.Printf("[Pool] Destroyed manager for session %s", sessionID)
 }
 
-// cleanupLoop runs adaptive cleanup for header-based sessions.
+// cleanupLoop runs the adaptive idle reaper for both header sessions and
+// per-MCP-session managers.
 func (p *Pool) cleanupLoop() {
 	for {
 		select {
@@ -260,57 +683,79 @@ func (p *Pool) cleanupLoop() {
 	}
 }
 
-// reap removes expired header sessions and calculates next interval.
-func (p *Pool) reap() {
+// reapMap scans entries for idle (age > idleTTL and !inUse()) entries and
+// closes + removes them, returning how many were reaped, the map's size
+// before reaping, and the duration until the next entry would expire (for
+// the caller's adaptive scheduling). Takes mu's locks itself; callers must
+// not be holding it.
+func reapMap(mu *sync.RWMutex, entries map[string]*sessionEntry, idleTTL time.Duration, poolType string) (removed, sizeBefore int, nextExpiry time.Duration) {
 	var toRemove []string
-	nextExpiry := time.Duration(1<<63 - 1) // max duration
+	nextExpiry = time.Duration(1<<63 - 1) // max duration
 
 	// First pass: identify expired and calculate next expiry
-	p.headerCacheMu.RLock()
-	for key, entry := range p.headerCache {
+	mu.RLock()
+	for key, entry := range entries {
 		age := entry.age()
-		// Only consider for removal if expired AND not in use
-		if age > p.timeout && !entry.inUse() {
+		switch {
+		case age > idleTTL && !entry.inUse():
 			toRemove = append(toRemove, key)
-		} else if age <= p.timeout {
-			timeUntilExpiry := p.timeout - age
-			if timeUntilExpiry < nextExpiry {
-				nextExpiry = timeUntilExpiry
-			}
-		}
-		// If expired but in use, check again soon
-		if age > p.timeout && entry.inUse() {
-			log.Printf("[Pool] Skipping cleanup for %s: still in use (active=%d)", key, entry.activeReqs.Load())
+		case age > idleTTL:
+			// Expired but still in use: check again soon rather than at
+			// the normal TTL cadence.
+			log.Printf("[Pool] Skipping cleanup for %s %s: still in use (active=%d)", poolType, key, entry.activeReqs.Load())
 			if minCleanupInterval < nextExpiry {
 				nextExpiry = minCleanupInterval
 			}
+		default:
+			if timeUntilExpiry := idleTTL - age; timeUntilExpiry < nextExpiry {
+				nextExpiry = timeUntilExpiry
+			}
 		}
 	}
-	sessionCount := len(p.headerCache)
-	p.headerCacheMu.RUnlock()
+	sizeBefore = len(entries)
+	mu.RUnlock()
 
-	// Second pass: remove expired (with close outside main lock)
+	// Second pass: remove expired (with close outside the lock)
 	for _, key := range toRemove {
 		var mgr *Manager
 
-		p.headerCacheMu.Lock()
-		if entry, ok := p.headerCache[key]; ok {
+		mu.Lock()
+		if entry, ok := entries[key]; ok {
 			// Triple-check: expired AND not in use
-			if entry.age() > p.timeout && !entry.inUse() {
-				delete(p.headerCache, key)
+			if entry.age() > idleTTL && !entry.inUse() {
+				delete(entries, key)
 				mgr = entry.manager
-				log.Printf("[Pool] Cleaning up idle header session: %s", key)
+				log.Printf("[Pool] Reaping idle %s entry: %s", poolType, key)
 			}
 		}
-		p.headerCacheMu.Unlock()
+		mu.Unlock()
 
 		if mgr != nil {
 			mgr.Close()
+			metrics.PoolManagers.WithLabelValues(poolType).Dec()
+			removed++
 		}
 	}
 
+	return removed, sizeBefore, nextExpiry
+}
+
+// reap removes expired header sessions and session managers, then
+// recalculates the adaptive cleanup interval from whichever map has the
+// soonest-expiring entry.
+func (p *Pool) reap() {
+	headerRemoved, headerSize, headerNext := reapMap(&p.headerCacheMu, p.headerCache, p.timeout, "header")
+	sessionRemoved, sessionSize, sessionNext := reapMap(&p.managersMu, p.managers, p.timeout, "session")
+
+	nextExpiry := headerNext
+	if sessionNext < nextExpiry {
+		nextExpiry = sessionNext
+	}
+	totalSize := headerSize + sessionSize
+	totalRemoved := headerRemoved + sessionRemoved
+
 	// Adaptive sleep interval
-	if sessionCount == 0 || nextExpiry == time.Duration(1<<63-1) {
+	if totalSize == 0 || nextExpiry == time.Duration(1<<63-1) {
 		p.nextInterval = maxCleanupInterval
 	} else {
 		p.nextInterval = nextExpiry + time.Second
@@ -322,9 +767,9 @@ func (p *Pool) reap() {
 		}
 	}
 
-	if len(toRemove) > 0 || sessionCount > 0 {
-		log.Printf("[Pool] Cleanup: removed=%d, active=%d, next_check=%v", 
-			len(toRemove), sessionCount-len(toRemove), p.nextInterval)
+	if totalRemoved > 0 || totalSize > 0 {
+		log.Printf("[Pool] Cleanup: removed=%d, active=%d, next_check=%v",
+			totalRemoved, totalSize-totalRemoved, p.nextInterval)
 	}
 }
 
@@ -341,11 +786,12 @@ func (p *Pool) Close() {
 
 	// Close session managers
 	p.managersMu.Lock()
-	for id, mgr := range p.managers {
+	for id, entry := range p.managers {
 		log.Printf("[Pool] Closing session manager: %s", id)
-		mgr.Close()
+		entry.manager.Close()
+		metrics.PoolManagers.WithLabelValues("session").Dec()
 	}
-	p.managers = make(map[string]*Manager)
+	p.managers = make(map[string]*sessionEntry)
 	p.managersMu.Unlock()
 
 	// Close header cache - wait briefly for active requests
@@ -355,6 +801,7 @@ func (p *Pool) Close() {
 			log.Printf("[Pool] Warning: Closing header session %s with %d active requests", key, entry.activeReqs.Load())
 		}
 		entry.manager.Close()
+		metrics.PoolManagers.WithLabelValues("header").Dec()
 	}
 	p.headerCache = make(map[string]*sessionEntry)
 	p.headerCacheMu.Unlock()
@@ -366,3 +813,92 @@ func (p *Pool) Close() {
 func SessionHeader() string {
 	return sessionHeader
 }
+
+// PoolMetrics is a point-in-time snapshot of a Pool's size and capacity, for
+// on-demand introspection (the pool_stats MCP tool) without scraping
+// Prometheus. The Prometheus collectors in internal/metrics are the
+// authoritative, cumulative source of truth; this just mirrors their
+// current values plus the configured limits.
+type PoolMetrics struct {
+	HeaderSessions          int  `json:"header_sessions"`
+	SessionManagers         int  `json:"session_managers"`
+	HeaderActiveReqs        int  `json:"header_active_requests"`
+	MaxHeaderSessions       int  `json:"max_header_sessions,omitempty"`
+	MaxManagers             int  `json:"max_managers,omitempty"`
+	MaxPerRemoteConcurrency int  `json:"max_per_remote_concurrency,omitempty"`
+	GlobalMode              bool `json:"global_mode"`
+}
+
+// Metrics returns a snapshot of the pool's current size against its
+// configured capacity limits.
+func (p *Pool) Metrics() PoolMetrics {
+	snap := PoolMetrics{
+		GlobalMode:              p.globalMode,
+		MaxHeaderSessions:       p.opts.MaxHeaderSessions,
+		MaxManagers:             p.opts.MaxManagers,
+		MaxPerRemoteConcurrency: p.opts.MaxPerRemoteConcurrency,
+	}
+
+	p.headerCacheMu.RLock()
+	snap.HeaderSessions = len(p.headerCache)
+	for _, entry := range p.headerCache {
+		snap.HeaderActiveReqs += int(entry.activeReqs.Load())
+	}
+	p.headerCacheMu.RUnlock()
+
+	p.managersMu.RLock()
+	snap.SessionManagers = len(p.managers)
+	p.managersMu.RUnlock()
+
+	return snap
+}
+
+// PoolStats is a live/in-use/idle breakdown of the pool's entries, for
+// observing the idle reaper: idle entries are the ones it will close on its
+// next pass once they cross the configured IdleTTL.
+type PoolStats struct {
+	HeaderSessionsLive  int `json:"header_sessions_live"`
+	HeaderSessionsInUse int `json:"header_sessions_in_use"`
+	HeaderSessionsIdle  int `json:"header_sessions_idle"`
+
+	SessionManagersLive  int `json:"session_managers_live"`
+	SessionManagersInUse int `json:"session_managers_in_use"`
+	SessionManagersIdle  int `json:"session_managers_idle"`
+
+	IdleTTL time.Duration `json:"idle_ttl"`
+
+	// PanicsTotal counts panics passed to RecordPanic (those Do recovers
+	// from fn, and those fed in by recovers placed elsewhere), mirroring
+	// metrics.PoolPanicsTotal.
+	PanicsTotal int64 `json:"panics_total"`
+}
+
+// PoolStats returns a live/in-use/idle breakdown of headerCache and
+// managers, for the pool_stats MCP tool to surface alongside Metrics().
+func (p *Pool) PoolStats() PoolStats {
+	stats := PoolStats{IdleTTL: p.timeout, PanicsTotal: p.panicsTotal.Load()}
+
+	p.headerCacheMu.RLock()
+	stats.HeaderSessionsLive = len(p.headerCache)
+	for _, entry := range p.headerCache {
+		if entry.inUse() {
+			stats.HeaderSessionsInUse++
+		} else {
+			stats.HeaderSessionsIdle++
+		}
+	}
+	p.headerCacheMu.RUnlock()
+
+	p.managersMu.RLock()
+	stats.SessionManagersLive = len(p.managers)
+	for _, entry := range p.managers {
+		if entry.inUse() {
+			stats.SessionManagersInUse++
+		} else {
+			stats.SessionManagersIdle++
+		}
+	}
+	p.managersMu.RUnlock()
+
+	return stats
+}
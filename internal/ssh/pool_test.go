@@ -1,6 +1,8 @@
 package ssh
 
 import (
+	"context"
+	"errors"
 	"testing"
 	"time"
 )
@@ -155,7 +157,7 @@ func TestSessionEntry(t *testing.T) {
 
 	t.Run("acquire and release track active requests", func(t *testing.T) {
 		entry2 := &sessionEntry{manager: mgr}
-		
+
 		if entry2.activeReqs.Load() != 0 {
 			t.Error("expected initial activeReqs to be 0")
 		}
@@ -199,7 +201,7 @@ func TestPoolTouchHeaderAcquireRelease(t *testing.T) {
 
 	t.Run("TouchHeader creates and acquires", func(t *testing.T) {
 		pool.TouchHeader(headerKey)
-		
+
 		pool.headerCacheMu.RLock()
 		entry := pool.headerCache[headerKey]
 		pool.headerCacheMu.RUnlock()
@@ -214,7 +216,7 @@ func TestPoolTouchHeaderAcquireRelease(t *testing.T) {
 
 	t.Run("second TouchHeader increments active count", func(t *testing.T) {
 		pool.TouchHeader(headerKey)
-		
+
 		pool.headerCacheMu.RLock()
 		entry := pool.headerCache[headerKey]
 		pool.headerCacheMu.RUnlock()
@@ -226,7 +228,7 @@ func TestPoolTouchHeaderAcquireRelease(t *testing.T) {
 
 	t.Run("ReleaseHeader decrements active count", func(t *testing.T) {
 		pool.ReleaseHeader(headerKey)
-		
+
 		pool.headerCacheMu.RLock()
 		entry := pool.headerCache[headerKey]
 		pool.headerCacheMu.RUnlock()
@@ -255,3 +257,318 @@ func TestPoolTouchHeaderAcquireRelease(t *testing.T) {
 		}
 	})
 }
+
+func TestPoolHeaderLimits(t *testing.T) {
+	t.Run("MaxConcurrent returns ErrBusy once the cap is hit", func(t *testing.T) {
+		pool := NewPoolWithOptions(false, NewMemoryStore(), PoolOptions{
+			HeaderLimits: HeaderLimits{MaxConcurrent: 2},
+		})
+		defer pool.Close()
+
+		headerKey := "busy-key"
+		if err := pool.TouchHeaderCtx(context.Background(), headerKey); err != nil {
+			t.Fatalf("unexpected error on first acquire: %v", err)
+		}
+		if err := pool.TouchHeaderCtx(context.Background(), headerKey); err != nil {
+			t.Fatalf("unexpected error on second acquire: %v", err)
+		}
+		if err := pool.TouchHeaderCtx(context.Background(), headerKey); !errors.Is(err, ErrBusy) {
+			t.Errorf("expected ErrBusy at MaxConcurrent, got %v", err)
+		}
+
+		pool.ReleaseHeader(headerKey)
+		if err := pool.TouchHeaderCtx(context.Background(), headerKey); err != nil {
+			t.Errorf("expected acquire to succeed again after a release, got %v", err)
+		}
+	})
+
+	t.Run("RatePerSecond returns ErrRateLimited once burst is exhausted", func(t *testing.T) {
+		pool := NewPoolWithOptions(false, NewMemoryStore(), PoolOptions{
+			HeaderLimits: HeaderLimits{RatePerSecond: 0.001, Burst: 1},
+		})
+		defer pool.Close()
+
+		headerKey := "rate-limited-key"
+		if err := pool.TouchHeaderCtx(context.Background(), headerKey); err != nil {
+			t.Fatalf("unexpected error on first acquire: %v", err)
+		}
+		if err := pool.TouchHeaderCtx(context.Background(), headerKey); !errors.Is(err, ErrRateLimited) {
+			t.Errorf("expected ErrRateLimited once burst is exhausted, got %v", err)
+		}
+	})
+
+	t.Run("TouchHeader succeeds within generous HeaderLimits", func(t *testing.T) {
+		pool := NewPoolWithOptions(false, NewMemoryStore(), PoolOptions{
+			HeaderLimits: HeaderLimits{RatePerSecond: 1000, Burst: 1000},
+		})
+		defer pool.Close()
+
+		headerKey := "back-compat-key"
+		for i := 0; i < 5; i++ {
+			if err := pool.TouchHeader(headerKey); err != nil {
+				t.Fatalf("TouchHeader call %d: unexpected error: %v", i, err)
+			}
+		}
+	})
+
+	t.Run("zero-value HeaderLimits stays unlimited", func(t *testing.T) {
+		pool := NewPool(false)
+		defer pool.Close()
+
+		headerKey := "unlimited-key"
+		for i := 0; i < 10; i++ {
+			if err := pool.TouchHeaderCtx(context.Background(), headerKey); err != nil {
+				t.Fatalf("call %d: unexpected error: %v", i, err)
+			}
+		}
+	})
+}
+
+// backdate makes entry look idle for longer than d by rewriting its stored
+// lastAccessed directly, the same trick TestSessionEntry uses to make age()
+// deterministic without a real sleep.
+func backdate(entry *sessionEntry, d time.Duration) {
+	entry.lastAccessed.Store(time.Now().Add(-d).Unix())
+}
+
+func TestPoolReap(t *testing.T) {
+	t.Run("reaps idle header sessions and managers past IdleTTL", func(t *testing.T) {
+		pool := NewPoolWithOptions(false, NewMemoryStore(), PoolOptions{IdleTTL: time.Minute})
+		defer pool.Close()
+
+		pool.TouchHeader("idle-header")
+		pool.ReleaseHeader("idle-header")
+		pool.CreateSession("idle-session")
+
+		pool.headerCacheMu.Lock()
+		backdate(pool.headerCache["idle-header"], 2*time.Minute)
+		pool.headerCacheMu.Unlock()
+
+		pool.managersMu.Lock()
+		backdate(pool.managers["idle-session"], 2*time.Minute)
+		pool.managersMu.Unlock()
+
+		pool.reap()
+
+		pool.headerCacheMu.RLock()
+		_, headerStillThere := pool.headerCache["idle-header"]
+		pool.headerCacheMu.RUnlock()
+		if headerStillThere {
+			t.Error("expected idle header session to be reaped")
+		}
+
+		pool.managersMu.RLock()
+		_, sessionStillThere := pool.managers["idle-session"]
+		pool.managersMu.RUnlock()
+		if sessionStillThere {
+			t.Error("expected idle session manager to be reaped")
+		}
+	})
+
+	t.Run("does not reap in-use entries even past IdleTTL", func(t *testing.T) {
+		pool := NewPoolWithOptions(false, NewMemoryStore(), PoolOptions{IdleTTL: time.Minute})
+		defer pool.Close()
+
+		pool.TouchHeader("busy-header") // active=1, never released
+
+		pool.headerCacheMu.Lock()
+		backdate(pool.headerCache["busy-header"], 2*time.Minute)
+		pool.headerCacheMu.Unlock()
+
+		pool.reap()
+
+		pool.headerCacheMu.RLock()
+		_, stillThere := pool.headerCache["busy-header"]
+		pool.headerCacheMu.RUnlock()
+		if !stillThere {
+			t.Error("expected in-use entry to survive reap despite exceeding IdleTTL")
+		}
+	})
+
+	t.Run("does not reap entries within IdleTTL", func(t *testing.T) {
+		pool := NewPoolWithOptions(false, NewMemoryStore(), PoolOptions{IdleTTL: time.Minute})
+		defer pool.Close()
+
+		pool.TouchHeader("fresh-header")
+		pool.ReleaseHeader("fresh-header")
+
+		pool.reap()
+
+		pool.headerCacheMu.RLock()
+		_, stillThere := pool.headerCache["fresh-header"]
+		pool.headerCacheMu.RUnlock()
+		if !stillThere {
+			t.Error("expected fresh entry to survive reap")
+		}
+	})
+}
+
+func TestPoolStats(t *testing.T) {
+	pool := NewPoolWithOptions(false, NewMemoryStore(), PoolOptions{IdleTTL: 90 * time.Second})
+	defer pool.Close()
+
+	pool.TouchHeader("in-use-header") // active=1
+	pool.TouchHeader("idle-header")
+	pool.ReleaseHeader("idle-header")
+	pool.CreateSession("idle-session")
+
+	stats := pool.PoolStats()
+
+	if stats.HeaderSessionsLive != 2 {
+		t.Errorf("expected 2 live header sessions, got %d", stats.HeaderSessionsLive)
+	}
+	if stats.HeaderSessionsInUse != 1 {
+		t.Errorf("expected 1 in-use header session, got %d", stats.HeaderSessionsInUse)
+	}
+	if stats.HeaderSessionsIdle != 1 {
+		t.Errorf("expected 1 idle header session, got %d", stats.HeaderSessionsIdle)
+	}
+	if stats.SessionManagersLive != 1 {
+		t.Errorf("expected 1 live session manager, got %d", stats.SessionManagersLive)
+	}
+	if stats.SessionManagersIdle != 1 {
+		t.Errorf("expected 1 idle session manager, got %d", stats.SessionManagersIdle)
+	}
+	if stats.IdleTTL != 90*time.Second {
+		t.Errorf("expected IdleTTL=90s, got %v", stats.IdleTTL)
+	}
+}
+
+func TestPoolDo(t *testing.T) {
+	t.Run("invokes fn with the session-keyed manager", func(t *testing.T) {
+		pool := NewPool(false)
+		defer pool.Close()
+		pool.CreateSession("sess-1")
+
+		var got *Manager
+		err := pool.Do(context.Background(), "sess-1", func(mgr *Manager) error {
+			got = mgr
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got != pool.Get("sess-1") {
+			t.Error("expected fn to receive the session's manager")
+		}
+	})
+
+	t.Run("lazily creates and acquires a header-keyed manager", func(t *testing.T) {
+		pool := NewPool(false)
+		defer pool.Close()
+
+		var gotDuringFn int32
+		err := pool.Do(context.Background(), "header-key", func(mgr *Manager) error {
+			pool.headerCacheMu.RLock()
+			gotDuringFn = pool.headerCache["header-key"].activeReqs.Load()
+			pool.headerCacheMu.RUnlock()
+			if mgr == nil {
+				t.Error("expected a non-nil manager")
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if gotDuringFn != 1 {
+			t.Errorf("expected activeReqs=1 while fn ran, got %d", gotDuringFn)
+		}
+
+		pool.headerCacheMu.RLock()
+		after := pool.headerCache["header-key"].activeReqs.Load()
+		pool.headerCacheMu.RUnlock()
+		if after != 0 {
+			t.Errorf("expected activeReqs=0 after Do returns, got %d", after)
+		}
+	})
+
+	t.Run("propagates fn's error", func(t *testing.T) {
+		pool := NewPool(false)
+		defer pool.Close()
+
+		wantErr := errors.New("boom")
+		err := pool.Do(context.Background(), "header-key", func(mgr *Manager) error {
+			return wantErr
+		})
+		if !errors.Is(err, wantErr) {
+			t.Errorf("expected %v, got %v", wantErr, err)
+		}
+	})
+
+	t.Run("recovers a panic as a PanicError and releases the acquire", func(t *testing.T) {
+		pool := NewPool(false)
+		defer pool.Close()
+
+		var handled bool
+		pool.SetPanicHandler(func(key string, v any, stack []byte) {
+			handled = true
+			if key != "header-key" {
+				t.Errorf("expected handler key=header-key, got %q", key)
+			}
+		})
+
+		err := pool.Do(context.Background(), "header-key", func(mgr *Manager) error {
+			panic("kaboom")
+		})
+
+		var panicErr *PanicError
+		if !errors.As(err, &panicErr) {
+			t.Fatalf("expected *PanicError, got %v (%T)", err, err)
+		}
+		if !handled {
+			t.Error("expected panicHandler to be invoked")
+		}
+		if pool.PoolStats().PanicsTotal != 1 {
+			t.Errorf("expected PanicsTotal=1, got %d", pool.PoolStats().PanicsTotal)
+		}
+
+		pool.headerCacheMu.RLock()
+		active := pool.headerCache["header-key"].activeReqs.Load()
+		pool.headerCacheMu.RUnlock()
+		if active != 0 {
+			t.Errorf("expected activeReqs=0 after a panicking fn, got %d", active)
+		}
+	})
+
+	t.Run("RecordPanic is callable outside Do for recovers placed elsewhere", func(t *testing.T) {
+		pool := NewPool(false)
+		defer pool.Close()
+
+		var handled bool
+		pool.SetPanicHandler(func(key string, v any, stack []byte) {
+			handled = true
+			if key != "some_tool" {
+				t.Errorf("expected handler key=some_tool, got %q", key)
+			}
+		})
+
+		pool.RecordPanic("some_tool", "kaboom", []byte("stack"))
+
+		if !handled {
+			t.Error("expected panicHandler to be invoked")
+		}
+		if pool.PoolStats().PanicsTotal != 1 {
+			t.Errorf("expected PanicsTotal=1, got %d", pool.PoolStats().PanicsTotal)
+		}
+	})
+
+	t.Run("returns ctx error without calling fn", func(t *testing.T) {
+		pool := NewPool(false)
+		defer pool.Close()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		called := false
+		err := pool.Do(ctx, "header-key", func(mgr *Manager) error {
+			called = true
+			return nil
+		})
+		if err == nil {
+			t.Error("expected an error for a canceled context")
+		}
+		if called {
+			t.Error("expected fn not to be called for a canceled context")
+		}
+	})
+}
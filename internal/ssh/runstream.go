@@ -0,0 +1,235 @@
+package ssh
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// runStreamReadSize is the buffer size used when pumping a streaming
+// command's stdout/stderr pipes.
+const runStreamReadSize = 4096
+
+// RunEventType identifies the kind of event yielded by RunStream.
+type RunEventType int
+
+const (
+	// StdoutChunk carries a slice of the command's stdout as it arrives.
+	StdoutChunk RunEventType = iota
+	// StderrChunk carries a slice of the command's stderr as it arrives.
+	StderrChunk
+	// Exit is the final event, reporting the command's exit code (or Err
+	// if the command couldn't be waited on at all).
+	Exit
+)
+
+// RunEvent is one event from a streaming command execution started by
+// RunStream.
+type RunEvent struct {
+	Type     RunEventType
+	Data     []byte
+	ExitCode int
+	Err      error
+}
+
+// RunStream starts cmd with the same CWD tracking as Run, but streams
+// stdout and stderr as they arrive instead of buffering the whole output,
+// so a caller can relay partial output from a long-running command (tail
+// -f, a package install, a build). The delimiter/pwd trick Run uses to
+// track CWD is detected by the stdout scanner as bytes arrive and
+// stripped before reaching the caller, rather than found by indexing into
+// the fully-buffered output afterwards.
+func (c *Client) RunStream(ctx context.Context, cmd string) (<-chan RunEvent, error) {
+	c.mu.Lock()
+	if c.conn == nil {
+		c.mu.Unlock()
+		return nil, fmt.Errorf("not connected")
+	}
+	conn := c.conn
+	cwd := c.cwd
+	escalated := c.wrapEscalation(cmd)
+	c.mu.Unlock()
+
+	delimiter := fmt.Sprintf("___MCP_PWD_%d___", time.Now().UnixNano())
+	wrappedCmd := fmt.Sprintf(
+		`cd %q && %s; __EXIT__=$?; echo ""; echo "%s"; pwd; exit $__EXIT__`,
+		cwd, escalated, delimiter,
+	)
+
+	session, err := conn.NewSession()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create session: %w", err)
+	}
+
+	stdout, err := session.StdoutPipe()
+	if err != nil {
+		session.Close()
+		return nil, fmt.Errorf("failed to open stdout pipe: %w", err)
+	}
+	stderr, err := session.StderrPipe()
+	if err != nil {
+		session.Close()
+		return nil, fmt.Errorf("failed to open stderr pipe: %w", err)
+	}
+
+	if err := session.Start(wrappedCmd); err != nil {
+		session.Close()
+		return nil, fmt.Errorf("failed to start command: %w", err)
+	}
+
+	events := make(chan RunEvent, 16)
+
+	var pumps sync.WaitGroup
+	pumps.Add(2)
+	go func() {
+		defer pumps.Done()
+		c.scanStdoutWithCWD(stdout, delimiter, events)
+	}()
+	go func() {
+		defer pumps.Done()
+		streamChunks(stderr, StderrChunk, events)
+	}()
+
+	go func() {
+		<-ctx.Done()
+		session.Signal(ssh.SIGKILL)
+	}()
+
+	go func() {
+		pumps.Wait()
+
+		var exitCode int
+		waitErr := session.Wait()
+		if exitErr, ok := waitErr.(*ssh.ExitError); ok {
+			exitCode = exitErr.ExitStatus()
+			waitErr = nil
+		}
+		session.Close()
+
+		events <- RunEvent{Type: Exit, ExitCode: exitCode, Err: waitErr}
+		close(events)
+	}()
+
+	return events, nil
+}
+
+// scanStdoutWithCWD streams r in StdoutChunk events, watching for delimiter
+// as bytes arrive. Once found, everything before it is flushed as a final
+// chunk and everything after (the pwd line Run's wrapper prints) is parsed
+// into the client's tracked CWD instead of being emitted to the caller.
+func (c *Client) scanStdoutWithCWD(r io.Reader, delimiter string, events chan<- RunEvent) {
+	delim := []byte(delimiter)
+	var buf []byte
+	chunk := make([]byte, runStreamReadSize)
+
+	for {
+		n, err := r.Read(chunk)
+		if n > 0 {
+			buf = append(buf, chunk[:n]...)
+
+			if idx := bytes.Index(buf, delim); idx != -1 {
+				if idx > 0 {
+					events <- RunEvent{Type: StdoutChunk, Data: append([]byte(nil), buf[:idx]...)}
+				}
+				rest := drainRemaining(r, buf[idx+len(delim):])
+				if newCWD := strings.TrimSpace(string(rest)); newCWD != "" {
+					c.mu.Lock()
+					c.cwd = newCWD
+					c.mu.Unlock()
+				}
+				return
+			}
+
+			// Hold back a tail as long as the delimiter so a match split
+			// across two reads isn't missed.
+			if safe := len(buf) - len(delim); safe > 0 {
+				events <- RunEvent{Type: StdoutChunk, Data: append([]byte(nil), buf[:safe]...)}
+				buf = buf[safe:]
+			}
+		}
+		if err != nil {
+			if len(buf) > 0 {
+				events <- RunEvent{Type: StdoutChunk, Data: buf}
+			}
+			return
+		}
+	}
+}
+
+// drainRemaining reads r to EOF, appending to already. Used once the CWD
+// delimiter has been found to collect the trailing pwd output.
+func drainRemaining(r io.Reader, already []byte) []byte {
+	buf := already
+	chunk := make([]byte, runStreamReadSize)
+	for {
+		n, err := r.Read(chunk)
+		if n > 0 {
+			buf = append(buf, chunk[:n]...)
+		}
+		if err != nil {
+			return buf
+		}
+	}
+}
+
+// streamChunks relays r as typ events until EOF.
+func streamChunks(r io.Reader, typ RunEventType, events chan<- RunEvent) {
+	buf := make([]byte, runStreamReadSize)
+	for {
+		n, err := r.Read(buf)
+		if n > 0 {
+			data := make([]byte, n)
+			copy(data, buf[:n])
+			events <- RunEvent{Type: typ, Data: data}
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// RunStream resolves target and streams cmd's output via Client.RunStream,
+// holding the alias lock for the duration of the stream (like Run does for
+// the whole call) so CWD tracking and concurrent commands on the same
+// connection stay serialized.
+func (m *Manager) RunStream(ctx context.Context, cmd, target string) (<-chan RunEvent, error) {
+	alias, err := m.resolveTarget(target)
+	if err != nil {
+		return nil, err
+	}
+
+	lock := m.getAliasLock(alias)
+	lock.Lock()
+
+	m.mu.RLock()
+	client := m.connections[alias]
+	m.mu.RUnlock()
+
+	if client == nil {
+		lock.Unlock()
+		return nil, fmt.Errorf("connection '%s' not found", alias)
+	}
+
+	events, err := client.RunStream(ctx, cmd)
+	if err != nil {
+		lock.Unlock()
+		return nil, err
+	}
+
+	out := make(chan RunEvent, 16)
+	go func() {
+		defer close(out)
+		defer lock.Unlock()
+		for ev := range events {
+			out <- ev
+		}
+	}()
+
+	return out, nil
+}
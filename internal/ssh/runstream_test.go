@@ -0,0 +1,63 @@
+package ssh
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestScanStdoutWithCWDStripsDelimiterAndPWD(t *testing.T) {
+	c := &Client{cwd: "/old"}
+	delimiter := "___MCP_PWD_123___"
+	input := "hello\nworld\n\n" + delimiter + "\n/new/cwd\n"
+
+	events := make(chan RunEvent, 16)
+	c.scanStdoutWithCWD(strings.NewReader(input), delimiter, events)
+	close(events)
+
+	var got strings.Builder
+	for ev := range events {
+		if ev.Type != StdoutChunk {
+			t.Fatalf("unexpected event type %v", ev.Type)
+		}
+		got.Write(ev.Data)
+	}
+
+	if got.String() != "hello\nworld\n\n" {
+		t.Errorf("expected stdout before delimiter, got %q", got.String())
+	}
+	if c.cwd != "/new/cwd" {
+		t.Errorf("expected cwd updated to /new/cwd, got %q", c.cwd)
+	}
+}
+
+func TestScanStdoutWithCWDSplitAcrossReads(t *testing.T) {
+	c := &Client{cwd: "/old"}
+	delimiter := "___MCP_PWD_456___"
+
+	r1, w1 := io.Pipe()
+	go func() {
+		// Split the delimiter itself across two writes.
+		w1.Write([]byte("partial output "))
+		w1.Write([]byte(delimiter[:5]))
+		w1.Write([]byte(delimiter[5:]))
+		w1.Write([]byte("\n/split/cwd\n"))
+		w1.Close()
+	}()
+
+	events := make(chan RunEvent, 16)
+	c.scanStdoutWithCWD(r1, delimiter, events)
+	close(events)
+
+	var got strings.Builder
+	for ev := range events {
+		got.Write(ev.Data)
+	}
+
+	if got.String() != "partial output " {
+		t.Errorf("expected %q, got %q", "partial output ", got.String())
+	}
+	if c.cwd != "/split/cwd" {
+		t.Errorf("expected cwd updated to /split/cwd, got %q", c.cwd)
+	}
+}
@@ -1,12 +1,13 @@
 package ssh
 
 import (
+	"path/filepath"
 	"strings"
 	"testing"
 )
 
 // TestSecurityPathTraversal enforces strict security boundaries.
-// These tests act as a "source of truth" for path validation logic 
+// These tests act as a "source of truth" for path validation logic
 // and must NOT be modified to make code pass if they fail.
 func TestSecurityPathTraversal(t *testing.T) {
 	mgr := NewManager("", "/data/safe_root")
@@ -51,7 +52,7 @@ func TestSecurityPathTraversal(t *testing.T) {
 	for _, tc := range testCases {
 		t.Run(tc.desc, func(t *testing.T) {
 			cleanPath, err := mgr.validatePath(tc.inputPath, "")
-			
+
 			if tc.shouldError {
 				if err == nil {
 					t.Errorf("SECURITY FAIL: Path %q escaped allowed root! Resolved to: %q", tc.inputPath, cleanPath)
@@ -70,11 +71,51 @@ func TestSecurityPathTraversal(t *testing.T) {
 	}
 }
 
+// FuzzValidatePath feeds arbitrary byte strings at validatePath — raw bytes,
+// not just the handful of traversal patterns TestSecurityPathTraversal
+// anchors on — and checks the one invariant that actually matters: whatever
+// cleanPath comes back on success must be inside root. NUL bytes, backslash
+// and mixed separators, and %2e%2e-style encodings are seeded explicitly
+// since those are the cases most likely to slip past filepath.Clean alone.
+func FuzzValidatePath(f *testing.F) {
+	seeds := []string{
+		"/data/safe_root/file.txt",
+		"/data/safe_root/../etc/passwd",
+		"../../etc/passwd",
+		"/data/safe_root/./../safe_root_sibling",
+		"/data/safe_root/\x00/etc/passwd",
+		"\x00",
+		"/data/safe_root/..\\..\\etc\\passwd",
+		"/data/safe_root/%2e%2e/%2e%2e/etc/passwd",
+		"/data/safe_root/./././file.txt",
+		"data/file.txt",
+		"",
+	}
+	for _, s := range seeds {
+		f.Add(s)
+	}
+
+	root := "/data/safe_root"
+	mgr := NewManager("", root)
+	defer mgr.Close()
+
+	f.Fuzz(func(t *testing.T, inputPath string) {
+		cleanPath, err := mgr.validatePath(inputPath, "")
+		if err != nil {
+			return
+		}
+		rel, relErr := filepath.Rel(root, cleanPath)
+		if relErr != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			t.Errorf("SECURITY FAIL: validatePath(%q) returned %q, which escapes root %q (rel=%q)", inputPath, cleanPath, root, rel)
+		}
+	})
+}
+
 // TestSecurityKeyGeneration ensures private keys are generated with correct permissions.
 func TestSecurityKeyGeneration(t *testing.T) {
 	// This functionality is in keys.go, but we mock the check here or verify logic
 	// For now, we verify the implementation constants
-	
+
 	// We check the constants in code for correct permissions
 	// This is a "policy" test
 	const expectedPrivKeyPerm = 0600
@@ -0,0 +1,285 @@
+package ssh
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/crypto/ssh"
+)
+
+// shellReadChunkSize is the buffer size used by the background reader that
+// pumps PTY output into a ShellSession's pending buffer.
+const shellReadChunkSize = 4096
+
+// PtyOpts configures a new interactive PTY session.
+type PtyOpts struct {
+	Cols int
+	Rows int
+	Term string
+	// Modes overrides individual terminal modes (ssh.ECHO, ssh.ICRNL, etc.)
+	// on top of NewShell's defaults. Nil leaves the defaults as they are;
+	// every entry given, a zero value included, replaces the default for
+	// its opcode.
+	Modes ssh.TerminalModes
+}
+
+// ShellSession tracks a single interactive PTY session, letting a caller
+// drive an interactive command (vim, top, a sudo prompt) one read/write at a
+// time instead of the one-shot Run/Execute path.
+type ShellSession struct {
+	ID    string
+	Alias string
+
+	session *ssh.Session
+	stdin   io.WriteCloser
+	stdout  io.Reader
+
+	mu       sync.Mutex
+	pending  bytes.Buffer
+	newData  chan struct{}
+	done     chan struct{}
+	closed   bool
+	closeErr error
+}
+
+// OpenShell allocates a new interactive PTY session against target, starts
+// the remote login shell, and returns a session ID for use with
+// WriteStdin/ReadStdout/Resize/CloseShell.
+func (m *Manager) OpenShell(ctx context.Context, target string, opts PtyOpts) (string, error) {
+	alias, err := m.resolveTarget(target)
+	if err != nil {
+		return "", err
+	}
+
+	client, err := m.clientForAlias(alias)
+	if err != nil {
+		return "", err
+	}
+
+	if opts.Cols == 0 {
+		opts.Cols = 80
+	}
+	if opts.Rows == 0 {
+		opts.Rows = 24
+	}
+
+	session, stdin, stdout, err := client.NewShell(opts.Cols, opts.Rows, opts.Term, opts.Modes)
+	if err != nil {
+		return "", err
+	}
+
+	shell := &ShellSession{
+		ID:      uuid.NewString(),
+		Alias:   alias,
+		session: session,
+		stdin:   stdin,
+		stdout:  stdout,
+		newData: make(chan struct{}, 1),
+		done:    make(chan struct{}),
+	}
+
+	go shell.pump()
+	go shell.waitForExit()
+
+	m.shellsMu.Lock()
+	m.shells[shell.ID] = shell
+	m.shellsMu.Unlock()
+
+	log.Printf("[Shell] Opened %s on %s (%dx%d)", shell.ID, alias, opts.Cols, opts.Rows)
+	return shell.ID, nil
+}
+
+// pump continuously reads from the session's stdout into the pending buffer,
+// signaling newData whenever bytes arrive so a blocked ReadStdout can wake up.
+func (s *ShellSession) pump() {
+	buf := make([]byte, shellReadChunkSize)
+	for {
+		n, err := s.stdout.Read(buf)
+		if n > 0 {
+			s.mu.Lock()
+			s.pending.Write(buf[:n])
+			s.mu.Unlock()
+			select {
+			case s.newData <- struct{}{}:
+			default:
+			}
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// waitForExit waits for the remote shell to exit (or the session to be
+// closed) and marks the session done so ReadStdout callers stop blocking.
+func (s *ShellSession) waitForExit() {
+	err := s.session.Wait()
+	s.mu.Lock()
+	s.closeErr = err
+	s.mu.Unlock()
+	close(s.done)
+}
+
+// shellByID looks up an open ShellSession by ID.
+func (m *Manager) shellByID(sessionID string) (*ShellSession, error) {
+	m.shellsMu.Lock()
+	shell, ok := m.shells[sessionID]
+	m.shellsMu.Unlock()
+
+	if !ok {
+		return nil, fmt.Errorf("no shell session with id '%s'", sessionID)
+	}
+	return shell, nil
+}
+
+// WriteStdin sends data to the shell session's stdin, e.g. a command
+// followed by "\n" or an interactive prompt response.
+func (m *Manager) WriteStdin(sessionID string, data []byte) error {
+	shell, err := m.shellByID(sessionID)
+	if err != nil {
+		return err
+	}
+
+	if _, err := shell.stdin.Write(data); err != nil {
+		return fmt.Errorf("failed to write to shell stdin: %w", err)
+	}
+	return nil
+}
+
+// ReadStdout returns up to maxBytes of output accumulated since the last
+// read, waiting up to timeout for at least one byte to become available if
+// the buffer is currently empty. ok is false once the remote shell has
+// exited and there's no more buffered output to drain.
+func (m *Manager) ReadStdout(sessionID string, maxBytes int, timeout time.Duration) (data []byte, ok bool, err error) {
+	shell, err := m.shellByID(sessionID)
+	if err != nil {
+		return nil, false, err
+	}
+
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+
+	for {
+		shell.mu.Lock()
+		if shell.pending.Len() > 0 {
+			data = shell.pending.Next(maxBytes)
+			shell.mu.Unlock()
+			return data, true, nil
+		}
+		shell.mu.Unlock()
+
+		select {
+		case <-shell.newData:
+			continue
+		case <-shell.done:
+			shell.mu.Lock()
+			if shell.pending.Len() > 0 {
+				data = shell.pending.Next(maxBytes)
+				shell.mu.Unlock()
+				return data, true, nil
+			}
+			shell.mu.Unlock()
+			return nil, false, nil
+		case <-deadline.C:
+			return nil, true, nil
+		}
+	}
+}
+
+// Resize changes the PTY window size of an open shell session.
+func (m *Manager) Resize(sessionID string, cols, rows int) error {
+	shell, err := m.shellByID(sessionID)
+	if err != nil {
+		return err
+	}
+
+	if err := shell.session.WindowChange(rows, cols); err != nil {
+		return fmt.Errorf("failed to resize shell: %w", err)
+	}
+	return nil
+}
+
+// CloseShell terminates and unregisters a shell session by ID.
+func (m *Manager) CloseShell(sessionID string) error {
+	m.shellsMu.Lock()
+	shell, ok := m.shells[sessionID]
+	if ok {
+		delete(m.shells, sessionID)
+	}
+	m.shellsMu.Unlock()
+
+	if !ok {
+		return fmt.Errorf("no shell session with id '%s'", sessionID)
+	}
+
+	return shell.close()
+}
+
+func (s *ShellSession) close() error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	s.closed = true
+	s.mu.Unlock()
+
+	s.stdin.Close()
+	return s.session.Close()
+}
+
+// closeShellsForAlias closes every shell session opened against alias.
+// Called on Disconnect so stale PTY sessions don't leak past their
+// connection's lifetime.
+func (m *Manager) closeShellsForAlias(alias string) {
+	m.shellsMu.Lock()
+	var stale []*ShellSession
+	for id, shell := range m.shells {
+		if shell.Alias == alias {
+			stale = append(stale, shell)
+			delete(m.shells, id)
+		}
+	}
+	m.shellsMu.Unlock()
+
+	for _, shell := range stale {
+		log.Printf("[Shell] Closing %s: connection %s was disconnected", shell.ID, alias)
+		shell.close()
+	}
+}
+
+// closeAllShells closes and unregisters every open shell session.
+func (m *Manager) closeAllShells() {
+	m.shellsMu.Lock()
+	shells := m.shells
+	m.shells = make(map[string]*ShellSession)
+	m.shellsMu.Unlock()
+
+	for _, shell := range shells {
+		shell.close()
+	}
+}
+
+// ListShells returns the IDs and aliases of all currently open shell sessions.
+func (m *Manager) ListShells() []ShellInfo {
+	m.shellsMu.Lock()
+	defer m.shellsMu.Unlock()
+
+	infos := make([]ShellInfo, 0, len(m.shells))
+	for _, shell := range m.shells {
+		infos = append(infos, ShellInfo{ID: shell.ID, Alias: shell.Alias})
+	}
+	return infos
+}
+
+// ShellInfo is a JSON-friendly snapshot of an open ShellSession.
+type ShellInfo struct {
+	ID    string `json:"id"`
+	Alias string `json:"alias"`
+}
@@ -0,0 +1,242 @@
+package ssh
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	redis "github.com/redis/go-redis/v9"
+)
+
+// redisKeyPrefix namespaces session keys in the shared Redis keyspace.
+const redisKeyPrefix = "sshmcp:session:"
+
+// ConnMeta is the persistable subset of a connection's identity — no
+// passwords or private key material, since those either live in the
+// system key on disk or must be re-supplied by the client.
+type ConnMeta struct {
+	Alias    string `json:"alias"`
+	Host     string `json:"host"`
+	Port     int    `json:"port"`
+	Username string `json:"username"`
+	Via      string `json:"via,omitempty"`
+}
+
+// ManagerState is a serializable snapshot of a Manager's connection set,
+// used to rehydrate a Manager in another process or after a restart.
+type ManagerState struct {
+	Primary     string     `json:"primary"`
+	Connections []ConnMeta `json:"connections"`
+}
+
+// PoolStore persists header-keyed Manager state outside process memory so it
+// can survive restarts and be shared across a horizontally-scaled fleet.
+type PoolStore interface {
+	// Save writes state for headerKey with the given TTL.
+	Save(ctx context.Context, headerKey string, state ManagerState, ttl time.Duration) error
+	// Load returns the stored state for headerKey, or nil if not found.
+	Load(ctx context.Context, headerKey string) (*ManagerState, error)
+	// Touch refreshes the TTL for headerKey without changing its value.
+	Touch(ctx context.Context, headerKey string, ttl time.Duration) error
+	// Delete removes any stored state for headerKey.
+	Delete(ctx context.Context, headerKey string) error
+}
+
+// MemoryStore is the default PoolStore — an in-process map. It provides the
+// same interface as RedisStore but does not survive restarts, matching the
+// pool's original single-process behavior.
+type MemoryStore struct {
+	mu      sync.Mutex
+	entries map[string]memoryEntry
+}
+
+type memoryEntry struct {
+	state   ManagerState
+	expires time.Time
+}
+
+// NewMemoryStore creates a new in-process PoolStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{entries: make(map[string]memoryEntry)}
+}
+
+func (s *MemoryStore) Save(_ context.Context, headerKey string, state ManagerState, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries[headerKey] = memoryEntry{state: state, expires: time.Now().Add(ttl)}
+	return nil
+}
+
+func (s *MemoryStore) Load(_ context.Context, headerKey string) (*ManagerState, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.entries[headerKey]
+	if !ok || time.Now().After(entry.expires) {
+		return nil, nil
+	}
+	state := entry.state
+	return &state, nil
+}
+
+func (s *MemoryStore) Touch(_ context.Context, headerKey string, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.entries[headerKey]
+	if !ok {
+		return nil
+	}
+	entry.expires = time.Now().Add(ttl)
+	s.entries[headerKey] = entry
+	return nil
+}
+
+func (s *MemoryStore) Delete(_ context.Context, headerKey string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.entries, headerKey)
+	return nil
+}
+
+// RedisStore persists Manager state in Redis so header-keyed sessions survive
+// process restarts and can be shared across a fleet of ssh-mcp instances
+// behind a load balancer without sticky routing.
+type RedisStore struct {
+	client *redis.Client
+}
+
+// NewRedisStore creates a RedisStore connected to the given Redis URL
+// (e.g. "redis://localhost:6379/0").
+func NewRedisStore(redisURL string) (*RedisStore, error) {
+	opts, err := redis.ParseURL(redisURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid redis URL: %w", err)
+	}
+	return &RedisStore{client: redis.NewClient(opts)}, nil
+}
+
+func (s *RedisStore) key(headerKey string) string {
+	return redisKeyPrefix + headerKey
+}
+
+func (s *RedisStore) Save(ctx context.Context, headerKey string, state ManagerState, ttl time.Duration) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return fmt.Errorf("failed to marshal manager state: %w", err)
+	}
+	return s.client.Set(ctx, s.key(headerKey), data, ttl).Err()
+}
+
+func (s *RedisStore) Load(ctx context.Context, headerKey string) (*ManagerState, error) {
+	data, err := s.client.Get(ctx, s.key(headerKey)).Bytes()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load manager state: %w", err)
+	}
+
+	var state ManagerState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal manager state: %w", err)
+	}
+	return &state, nil
+}
+
+func (s *RedisStore) Touch(ctx context.Context, headerKey string, ttl time.Duration) error {
+	return s.client.Expire(ctx, s.key(headerKey), ttl).Err()
+}
+
+func (s *RedisStore) Delete(ctx context.Context, headerKey string) error {
+	return s.client.Del(ctx, s.key(headerKey)).Err()
+}
+
+// Close releases the underlying Redis connection pool.
+func (s *RedisStore) Close() error {
+	return s.client.Close()
+}
+
+// Snapshot captures the current connection set as a serializable state,
+// excluding secrets (passwords and private key material never leave memory).
+func (m *Manager) Snapshot() ManagerState {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	state := ManagerState{Primary: m.primary}
+	for alias, client := range m.connections {
+		if client == nil {
+			continue
+		}
+		state.Connections = append(state.Connections, ConnMeta{
+			Alias:    alias,
+			Host:     client.creds.Host,
+			Port:     client.creds.Port,
+			Username: client.creds.Username,
+			Via:      client.creds.Via,
+		})
+	}
+	return state
+}
+
+// RehydrateManager reconnects every alias described by state using the
+// system key, rebuilding a Manager after a process restart or on another
+// node in the fleet. Jump-host ("via") ordering is respected by attempting
+// direct connections first, then retrying aliases whose jump host is now
+// available. Aliases that fail to reconnect are skipped with a warning —
+// the caller still gets a usable Manager for everything that succeeded.
+func RehydrateManager(keyPath string, state ManagerState) *Manager {
+	mgr := NewManager(keyPath, "/")
+
+	remaining := state.Connections
+	for len(remaining) > 0 {
+		progressed := false
+		var stillRemaining []ConnMeta
+
+		for _, meta := range remaining {
+			if meta.Via != "" {
+				mgr.mu.RLock()
+				_, jumpReady := mgr.connections[meta.Via]
+				mgr.mu.RUnlock()
+				if !jumpReady {
+					stillRemaining = append(stillRemaining, meta)
+					continue
+				}
+			}
+
+			_, err := mgr.Connect(context.Background(), ConnectOptions{
+				Host:     meta.Host,
+				Port:     meta.Port,
+				Username: meta.Username,
+				Alias:    meta.Alias,
+				Via:      meta.Via,
+			})
+			if err != nil {
+				log.Printf("[Manager] Failed to rehydrate connection '%s': %v", meta.Alias, err)
+				continue
+			}
+			progressed = true
+		}
+
+		if !progressed {
+			for _, meta := range stillRemaining {
+				log.Printf("[Manager] Failed to rehydrate connection '%s': jump host '%s' unavailable", meta.Alias, meta.Via)
+			}
+			break
+		}
+		remaining = stillRemaining
+	}
+
+	if state.Primary != "" {
+		mgr.mu.Lock()
+		if _, ok := mgr.connections[state.Primary]; ok {
+			mgr.primary = state.Primary
+		}
+		mgr.mu.Unlock()
+	}
+
+	return mgr
+}
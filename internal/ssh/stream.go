@@ -0,0 +1,274 @@
+package ssh
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+)
+
+// ProgressFunc is called as bytes move through a streaming transfer, with the
+// cumulative count transferred so far. A future SSE tool can use this to
+// relay progress events to the MCP client. May be nil.
+type ProgressFunc func(transferred int64)
+
+// progressReadCloser wraps an io.ReadCloser, invoking onProgress with the
+// cumulative byte count after every successful Read.
+type progressReadCloser struct {
+	io.ReadCloser
+	onProgress ProgressFunc
+	total      int64
+}
+
+func (p *progressReadCloser) Read(buf []byte) (int, error) {
+	n, err := p.ReadCloser.Read(buf)
+	if n > 0 && p.onProgress != nil {
+		p.total += int64(n)
+		p.onProgress(p.total)
+	}
+	return n, err
+}
+
+// progressWriteCloser wraps an io.WriteCloser, invoking onProgress with the
+// cumulative byte count after every successful Write.
+type progressWriteCloser struct {
+	io.WriteCloser
+	onProgress ProgressFunc
+	total      int64
+}
+
+func (p *progressWriteCloser) Write(buf []byte) (int, error) {
+	n, err := p.WriteCloser.Write(buf)
+	if n > 0 && p.onProgress != nil {
+		p.total += int64(n)
+		p.onProgress(p.total)
+	}
+	return n, err
+}
+
+// OpenRead opens path for streaming reads without loading the whole file
+// into memory. The alias lock isn't taken; the connection map is only
+// read-locked to resolve the connection — the caller reads at its own pace via the returned
+// io.ReadCloser, which must be closed when done. onProgress may be nil.
+func (m *Manager) OpenRead(ctx context.Context, path, target string, onProgress ProgressFunc) (io.ReadCloser, error) {
+	alias, err := m.resolveTarget(target)
+	if err != nil {
+		return nil, err
+	}
+
+	resolved := m.resolvePath(path, alias)
+
+	client, err := m.clientForAlias(alias)
+	if err != nil {
+		return nil, err
+	}
+
+	sftpClient, err := client.SFTP()
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := sftpClient.Open(resolved)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+
+	if onProgress == nil {
+		return file, nil
+	}
+	return &progressReadCloser{ReadCloser: file, onProgress: onProgress}, nil
+}
+
+// OpenWrite opens path for streaming writes without buffering the whole
+// payload in memory. The alias lock isn't taken; the connection map is only
+// read-locked to resolve the connection; the caller writes at its own pace via the returned
+// io.WriteCloser, which must be closed to flush and release the SFTP handle.
+// onProgress may be nil.
+func (m *Manager) OpenWrite(ctx context.Context, path, target string, onProgress ProgressFunc) (io.WriteCloser, error) {
+	alias, err := m.resolveTarget(target)
+	if err != nil {
+		return nil, err
+	}
+
+	resolved := m.resolvePath(path, alias)
+
+	client, err := m.clientForAlias(alias)
+	if err != nil {
+		return nil, err
+	}
+
+	sftpClient, err := client.SFTP()
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := sftpClient.Create(resolved)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create file: %w", err)
+	}
+
+	if onProgress == nil {
+		return file, nil
+	}
+	return &progressWriteCloser{WriteCloser: file, onProgress: onProgress}, nil
+}
+
+// ReadFileRange reads up to length bytes of path starting at offset, for
+// iterating through a large file in bounded pieces (e.g. ssh_read_chunk).
+// Unlike ReadFile, the alias lock isn't taken — sftp.Client is safe for
+// concurrent use, so a chunked read
+// doesn't block other operations on the same alias.
+func (m *Manager) ReadFileRange(path string, offset, length int64, target string) ([]byte, error) {
+	alias, err := m.resolveTarget(target)
+	if err != nil {
+		return nil, err
+	}
+
+	resolved := m.resolvePath(path, alias)
+
+	client, err := m.clientForAlias(alias)
+	if err != nil {
+		return nil, err
+	}
+
+	sftpClient, err := client.SFTP()
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := sftpClient.Open(resolved)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("failed to seek to offset %d: %w", offset, err)
+	}
+
+	buf := make([]byte, length)
+	n, err := io.ReadFull(file, buf)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return nil, fmt.Errorf("failed to read range: %w", err)
+	}
+
+	return buf[:n], nil
+}
+
+// WriteFileAt writes chunk into path at offset, creating the file if it
+// doesn't exist. Used for one-shot chunked writes (ssh_write_chunk without a
+// resumable token); for multi-call uploads that must survive a retry of any
+// individual chunk, use BeginUpload/WriteChunk instead.
+func (m *Manager) WriteFileAt(path string, offset int64, chunk []byte, target string) error {
+	alias, err := m.resolveTarget(target)
+	if err != nil {
+		return err
+	}
+
+	resolved := m.resolvePath(path, alias)
+
+	client, err := m.clientForAlias(alias)
+	if err != nil {
+		return err
+	}
+
+	sftpClient, err := client.SFTP()
+	if err != nil {
+		return err
+	}
+
+	file, err := sftpClient.OpenFile(resolved, os.O_WRONLY|os.O_CREATE)
+	if err != nil {
+		return fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to seek to offset %d: %w", offset, err)
+	}
+
+	if _, err := file.Write(chunk); err != nil {
+		return fmt.Errorf("failed to write chunk: %w", err)
+	}
+
+	return nil
+}
+
+// BeginUpload starts a resumable upload to path, returning a token that
+// WriteChunk calls use to append sequential chunks across multiple MCP tool
+// calls. If a chunk call fails partway through, the client can call
+// WriteChunk again with the same token and the same chunk — the offset only
+// advances on success, so retries are safe.
+func (m *Manager) BeginUpload(path, target string) (token string, err error) {
+	alias, err := m.resolveTarget(target)
+	if err != nil {
+		return "", err
+	}
+
+	resolved := m.resolvePath(path, alias)
+
+	client, err := m.clientForAlias(alias)
+	if err != nil {
+		return "", err
+	}
+
+	return client.BeginUpload(resolved), nil
+}
+
+// WriteChunk appends chunk to the upload identified by token at its next
+// expected offset, returning the offset reached after the write.
+func (m *Manager) WriteChunk(token string, chunk []byte, target string) (int64, error) {
+	alias, err := m.resolveTarget(target)
+	if err != nil {
+		return 0, err
+	}
+
+	client, err := m.clientForAlias(alias)
+	if err != nil {
+		return 0, err
+	}
+
+	path, offset, ok := client.NextChunk(token)
+	if !ok {
+		return 0, fmt.Errorf("unknown upload token: %s", token)
+	}
+
+	sftpClient, err := client.SFTP()
+	if err != nil {
+		return 0, err
+	}
+
+	file, err := sftpClient.OpenFile(path, os.O_WRONLY|os.O_CREATE)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		return 0, fmt.Errorf("failed to seek to offset %d: %w", offset, err)
+	}
+
+	n, err := file.Write(chunk)
+	if err != nil {
+		return 0, fmt.Errorf("failed to write chunk: %w", err)
+	}
+
+	client.AdvanceUpload(token, int64(n))
+	return offset + int64(n), nil
+}
+
+// EndUpload finalizes an upload token, discarding its resumable state.
+func (m *Manager) EndUpload(token, target string) error {
+	alias, err := m.resolveTarget(target)
+	if err != nil {
+		return err
+	}
+
+	client, err := m.clientForAlias(alias)
+	if err != nil {
+		return err
+	}
+
+	client.EndUpload(token)
+	return nil
+}
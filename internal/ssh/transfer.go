@@ -0,0 +1,585 @@
+package ssh
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/sftp"
+)
+
+// TransferOptions configures a Get or Put file transfer.
+type TransferOptions struct {
+	// Recursive allows a source that resolves to a directory to be walked
+	// and mirrored, rather than returning an error.
+	Recursive bool
+	// PreserveMode copies the source file's permission bits onto the
+	// destination after the copy completes.
+	PreserveMode bool
+	// PreserveTimes copies the source file's mtime onto the destination
+	// after the copy completes.
+	PreserveTimes bool
+	// Resume skips the bytes already present at the destination (if any)
+	// and seeks both sides forward instead of overwriting from scratch, so
+	// an interrupted transfer can continue on retry.
+	Resume bool
+	// OnProgress, if set, is called with the cumulative bytes transferred
+	// across every file in the call (all files, for a recursive transfer).
+	OnProgress ProgressFunc
+	// LocalRoot, if set, confines every local filesystem path Get/Put
+	// touches (the top-level localDst/localSrc and every per-file path a
+	// recursive transfer resolves under it) to this directory, via the
+	// same containment check Manager.validatePath applies elsewhere.
+	// Manager.Get/Put set it from the Manager's rootDir; left empty it
+	// imposes no restriction, matching rootDir "/".
+	LocalRoot string
+}
+
+// progressCounter wraps a reader, reporting each read's byte count to add
+// rather than a running total, so a caller can accumulate progress across
+// more than one reader (e.g. the files of a recursive transfer).
+type progressCounter struct {
+	io.Reader
+	add func(int64)
+}
+
+func (p *progressCounter) Read(buf []byte) (int, error) {
+	n, err := p.Reader.Read(buf)
+	if n > 0 && p.add != nil {
+		p.add(int64(n))
+	}
+	return n, err
+}
+
+// checkLocalRoot cleans path and, if root is set, confines it there via
+// validateLocalPath. Get/Put and their per-file helpers call this on every
+// local path before it reaches the filesystem, so a crafted local_path/
+// remote directory entry can't escape opts.LocalRoot.
+func checkLocalRoot(path, root string) (string, error) {
+	return validateLocalPath(filepath.Clean(path), root)
+}
+
+// Get downloads remoteSrc to localDst. remoteSrc may contain glob
+// metacharacters, expanded against the remote filesystem via sftp.Glob. If a
+// match is a directory, opts.Recursive must be set; the directory is walked
+// with sftp.Client.Walk and mirrored under localDst.
+func (c *Client) Get(remoteSrc, localDst string, opts TransferOptions) error {
+	localDst, err := checkLocalRoot(localDst, opts.LocalRoot)
+	if err != nil {
+		return err
+	}
+
+	sftpClient, err := c.SFTP()
+	if err != nil {
+		return err
+	}
+
+	matches, err := sftpClient.Glob(remoteSrc)
+	if err != nil {
+		return fmt.Errorf("failed to expand remote glob %q: %w", remoteSrc, err)
+	}
+	if len(matches) == 0 {
+		matches = []string{remoteSrc}
+	}
+
+	var transferred int64
+	addProgress := func(n int64) {
+		transferred += n
+		if opts.OnProgress != nil {
+			opts.OnProgress(transferred)
+		}
+	}
+
+	for _, match := range matches {
+		info, err := sftpClient.Stat(match)
+		if err != nil {
+			return fmt.Errorf("failed to stat %q: %w", match, err)
+		}
+
+		dst := localDst
+		if len(matches) > 1 || info.IsDir() {
+			dst = filepath.Join(localDst, filepath.Base(match))
+		}
+
+		if info.IsDir() {
+			if !opts.Recursive {
+				return fmt.Errorf("%q is a directory; set Recursive to transfer it", match)
+			}
+			if err := c.getDir(sftpClient, match, dst, opts, addProgress); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err := c.getFile(sftpClient, match, dst, info, opts, addProgress); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// getDir mirrors remote directory root under localRoot, walking it with
+// sftp.Client.Walk (backed by kr/fs) so entries are visited without reading
+// the whole tree into memory up front.
+func (c *Client) getDir(sftpClient *sftp.Client, root, localRoot string, opts TransferOptions, addProgress func(int64)) error {
+	walker := sftpClient.Walk(root)
+	for walker.Step() {
+		if err := walker.Err(); err != nil {
+			return fmt.Errorf("failed to walk remote directory %q: %w", root, err)
+		}
+
+		rel, err := filepath.Rel(root, walker.Path())
+		if err != nil {
+			return fmt.Errorf("failed to compute relative path for %q: %w", walker.Path(), err)
+		}
+		dst := filepath.Join(localRoot, rel)
+
+		info := walker.Stat()
+		if info.IsDir() {
+			dst, err := checkLocalRoot(dst, opts.LocalRoot)
+			if err != nil {
+				return err
+			}
+			if err := os.MkdirAll(dst, 0o755); err != nil {
+				return fmt.Errorf("failed to create local directory %q: %w", dst, err)
+			}
+			continue
+		}
+
+		if err := c.getFile(sftpClient, walker.Path(), dst, info, opts, addProgress); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// getFile copies a single remote file to localPath, resuming from the
+// destination's existing size when opts.Resume is set.
+func (c *Client) getFile(sftpClient *sftp.Client, remotePath, localPath string, remoteInfo os.FileInfo, opts TransferOptions, addProgress func(int64)) error {
+	localPath, err := checkLocalRoot(localPath, opts.LocalRoot)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(localPath), 0o755); err != nil {
+		return fmt.Errorf("failed to create local directory: %w", err)
+	}
+
+	remote, err := sftpClient.Open(remotePath)
+	if err != nil {
+		return fmt.Errorf("failed to open remote file %q: %w", remotePath, err)
+	}
+	defer remote.Close()
+
+	flags := os.O_WRONLY | os.O_CREATE
+	var offset int64
+	if opts.Resume {
+		if existing, err := os.Stat(localPath); err == nil {
+			offset = existing.Size()
+		}
+	} else {
+		flags |= os.O_TRUNC
+	}
+
+	local, err := os.OpenFile(localPath, flags, remoteInfo.Mode().Perm())
+	if err != nil {
+		return fmt.Errorf("failed to open local file %q: %w", localPath, err)
+	}
+	defer local.Close()
+
+	if offset > 0 {
+		if _, err := remote.Seek(offset, io.SeekStart); err != nil {
+			return fmt.Errorf("failed to seek remote file %q to offset %d: %w", remotePath, offset, err)
+		}
+		if _, err := local.Seek(offset, io.SeekStart); err != nil {
+			return fmt.Errorf("failed to seek local file %q to offset %d: %w", localPath, offset, err)
+		}
+	}
+
+	var reader io.Reader = remote
+	if opts.OnProgress != nil {
+		reader = &progressCounter{Reader: remote, add: addProgress}
+	}
+
+	if _, err := io.Copy(local, reader); err != nil {
+		return fmt.Errorf("failed to copy %q: %w", remotePath, err)
+	}
+
+	if opts.PreserveMode {
+		if err := os.Chmod(localPath, remoteInfo.Mode().Perm()); err != nil {
+			return fmt.Errorf("failed to preserve mode on %q: %w", localPath, err)
+		}
+	}
+	if opts.PreserveTimes {
+		mtime := remoteInfo.ModTime()
+		if err := os.Chtimes(localPath, mtime, mtime); err != nil {
+			return fmt.Errorf("failed to preserve mtime on %q: %w", localPath, err)
+		}
+	}
+
+	return nil
+}
+
+// syncTempSuffix marks the in-progress destination file SyncFile writes
+// through before renaming it over the real destPath, so a partial transfer
+// never leaves a half-written file at the name callers expect.
+const syncTempSuffix = ".sync-tmp"
+
+// sftpMkdirParents creates every path segment of dir on sftpClient, in
+// order, the way `mkdir -p` walks a path one component at a time. A Mkdir
+// failure is treated as non-fatal if the segment already exists (the
+// server returned SSH_FX_FAILURE, but Stat succeeds) - any other failure is
+// returned as an error.
+func sftpMkdirParents(sftpClient *sftp.Client, dir string) error {
+	dir = path.Clean(dir)
+	if dir == "." || dir == "/" || dir == "" {
+		return nil
+	}
+
+	segments := strings.Split(strings.TrimPrefix(dir, "/"), "/")
+	current := ""
+	if strings.HasPrefix(dir, "/") {
+		current = "/"
+	}
+	for _, seg := range segments {
+		if seg == "" {
+			continue
+		}
+		current = path.Join(current, seg)
+		if err := sftpClient.Mkdir(current); err != nil {
+			if _, statErr := sftpClient.Stat(current); statErr != nil {
+				return fmt.Errorf("failed to create directory %q: %w", current, err)
+			}
+		}
+	}
+	return nil
+}
+
+// SyncFile streams sourcePath on sourceTarget to destPath on destTarget in
+// chunkSize-sized reads, without ever buffering the whole file in Go memory
+// the way the old ReadFile+WriteFile implementation did. It writes through
+// destPath+syncTempSuffix and renames that over destPath only once the
+// whole transfer succeeds, so a crash mid-copy can't leave a partially
+// written destPath. When resume is true, it stats the temp file, seeks both
+// sides past its existing size, and continues instead of starting over.
+// onProgress, if set, is called after every chunk with the cumulative bytes
+// transferred and the source file's total size.
+func (m *Manager) SyncFile(ctx context.Context, sourcePath, sourceTarget, destPath, destTarget string, chunkSize int64, resume bool, onProgress func(transferred, total int64)) (int64, error) {
+	srcAlias, err := m.resolveTarget(sourceTarget)
+	if err != nil {
+		return 0, fmt.Errorf("source: %w", err)
+	}
+	dstAlias, err := m.resolveTarget(destTarget)
+	if err != nil {
+		return 0, fmt.Errorf("destination: %w", err)
+	}
+
+	resolvedSrc := m.resolvePath(sourcePath, srcAlias)
+	resolvedDst := m.resolvePath(destPath, dstAlias)
+
+	srcClient, err := m.clientForAlias(srcAlias)
+	if err != nil {
+		return 0, fmt.Errorf("source: %w", err)
+	}
+	dstClient, err := m.clientForAlias(dstAlias)
+	if err != nil {
+		return 0, fmt.Errorf("destination: %w", err)
+	}
+
+	srcSFTP, err := srcClient.SFTP()
+	if err != nil {
+		return 0, fmt.Errorf("source: %w", err)
+	}
+	dstSFTP, err := dstClient.SFTP()
+	if err != nil {
+		return 0, fmt.Errorf("destination: %w", err)
+	}
+
+	srcInfo, err := srcSFTP.Stat(resolvedSrc)
+	if err != nil {
+		return 0, fmt.Errorf("failed to stat source %q: %w", resolvedSrc, err)
+	}
+
+	srcFile, err := srcSFTP.Open(resolvedSrc)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open source %q: %w", resolvedSrc, err)
+	}
+	defer srcFile.Close()
+
+	if err := sftpMkdirParents(dstSFTP, path.Dir(resolvedDst)); err != nil {
+		return 0, fmt.Errorf("destination: %w", err)
+	}
+
+	tempPath := resolvedDst + syncTempSuffix
+
+	flags := os.O_WRONLY | os.O_CREATE
+	var offset int64
+	if resume {
+		if existing, err := dstSFTP.Stat(tempPath); err == nil {
+			offset = existing.Size()
+		}
+	} else {
+		flags |= os.O_TRUNC
+	}
+
+	dstFile, err := dstSFTP.OpenFile(tempPath, flags)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open destination temp file %q: %w", tempPath, err)
+	}
+	defer dstFile.Close()
+
+	if offset > 0 {
+		if _, err := srcFile.Seek(offset, io.SeekStart); err != nil {
+			return 0, fmt.Errorf("failed to seek source %q to offset %d: %w", resolvedSrc, offset, err)
+		}
+		if _, err := dstFile.Seek(offset, io.SeekStart); err != nil {
+			return 0, fmt.Errorf("failed to seek destination temp file to offset %d: %w", offset, err)
+		}
+	}
+
+	buf := make([]byte, chunkSize)
+	transferred := offset
+	for {
+		select {
+		case <-ctx.Done():
+			return transferred, ctx.Err()
+		default:
+		}
+
+		n, readErr := srcFile.Read(buf)
+		if n > 0 {
+			if _, err := dstFile.Write(buf[:n]); err != nil {
+				return transferred, fmt.Errorf("failed to write destination chunk: %w", err)
+			}
+			transferred += int64(n)
+			if onProgress != nil {
+				onProgress(transferred, srcInfo.Size())
+			}
+		}
+		if readErr == io.EOF {
+			break
+		}
+		if readErr != nil {
+			return transferred, fmt.Errorf("failed to read source chunk: %w", readErr)
+		}
+	}
+
+	if err := dstFile.Sync(); err != nil {
+		log.Printf("[Sync] fsync not supported by destination, skipping: %v", err)
+	}
+	if err := dstFile.Close(); err != nil {
+		return transferred, fmt.Errorf("failed to close destination temp file: %w", err)
+	}
+
+	if err := dstSFTP.PosixRename(tempPath, resolvedDst); err != nil {
+		// Fall back for servers without the posix-rename extension: sftp's
+		// plain Rename fails if resolvedDst already exists, so clear it first.
+		_ = dstSFTP.Remove(resolvedDst)
+		if err := dstSFTP.Rename(tempPath, resolvedDst); err != nil {
+			return transferred, fmt.Errorf("failed to rename %q to %q: %w", tempPath, resolvedDst, err)
+		}
+	}
+
+	return transferred, nil
+}
+
+// Put uploads localSrc to remoteDst. localSrc may contain glob
+// metacharacters, expanded against the local filesystem via filepath.Glob.
+// If a match is a directory, opts.Recursive must be set; the directory is
+// walked with filepath.Walk and mirrored under remoteDst.
+func (c *Client) Put(localSrc, remoteDst string, opts TransferOptions) error {
+	localSrc, err := checkLocalRoot(localSrc, opts.LocalRoot)
+	if err != nil {
+		return err
+	}
+
+	sftpClient, err := c.SFTP()
+	if err != nil {
+		return err
+	}
+
+	matches, err := filepath.Glob(localSrc)
+	if err != nil {
+		return fmt.Errorf("failed to expand local glob %q: %w", localSrc, err)
+	}
+	if len(matches) == 0 {
+		matches = []string{localSrc}
+	}
+
+	var transferred int64
+	addProgress := func(n int64) {
+		transferred += n
+		if opts.OnProgress != nil {
+			opts.OnProgress(transferred)
+		}
+	}
+
+	for _, match := range matches {
+		info, err := os.Stat(match)
+		if err != nil {
+			return fmt.Errorf("failed to stat %q: %w", match, err)
+		}
+
+		dst := remoteDst
+		if len(matches) > 1 || info.IsDir() {
+			dst = path.Join(remoteDst, filepath.Base(match))
+		}
+
+		if info.IsDir() {
+			if !opts.Recursive {
+				return fmt.Errorf("%q is a directory; set Recursive to transfer it", match)
+			}
+			if err := c.putDir(sftpClient, match, dst, opts, addProgress); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err := c.putFile(sftpClient, match, dst, info, opts, addProgress); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// putDir mirrors local directory root under remoteRoot, walking it with the
+// standard library's filepath.Walk.
+func (c *Client) putDir(sftpClient *sftp.Client, root, remoteRoot string, opts TransferOptions, addProgress func(int64)) error {
+	return filepath.Walk(root, func(localPath string, info os.FileInfo, err error) error {
+		if err != nil {
+			return fmt.Errorf("failed to walk local directory %q: %w", root, err)
+		}
+
+		rel, err := filepath.Rel(root, localPath)
+		if err != nil {
+			return fmt.Errorf("failed to compute relative path for %q: %w", localPath, err)
+		}
+		dst := path.Join(remoteRoot, filepath.ToSlash(rel))
+
+		if info.IsDir() {
+			if err := sftpClient.MkdirAll(dst); err != nil {
+				return fmt.Errorf("failed to create remote directory %q: %w", dst, err)
+			}
+			return nil
+		}
+
+		return c.putFile(sftpClient, localPath, dst, info, opts, addProgress)
+	})
+}
+
+// putFile copies a single local file to remotePath, resuming from the
+// destination's existing size when opts.Resume is set.
+func (c *Client) putFile(sftpClient *sftp.Client, localPath, remotePath string, localInfo os.FileInfo, opts TransferOptions, addProgress func(int64)) error {
+	localPath, err := checkLocalRoot(localPath, opts.LocalRoot)
+	if err != nil {
+		return err
+	}
+
+	if err := sftpClient.MkdirAll(path.Dir(remotePath)); err != nil {
+		return fmt.Errorf("failed to create remote directory: %w", err)
+	}
+
+	local, err := os.Open(localPath)
+	if err != nil {
+		return fmt.Errorf("failed to open local file %q: %w", localPath, err)
+	}
+	defer local.Close()
+
+	flags := os.O_WRONLY | os.O_CREATE
+	var offset int64
+	if opts.Resume {
+		if existing, err := sftpClient.Stat(remotePath); err == nil {
+			offset = existing.Size()
+		}
+	} else {
+		flags |= os.O_TRUNC
+	}
+
+	remote, err := sftpClient.OpenFile(remotePath, flags)
+	if err != nil {
+		return fmt.Errorf("failed to open remote file %q: %w", remotePath, err)
+	}
+	defer remote.Close()
+
+	if offset > 0 {
+		if _, err := local.Seek(offset, io.SeekStart); err != nil {
+			return fmt.Errorf("failed to seek local file %q to offset %d: %w", localPath, offset, err)
+		}
+		if _, err := remote.Seek(offset, io.SeekStart); err != nil {
+			return fmt.Errorf("failed to seek remote file %q to offset %d: %w", remotePath, offset, err)
+		}
+	}
+
+	var reader io.Reader = local
+	if opts.OnProgress != nil {
+		reader = &progressCounter{Reader: local, add: addProgress}
+	}
+
+	if _, err := io.Copy(remote, reader); err != nil {
+		return fmt.Errorf("failed to copy %q: %w", localPath, err)
+	}
+
+	if opts.PreserveMode {
+		if err := sftpClient.Chmod(remotePath, localInfo.Mode().Perm()); err != nil {
+			return fmt.Errorf("failed to preserve mode on %q: %w", remotePath, err)
+		}
+	}
+	if opts.PreserveTimes {
+		mtime := localInfo.ModTime()
+		if err := sftpClient.Chtimes(remotePath, mtime, mtime); err != nil {
+			return fmt.Errorf("failed to preserve mtime on %q: %w", remotePath, err)
+		}
+	}
+
+	return nil
+}
+
+// Get resolves target and downloads remoteSrc to localDst via the
+// connection's SFTP client. See Client.Get for transfer semantics. opts is
+// given the Manager's rootDir as LocalRoot, confining localDst (and every
+// path a recursive transfer writes under it) the same as validatePath
+// does for other local filesystem access.
+func (m *Manager) Get(remoteSrc, localDst, target string, opts TransferOptions) error {
+	alias, err := m.resolveTarget(target)
+	if err != nil {
+		return err
+	}
+
+	remoteSrc = m.resolvePath(remoteSrc, alias)
+	opts.LocalRoot = m.rootDir
+
+	client, err := m.clientForAlias(alias)
+	if err != nil {
+		return err
+	}
+
+	return client.Get(remoteSrc, localDst, opts)
+}
+
+// Put resolves target and uploads localSrc to remoteDst via the
+// connection's SFTP client. See Client.Put for transfer semantics. opts
+// is given the Manager's rootDir as LocalRoot, confining localSrc the
+// same as validatePath does for other local filesystem access.
+func (m *Manager) Put(localSrc, remoteDst, target string, opts TransferOptions) error {
+	alias, err := m.resolveTarget(target)
+	if err != nil {
+		return err
+	}
+
+	remoteDst = m.resolvePath(remoteDst, alias)
+	opts.LocalRoot = m.rootDir
+
+	client, err := m.clientForAlias(alias)
+	if err != nil {
+		return err
+	}
+
+	return client.Put(localSrc, remoteDst, opts)
+}
@@ -0,0 +1,70 @@
+package ssh
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestManagerTransferRejectsLocalPathEscape exercises Manager.Get/Put —
+// the same entry points createDownloadHandler/createUploadHandler call —
+// rather than validatePath in isolation, so a regression here would mean
+// the upload/download MCP tools are reading/writing outside rootDir, not
+// just that the standalone path-check function works.
+func TestManagerTransferRejectsLocalPathEscape(t *testing.T) {
+	mgr := NewManager("", "/data/safe_root")
+	defer mgr.Close()
+
+	mgr.connections["primary"] = &Client{}
+	mgr.primary = "primary"
+
+	t.Run("Get rejects local_path outside rootDir", func(t *testing.T) {
+		err := mgr.Get("/remote/file.txt", "/etc/ssh_host_rsa_key", "primary", TransferOptions{})
+		if err == nil {
+			t.Fatal("SECURITY FAIL: download wrote outside rootDir")
+		}
+		if !strings.Contains(err.Error(), "escapes allowed root") {
+			t.Errorf("expected a containment error, got: %v", err)
+		}
+	})
+
+	t.Run("Put rejects local_path outside rootDir", func(t *testing.T) {
+		err := mgr.Put("/root/.ssh/authorized_keys", "/remote/file.txt", "primary", TransferOptions{})
+		if err == nil {
+			t.Fatal("SECURITY FAIL: upload read outside rootDir")
+		}
+		if !strings.Contains(err.Error(), "escapes allowed root") {
+			t.Errorf("expected a containment error, got: %v", err)
+		}
+	})
+}
+
+// TestGetDirRejectsEscapingWalkEntry reproduces the exact path computation
+// getDir performs for each directory entry sftp.Client.Walk reports: join
+// the remote entry's path-relative-to-root onto localRoot, then gate the
+// result through checkLocalRoot before it ever reaches os.MkdirAll. A
+// malicious or compromised remote SFTP server controls walker.Path() (it's
+// free to report whatever entry names it likes in a readdir response), so
+// rel can be a "..'"-escaping path even though root and localRoot are both
+// well-formed; getDir's MkdirAll branch must not create anything outside
+// localRoot when that happens.
+func TestGetDirRejectsEscapingWalkEntry(t *testing.T) {
+	const root = "/home/user/data"
+	const localRoot = "/data/safe_root"
+
+	// walker.Path() as reported by a hostile server for a subdirectory
+	// entry named "../../../etc/cron.d" inside root.
+	entryPath := filepath.Join(root, "../../../etc/cron.d")
+
+	rel, err := filepath.Rel(root, entryPath)
+	if err != nil {
+		t.Fatalf("filepath.Rel: %v", err)
+	}
+	dst := filepath.Join(localRoot, rel)
+
+	if _, err := checkLocalRoot(dst, localRoot); err == nil {
+		t.Fatal("SECURITY FAIL: getDir's MkdirAll would have created a directory outside localRoot")
+	} else if !strings.Contains(err.Error(), "escapes allowed root") {
+		t.Errorf("expected a containment error, got: %v", err)
+	}
+}
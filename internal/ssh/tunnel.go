@@ -0,0 +1,424 @@
+package ssh
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// TunnelType identifies the kind of port-forward a Tunnel implements.
+type TunnelType string
+
+const (
+	// TunnelLocal forwards a local listen address to a remote address (ssh -L).
+	TunnelLocal TunnelType = "local"
+	// TunnelRemote forwards a remote listen address back to a local address (ssh -R).
+	TunnelRemote TunnelType = "remote"
+	// TunnelSOCKS5 runs a SOCKS5 proxy that dials out through the SSH connection (ssh -D).
+	TunnelSOCKS5 TunnelType = "socks5"
+)
+
+// Tunnel tracks a single open port-forward or SOCKS5 proxy.
+type Tunnel struct {
+	ID         string     `json:"id"`
+	Type       TunnelType `json:"type"`
+	Alias      string     `json:"alias"`
+	ListenAddr string     `json:"listen_addr"`
+	RemoteAddr string     `json:"remote_addr,omitempty"`
+
+	listener net.Listener
+	bytesIn  atomic.Int64
+	bytesOut atomic.Int64
+	closed   atomic.Bool
+}
+
+// BytesIn returns the number of bytes read from the listen side.
+func (t *Tunnel) BytesIn() int64 { return t.bytesIn.Load() }
+
+// BytesOut returns the number of bytes written back to the listen side.
+func (t *Tunnel) BytesOut() int64 { return t.bytesOut.Load() }
+
+// TunnelInfo is a JSON-friendly snapshot of a Tunnel's state.
+type TunnelInfo struct {
+	ID         string     `json:"id"`
+	Type       TunnelType `json:"type"`
+	Alias      string     `json:"alias"`
+	ListenAddr string     `json:"listen_addr"`
+	RemoteAddr string     `json:"remote_addr,omitempty"`
+	BytesIn    int64      `json:"bytes_in"`
+	BytesOut   int64      `json:"bytes_out"`
+}
+
+// generateTunnelID creates a unique tunnel identifier.
+func generateTunnelID(t TunnelType) string {
+	return fmt.Sprintf("tun-%s-%d", t, time.Now().UnixNano())
+}
+
+// OpenLocalForward opens a local listener that pipes accepted connections
+// through the SSH connection identified by alias to remoteAddr (ssh -L).
+func (m *Manager) OpenLocalForward(alias, listenAddr, remoteAddr string) (string, error) {
+	client, err := m.clientForAlias(alias)
+	if err != nil {
+		return "", err
+	}
+
+	listener, err := net.Listen("tcp", listenAddr)
+	if err != nil {
+		return "", fmt.Errorf("failed to listen on %s: %w", listenAddr, err)
+	}
+
+	tunnel := &Tunnel{
+		ID:         generateTunnelID(TunnelLocal),
+		Type:       TunnelLocal,
+		Alias:      alias,
+		ListenAddr: listener.Addr().String(),
+		RemoteAddr: remoteAddr,
+		listener:   listener,
+	}
+
+	m.registerTunnel(tunnel)
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				if !isClosedListenerError(err) {
+					log.Printf("[Tunnel] %s: accept error: %v", tunnel.ID, err)
+				}
+				return
+			}
+			go m.pipeLocalForward(tunnel, client, conn, remoteAddr)
+		}
+	}()
+
+	log.Printf("[Tunnel] Opened local forward %s: %s -> %s (via %s)", tunnel.ID, tunnel.ListenAddr, remoteAddr, alias)
+	return tunnel.ID, nil
+}
+
+// OpenRemoteForward asks the remote SSH server to listen on listenAddr and
+// pipe accepted connections back to localAddr on this host (ssh -R).
+func (m *Manager) OpenRemoteForward(alias, listenAddr, localAddr string) (string, error) {
+	client, err := m.clientForAlias(alias)
+	if err != nil {
+		return "", err
+	}
+
+	remoteListener, err := client.ListenRemote(listenAddr)
+	if err != nil {
+		return "", fmt.Errorf("failed to request remote listen on %s: %w", listenAddr, err)
+	}
+
+	tunnel := &Tunnel{
+		ID:         generateTunnelID(TunnelRemote),
+		Type:       TunnelRemote,
+		Alias:      alias,
+		ListenAddr: listenAddr,
+		RemoteAddr: localAddr,
+		listener:   remoteListener,
+	}
+
+	m.registerTunnel(tunnel)
+
+	go func() {
+		for {
+			conn, err := remoteListener.Accept()
+			if err != nil {
+				if !isClosedListenerError(err) {
+					log.Printf("[Tunnel] %s: accept error: %v", tunnel.ID, err)
+				}
+				return
+			}
+			go m.pipeRemoteForward(tunnel, conn, localAddr)
+		}
+	}()
+
+	log.Printf("[Tunnel] Opened remote forward %s: %s <- %s (via %s)", tunnel.ID, listenAddr, localAddr, alias)
+	return tunnel.ID, nil
+}
+
+// OpenSOCKS5 starts a local SOCKS5 proxy on listenAddr that dials destinations
+// through the SSH connection identified by alias (ssh -D).
+func (m *Manager) OpenSOCKS5(alias, listenAddr string) (string, error) {
+	client, err := m.clientForAlias(alias)
+	if err != nil {
+		return "", err
+	}
+
+	listener, err := net.Listen("tcp", listenAddr)
+	if err != nil {
+		return "", fmt.Errorf("failed to listen on %s: %w", listenAddr, err)
+	}
+
+	tunnel := &Tunnel{
+		ID:         generateTunnelID(TunnelSOCKS5),
+		Type:       TunnelSOCKS5,
+		Alias:      alias,
+		ListenAddr: listener.Addr().String(),
+		listener:   listener,
+	}
+
+	m.registerTunnel(tunnel)
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				if !isClosedListenerError(err) {
+					log.Printf("[Tunnel] %s: accept error: %v", tunnel.ID, err)
+				}
+				return
+			}
+			go m.serveSOCKS5(tunnel, client, conn)
+		}
+	}()
+
+	log.Printf("[Tunnel] Opened SOCKS5 proxy %s: %s (via %s)", tunnel.ID, tunnel.ListenAddr, alias)
+	return tunnel.ID, nil
+}
+
+// clientForAlias resolves a connection alias to its live Client.
+func (m *Manager) clientForAlias(alias string) (*Client, error) {
+	m.mu.RLock()
+	client := m.connections[alias]
+	m.mu.RUnlock()
+
+	if client == nil {
+		return nil, fmt.Errorf("no connection with alias '%s'", alias)
+	}
+	return client, nil
+}
+
+// registerTunnel adds a tunnel to the manager's tracked tunnel set.
+func (m *Manager) registerTunnel(t *Tunnel) {
+	m.mu.Lock()
+	m.tunnels[t.ID] = t
+	m.mu.Unlock()
+}
+
+// CloseTunnel closes and unregisters a tunnel by ID.
+func (m *Manager) CloseTunnel(tunnelID string) error {
+	m.mu.Lock()
+	tunnel, ok := m.tunnels[tunnelID]
+	if ok {
+		delete(m.tunnels, tunnelID)
+	}
+	m.mu.Unlock()
+
+	if !ok {
+		return fmt.Errorf("no tunnel with id '%s'", tunnelID)
+	}
+
+	return tunnel.close()
+}
+
+// closeTunnelsForAlias closes every tunnel bound to alias. Called when the
+// underlying connection is lost and reconnected, since listeners opened
+// against the old *ssh.Client can no longer serve traffic.
+func (m *Manager) closeTunnelsForAlias(alias string) {
+	m.mu.Lock()
+	var stale []*Tunnel
+	for id, t := range m.tunnels {
+		if t.Alias == alias {
+			stale = append(stale, t)
+			delete(m.tunnels, id)
+		}
+	}
+	m.mu.Unlock()
+
+	for _, t := range stale {
+		log.Printf("[Tunnel] Closing %s: connection %s was reset", t.ID, alias)
+		t.close()
+	}
+}
+
+func (t *Tunnel) close() error {
+	if !t.closed.CompareAndSwap(false, true) {
+		return nil // already closed
+	}
+	if t.listener != nil {
+		return t.listener.Close()
+	}
+	return nil
+}
+
+// ListTunnels returns a snapshot of all currently open tunnels.
+func (m *Manager) ListTunnels() []TunnelInfo {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	infos := make([]TunnelInfo, 0, len(m.tunnels))
+	for _, t := range m.tunnels {
+		infos = append(infos, TunnelInfo{
+			ID:         t.ID,
+			Type:       t.Type,
+			Alias:      t.Alias,
+			ListenAddr: t.ListenAddr,
+			RemoteAddr: t.RemoteAddr,
+			BytesIn:    t.BytesIn(),
+			BytesOut:   t.BytesOut(),
+		})
+	}
+	return infos
+}
+
+// pipeLocalForward dials remoteAddr through client and copies bytes both ways.
+func (m *Manager) pipeLocalForward(tunnel *Tunnel, client *Client, conn net.Conn, remoteAddr string) {
+	defer conn.Close()
+
+	remoteConn, err := client.DialRemote("tcp", remoteAddr)
+	if err != nil {
+		log.Printf("[Tunnel] %s: failed to dial %s: %v", tunnel.ID, remoteAddr, err)
+		return
+	}
+	defer remoteConn.Close()
+
+	copyBoth(tunnel, conn, remoteConn)
+}
+
+// pipeRemoteForward dials localAddr on this host and copies bytes both ways.
+func (m *Manager) pipeRemoteForward(tunnel *Tunnel, conn net.Conn, localAddr string) {
+	defer conn.Close()
+
+	localConn, err := net.Dial("tcp", localAddr)
+	if err != nil {
+		log.Printf("[Tunnel] %s: failed to dial local %s: %v", tunnel.ID, localAddr, err)
+		return
+	}
+	defer localConn.Close()
+
+	copyBoth(tunnel, conn, localConn)
+}
+
+// copyBoth pipes data bidirectionally between a (the listen side) and b,
+// tracking byte counts on tunnel.
+func copyBoth(tunnel *Tunnel, a, b net.Conn) {
+	done := make(chan struct{}, 2)
+
+	go func() {
+		n, _ := io.Copy(b, a)
+		tunnel.bytesIn.Add(n)
+		done <- struct{}{}
+	}()
+	go func() {
+		n, _ := io.Copy(a, b)
+		tunnel.bytesOut.Add(n)
+		done <- struct{}{}
+	}()
+
+	<-done
+	<-done
+}
+
+// --- Minimal SOCKS5 server (CONNECT command only, no authentication) ---
+
+func (m *Manager) serveSOCKS5(tunnel *Tunnel, client *Client, conn net.Conn) {
+	defer conn.Close()
+
+	target, err := socks5Handshake(conn)
+	if err != nil {
+		log.Printf("[Tunnel] %s: SOCKS5 handshake failed: %v", tunnel.ID, err)
+		return
+	}
+
+	remoteConn, err := client.DialRemote("tcp", target)
+	if err != nil {
+		socks5Reply(conn, 0x05) // general failure
+		log.Printf("[Tunnel] %s: failed to dial %s: %v", tunnel.ID, target, err)
+		return
+	}
+	defer remoteConn.Close()
+
+	if err := socks5Reply(conn, 0x00); err != nil {
+		return
+	}
+
+	copyBoth(tunnel, conn, remoteConn)
+}
+
+// socks5Handshake performs the SOCKS5 greeting and CONNECT request, returning
+// the requested "host:port" destination.
+func socks5Handshake(conn net.Conn) (string, error) {
+	// Greeting: VER NMETHODS METHODS...
+	header := make([]byte, 2)
+	if _, err := io.ReadFull(conn, header); err != nil {
+		return "", fmt.Errorf("failed to read greeting: %w", err)
+	}
+	if header[0] != 0x05 {
+		return "", errors.New("unsupported SOCKS version")
+	}
+	methods := make([]byte, header[1])
+	if _, err := io.ReadFull(conn, methods); err != nil {
+		return "", fmt.Errorf("failed to read auth methods: %w", err)
+	}
+	// No authentication required.
+	if _, err := conn.Write([]byte{0x05, 0x00}); err != nil {
+		return "", err
+	}
+
+	// Request: VER CMD RSV ATYP DST.ADDR DST.PORT
+	req := make([]byte, 4)
+	if _, err := io.ReadFull(conn, req); err != nil {
+		return "", fmt.Errorf("failed to read request: %w", err)
+	}
+	if req[0] != 0x05 {
+		return "", errors.New("unsupported SOCKS version in request")
+	}
+	if req[1] != 0x01 {
+		return "", fmt.Errorf("unsupported SOCKS command %d (only CONNECT is supported)", req[1])
+	}
+
+	var host string
+	switch req[3] {
+	case 0x01: // IPv4
+		addr := make([]byte, 4)
+		if _, err := io.ReadFull(conn, addr); err != nil {
+			return "", err
+		}
+		host = net.IP(addr).String()
+	case 0x03: // domain name
+		lenBuf := make([]byte, 1)
+		if _, err := io.ReadFull(conn, lenBuf); err != nil {
+			return "", err
+		}
+		domain := make([]byte, lenBuf[0])
+		if _, err := io.ReadFull(conn, domain); err != nil {
+			return "", err
+		}
+		host = string(domain)
+	case 0x04: // IPv6
+		addr := make([]byte, 16)
+		if _, err := io.ReadFull(conn, addr); err != nil {
+			return "", err
+		}
+		host = net.IP(addr).String()
+	default:
+		return "", fmt.Errorf("unsupported address type %d", req[3])
+	}
+
+	portBuf := make([]byte, 2)
+	if _, err := io.ReadFull(conn, portBuf); err != nil {
+		return "", err
+	}
+	port := int(portBuf[0])<<8 | int(portBuf[1])
+
+	return net.JoinHostPort(host, fmt.Sprintf("%d", port)), nil
+}
+
+// socks5Reply writes a minimal SOCKS5 reply with the given status code,
+// always reporting a bound address of 0.0.0.0:0 (we don't expose it).
+func socks5Reply(conn net.Conn, status byte) error {
+	reply := []byte{0x05, status, 0x00, 0x01, 0, 0, 0, 0, 0, 0}
+	_, err := conn.Write(reply)
+	return err
+}
+
+// isClosedListenerError reports whether err indicates a listener was closed,
+// used to avoid logging noisy errors during normal shutdown.
+func isClosedListenerError(err error) bool {
+	return err != nil && strings.Contains(err.Error(), "use of closed network connection")
+}
@@ -0,0 +1,532 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"ssh-mcp/internal/ssh"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// registerComposeTools registers compose_* tools, which dispatch through the
+// "docker compose" v2 plugin (falling back to legacy docker-compose) rather
+// than the container.ContainerRuntime abstraction used by docker_*, since
+// Compose has no equivalent across Podman/containerd.
+func registerComposeTools(s *server.MCPServer, pool *ssh.Pool) {
+	composeFileDesc := "Directory holding docker-compose.yml, or pass compose_file instead for a specific file"
+
+	// compose_ps
+	s.AddTool(
+		mcp.NewTool("compose_ps",
+			mcp.WithDescription("List a Compose project's services with their image, status, health, and published ports"),
+			mcp.WithString("project_dir", mcp.Description(composeFileDesc)),
+			mcp.WithString("compose_file", mcp.Description("Path to a specific compose file (alternative to project_dir)")),
+			mcp.WithArray("services", mcp.Description("Limit to these services (default: all)")),
+			mcp.WithArray("profiles", mcp.Description("Compose profiles to enable")),
+			mcp.WithString("target", mcp.Description("Connection alias (default: primary)")),
+		),
+		createComposePsHandler(pool),
+	)
+
+	// compose_up
+	s.AddTool(
+		mcp.NewTool("compose_up",
+			mcp.WithDescription(`Bring up a Compose project ("docker compose up -d"), streaming pull/build/start output via MCP notifications as it runs.`),
+			mcp.WithString("project_dir", mcp.Description(composeFileDesc)),
+			mcp.WithString("compose_file", mcp.Description("Path to a specific compose file (alternative to project_dir)")),
+			mcp.WithArray("services", mcp.Description("Only start these services (default: all)")),
+			mcp.WithArray("profiles", mcp.Description("Compose profiles to enable")),
+			mcp.WithBoolean("build", mcp.Description("Rebuild images before starting (adds --build)")),
+			mcp.WithBoolean("force_recreate", mcp.Description("Recreate containers even if their config is unchanged")),
+			mcp.WithNumber("max_output_bytes", mcp.Description("Stop collecting/notifying after this many bytes (default: 1048576)")),
+			mcp.WithNumber("max_duration_sec", mcp.Description("Stop streaming after this many seconds (default: 600)")),
+			mcp.WithNumber("idle_timeout_sec", mcp.Description("Stop streaming after this many seconds with no new output (default: 60)")),
+			mcp.WithString("target", mcp.Description("Connection alias (default: primary)")),
+		),
+		createComposeUpHandler(pool),
+	)
+
+	// compose_down
+	s.AddTool(
+		mcp.NewTool("compose_down",
+			mcp.WithDescription(`Tear down a Compose project ("docker compose down"), streaming output via MCP notifications as it runs.`),
+			mcp.WithString("project_dir", mcp.Description(composeFileDesc)),
+			mcp.WithString("compose_file", mcp.Description("Path to a specific compose file (alternative to project_dir)")),
+			mcp.WithBoolean("volumes", mcp.Description("Also remove named volumes declared in the project (adds -v)")),
+			mcp.WithNumber("max_output_bytes", mcp.Description("Stop collecting/notifying after this many bytes (default: 1048576)")),
+			mcp.WithNumber("max_duration_sec", mcp.Description("Stop streaming after this many seconds (default: 300)")),
+			mcp.WithNumber("idle_timeout_sec", mcp.Description("Stop streaming after this many seconds with no new output (default: 60)")),
+			mcp.WithString("target", mcp.Description("Connection alias (default: primary)")),
+		),
+		createComposeDownHandler(pool),
+	)
+
+	// compose_logs
+	s.AddTool(
+		mcp.NewTool("compose_logs",
+			mcp.WithDescription(`Get logs from a Compose project's services. Set follow=true to stream new lines as they're written, relayed via MCP notifications, instead of returning a fixed tail.`),
+			mcp.WithString("project_dir", mcp.Description(composeFileDesc)),
+			mcp.WithString("compose_file", mcp.Description("Path to a specific compose file (alternative to project_dir)")),
+			mcp.WithArray("services", mcp.Description("Limit to these services (default: all)")),
+			mcp.WithNumber("lines", mcp.Description("Number of lines to show when not following (default: 100)")),
+			mcp.WithBoolean("follow", mcp.Description("Stream new lines as they're written instead of returning a fixed tail")),
+			mcp.WithNumber("max_output_bytes", mcp.Description("Stop collecting/notifying after this many bytes when following (default: 1048576)")),
+			mcp.WithNumber("max_duration_sec", mcp.Description("Stop streaming after this many seconds when following (default: 300)")),
+			mcp.WithNumber("idle_timeout_sec", mcp.Description("Stop streaming after this many seconds with no new output when following (default: 30)")),
+			mcp.WithString("target", mcp.Description("Connection alias (default: primary)")),
+		),
+		createComposeLogsHandler(pool),
+	)
+
+	// compose_restart
+	s.AddTool(
+		mcp.NewTool("compose_restart",
+			mcp.WithDescription("Restart one or more services in a Compose project"),
+			mcp.WithString("project_dir", mcp.Description(composeFileDesc)),
+			mcp.WithString("compose_file", mcp.Description("Path to a specific compose file (alternative to project_dir)")),
+			mcp.WithArray("services", mcp.Description("Only restart these services (default: all)")),
+			mcp.WithString("target", mcp.Description("Connection alias (default: primary)")),
+		),
+		createComposeRestartHandler(pool),
+	)
+
+	// compose_config
+	s.AddTool(
+		mcp.NewTool("compose_config",
+			mcp.WithDescription("Render the fully merged/interpolated Compose configuration, useful for validating a project before compose_up"),
+			mcp.WithString("project_dir", mcp.Description(composeFileDesc)),
+			mcp.WithString("compose_file", mcp.Description("Path to a specific compose file (alternative to project_dir)")),
+			mcp.WithArray("profiles", mcp.Description("Compose profiles to enable")),
+			mcp.WithString("target", mcp.Description("Connection alias (default: primary)")),
+		),
+		createComposeConfigHandler(pool),
+	)
+}
+
+// composeScope identifies which project a compose_* call targets and any
+// service/profile selectors, parsed once per handler from the request.
+type composeScope struct {
+	projectArgs string
+	services    []string
+	profiles    []string
+}
+
+// composeScopeFromRequest validates that exactly one of project_dir or
+// compose_file was given and builds the "-f <file>"/"--project-directory
+// <dir>" flag, along with any services/profiles selectors.
+func composeScopeFromRequest(req mcp.CallToolRequest) (composeScope, error) {
+	projectDir := req.GetString("project_dir", "")
+	composeFile := req.GetString("compose_file", "")
+
+	if projectDir == "" && composeFile == "" {
+		return composeScope{}, fmt.Errorf("one of project_dir or compose_file is required")
+	}
+
+	var args string
+	if composeFile != "" {
+		args = "-f " + shellQuote(composeFile)
+	} else {
+		args = "--project-directory " + shellQuote(projectDir)
+	}
+
+	return composeScope{
+		projectArgs: args,
+		services:    req.GetStringSlice("services", nil),
+		profiles:    req.GetStringSlice("profiles", nil),
+	}, nil
+}
+
+// flags renders the scope's --project-directory/-f and --profile flags,
+// suitable for splicing between the CLI name and the subcommand.
+func (sc composeScope) flags() string {
+	var b strings.Builder
+	b.WriteString(" " + sc.projectArgs)
+	for _, p := range sc.profiles {
+		b.WriteString(" --profile " + shellQuote(p))
+	}
+	return b.String()
+}
+
+// serviceArgs renders the scope's service selectors as trailing positional
+// arguments, for subcommands (up, logs, restart) that take services after
+// any other flags.
+func (sc composeScope) serviceArgs() string {
+	var b strings.Builder
+	for _, svc := range sc.services {
+		b.WriteString(" " + shellQuote(svc))
+	}
+	return b.String()
+}
+
+// composeCLI detects whether the target has the "docker compose" v2 plugin
+// or only the legacy standalone "docker-compose" binary, preferring the
+// former. Unlike containerCLI/resolvedCLI, Compose isn't part of the
+// pluggable container.Runtime abstraction - it's Docker-specific.
+func composeCLI(ctx context.Context, mgr *ssh.Manager, target string) (string, error) {
+	if out, err := mgr.Execute(ctx, "docker compose version >/dev/null 2>&1 && echo ok || echo missing", target); err == nil && containsString(out, "ok") {
+		return "docker compose", nil
+	}
+	if out, err := mgr.Execute(ctx, "command -v docker-compose >/dev/null 2>&1 && echo ok || echo missing", target); err == nil && containsString(out, "ok") {
+		return "docker-compose", nil
+	}
+	return "", fmt.Errorf("neither the \"docker compose\" plugin nor legacy \"docker-compose\" is available on target")
+}
+
+// composePublisher mirrors one entry of a service's "Publishers" array from
+// "docker compose ps --format json".
+type composePublisher struct {
+	URL           string `json:"URL"`
+	TargetPort    int    `json:"TargetPort"`
+	PublishedPort int    `json:"PublishedPort"`
+	Protocol      string `json:"Protocol"`
+}
+
+// composePsEntry mirrors the subset of "docker compose ps --format json"
+// fields exposed by compose_ps.
+type composePsEntry struct {
+	Name       string             `json:"Name"`
+	Service    string             `json:"Service"`
+	Image      string             `json:"Image"`
+	Status     string             `json:"Status"`
+	Health     string             `json:"Health"`
+	Publishers []composePublisher `json:"Publishers"`
+}
+
+// composeService is compose_ps's flattened, output-facing shape.
+type composeService struct {
+	Name   string   `json:"name"`
+	Image  string   `json:"image"`
+	Status string   `json:"status"`
+	Health string   `json:"health,omitempty"`
+	Ports  []string `json:"ports,omitempty"`
+}
+
+// parseComposePsOutput parses "docker compose ps --format json" output,
+// which is a single JSON array on modern Compose but one JSON object per
+// line on some older versions - both are tried.
+func parseComposePsOutput(output string) ([]composeService, error) {
+	var entries []composePsEntry
+
+	trimmed := strings.TrimSpace(output)
+	if trimmed == "" {
+		return nil, nil
+	}
+
+	if err := json.Unmarshal([]byte(trimmed), &entries); err != nil {
+		entries = nil
+		for _, line := range strings.Split(trimmed, "\n") {
+			line = strings.TrimSpace(line)
+			if line == "" {
+				continue
+			}
+			var entry composePsEntry
+			if err := json.Unmarshal([]byte(line), &entry); err != nil {
+				return nil, fmt.Errorf("failed to parse compose ps output: %w", err)
+			}
+			entries = append(entries, entry)
+		}
+	}
+
+	services := make([]composeService, 0, len(entries))
+	for _, e := range entries {
+		name := e.Service
+		if name == "" {
+			name = e.Name
+		}
+		svc := composeService{
+			Name:   name,
+			Image:  e.Image,
+			Status: e.Status,
+			Health: e.Health,
+		}
+		for _, pub := range e.Publishers {
+			if pub.PublishedPort == 0 {
+				continue
+			}
+			svc.Ports = append(svc.Ports, fmt.Sprintf("%d->%d/%s", pub.PublishedPort, pub.TargetPort, pub.Protocol))
+		}
+		services = append(services, svc)
+	}
+	return services, nil
+}
+
+func createComposePsHandler(pool *ssh.Pool) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		mgr := getManager(ctx, pool)
+		if mgr == nil {
+			return mcp.NewToolResultError("No active session"), nil
+		}
+
+		target := req.GetString("target", "primary")
+
+		scope, err := composeScopeFromRequest(req)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		cli, err := composeCLI(ctx, mgr, target)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		cmd := fmt.Sprintf("%s%s ps --format json%s 2>&1", cli, scope.flags(), scope.serviceArgs())
+		output, err := mgr.Execute(ctx, cmd, target)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		services, parseErr := parseComposePsOutput(output)
+		if parseErr != nil {
+			log.Printf("[Tool:compose_ps] %v, returning raw output", parseErr)
+			return mcp.NewToolResultText(output), nil
+		}
+
+		jsonBytes, err := json.MarshalIndent(map[string]interface{}{
+			"services": services,
+		}, "", "  ")
+		if err != nil {
+			return mcp.NewToolResultError("Failed to format service list"), nil
+		}
+		return mcp.NewToolResultText(string(jsonBytes)), nil
+	}
+}
+
+func createComposeUpHandler(pool *ssh.Pool) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		mgr := getManager(ctx, pool)
+		if mgr == nil {
+			return mcp.NewToolResultError("No active session"), nil
+		}
+
+		target := req.GetString("target", "primary")
+		build := req.GetBool("build", false)
+		forceRecreate := req.GetBool("force_recreate", false)
+		maxBytes := req.GetInt("max_output_bytes", 1<<20)
+		maxDuration := time.Duration(req.GetInt("max_duration_sec", 600)) * time.Second
+		idleTimeout := time.Duration(req.GetInt("idle_timeout_sec", 60)) * time.Second
+
+		scope, err := composeScopeFromRequest(req)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		cli, err := composeCLI(ctx, mgr, target)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		upFlags := " -d"
+		if build {
+			upFlags += " --build"
+		}
+		if forceRecreate {
+			upFlags += " --force-recreate"
+		}
+		cmd := fmt.Sprintf("%s%s up%s%s 2>&1", cli, scope.flags(), upFlags, scope.serviceArgs())
+
+		return streamComposeCommand(ctx, mgr, target, "compose_up", cmd, maxBytes, maxDuration, idleTimeout)
+	}
+}
+
+func createComposeDownHandler(pool *ssh.Pool) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		mgr := getManager(ctx, pool)
+		if mgr == nil {
+			return mcp.NewToolResultError("No active session"), nil
+		}
+
+		target := req.GetString("target", "primary")
+		volumes := req.GetBool("volumes", false)
+		maxBytes := req.GetInt("max_output_bytes", 1<<20)
+		maxDuration := time.Duration(req.GetInt("max_duration_sec", 300)) * time.Second
+		idleTimeout := time.Duration(req.GetInt("idle_timeout_sec", 60)) * time.Second
+
+		scope, err := composeScopeFromRequest(req)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		cli, err := composeCLI(ctx, mgr, target)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		downFlags := ""
+		if volumes {
+			downFlags += " -v"
+		}
+		cmd := fmt.Sprintf("%s%s down%s 2>&1", cli, scope.flags(), downFlags)
+
+		return streamComposeCommand(ctx, mgr, target, "compose_down", cmd, maxBytes, maxDuration, idleTimeout)
+	}
+}
+
+func createComposeLogsHandler(pool *ssh.Pool) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		mgr := getManager(ctx, pool)
+		if mgr == nil {
+			return mcp.NewToolResultError("No active session"), nil
+		}
+
+		target := req.GetString("target", "primary")
+		lines := req.GetInt("lines", 100)
+		follow := req.GetBool("follow", false)
+		maxBytes := req.GetInt("max_output_bytes", 1<<20)
+		maxDuration := time.Duration(req.GetInt("max_duration_sec", 300)) * time.Second
+		idleTimeout := time.Duration(req.GetInt("idle_timeout_sec", 30)) * time.Second
+
+		scope, err := composeScopeFromRequest(req)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		cli, err := composeCLI(ctx, mgr, target)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		if !follow {
+			cmd := fmt.Sprintf("%s%s logs --tail %d%s 2>&1", cli, scope.flags(), lines, scope.serviceArgs())
+			output, err := mgr.Execute(ctx, cmd, target)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			return mcp.NewToolResultText(output), nil
+		}
+
+		cmd := fmt.Sprintf("%s%s logs -f --tail %d%s 2>&1", cli, scope.flags(), lines, scope.serviceArgs())
+
+		log.Printf("[Tool:compose_logs] Streaming: %s (target=%s)", cmd, target)
+
+		cctx, cancel := context.WithTimeout(ctx, maxDuration)
+		defer cancel()
+
+		srv := server.ServerFromContext(ctx)
+
+		output, _, truncated, err := streamCapped(cctx, mgr, cmd, target, maxBytes, idleTimeout,
+			func(data []byte) []byte { return data },
+			func(chunk []byte) {
+				if srv == nil {
+					return
+				}
+				if notifyErr := srv.SendNotificationToClient(ctx, "notifications/message", map[string]any{
+					"tool": "compose_logs",
+					"data": string(chunk),
+				}); notifyErr != nil {
+					log.Printf("[Tool:compose_logs] Failed to send notification: %v", notifyErr)
+				}
+			},
+		)
+		if err != nil && !truncated {
+			log.Printf("[Tool:compose_logs] Error: %v", err)
+		}
+		if truncated {
+			output += fmt.Sprintf("\n... [output truncated at %d bytes]", maxBytes)
+		}
+
+		log.Printf("[Tool:compose_logs] Done (%d bytes output)", len(output))
+		return mcp.NewToolResultText(output), nil
+	}
+}
+
+func createComposeRestartHandler(pool *ssh.Pool) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		mgr := getManager(ctx, pool)
+		if mgr == nil {
+			return mcp.NewToolResultError("No active session"), nil
+		}
+
+		target := req.GetString("target", "primary")
+
+		scope, err := composeScopeFromRequest(req)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		cli, err := composeCLI(ctx, mgr, target)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		cmd := fmt.Sprintf("%s%s restart%s 2>&1", cli, scope.flags(), scope.serviceArgs())
+		output, err := mgr.Execute(ctx, cmd, target)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		return mcp.NewToolResultText(output), nil
+	}
+}
+
+func createComposeConfigHandler(pool *ssh.Pool) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		mgr := getManager(ctx, pool)
+		if mgr == nil {
+			return mcp.NewToolResultError("No active session"), nil
+		}
+
+		target := req.GetString("target", "primary")
+
+		scope, err := composeScopeFromRequest(req)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		cli, err := composeCLI(ctx, mgr, target)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		cmd := fmt.Sprintf("%s%s config 2>&1", cli, scope.flags())
+		output, err := mgr.Execute(ctx, cmd, target)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		return mcp.NewToolResultText(output), nil
+	}
+}
+
+// streamComposeCommand runs cmd via streamCapped, relaying output as MCP
+// notifications labeled by toolName, and returns the collected (possibly
+// truncated) output as the tool result. Shared by compose_up/compose_down,
+// which both just run a Compose subcommand to completion and report what it
+// printed, unlike compose_logs which distinguishes follow vs. non-follow.
+func streamComposeCommand(ctx context.Context, mgr *ssh.Manager, target, toolName, cmd string, maxBytes int, maxDuration, idleTimeout time.Duration) (*mcp.CallToolResult, error) {
+	log.Printf("[Tool:%s] Running: %s (target=%s)", toolName, cmd, target)
+
+	cctx, cancel := context.WithTimeout(ctx, maxDuration)
+	defer cancel()
+
+	srv := server.ServerFromContext(ctx)
+
+	output, exitCode, truncated, err := streamCapped(cctx, mgr, cmd, target, maxBytes, idleTimeout,
+		func(data []byte) []byte { return data },
+		func(chunk []byte) {
+			if srv == nil {
+				return
+			}
+			if notifyErr := srv.SendNotificationToClient(ctx, "notifications/message", map[string]any{
+				"tool": toolName,
+				"data": string(chunk),
+			}); notifyErr != nil {
+				log.Printf("[Tool:%s] Failed to send notification: %v", toolName, notifyErr)
+			}
+		},
+	)
+	if err != nil && !truncated {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if truncated {
+		output += fmt.Sprintf("\n... [output truncated at %d bytes]", maxBytes)
+	}
+
+	log.Printf("[Tool:%s] Done (exit=%d, %d bytes output)", toolName, exitCode, len(output))
+	return mcp.NewToolResultText(output), nil
+}
@@ -2,8 +2,11 @@ package tools
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
+	"strings"
 	"time"
 
 	"ssh-mcp/internal/ssh"
@@ -25,6 +28,15 @@ func registerCoreTools(s *server.MCPServer, pool *ssh.Pool) {
 			mcp.WithString("private_key_path", mcp.Description("Path to private key file")),
 			mcp.WithString("alias", mcp.Description("Connection alias (auto-generated if not provided)")),
 			mcp.WithString("via", mcp.Description("Jump host alias for tunneling")),
+			mcp.WithNumber("reconnect_max_retries", mcp.Description("Max reconnect attempts after a dropped connection (default: 3)")),
+			mcp.WithNumber("reconnect_base_delay_ms", mcp.Description("Initial reconnect backoff delay in ms, doubled each attempt (default: 500)")),
+			mcp.WithNumber("reconnect_max_delay_ms", mcp.Description("Maximum reconnect backoff delay in ms (default: 10000)")),
+			mcp.WithString("host_key_mode", mcp.Description("Host key verification: 'strict' (known_hosts only), 'tofu' (pin on first connect, default), or 'insecure' (no verification)")),
+			mcp.WithBoolean("require_root", mcp.Description("Fail the connection unless the user is root or passwordless-sudo capable")),
+			mcp.WithString("escalate", mcp.Description("Set to 'sudo' to transparently prefix run commands with 'sudo -n' when not already root")),
+			mcp.WithNumber("keepalive_interval_sec", mcp.Description("Seconds between keepalive probes (default: 30)")),
+			mcp.WithNumber("keepalive_count_max", mcp.Description("Consecutive missed keepalives before the connection is considered unhealthy (default: 3)")),
+			mcp.WithBoolean("auto_reconnect", mcp.Description("Automatically reconnect when keepalive probes fail past keepalive_count_max")),
 		),
 		createConnectHandler(pool),
 	)
@@ -49,6 +61,19 @@ func registerCoreTools(s *server.MCPServer, pool *ssh.Pool) {
 		createRunHandler(pool),
 	)
 
+	// run_stream
+	s.AddTool(
+		mcp.NewTool("run_stream",
+			mcp.WithDescription(`Execute a shell command and stream its stdout/stderr as they arrive via MCP notifications instead of waiting for it to finish. Use for long-running commands (tail -f, package installs, builds) where partial output matters.
+
+Each chunk is sent as a "notifications/message" notification with {"stream": "stdout"|"stderr", "data"}; the tool result contains the full (possibly truncated) output once the command exits.`),
+			mcp.WithString("command", mcp.Required(), mcp.Description("Shell command to execute")),
+			mcp.WithString("target", mcp.Description("Connection alias (default: primary)")),
+			mcp.WithNumber("max_output_bytes", mcp.Description("Stop collecting/notifying after this many bytes, appending a truncation marker (default: 1048576)")),
+		),
+		createRunStreamHandler(pool),
+	)
+
 	// identity
 	s.AddTool(
 		mcp.NewTool("identity",
@@ -65,6 +90,42 @@ func registerCoreTools(s *server.MCPServer, pool *ssh.Pool) {
 		),
 		createInfoHandler(pool),
 	)
+
+	// whoami
+	s.AddTool(
+		mcp.NewTool("whoami",
+			mcp.WithDescription("Get the connected user's identity and sudo capability, for reasoning about privileges before running destructive commands"),
+			mcp.WithString("target", mcp.Description("Connection alias (default: primary)")),
+		),
+		createWhoamiHandler(pool),
+	)
+
+	// pool_stats
+	s.AddTool(
+		mcp.NewTool("pool_stats",
+			mcp.WithDescription("Get a snapshot of the SSH connection pool's current size, configured capacity limits, in-flight request counts, and a live/in-use/idle breakdown of entries the idle reaper will close, for on-demand introspection without scraping /metrics"),
+		),
+		createPoolStatsHandler(pool),
+	)
+
+	// health
+	s.AddTool(
+		mcp.NewTool("health",
+			mcp.WithDescription("Get the connection's keepalive health (last probe latency and result), for detecting a silently dead connection before it fails a command"),
+			mcp.WithString("target", mcp.Description("Connection alias (default: primary)")),
+		),
+		createHealthHandler(pool),
+	)
+
+	// known_hosts
+	s.AddTool(
+		mcp.NewTool("known_hosts",
+			mcp.WithDescription("List or remove pinned SSH host key fingerprints"),
+			mcp.WithString("action", mcp.Required(), mcp.Description("'list' or 'remove'")),
+			mcp.WithString("host", mcp.Description("Host to remove (required for action=remove)")),
+		),
+		createKnownHostsHandler(pool),
+	)
 }
 
 func createConnectHandler(pool *ssh.Pool) server.ToolHandlerFunc {
@@ -83,26 +144,50 @@ func createConnectHandler(pool *ssh.Pool) server.ToolHandlerFunc {
 		keyPath := req.GetString("private_key_path", "")
 		alias := req.GetString("alias", "")
 		via := req.GetString("via", "")
+		reconnectMaxRetries := req.GetInt("reconnect_max_retries", 0)
+		reconnectBaseDelayMs := req.GetInt("reconnect_base_delay_ms", 0)
+		reconnectMaxDelayMs := req.GetInt("reconnect_max_delay_ms", 0)
+		hostKeyMode := req.GetString("host_key_mode", "")
+		requireRoot := req.GetBool("require_root", false)
+		escalate := req.GetString("escalate", "")
+		keepaliveIntervalSec := req.GetInt("keepalive_interval_sec", 0)
+		keepaliveCountMax := req.GetInt("keepalive_count_max", 0)
+		autoReconnect := req.GetBool("auto_reconnect", false)
 
 		log.Printf("[Tool:connect] Connecting to %s@%s:%d", username, host, port)
 
 		opts := ssh.ConnectOptions{
-			Host:           host,
-			Port:           port,
-			Username:       username,
-			Password:       password,
-			PrivateKeyPath: keyPath,
-			Alias:          alias,
-			Via:            via,
+			Host:                 host,
+			Port:                 port,
+			Username:             username,
+			Password:             password,
+			PrivateKeyPath:       keyPath,
+			Alias:                alias,
+			Via:                  via,
+			ReconnectMaxRetries:  reconnectMaxRetries,
+			ReconnectBaseDelayMs: reconnectBaseDelayMs,
+			ReconnectMaxDelayMs:  reconnectMaxDelayMs,
+			HostKeyMode:          ssh.HostKeyMode(hostKeyMode),
+			RequireRoot:          requireRoot,
+			Escalate:             escalate,
+			KeepaliveIntervalSec: keepaliveIntervalSec,
+			KeepaliveCountMax:    keepaliveCountMax,
+			AutoReconnect:        autoReconnect,
 		}
 
 		resultAlias, err := mgr.Connect(ctx, opts)
 		if err != nil {
+			var mismatch *ssh.HostKeyMismatchError
+			if errors.As(err, &mismatch) {
+				log.Printf("[Tool:connect] Host key mismatch: %v", mismatch)
+				return mcp.NewToolResultError(fmt.Sprintf("HOST KEY MISMATCH: %v", mismatch)), nil
+			}
 			log.Printf("[Tool:connect] Error: %v", err)
 			return mcp.NewToolResultError(err.Error()), nil
 		}
 
 		log.Printf("[Tool:connect] Success: %s", resultAlias)
+		pool.PersistFromContext(ctx)
 		return mcp.NewToolResultText(fmt.Sprintf("Connected to %s@%s (alias: %s)", username, host, resultAlias)), nil
 	}
 }
@@ -120,6 +205,7 @@ func createDisconnectHandler(pool *ssh.Pool) server.ToolHandlerFunc {
 			return mcp.NewToolResultError(err.Error()), nil
 		}
 
+		pool.PersistFromContext(ctx)
 		return mcp.NewToolResultText(msg), nil
 	}
 }
@@ -162,6 +248,93 @@ func createRunHandler(pool *ssh.Pool) server.ToolHandlerFunc {
 	}
 }
 
+// createRunStreamHandler relays RunStream's events as MCP notifications
+// while collecting output up to max_output_bytes, then returns the
+// collected (possibly truncated) output as the tool result.
+func createRunStreamHandler(pool *ssh.Pool) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		mgr := getManager(ctx, pool)
+		if mgr == nil {
+			return mcp.NewToolResultError("No active session"), nil
+		}
+
+		command, _ := req.RequireString("command")
+		target := req.GetString("target", "primary")
+		maxBytes := req.GetInt("max_output_bytes", 1<<20)
+
+		log.Printf("[Tool:run_stream] Executing: %s (target=%s)", command, target)
+
+		events, err := mgr.RunStream(ctx, command, target)
+		if err != nil {
+			log.Printf("[Tool:run_stream] Error: %v", err)
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		srv := server.ServerFromContext(ctx)
+		var stdout, stderr strings.Builder
+		total := 0
+		truncated := false
+
+		for ev := range events {
+			switch ev.Type {
+			case ssh.StdoutChunk, ssh.StderrChunk:
+				if truncated {
+					continue
+				}
+
+				data := ev.Data
+				if total+len(data) > maxBytes {
+					data = data[:maxBytes-total]
+					truncated = true
+				}
+				total += len(data)
+
+				stream := "stdout"
+				dst := &stdout
+				if ev.Type == ssh.StderrChunk {
+					stream = "stderr"
+					dst = &stderr
+				}
+				dst.Write(data)
+
+				if srv != nil {
+					if notifyErr := srv.SendNotificationToClient(ctx, "notifications/message", map[string]any{
+						"stream": stream,
+						"data":   string(data),
+					}); notifyErr != nil {
+						log.Printf("[Tool:run_stream] Failed to send notification: %v", notifyErr)
+					}
+				}
+
+			case ssh.Exit:
+				if ev.Err != nil {
+					log.Printf("[Tool:run_stream] Error: %v", ev.Err)
+					return mcp.NewToolResultError(ev.Err.Error()), nil
+				}
+
+				result := stdout.String()
+				if stderr.Len() > 0 {
+					if result != "" {
+						result += "\n"
+					}
+					result += stderr.String()
+				}
+				if truncated {
+					result += fmt.Sprintf("\n... [output truncated at %d bytes]", maxBytes)
+				}
+				if ev.ExitCode != 0 {
+					result += fmt.Sprintf("\n[Exit Code: %d]", ev.ExitCode)
+				}
+
+				log.Printf("[Tool:run_stream] Success (%d bytes output)", total)
+				return mcp.NewToolResultText(result), nil
+			}
+		}
+
+		return mcp.NewToolResultText(stdout.String()), nil
+	}
+}
+
 func createIdentityHandler(pool *ssh.Pool) server.ToolHandlerFunc {
 	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		mgr := getManager(ctx, pool)
@@ -195,10 +368,125 @@ func createInfoHandler(pool *ssh.Pool) server.ToolHandlerFunc {
 			return mcp.NewToolResultError(err.Error()), nil
 		}
 
+		if caps, err := mgr.Capabilities(target); err == nil {
+			output += "\n" + formatCapabilities(caps)
+		}
+
 		return mcp.NewToolResultText(output), nil
 	}
 }
 
+func createWhoamiHandler(pool *ssh.Pool) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		mgr := getManager(ctx, pool)
+		if mgr == nil {
+			return mcp.NewToolResultError("No active session"), nil
+		}
+
+		target := req.GetString("target", "primary")
+
+		caps, err := mgr.Capabilities(target)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		return mcp.NewToolResultText(formatCapabilities(caps)), nil
+	}
+}
+
+func createPoolStatsHandler(pool *ssh.Pool) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		stats := struct {
+			ssh.PoolMetrics
+			ssh.PoolStats
+		}{pool.Metrics(), pool.PoolStats()}
+
+		data, err := json.MarshalIndent(stats, "", "  ")
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		return mcp.NewToolResultText(string(data)), nil
+	}
+}
+
+func createHealthHandler(pool *ssh.Pool) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		mgr := getManager(ctx, pool)
+		if mgr == nil {
+			return mcp.NewToolResultError("No active session"), nil
+		}
+
+		target := req.GetString("target", "primary")
+
+		status, err := mgr.Health(target)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		if status.CheckedAt.IsZero() {
+			return mcp.NewToolResultText("No keepalive probe yet (auto_reconnect was not enabled at connect time)."), nil
+		}
+		if status.Healthy {
+			return mcp.NewToolResultText(fmt.Sprintf("Healthy (latency=%s, checked=%s)", status.Latency, status.CheckedAt.Format(time.RFC3339))), nil
+		}
+		return mcp.NewToolResultText(fmt.Sprintf("Unhealthy (checked=%s)", status.CheckedAt.Format(time.RFC3339))), nil
+	}
+}
+
+// formatCapabilities renders a Capabilities probe as human-readable text.
+func formatCapabilities(caps ssh.Capabilities) string {
+	switch {
+	case caps.UID == "0":
+		return fmt.Sprintf("User: %s (uid=0, root)", caps.User)
+	case caps.CanSudo:
+		return fmt.Sprintf("User: %s (uid=%s, passwordless sudo available)", caps.User, caps.UID)
+	case caps.SudoNeedsPassword:
+		return fmt.Sprintf("User: %s (uid=%s, sudo requires a password)", caps.User, caps.UID)
+	default:
+		return fmt.Sprintf("User: %s (uid=%s, no sudo access)", caps.User, caps.UID)
+	}
+}
+
+func createKnownHostsHandler(pool *ssh.Pool) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		mgr := getManager(ctx, pool)
+		if mgr == nil {
+			return mcp.NewToolResultError("No active session"), nil
+		}
+
+		action, _ := req.RequireString("action")
+
+		switch action {
+		case "list":
+			entries, err := mgr.ListHostKeys()
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			if len(entries) == 0 {
+				return mcp.NewToolResultText("No pinned host keys."), nil
+			}
+
+			result := "Pinned host keys:\n"
+			for _, e := range entries {
+				result += fmt.Sprintf("- %s: %s %s\n", e.Host, e.KeyType, e.Fingerprint)
+			}
+			return mcp.NewToolResultText(result), nil
+
+		case "remove":
+			host, _ := req.RequireString("host")
+			removed, err := mgr.RemoveHostKey(host)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			return mcp.NewToolResultText(fmt.Sprintf("Removed %d known_hosts entries for %s", removed, host)), nil
+
+		default:
+			return mcp.NewToolResultError(fmt.Sprintf("unknown action %q (expected 'list' or 'remove')", action)), nil
+		}
+	}
+}
+
 // getManager retrieves the SSH manager for the current session.
 // Strategy:
 // 1. Global mode: Single shared manager (-global flag)
@@ -223,4 +511,3 @@ func getManager(ctx context.Context, pool *ssh.Pool) *ssh.Manager {
 
 	return pool.Get(session.SessionID())
 }
-
@@ -3,6 +3,8 @@ package tools
 import (
 	"context"
 	"fmt"
+	"strconv"
+	"strings"
 
 	"ssh-mcp/internal/ssh"
 
@@ -10,19 +12,30 @@ import (
 	"github.com/mark3labs/mcp-go/server"
 )
 
+// runtimeDesc is the shared description for the "runtime" parameter accepted
+// by db_query and db_schema.
+const runtimeDesc = "Where the client binary runs: docker, podman, nerdctl, kubectl, or local (default: docker)"
+
 // registerDBTools registers database query tools.
 func registerDBTools(s *server.MCPServer, pool *ssh.Pool) {
 	// db_query
 	s.AddTool(
 		mcp.NewTool("db_query",
-			mcp.WithDescription("Execute SQL/CQL/MongoDB query inside a database container"),
-			mcp.WithString("container", mcp.Required(), mcp.Description("Docker container name running the database")),
-			mcp.WithString("db_type", mcp.Required(), mcp.Description("Database type: postgres, mysql, scylladb, cassandra, mongodb")),
+			mcp.WithDescription("Execute SQL/CQL/MongoDB/Redis/Elasticsearch query inside a database container"),
+			mcp.WithString("container", mcp.Description("Container/pod name running the database (required unless runtime is local)")),
+			mcp.WithString("db_type", mcp.Required(), mcp.Description("Database type: postgres, mysql, scylladb, cassandra, mongodb, redis, elasticsearch, clickhouse, cockroachdb")),
 			mcp.WithString("query", mcp.Required(), mcp.Description("Query to execute")),
 			mcp.WithString("database", mcp.Description("Database/keyspace name")),
 			mcp.WithString("username", mcp.Description("Database username")),
 			mcp.WithString("password", mcp.Description("Database password")),
 			mcp.WithNumber("timeout", mcp.Description("Query timeout in seconds (default: 60)")),
+			mcp.WithBoolean("read_only", mcp.Description("Reject non-SELECT/SHOW/EXPLAIN queries and run them in a rolled-back read-only transaction where supported (default: true)")),
+			mcp.WithArray("params", mcp.Description("Values to bind into the query as :p1, :p2 (postgres), @p1, @p2 (mysql), or p1, p2 (mongodb) instead of string-concatenating them")),
+			mcp.WithNumber("row_limit", mcp.Description("Cap SELECT results by appending LIMIT if the query doesn't already have one (default: 0, no cap)")),
+			mcp.WithString("runtime", mcp.Description(runtimeDesc)),
+			mcp.WithString("namespace", mcp.Description("Kubernetes namespace (runtime=kubectl)")),
+			mcp.WithString("pod", mcp.Description("Kubernetes pod name (runtime=kubectl; alternative to container)")),
+			mcp.WithString("pod_container", mcp.Description("Container within the pod to exec into (runtime=kubectl, optional)")),
 			mcp.WithString("target", mcp.Description("Connection alias (default: primary)")),
 		),
 		createDBQueryHandler(pool),
@@ -31,12 +44,16 @@ func registerDBTools(s *server.MCPServer, pool *ssh.Pool) {
 	// db_schema
 	s.AddTool(
 		mcp.NewTool("db_schema",
-			mcp.WithDescription("Get database schema (tables/collections list)"),
-			mcp.WithString("container", mcp.Required(), mcp.Description("Docker container name")),
-			mcp.WithString("db_type", mcp.Required(), mcp.Description("Database type: postgres, mysql, scylladb, cassandra, mongodb")),
+			mcp.WithDescription("Get database schema (tables/collections/indices/keyspace list)"),
+			mcp.WithString("container", mcp.Description("Container/pod name (required unless runtime is local)")),
+			mcp.WithString("db_type", mcp.Required(), mcp.Description("Database type: postgres, mysql, scylladb, cassandra, mongodb, redis, elasticsearch, clickhouse, cockroachdb")),
 			mcp.WithString("database", mcp.Description("Database/keyspace name")),
 			mcp.WithString("username", mcp.Description("Database username")),
 			mcp.WithString("password", mcp.Description("Database password")),
+			mcp.WithString("runtime", mcp.Description(runtimeDesc)),
+			mcp.WithString("namespace", mcp.Description("Kubernetes namespace (runtime=kubectl)")),
+			mcp.WithString("pod", mcp.Description("Kubernetes pod name (runtime=kubectl; alternative to container)")),
+			mcp.WithString("pod_container", mcp.Description("Container within the pod to exec into (runtime=kubectl, optional)")),
 			mcp.WithString("target", mcp.Description("Connection alias (default: primary)")),
 		),
 		createDBSchemaHandler(pool),
@@ -52,6 +69,282 @@ func registerDBTools(s *server.MCPServer, pool *ssh.Pool) {
 	)
 }
 
+// execRuntime identifies where db_query/db_schema's client binary runs -
+// inside a docker/podman/nerdctl container, inside a Kubernetes pod, or
+// directly on the SSH host - so the command builder for each db_type stays
+// the same regardless of deployment shape.
+type execRuntime struct {
+	kind         string // "docker", "podman", "nerdctl", "kubectl", or "local"
+	container    string
+	namespace    string
+	pod          string
+	podContainer string
+}
+
+// parseExecRuntime reads and validates the runtime/container/namespace/pod
+// parameters shared by db_query and db_schema.
+func parseExecRuntime(req mcp.CallToolRequest) (execRuntime, error) {
+	r := execRuntime{
+		kind:         req.GetString("runtime", "docker"),
+		container:    req.GetString("container", ""),
+		namespace:    req.GetString("namespace", ""),
+		pod:          req.GetString("pod", ""),
+		podContainer: req.GetString("pod_container", ""),
+	}
+
+	switch r.kind {
+	case "docker", "podman", "nerdctl":
+		if r.container == "" {
+			return execRuntime{}, fmt.Errorf("container is required for runtime %q", r.kind)
+		}
+	case "kubectl":
+		if r.pod == "" {
+			return execRuntime{}, fmt.Errorf("pod is required for runtime kubectl")
+		}
+	case "local":
+		// Runs directly on the SSH host; no container/pod needed.
+	default:
+		return execRuntime{}, fmt.Errorf("unknown runtime %q: must be docker, podman, nerdctl, kubectl, or local", r.kind)
+	}
+
+	return r, nil
+}
+
+// checkAvailable verifies the CLI this runtime needs (docker/podman/nerdctl/
+// kubectl) is on the target. "local" has no wrapper binary to check - the
+// client command itself will fail if missing.
+func (r execRuntime) checkAvailable(ctx context.Context, mgr *ssh.Manager, target string) error {
+	var binary string
+	switch r.kind {
+	case "docker", "podman", "nerdctl":
+		binary = r.kind
+	case "kubectl":
+		binary = "kubectl"
+	default:
+		return nil
+	}
+	output, err := mgr.Execute(ctx, fmt.Sprintf("command -v %s >/dev/null 2>&1 && echo 'ok' || echo 'missing'", binary), target)
+	if err != nil {
+		return err
+	}
+	if !containsString(output, "ok") {
+		return fmt.Errorf("%s command not found on target", binary)
+	}
+	return nil
+}
+
+// dbEnvVar is one "KEY=value" environment assignment to apply to the client
+// invocation, e.g. PGPASSWORD for psql or REDISCLI_AUTH for redis-cli.
+type dbEnvVar struct {
+	key   string
+	value string
+}
+
+// command builds the full remote shell command for running binaryAndArgs
+// (e.g. "psql -U user -d db -c 'select 1'") through this runtime, applying
+// env as environment assignments and wrapping in "timeout timeoutSec" when
+// positive, via whichever convention the runtime's exec supports.
+func (r execRuntime) command(env []dbEnvVar, timeoutSec int, binaryAndArgs string) string {
+	var parts []string
+
+	switch r.kind {
+	case "docker", "podman", "nerdctl":
+		parts = append(parts, r.kind, "exec")
+		for _, kv := range env {
+			parts = append(parts, "-e", kv.key+"="+shellQuote(kv.value))
+		}
+		parts = append(parts, shellQuote(r.container))
+	case "kubectl":
+		parts = append(parts, "kubectl", "exec")
+		if r.namespace != "" {
+			parts = append(parts, "-n", shellQuote(r.namespace))
+		}
+		parts = append(parts, shellQuote(r.pod))
+		if r.podContainer != "" {
+			parts = append(parts, "-c", shellQuote(r.podContainer))
+		}
+		parts = append(parts, "--")
+		for _, kv := range env {
+			parts = append(parts, "env", kv.key+"="+shellQuote(kv.value))
+		}
+	case "local":
+		for _, kv := range env {
+			parts = append(parts, "env", kv.key+"="+shellQuote(kv.value))
+		}
+	}
+
+	if timeoutSec > 0 {
+		parts = append(parts, "timeout", strconv.Itoa(timeoutSec))
+	}
+	parts = append(parts, binaryAndArgs)
+
+	return strings.Join(parts, " ")
+}
+
+// buildDBCommand builds the client invocation for dbType/query against the
+// given runtime, shared by db_query (timeout > 0, params may be non-empty)
+// and db_schema (timeout 0 meaning no "timeout" wrapper, params always nil).
+// Returns an error for unsupported db types or, for db_types with no bind
+// mechanism, a non-empty params.
+func buildDBCommand(runtime execRuntime, dbType, query, database, username, password string, timeoutSec int, params []interface{}) (string, error) {
+	switch dbType {
+	case "postgres":
+		user := "postgres"
+		if username != "" {
+			user = username
+		}
+		db := database
+		if db == "" {
+			db = "postgres"
+		}
+		varFlags, err := pgParamFlags(params)
+		if err != nil {
+			return "", err
+		}
+		bin := fmt.Sprintf("psql -U %s -d %s", shellQuote(user), shellQuote(db))
+		for _, flag := range varFlags {
+			bin += " " + flag
+		}
+		bin += fmt.Sprintf(" -c %s 2>&1", shellQuote(query))
+		return runtime.command([]dbEnvVar{{"PGPASSWORD", password}}, timeoutSec, bin), nil
+
+	case "mysql":
+		user := "root"
+		if username != "" {
+			user = username
+		}
+		initCmd, err := mysqlParamSetup(params)
+		if err != nil {
+			return "", err
+		}
+		bin := fmt.Sprintf("mysql -u%s", shellQuote(user))
+		if password != "" {
+			bin += fmt.Sprintf(" -p%s", shellQuote(password))
+		}
+		if initCmd != "" {
+			bin += fmt.Sprintf(" --init-command=%s", shellQuote(initCmd))
+		}
+		if database != "" {
+			bin += fmt.Sprintf(" %s", shellQuote(database))
+		}
+		bin += fmt.Sprintf(" -e %s 2>&1", shellQuote(query))
+		return runtime.command(nil, timeoutSec, bin), nil
+
+	case "scylladb", "cassandra":
+		if len(params) > 0 {
+			return "", fmt.Errorf("params not supported for db_type %s", dbType)
+		}
+		bin := "cqlsh"
+		if username != "" {
+			bin += fmt.Sprintf(" -u %s", shellQuote(username))
+		}
+		if password != "" {
+			bin += fmt.Sprintf(" -p %s", shellQuote(password))
+		}
+		bin += fmt.Sprintf(" -e %s 2>&1", shellQuote(query))
+		return runtime.command(nil, timeoutSec, bin), nil
+
+	case "mongodb":
+		db := database
+		if db == "" {
+			db = "admin"
+		}
+		prelude, err := mongoParamPrelude(params)
+		if err != nil {
+			return "", err
+		}
+		bin := fmt.Sprintf("mongosh --quiet %s", shellQuote(db))
+		if username != "" && password != "" {
+			bin += fmt.Sprintf(" -u %s -p %s --authenticationDatabase admin", shellQuote(username), shellQuote(password))
+		}
+		evalScript := query
+		if prelude != "" {
+			evalScript = prelude + " " + query
+		}
+		bin += fmt.Sprintf(" --eval %s 2>&1", shellQuote(evalScript))
+		return runtime.command(nil, timeoutSec, bin), nil
+
+	case "redis":
+		if len(params) > 0 {
+			return "", fmt.Errorf("params not supported for db_type %s", dbType)
+		}
+		var env []dbEnvVar
+		bin := "redis-cli"
+		if password != "" {
+			env = []dbEnvVar{{"REDISCLI_AUTH", password}}
+			bin += " --no-auth-warning"
+		}
+		if database != "" {
+			bin += fmt.Sprintf(" -n %s", shellQuote(database))
+		}
+		// query is the redis-cli command and its arguments (e.g. "GET foo").
+		// Word-split it ourselves and shellQuote each token individually -
+		// splicing query into bin unquoted (as this used to) lets any shell
+		// metacharacter in it (";", "|", "$(...)", backticks) run as an
+		// arbitrary command on the target, not just as extra redis-cli args.
+		args := strings.Fields(query)
+		if len(args) == 0 {
+			return "", fmt.Errorf("empty redis query")
+		}
+		for _, arg := range args {
+			bin += " " + shellQuote(arg)
+		}
+		bin += " 2>&1"
+		return runtime.command(env, timeoutSec, bin), nil
+
+	case "elasticsearch":
+		if len(params) > 0 {
+			return "", fmt.Errorf("params not supported for db_type %s", dbType)
+		}
+		authArg := ""
+		if username != "" {
+			authArg = fmt.Sprintf(" -u %s", shellQuote(username+":"+password))
+		}
+		path := query
+		if !strings.HasPrefix(path, "/") {
+			path = "/" + path
+		}
+		bin := fmt.Sprintf("curl -s%s %s 2>&1", authArg, shellQuote("http://localhost:9200"+path))
+		return runtime.command(nil, timeoutSec, bin), nil
+
+	case "clickhouse":
+		if len(params) > 0 {
+			return "", fmt.Errorf("params not supported for db_type %s", dbType)
+		}
+		bin := "clickhouse-client"
+		if username != "" {
+			bin += fmt.Sprintf(" --user %s", shellQuote(username))
+		}
+		if password != "" {
+			bin += fmt.Sprintf(" --password %s", shellQuote(password))
+		}
+		if database != "" {
+			bin += fmt.Sprintf(" --database %s", shellQuote(database))
+		}
+		bin += fmt.Sprintf(" --query %s 2>&1", shellQuote(query))
+		return runtime.command(nil, timeoutSec, bin), nil
+
+	case "cockroachdb":
+		if len(params) > 0 {
+			return "", fmt.Errorf("params not supported for db_type %s", dbType)
+		}
+		user := "root"
+		if username != "" {
+			user = username
+		}
+		db := database
+		if db == "" {
+			db = "defaultdb"
+		}
+		bin := fmt.Sprintf("cockroach sql --insecure --user=%s --database=%s -e %s 2>&1",
+			shellQuote(user), shellQuote(db), shellQuote(query))
+		return runtime.command(nil, timeoutSec, bin), nil
+
+	default:
+		return "", fmt.Errorf("unsupported database type: %s. Supported: postgres, mysql, scylladb, cassandra, mongodb, redis, elasticsearch, clickhouse, cockroachdb", dbType)
+	}
+}
+
 func createDBQueryHandler(pool *ssh.Pool) server.ToolHandlerFunc {
 	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		mgr := getManager(ctx, pool)
@@ -59,71 +352,38 @@ func createDBQueryHandler(pool *ssh.Pool) server.ToolHandlerFunc {
 			return mcp.NewToolResultError("No active session"), nil
 		}
 
-		container, _ := req.RequireString("container")
 		dbType, _ := req.RequireString("db_type")
 		query, _ := req.RequireString("query")
 		database := req.GetString("database", "")
 		username := req.GetString("username", "")
 		password := req.GetString("password", "")
 		timeout := req.GetInt("timeout", 60)
+		readOnly := req.GetBool("read_only", true)
+		rowLimit := req.GetInt("row_limit", 0)
+		params := getAnySlice(req, "params")
 		target := req.GetString("target", "primary")
 
-		if err := checkDockerAvailable(ctx, mgr, target); err != nil {
+		runtime, err := parseExecRuntime(req)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		if err := runtime.checkAvailable(ctx, mgr, target); err != nil {
 			return mcp.NewToolResultError(err.Error()), nil
 		}
 
-		var cmd string
-		switch dbType {
-		case "postgres":
-			user := "postgres"
-			if username != "" {
-				user = username
-			}
-			db := database
-			if db == "" {
-				db = "postgres"
-			}
-			// Use PGPASSWORD env variable and echo query through stdin
-			cmd = fmt.Sprintf("docker exec -e PGPASSWORD=%s %s timeout %d psql -U %s -d %s -c %s 2>&1",
-				shellQuote(password), shellQuote(container), timeout, shellQuote(user), shellQuote(db), shellQuote(query))
-
-		case "mysql":
-			user := "root"
-			if username != "" {
-				user = username
-			}
-			cmd = fmt.Sprintf("docker exec %s timeout %d mysql -u%s", shellQuote(container), timeout, shellQuote(user))
-			if password != "" {
-				cmd += fmt.Sprintf(" -p%s", shellQuote(password))
-			}
-			if database != "" {
-				cmd += fmt.Sprintf(" %s", shellQuote(database))
-			}
-			cmd += fmt.Sprintf(" -e %s 2>&1", shellQuote(query))
-
-		case "scylladb", "cassandra":
-			cmd = fmt.Sprintf("docker exec %s timeout %d cqlsh", shellQuote(container), timeout)
-			if username != "" {
-				cmd += fmt.Sprintf(" -u %s", shellQuote(username))
-			}
-			if password != "" {
-				cmd += fmt.Sprintf(" -p %s", shellQuote(password))
-			}
-			cmd += fmt.Sprintf(" -e %s 2>&1", shellQuote(query))
-
-		case "mongodb":
-			db := database
-			if db == "" {
-				db = "admin"
-			}
-			cmd = fmt.Sprintf("docker exec %s timeout %d mongosh --quiet %s", shellQuote(container), timeout, shellQuote(db))
-			if username != "" && password != "" {
-				cmd += fmt.Sprintf(" -u %s -p %s --authenticationDatabase admin", shellQuote(username), shellQuote(password))
+		if readOnly {
+			if err := checkReadOnlyQuery(dbType, query); err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
 			}
-			cmd += fmt.Sprintf(" --eval %s 2>&1", shellQuote(query))
+		}
+		query = applyRowLimit(dbType, query, rowLimit)
+		if readOnly {
+			query = wrapReadOnlyTransaction(dbType, query)
+		}
 
-		default:
-			return mcp.NewToolResultError(fmt.Sprintf("Unsupported database type: %s. Supported: postgres, mysql, scylladb, cassandra, mongodb", dbType)), nil
+		cmd, err := buildDBCommand(runtime, dbType, query, database, username, password, timeout, params)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
 		}
 
 		output, err := mgr.Execute(ctx, cmd, target)
@@ -142,14 +402,17 @@ func createDBSchemaHandler(pool *ssh.Pool) server.ToolHandlerFunc {
 			return mcp.NewToolResultError("No active session"), nil
 		}
 
-		container, _ := req.RequireString("container")
 		dbType, _ := req.RequireString("db_type")
 		database := req.GetString("database", "")
 		username := req.GetString("username", "")
 		password := req.GetString("password", "")
 		target := req.GetString("target", "primary")
 
-		if err := checkDockerAvailable(ctx, mgr, target); err != nil {
+		runtime, err := parseExecRuntime(req)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		if err := runtime.checkAvailable(ctx, mgr, target); err != nil {
 			return mcp.NewToolResultError(err.Error()), nil
 		}
 
@@ -167,56 +430,25 @@ func createDBSchemaHandler(pool *ssh.Pool) server.ToolHandlerFunc {
 			}
 		case "mongodb":
 			query = "db.getCollectionNames()"
+		case "redis":
+			query = "INFO keyspace"
+		case "elasticsearch":
+			query = "/_cat/indices?v"
+		case "clickhouse":
+			query = "SHOW TABLES"
+		case "cockroachdb":
+			if database != "" {
+				query = fmt.Sprintf("SHOW TABLES FROM %s;", database)
+			} else {
+				query = "SHOW DATABASES;"
+			}
 		default:
 			return mcp.NewToolResultError(fmt.Sprintf("Unsupported database type: %s", dbType)), nil
 		}
 
-		// Build command directly
-		var cmd string
-		switch dbType {
-		case "postgres":
-			user := "postgres"
-			if username != "" {
-				user = username
-			}
-			db := database
-			if db == "" {
-				db = "postgres"
-			}
-			cmd = fmt.Sprintf("docker exec -e PGPASSWORD=%s %s psql -U %s -d %s -c %s 2>&1",
-				shellQuote(password), shellQuote(container), shellQuote(user), shellQuote(db), shellQuote(query))
-		case "mysql":
-			user := "root"
-			if username != "" {
-				user = username
-			}
-			cmd = fmt.Sprintf("docker exec %s mysql -u%s", shellQuote(container), shellQuote(user))
-			if password != "" {
-				cmd += fmt.Sprintf(" -p%s", shellQuote(password))
-			}
-			if database != "" {
-				cmd += fmt.Sprintf(" %s", shellQuote(database))
-			}
-			cmd += fmt.Sprintf(" -e %s 2>&1", shellQuote(query))
-		case "scylladb", "cassandra":
-			cmd = fmt.Sprintf("docker exec %s cqlsh", shellQuote(container))
-			if username != "" {
-				cmd += fmt.Sprintf(" -u %s", shellQuote(username))
-			}
-			if password != "" {
-				cmd += fmt.Sprintf(" -p %s", shellQuote(password))
-			}
-			cmd += fmt.Sprintf(" -e %s 2>&1", shellQuote(query))
-		case "mongodb":
-			db := database
-			if db == "" {
-				db = "admin"
-			}
-			cmd = fmt.Sprintf("docker exec %s mongosh --quiet %s", shellQuote(container), shellQuote(db))
-			if username != "" && password != "" {
-				cmd += fmt.Sprintf(" -u %s -p %s --authenticationDatabase admin", shellQuote(username), shellQuote(password))
-			}
-			cmd += fmt.Sprintf(" --eval %s 2>&1", shellQuote(query))
+		cmd, err := buildDBCommand(runtime, dbType, query, database, username, password, 0, nil)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
 		}
 
 		output, err := mgr.Execute(ctx, cmd, target)
@@ -251,6 +483,9 @@ func createListDBContainersHandler(pool *ssh.Pool) server.ToolHandlerFunc {
     *cassandra*) echo "$name|$image|cassandra" ;;
     *mongo*) echo "$name|$image|mongodb" ;;
     *redis*) echo "$name|$image|redis" ;;
+    *elasticsearch*|*elastic/elasticsearch*) echo "$name|$image|elasticsearch" ;;
+    *clickhouse*) echo "$name|$image|clickhouse" ;;
+    *cockroach*) echo "$name|$image|cockroachdb" ;;
   esac
 done 2>/dev/null`
 
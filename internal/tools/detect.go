@@ -0,0 +1,158 @@
+// Package tools: detect.go adds content-based file type detection for
+// validate, so a remote path with no extension (or a misleading one)
+// can still be checked. DetectFileType is tried by ValidateContent
+// whenever the caller passes an empty or "auto" file type; the plain
+// extension/basename table in files.go remains the fast path when a
+// type is already known or the extension is unambiguous.
+package tools
+
+import (
+	"bufio"
+	"regexp"
+	"strings"
+)
+
+// tomlTypedValueRe matches an assignment to a TOML-only literal (a bare
+// boolean or a float), used to break a TOML/INI tie: plain INI has no
+// typed values, so a file with one is more likely TOML that happens to
+// also satisfy INI's loose key=value grammar.
+var tomlTypedValueRe = regexp.MustCompile(`=\s*(true|false|\d+\.\d+)\b`)
+
+// DetectFileType guesses a file's type from its path and content when the
+// caller doesn't already know it (fileType == "" or "auto" in
+// ValidateContent). Detection order: shebang/directive sniffing of the
+// content, then the existing basename/extension table. When content
+// sniffing and the basename table disagree, or an INI candidate's content
+// holds a typed value (which adds TOML as a candidate), every candidate is
+// actually run through its validator and the ones
+// that parse cleanly win; a remaining TOML/INI tie resolves to TOML if the
+// content contains a typed value plain INI can't express. Returns "" if
+// nothing plausible is found.
+func DetectFileType(path, content string) string {
+	if isShebangScript(content) {
+		return ""
+	}
+
+	var candidates []string
+	add := func(ft string) {
+		if ft == "" {
+			return
+		}
+		for _, c := range candidates {
+			if c == ft {
+				return
+			}
+		}
+		candidates = append(candidates, ft)
+	}
+
+	add(sniffContentFileType(content))
+	add(detectFileType(path))
+	// INI-looking content holding a typed value may equally be TOML, so
+	// TOML joins the candidates and the validators decide.
+	for _, c := range candidates {
+		if c == "ini" && tomlTypedValueRe.MatchString(content) {
+			add("toml")
+			break
+		}
+	}
+
+	switch len(candidates) {
+	case 0:
+		return ""
+	case 1:
+		return candidates[0]
+	}
+
+	var clean []string
+	for _, c := range candidates {
+		if r := ValidateContent(path, content, c); r != nil && r.Valid {
+			clean = append(clean, c)
+		}
+	}
+	switch len(clean) {
+	case 0:
+		return candidates[0]
+	case 1:
+		return clean[0]
+	default:
+		return resolveFileTypeTie(clean, content)
+	}
+}
+
+// isShebangScript reports whether content opens with a "#!" interpreter
+// line, i.e. is a script rather than one of the config formats this
+// package validates.
+func isShebangScript(content string) bool {
+	return strings.HasPrefix(strings.TrimLeft(content, " \t\r\n"), "#!")
+}
+
+// sniffContentFileType inspects the document itself for a type-identifying
+// marker: a Dockerfile directive/instruction, an XML prolog, a YAML
+// document marker, a leading JSON brace, or a top-level "[section]"
+// header (systemd if the name is a known unit-file section, INI
+// otherwise). Returns "" if nothing matches.
+func sniffContentFileType(content string) string {
+	trimmed := strings.TrimLeft(content, " \t\r\n")
+
+	firstLine := trimmed
+	if idx := strings.IndexByte(trimmed, '\n'); idx >= 0 {
+		firstLine = trimmed[:idx]
+	}
+	firstLine = strings.TrimRight(firstLine, "\r")
+
+	switch {
+	case strings.HasPrefix(firstLine, "# syntax=docker/"):
+		return "dockerfile"
+	case strings.HasPrefix(strings.ToUpper(firstLine), "FROM "):
+		return "dockerfile"
+	case strings.HasPrefix(trimmed, "<?xml"):
+		return "xml"
+	case strings.HasPrefix(trimmed, "---"):
+		return "yaml"
+	case strings.HasPrefix(trimmed, "{"), strings.HasPrefix(trimmed, "["):
+		return "json"
+	}
+
+	if name, ok := firstSectionHeader(content); ok {
+		if systemdKnownSections[name] {
+			return "systemd"
+		}
+		return "ini"
+	}
+
+	return ""
+}
+
+// firstSectionHeader returns the name inside the first non-blank,
+// non-comment "[Name]" line at the very start of the file — i.e. a line
+// that looks like an INI/systemd section header appears before anything
+// else of substance.
+func firstSectionHeader(content string) (string, bool) {
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") && len(line) > 2 {
+			return line[1 : len(line)-1], true
+		}
+		return "", false
+	}
+	return "", false
+}
+
+// resolveFileTypeTie picks one type among several that all parse the
+// content cleanly. Candidate order already favors content sniffing over
+// the basename table, so that order is the default tie-break; the one
+// case worth overriding is TOML vs INI, since plain INI can't express a
+// typed boolean/float value.
+func resolveFileTypeTie(candidates []string, content string) string {
+	for _, c := range candidates {
+		if c == "toml" && tomlTypedValueRe.MatchString(content) {
+			return "toml"
+		}
+	}
+	return candidates[0]
+}
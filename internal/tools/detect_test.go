@@ -0,0 +1,83 @@
+package tools
+
+import "testing"
+
+func TestDetectFileTypeShebangIsNotAConfigType(t *testing.T) {
+	if got := DetectFileType("deploy", "#!/bin/bash\necho hi\n"); got != "" {
+		t.Fatalf("got %q, want empty for a shell script", got)
+	}
+}
+
+func TestDetectFileTypeDockerfileDirective(t *testing.T) {
+	if got := DetectFileType("build-ci", "# syntax=docker/dockerfile:1\nFROM alpine\n"); got != "dockerfile" {
+		t.Fatalf("got %q, want dockerfile", got)
+	}
+}
+
+func TestDetectFileTypeFromInstructionNoExtension(t *testing.T) {
+	if got := DetectFileType("ci-image", "FROM alpine:3.19\nRUN echo hi\n"); got != "dockerfile" {
+		t.Fatalf("got %q, want dockerfile", got)
+	}
+}
+
+func TestDetectFileTypeXMLProlog(t *testing.T) {
+	if got := DetectFileType("data", "<?xml version=\"1.0\"?>\n<root/>\n"); got != "xml" {
+		t.Fatalf("got %q, want xml", got)
+	}
+}
+
+func TestDetectFileTypeYAMLDocMarker(t *testing.T) {
+	if got := DetectFileType("data", "---\nfoo: bar\n"); got != "yaml" {
+		t.Fatalf("got %q, want yaml", got)
+	}
+}
+
+func TestDetectFileTypeLeadingBrace(t *testing.T) {
+	if got := DetectFileType("data", `{"a": 1}`); got != "json" {
+		t.Fatalf("got %q, want json", got)
+	}
+}
+
+func TestDetectFileTypeSystemdSection(t *testing.T) {
+	if got := DetectFileType("myapp", "[Service]\nExecStart=/usr/bin/myapp\nType=simple\n"); got != "systemd" {
+		t.Fatalf("got %q, want systemd", got)
+	}
+}
+
+func TestDetectFileTypeGenericSectionIsINI(t *testing.T) {
+	if got := DetectFileType("myapp.conf", "[general]\nkey = value\n"); got != "ini" {
+		t.Fatalf("got %q, want ini", got)
+	}
+}
+
+func TestDetectFileTypeExtensionFallback(t *testing.T) {
+	if got := DetectFileType("data.yaml", "foo: bar\n"); got != "yaml" {
+		t.Fatalf("got %q, want yaml", got)
+	}
+}
+
+func TestDetectFileTypeTOMLvsINITieBreak(t *testing.T) {
+	got := DetectFileType("settings.conf", "debug = true\ntimeout = 1.5\n")
+	if got != "toml" {
+		t.Fatalf("got %q, want toml (typed values should break the INI/TOML tie)", got)
+	}
+}
+
+func TestDetectFileTypeUnknown(t *testing.T) {
+	if got := DetectFileType("mystery", "just some plain text\nwith no structure\n"); got != "" {
+		t.Fatalf("got %q, want empty", got)
+	}
+}
+
+func TestValidateContentAutoDetectsDockerfile(t *testing.T) {
+	r := ValidateContent("ci-image", "FROM alpine:3.19\nRUN echo hi\n", "auto")
+	if r == nil {
+		t.Fatalf("expected a result, got nil")
+	}
+	if r.FileType != "dockerfile" {
+		t.Fatalf("got file type %q, want dockerfile", r.FileType)
+	}
+	if !r.Valid {
+		t.Fatalf("expected valid, got errors: %v", r.Errors)
+	}
+}
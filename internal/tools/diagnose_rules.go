@@ -0,0 +1,125 @@
+package tools
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// diagnoseRulesProductionPath/diagnoseRulesDevPath mirror ssh.ProductionKeyPath/
+// ssh.DevKeyPath's production-vs-local split, so per-target alert thresholds
+// live alongside the system SSH key rather than in a separate config root.
+const (
+	diagnoseRulesProductionPath = "/data/diagnose_rules.json"
+	diagnoseRulesDevPath        = "./data/diagnose_rules.json"
+)
+
+// diagnoseSeverity is how badly a breached threshold should be reported.
+type diagnoseSeverity string
+
+const (
+	severityWarn diagnoseSeverity = "warn"
+	severityCrit diagnoseSeverity = "crit"
+)
+
+// diagnoseThresholds is one target's configured "unhealthy" definition for
+// diagnose_system. Zero-value fields fall back to defaultDiagnoseThresholds.
+type diagnoseThresholds struct {
+	LoadRatio       float64          `json:"load_ratio,omitempty"`       // load1 / nproc above this is unhealthy
+	MemPct          float64          `json:"mem_pct,omitempty"`          // % memory used above this is unhealthy
+	DiskPct         float64          `json:"disk_pct,omitempty"`         // % disk used above this is unhealthy
+	OOMWindow       int              `json:"oom_window,omitempty"`       // OOM events above this count is unhealthy
+	FailedAllowlist []string         `json:"failed_allowlist,omitempty"` // failed services to ignore
+	Severity        diagnoseSeverity `json:"severity,omitempty"`         // severity reported for any breach (default: warn)
+}
+
+// defaultDiagnoseThresholds is used for any target without a stored rule set,
+// matching the hardcoded thresholds createDiagnoseHandler used before
+// diagnose_configure existed.
+var defaultDiagnoseThresholds = diagnoseThresholds{
+	LoadRatio: 2.0,
+	MemPct:    90,
+	DiskPct:   90,
+	OOMWindow: 0,
+	Severity:  severityWarn,
+}
+
+// withDefaults fills any zero-value field with defaultDiagnoseThresholds,
+// since a partially-configured rule set (e.g. only disk_pct set) should
+// still apply the default for every other check.
+func (t diagnoseThresholds) withDefaults() diagnoseThresholds {
+	if t.LoadRatio == 0 {
+		t.LoadRatio = defaultDiagnoseThresholds.LoadRatio
+	}
+	if t.MemPct == 0 {
+		t.MemPct = defaultDiagnoseThresholds.MemPct
+	}
+	if t.DiskPct == 0 {
+		t.DiskPct = defaultDiagnoseThresholds.DiskPct
+	}
+	if t.Severity == "" {
+		t.Severity = defaultDiagnoseThresholds.Severity
+	}
+	return t
+}
+
+// diagnoseRuleStore persists per-target diagnoseThresholds as a single JSON
+// file, read fully into memory and rewritten on every change - matching the
+// size and access pattern of the SSH alias/host-key state this sits beside.
+type diagnoseRuleStore struct {
+	mu    sync.Mutex
+	path  string
+	rules map[string]diagnoseThresholds
+}
+
+// newDiagnoseRuleStore loads (or initializes) the rule store from disk.
+func newDiagnoseRuleStore() *diagnoseRuleStore {
+	path := diagnoseRulesDevPath
+	if stat, err := os.Stat("/data"); err == nil && stat.IsDir() {
+		path = diagnoseRulesProductionPath
+	}
+
+	s := &diagnoseRuleStore{path: path, rules: map[string]diagnoseThresholds{}}
+	if data, err := os.ReadFile(path); err == nil {
+		_ = json.Unmarshal(data, &s.rules)
+	}
+	return s
+}
+
+// get returns target's configured thresholds merged over the defaults, or
+// the defaults alone if target has no stored rule set.
+func (s *diagnoseRuleStore) get(target string) diagnoseThresholds {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if t, ok := s.rules[target]; ok {
+		return t.withDefaults()
+	}
+	return defaultDiagnoseThresholds
+}
+
+// set stores target's thresholds and persists the whole rule set to disk.
+func (s *diagnoseRuleStore) set(target string, t diagnoseThresholds) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.rules[target] = t
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	data, err := json.MarshalIndent(s.rules, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal diagnose rules: %w", err)
+	}
+	if err := os.WriteFile(s.path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write diagnose rules: %w", err)
+	}
+	return nil
+}
+
+// defaultDiagnoseRuleStore is shared by diagnose_configure and
+// diagnose_system across requests, since rules are per-target configuration
+// rather than per-connection state.
+var defaultDiagnoseRuleStore = newDiagnoseRuleStore()
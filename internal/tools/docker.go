@@ -1,104 +1,368 @@
 package tools
 
 import (
+	"bytes"
 	"context"
+	"encoding/base64"
+	"encoding/json"
 	"fmt"
 	"log"
+	"net"
+	"regexp"
+	"strings"
+	"time"
 
+	"ssh-mcp/internal/container"
 	"ssh-mcp/internal/ssh"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
 )
 
-// registerDockerTools registers Docker-related tools.
+const runtimeParamDescription = "Container runtime to use: 'docker', 'containerd', or 'podman' (default: auto-detect)"
+
+// registerDockerTools registers docker_* tools, which dispatch through a
+// ContainerRuntime resolved per call (Docker, containerd, or Podman) rather
+// than assuming Docker. registerContainerAliasTools below exposes the same
+// handlers under engine-neutral container_* names.
 func registerDockerTools(s *server.MCPServer, pool *ssh.Pool) {
-	// docker_ps
+	registerContainerTools(s, pool, "docker")
+}
+
+// registerContainerAliasTools registers container_* aliases over the same
+// handlers as registerDockerTools, for callers that'd rather not imply
+// Docker specifically.
+func registerContainerAliasTools(s *server.MCPServer, pool *ssh.Pool) {
+	registerContainerTools(s, pool, "container")
+}
+
+func registerContainerTools(s *server.MCPServer, pool *ssh.Pool, prefix string) {
+	// {prefix}_ps
 	s.AddTool(
-		mcp.NewTool("docker_ps",
-			mcp.WithDescription("List Docker containers"),
+		mcp.NewTool(prefix+"_ps",
+			mcp.WithDescription("List containers"),
 			mcp.WithBoolean("all", mcp.Description("Show all containers (default: only running)")),
+			mcp.WithString("runtime", mcp.Description(runtimeParamDescription)),
 			mcp.WithString("target", mcp.Description("Connection alias (default: primary)")),
 		),
 		createDockerPsHandler(pool),
 	)
 
-	// docker_logs
+	// {prefix}_logs
 	s.AddTool(
-		mcp.NewTool("docker_logs",
-			mcp.WithDescription("Get logs from a Docker container"),
+		mcp.NewTool(prefix+"_logs",
+			mcp.WithDescription("Get logs from a container"),
 			mcp.WithString("container", mcp.Required(), mcp.Description("Container name or ID")),
 			mcp.WithNumber("lines", mcp.Description("Number of lines (default: 50)")),
+			mcp.WithString("runtime", mcp.Description(runtimeParamDescription)),
 			mcp.WithString("target", mcp.Description("Connection alias (default: primary)")),
 		),
 		createDockerLogsHandler(pool),
 	)
 
-	// docker_op
+	// {prefix}_logs_follow
+	s.AddTool(
+		mcp.NewTool(prefix+"_logs_follow",
+			mcp.WithDescription(`Stream a container's logs as they're written (like "docker logs -f"), relaying chunks via MCP notifications instead of waiting for the container to stop. Stops after max_duration_sec, idle_timeout_sec of silence, or max_output_bytes, whichever comes first.
+
+Each line is sent as a "notifications/message" notification with {"container", "line"}; the tool result contains the full (possibly filtered/truncated) output collected once streaming ends.`),
+			mcp.WithString("container", mcp.Required(), mcp.Description("Container name or ID")),
+			mcp.WithString("since", mcp.Description("Only show logs since this time (RFC3339 timestamp or duration like \"10m\")")),
+			mcp.WithBoolean("timestamps", mcp.Description("Prefix each line with its timestamp")),
+			mcp.WithString("grep", mcp.Description("Only relay/collect lines matching this regex, to avoid burning tokens on noise")),
+			mcp.WithNumber("max_output_bytes", mcp.Description("Stop collecting/notifying after this many bytes (default: 1048576)")),
+			mcp.WithNumber("max_duration_sec", mcp.Description("Stop streaming after this many seconds (default: 300)")),
+			mcp.WithNumber("idle_timeout_sec", mcp.Description("Stop streaming after this many seconds with no new output (default: 30)")),
+			mcp.WithString("runtime", mcp.Description(runtimeParamDescription)),
+			mcp.WithString("target", mcp.Description("Connection alias (default: primary)")),
+		),
+		createDockerLogsFollowHandler(pool),
+	)
+
+	// {prefix}_exec
 	s.AddTool(
-		mcp.NewTool("docker_op",
-			mcp.WithDescription("Start, stop, or restart a Docker container"),
+		mcp.NewTool(prefix+"_exec",
+			mcp.WithDescription(`Run a command inside a container, streaming stdout/stderr via MCP notifications as it runs. The command is rejected if it contains shell metacharacters (;&|<>$` + "`" + `(){} or backslash/newline) unless shell=true is set explicitly, since those are only meaningful once you've opted into a shell.`),
+			mcp.WithString("container", mcp.Required(), mcp.Description("Container name or ID")),
+			mcp.WithString("command", mcp.Required(), mcp.Description("Command (and arguments) to run inside the container")),
+			mcp.WithBoolean("shell", mcp.Description("Run command through \"sh -c\", allowing shell metacharacters (default: false)")),
+			mcp.WithBoolean("tty", mcp.Description("Allocate a pseudo-TTY for the exec session (default: false)")),
+			mcp.WithString("stdin", mcp.Description("Data to pipe to the command's stdin (implies -i)")),
+			mcp.WithNumber("max_output_bytes", mcp.Description("Stop collecting/notifying after this many bytes (default: 1048576)")),
+			mcp.WithNumber("max_duration_sec", mcp.Description("Stop streaming after this many seconds (default: 120)")),
+			mcp.WithNumber("idle_timeout_sec", mcp.Description("Stop streaming after this many seconds with no new output (default: 30)")),
+			mcp.WithString("runtime", mcp.Description(runtimeParamDescription)),
+			mcp.WithString("target", mcp.Description("Connection alias (default: primary)")),
+		),
+		createDockerExecHandler(pool),
+	)
+
+	// {prefix}_op
+	s.AddTool(
+		mcp.NewTool(prefix+"_op",
+			mcp.WithDescription("Start, stop, or restart a container"),
 			mcp.WithString("container", mcp.Required(), mcp.Description("Container name or ID")),
 			mcp.WithString("action", mcp.Required(), mcp.Description("Action: start, stop, restart")),
+			mcp.WithString("runtime", mcp.Description(runtimeParamDescription)),
 			mcp.WithString("target", mcp.Description("Connection alias (default: primary)")),
 		),
 		createDockerOpHandler(pool),
 	)
 
-	// docker_ip
+	// {prefix}_ip
 	s.AddTool(
-		mcp.NewTool("docker_ip",
-			mcp.WithDescription("Get IP address(es) of a Docker container"),
+		mcp.NewTool(prefix+"_ip",
+			mcp.WithDescription("Get IP address(es) of a container"),
 			mcp.WithString("container", mcp.Required(), mcp.Description("Container name")),
+			mcp.WithString("runtime", mcp.Description(runtimeParamDescription)),
 			mcp.WithString("target", mcp.Description("Connection alias (default: primary)")),
 		),
 		createDockerIPHandler(pool),
 	)
 
-	// docker_find_by_ip
+	// {prefix}_find_by_ip
 	s.AddTool(
-		mcp.NewTool("docker_find_by_ip",
-			mcp.WithDescription("Find which Docker container has a specific IP"),
+		mcp.NewTool(prefix+"_find_by_ip",
+			mcp.WithDescription("Find which container has a specific IP"),
 			mcp.WithString("ip", mcp.Required(), mcp.Description("IP address to search")),
+			mcp.WithString("runtime", mcp.Description(runtimeParamDescription)),
 			mcp.WithString("target", mcp.Description("Connection alias (default: primary)")),
 		),
 		createDockerFindByIPHandler(pool),
 	)
 
-	// docker_networks
+	// {prefix}_networks
 	s.AddTool(
-		mcp.NewTool("docker_networks",
-			mcp.WithDescription("List all Docker networks and their containers"),
+		mcp.NewTool(prefix+"_networks",
+			mcp.WithDescription("List all container networks and their containers"),
+			mcp.WithString("runtime", mcp.Description(runtimeParamDescription)),
 			mcp.WithString("target", mcp.Description("Connection alias (default: primary)")),
 		),
 		createDockerNetworksHandler(pool),
 	)
 
-	// docker_cp_from
+	// {prefix}_cp_from
 	s.AddTool(
-		mcp.NewTool("docker_cp_from",
-			mcp.WithDescription("Copy file from Docker container to host"),
+		mcp.NewTool(prefix+"_cp_from",
+			mcp.WithDescription("Copy file from a container to host"),
 			mcp.WithString("container", mcp.Required(), mcp.Description("Container name")),
 			mcp.WithString("container_path", mcp.Required(), mcp.Description("Path inside container")),
 			mcp.WithString("host_path", mcp.Required(), mcp.Description("Destination path on host")),
+			mcp.WithString("runtime", mcp.Description(runtimeParamDescription)),
 			mcp.WithString("target", mcp.Description("Connection alias (default: primary)")),
 		),
 		createDockerCpFromHandler(pool),
 	)
 
-	// docker_cp_to
+	// {prefix}_cp_to
 	s.AddTool(
-		mcp.NewTool("docker_cp_to",
-			mcp.WithDescription("Copy file from host to Docker container"),
+		mcp.NewTool(prefix+"_cp_to",
+			mcp.WithDescription("Copy file from host to a container"),
 			mcp.WithString("host_path", mcp.Required(), mcp.Description("Source path on host")),
 			mcp.WithString("container", mcp.Required(), mcp.Description("Container name")),
 			mcp.WithString("container_path", mcp.Required(), mcp.Description("Destination path inside container")),
+			mcp.WithString("runtime", mcp.Description(runtimeParamDescription)),
 			mcp.WithString("target", mcp.Description("Connection alias (default: primary)")),
 		),
 		createDockerCpToHandler(pool),
 	)
 }
 
+// managerHost adapts an ssh.Manager bound to one target to container.Host,
+// so internal/container can run commands and dial sockets on the remote
+// side without importing internal/ssh.
+type managerHost struct {
+	mgr    *ssh.Manager
+	target string
+}
+
+func (h managerHost) Execute(ctx context.Context, cmd string) (string, error) {
+	return h.mgr.Execute(ctx, cmd, h.target)
+}
+
+func (h managerHost) DialRemote(network, addr string) (net.Conn, error) {
+	client, err := h.mgr.Client(h.target)
+	if err != nil {
+		return nil, err
+	}
+	return client.DialRemote(network, addr)
+}
+
+// containerRuntimeFor returns the container runtime backend for target,
+// reusing the one cached on mgr if it matches requested (or requested is
+// empty, meaning any cached backend will do), and resolving/caching a new
+// one otherwise.
+func containerRuntimeFor(ctx context.Context, mgr *ssh.Manager, target string, requested container.Runtime) (container.ContainerRuntime, error) {
+	if cached, ok := mgr.ContainerRuntime(target); ok {
+		if rt, ok := cached.(container.ContainerRuntime); ok && (requested == "" || rt.Name() == requested) {
+			return rt, nil
+		}
+	}
+
+	rt, err := container.Resolve(ctx, managerHost{mgr: mgr, target: target}, requested)
+	if err != nil {
+		return nil, err
+	}
+	if err := mgr.SetContainerRuntime(target, rt); err != nil {
+		rt.Close()
+		return nil, err
+	}
+	return rt, nil
+}
+
+// containerCLI maps a resolved/requested runtime to the CLI binary (and, for
+// containerd, the nerdctl namespace flag) that docker_logs_follow and
+// docker_exec shell out to. Unlike the other docker_* handlers, these two
+// stream over ssh.Manager's RunStream rather than the Docker Engine API, so
+// they need a command line rather than a container.ContainerRuntime.
+func containerCLI(runtime container.Runtime) (string, error) {
+	switch runtime {
+	case "", container.RuntimeDocker:
+		return "docker", nil
+	case container.RuntimePodman:
+		return "podman", nil
+	case container.RuntimeContainerd:
+		return "nerdctl -n " + defaultContainerdNamespaceForCLI, nil
+	default:
+		return "", fmt.Errorf("unknown runtime %q: use docker, podman, or containerd", runtime)
+	}
+}
+
+// defaultContainerdNamespaceForCLI mirrors container.defaultContainerdNamespace;
+// duplicated here (rather than exported from internal/container) since it's
+// only needed to shell out to nerdctl directly.
+const defaultContainerdNamespaceForCLI = "k8s.io"
+
+// resolvedCLI picks the CLI for requested if given, otherwise resolves the
+// cached/auto-detected runtime for target so the right binary name is used
+// without forcing the caller to say which engine is in play.
+func resolvedCLI(ctx context.Context, mgr *ssh.Manager, target string, requested container.Runtime) (string, error) {
+	if requested != "" {
+		return containerCLI(requested)
+	}
+	if rt, err := containerRuntimeFor(ctx, mgr, target, ""); err == nil {
+		return containerCLI(rt.Name())
+	}
+	return containerCLI("")
+}
+
+// shellMetaChars matches characters that change meaning when a command line
+// is handed to a shell (pipes, redirects, substitution, grouping); docker_exec
+// rejects commands containing any of these unless shell=true is set.
+var shellMetaChars = regexp.MustCompile("[;&|<>$`(){}\\\\\n]")
+
+// lineFilterer buffers streamed bytes into lines and, if re is set, drops
+// lines that don't match it before they reach the caller - used by
+// docker_logs_follow's grep parameter so noisy logs don't burn tokens.
+type lineFilterer struct {
+	buf []byte
+	re  *regexp.Regexp
+}
+
+// feed appends data and returns any complete, filter-passing lines now
+// available; incomplete trailing data is held back for the next call.
+func (f *lineFilterer) feed(data []byte) []byte {
+	f.buf = append(f.buf, data...)
+
+	var out []byte
+	for {
+		idx := bytes.IndexByte(f.buf, '\n')
+		if idx == -1 {
+			break
+		}
+		line := f.buf[:idx+1]
+		f.buf = f.buf[idx+1:]
+		if f.re == nil || f.re.Match(line) {
+			out = append(out, line...)
+		}
+	}
+	return out
+}
+
+// flush returns a final partial line left in the buffer at EOF, if any.
+func (f *lineFilterer) flush() []byte {
+	if len(f.buf) == 0 {
+		return nil
+	}
+	line := f.buf
+	f.buf = nil
+	if f.re == nil || f.re.Match(line) {
+		return line
+	}
+	return nil
+}
+
+// streamCapped pumps events from mgr.RunStream through filter, notifying the
+// client with a "notifications/message" per forwarded chunk and collecting
+// up to maxBytes into the returned string. It stops early (cancelling the
+// underlying command) once idleTimeout elapses with no new chunk, in
+// addition to whatever deadline ctx itself carries.
+func streamCapped(ctx context.Context, mgr *ssh.Manager, cmd, target string, maxBytes int, idleTimeout time.Duration, filter func([]byte) []byte, notify func(chunk []byte)) (output string, exitCode int, truncated bool, err error) {
+	cctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	events, err := mgr.RunStream(cctx, cmd, target)
+	if err != nil {
+		return "", 0, false, err
+	}
+
+	idleTimer := time.NewTimer(idleTimeout)
+	defer idleTimer.Stop()
+
+	var collected strings.Builder
+	total := 0
+	idledOut := false
+
+	for {
+		select {
+		case ev, ok := <-events:
+			if !ok {
+				if idledOut && err == nil {
+					err = fmt.Errorf("stopped after %s of no output", idleTimeout)
+				}
+				return collected.String(), exitCode, truncated, err
+			}
+			if !idleTimer.Stop() {
+				select {
+				case <-idleTimer.C:
+				default:
+				}
+			}
+			idleTimer.Reset(idleTimeout)
+
+			switch ev.Type {
+			case ssh.StdoutChunk, ssh.StderrChunk:
+				if truncated {
+					continue
+				}
+				chunk := filter(ev.Data)
+				if len(chunk) == 0 {
+					continue
+				}
+				if total+len(chunk) > maxBytes {
+					chunk = chunk[:maxBytes-total]
+					truncated = true
+				}
+				total += len(chunk)
+				collected.Write(chunk)
+				if notify != nil {
+					notify(chunk)
+				}
+				if truncated {
+					cancel()
+				}
+			case ssh.Exit:
+				exitCode = ev.ExitCode
+				err = ev.Err
+			}
+
+		case <-idleTimer.C:
+			idledOut = true
+			cancel()
+		}
+	}
+}
+
 func createDockerPsHandler(pool *ssh.Pool) server.ToolHandlerFunc {
 	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		mgr := getManager(ctx, pool)
@@ -108,23 +372,27 @@ func createDockerPsHandler(pool *ssh.Pool) server.ToolHandlerFunc {
 
 		all := req.GetBool("all", false)
 		target := req.GetString("target", "primary")
+		runtime := container.Runtime(req.GetString("runtime", ""))
 
-		if err := checkDockerAvailable(ctx, mgr, target); err != nil {
-			return mcp.NewToolResultError(err.Error()), nil
+		rt, err := containerRuntimeFor(ctx, mgr, target, runtime)
+		if err != nil {
+			return dockerPsShell(ctx, mgr, target, all)
 		}
 
-		flag := ""
-		if all {
-			flag = "-a"
+		containers, err := rt.List(ctx, all)
+		if err != nil {
+			log.Printf("[Tool:docker_ps] %s error, falling back to shell: %v", rt.Name(), err)
+			return dockerPsShell(ctx, mgr, target, all)
 		}
 
-		cmd := fmt.Sprintf("docker ps %s --format 'table {{.ID}}\t{{.Image}}\t{{.Status}}\t{{.Names}}'", flag)
-		output, err := mgr.Execute(ctx, cmd, target)
+		jsonBytes, err := json.MarshalIndent(map[string]interface{}{
+			"runtime":    rt.Name(),
+			"containers": containers,
+		}, "", "  ")
 		if err != nil {
-			return mcp.NewToolResultError(err.Error()), nil
+			return mcp.NewToolResultError("Failed to format container list"), nil
 		}
-
-		return mcp.NewToolResultText(output), nil
+		return mcp.NewToolResultText(string(jsonBytes)), nil
 	}
 }
 
@@ -135,24 +403,204 @@ func createDockerLogsHandler(pool *ssh.Pool) server.ToolHandlerFunc {
 			return mcp.NewToolResultError("No active session"), nil
 		}
 
-		container, _ := req.RequireString("container")
+		containerName, _ := req.RequireString("container")
 		lines := req.GetInt("lines", 50)
 		target := req.GetString("target", "primary")
+		runtime := container.Runtime(req.GetString("runtime", ""))
 
-		if err := checkDockerAvailable(ctx, mgr, target); err != nil {
-			return mcp.NewToolResultError(err.Error()), nil
+		rt, err := containerRuntimeFor(ctx, mgr, target, runtime)
+		if err != nil {
+			return dockerLogsShell(ctx, mgr, target, containerName, lines)
 		}
 
-		cmd := fmt.Sprintf("docker logs --tail %d %s 2>&1", lines, shellQuote(container))
-		output, err := mgr.Execute(ctx, cmd, target)
+		output, err := rt.Logs(ctx, containerName, lines)
+		if err != nil {
+			log.Printf("[Tool:docker_logs] %s error, falling back to shell: %v", rt.Name(), err)
+			return dockerLogsShell(ctx, mgr, target, containerName, lines)
+		}
+
+		return mcp.NewToolResultText(output), nil
+	}
+}
+
+// createDockerLogsFollowHandler streams "<cli> logs -f" over ssh.Manager's
+// RunStream (rather than through a container.ContainerRuntime, which has no
+// streaming surface), relaying lines as MCP notifications and applying the
+// grep filter server-side.
+func createDockerLogsFollowHandler(pool *ssh.Pool) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		mgr := getManager(ctx, pool)
+		if mgr == nil {
+			return mcp.NewToolResultError("No active session"), nil
+		}
+
+		containerName, _ := req.RequireString("container")
+		since := req.GetString("since", "")
+		timestamps := req.GetBool("timestamps", false)
+		grep := req.GetString("grep", "")
+		target := req.GetString("target", "primary")
+		runtime := container.Runtime(req.GetString("runtime", ""))
+		maxBytes := req.GetInt("max_output_bytes", 1<<20)
+		maxDuration := time.Duration(req.GetInt("max_duration_sec", 300)) * time.Second
+		idleTimeout := time.Duration(req.GetInt("idle_timeout_sec", 30)) * time.Second
+
+		var re *regexp.Regexp
+		if grep != "" {
+			var err error
+			re, err = regexp.Compile(grep)
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("invalid grep pattern: %v", err)), nil
+			}
+		}
+
+		cli, err := resolvedCLI(ctx, mgr, target, runtime)
 		if err != nil {
 			return mcp.NewToolResultError(err.Error()), nil
 		}
 
+		flags := ""
+		if since != "" {
+			flags += " --since " + shellQuote(since)
+		}
+		if timestamps {
+			flags += " --timestamps"
+		}
+		cmd := fmt.Sprintf("%s logs -f%s %s 2>&1", cli, flags, shellQuote(containerName))
+
+		log.Printf("[Tool:docker_logs_follow] Streaming: %s (target=%s)", cmd, target)
+
+		cctx, cancel := context.WithTimeout(ctx, maxDuration)
+		defer cancel()
+
+		srv := server.ServerFromContext(ctx)
+		filter := &lineFilterer{re: re}
+
+		output, _, truncated, err := streamCapped(cctx, mgr, cmd, target, maxBytes, idleTimeout,
+			func(data []byte) []byte { return filter.feed(data) },
+			func(chunk []byte) {
+				if srv == nil {
+					return
+				}
+				if notifyErr := srv.SendNotificationToClient(ctx, "notifications/message", map[string]any{
+					"container": containerName,
+					"line":      string(chunk),
+				}); notifyErr != nil {
+					log.Printf("[Tool:docker_logs_follow] Failed to send notification: %v", notifyErr)
+				}
+			},
+		)
+		if tail := filter.flush(); len(tail) > 0 {
+			output += string(tail)
+		}
+		if err != nil && !truncated {
+			log.Printf("[Tool:docker_logs_follow] Error: %v", err)
+		}
+		if truncated {
+			output += fmt.Sprintf("\n... [output truncated at %d bytes]", maxBytes)
+		}
+
+		log.Printf("[Tool:docker_logs_follow] Done (%d bytes output)", len(output))
 		return mcp.NewToolResultText(output), nil
 	}
 }
 
+// createDockerExecHandler runs a command inside a container over
+// ssh.Manager's RunStream, streaming its output via MCP notifications and
+// reporting the exit code as structured JSON once the command finishes.
+func createDockerExecHandler(pool *ssh.Pool) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		mgr := getManager(ctx, pool)
+		if mgr == nil {
+			return mcp.NewToolResultError("No active session"), nil
+		}
+
+		containerName, _ := req.RequireString("container")
+		command, _ := req.RequireString("command")
+		useShell := req.GetBool("shell", false)
+		tty := req.GetBool("tty", false)
+		stdin := req.GetString("stdin", "")
+		target := req.GetString("target", "primary")
+		runtime := container.Runtime(req.GetString("runtime", ""))
+		maxBytes := req.GetInt("max_output_bytes", 1<<20)
+		maxDuration := time.Duration(req.GetInt("max_duration_sec", 120)) * time.Second
+		idleTimeout := time.Duration(req.GetInt("idle_timeout_sec", 30)) * time.Second
+
+		if !useShell && shellMetaChars.MatchString(command) {
+			return mcp.NewToolResultError("command contains shell metacharacters; set shell=true to run it through a shell"), nil
+		}
+
+		cli, err := resolvedCLI(ctx, mgr, target, runtime)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		opts := ""
+		if tty {
+			opts += " -t"
+		}
+		if stdin != "" {
+			opts += " -i"
+		}
+
+		inner := command
+		if useShell {
+			inner = "sh -c " + shellQuote(command)
+		}
+
+		execLine := fmt.Sprintf("%s exec%s %s %s 2>&1", cli, opts, shellQuote(containerName), inner)
+		if stdin != "" {
+			// A heredoc with a fixed delimiter would let stdin (fully
+			// attacker-controlled) smuggle a line matching that delimiter
+			// and inject arbitrary commands after it - bypassing the
+			// shellMetaChars check above entirely. Base64-encode stdin
+			// instead so its bytes never reach the shell unescaped.
+			encoded := base64.StdEncoding.EncodeToString([]byte(stdin))
+			execLine = fmt.Sprintf("echo %s | base64 -d | %s", shellQuote(encoded), execLine)
+		}
+
+		log.Printf("[Tool:docker_exec] Executing in %s: %s (target=%s)", containerName, command, target)
+
+		cctx, cancel := context.WithTimeout(ctx, maxDuration)
+		defer cancel()
+
+		srv := server.ServerFromContext(ctx)
+
+		output, exitCode, truncated, err := streamCapped(cctx, mgr, execLine, target, maxBytes, idleTimeout,
+			func(data []byte) []byte { return data },
+			func(chunk []byte) {
+				if srv == nil {
+					return
+				}
+				if notifyErr := srv.SendNotificationToClient(ctx, "notifications/message", map[string]any{
+					"container": containerName,
+					"data":      string(chunk),
+				}); notifyErr != nil {
+					log.Printf("[Tool:docker_exec] Failed to send notification: %v", notifyErr)
+				}
+			},
+		)
+		if err != nil && !truncated {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		if truncated {
+			output += fmt.Sprintf("\n... [output truncated at %d bytes]", maxBytes)
+		}
+
+		jsonBytes, jsonErr := json.MarshalIndent(map[string]interface{}{
+			"container": containerName,
+			"exit_code": exitCode,
+			"truncated": truncated,
+			"output":    output,
+		}, "", "  ")
+		if jsonErr != nil {
+			return mcp.NewToolResultError("Failed to format exec result"), nil
+		}
+
+		log.Printf("[Tool:docker_exec] Done (exit=%d, %d bytes output)", exitCode, len(output))
+		return mcp.NewToolResultText(string(jsonBytes)), nil
+	}
+}
+
 func createDockerOpHandler(pool *ssh.Pool) server.ToolHandlerFunc {
 	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		mgr := getManager(ctx, pool)
@@ -160,26 +608,26 @@ func createDockerOpHandler(pool *ssh.Pool) server.ToolHandlerFunc {
 			return mcp.NewToolResultError("No active session"), nil
 		}
 
-		container, _ := req.RequireString("container")
+		containerName, _ := req.RequireString("container")
 		action, _ := req.RequireString("action")
 		target := req.GetString("target", "primary")
+		runtime := container.Runtime(req.GetString("runtime", ""))
 
 		if action != "start" && action != "stop" && action != "restart" {
 			return mcp.NewToolResultError("Invalid action. Use: start, stop, restart"), nil
 		}
 
-		if err := checkDockerAvailable(ctx, mgr, target); err != nil {
-			return mcp.NewToolResultError(err.Error()), nil
+		rt, err := containerRuntimeFor(ctx, mgr, target, runtime)
+		if err != nil {
+			return dockerOpShell(ctx, mgr, target, containerName, action)
 		}
 
-		cmd := fmt.Sprintf("docker %s %s 2>&1", shellQuote(action), shellQuote(container))
-		output, err := mgr.Execute(ctx, cmd, target)
-		if err != nil {
-			log.Printf("[Tool:docker_op] Error: %v", err)
-			return mcp.NewToolResultError(err.Error()), nil
+		if err := rt.Op(ctx, containerName, action); err != nil {
+			log.Printf("[Tool:docker_op] %s error, falling back to shell: %v", rt.Name(), err)
+			return dockerOpShell(ctx, mgr, target, containerName, action)
 		}
 
-		return mcp.NewToolResultText(fmt.Sprintf("%s: %s\n%s", action, container, output)), nil
+		return mcp.NewToolResultText(fmt.Sprintf("%s: %s", action, containerName)), nil
 	}
 }
 
@@ -190,20 +638,30 @@ func createDockerIPHandler(pool *ssh.Pool) server.ToolHandlerFunc {
 			return mcp.NewToolResultError("No active session"), nil
 		}
 
-		container, _ := req.RequireString("container")
+		containerName, _ := req.RequireString("container")
 		target := req.GetString("target", "primary")
+		runtime := container.Runtime(req.GetString("runtime", ""))
 
-		if err := checkDockerAvailable(ctx, mgr, target); err != nil {
-			return mcp.NewToolResultError(err.Error()), nil
+		rt, err := containerRuntimeFor(ctx, mgr, target, runtime)
+		if err != nil {
+			return dockerIPShell(ctx, mgr, target, containerName)
 		}
 
-		cmd := fmt.Sprintf("docker inspect --format '{{range $net, $conf := .NetworkSettings.Networks}}{{$net}}:{{$conf.IPAddress}}|{{end}}' %s 2>/dev/null", shellQuote(container))
-		output, err := mgr.Execute(ctx, cmd, target)
+		networks, err := rt.Inspect(ctx, containerName)
 		if err != nil {
-			return mcp.NewToolResultError(err.Error()), nil
+			log.Printf("[Tool:docker_ip] %s error, falling back to shell: %v", rt.Name(), err)
+			return dockerIPShell(ctx, mgr, target, containerName)
 		}
 
-		return mcp.NewToolResultText(fmt.Sprintf("Container: %s\nNetworks: %s", container, output)), nil
+		jsonBytes, err := json.MarshalIndent(map[string]interface{}{
+			"runtime":   rt.Name(),
+			"container": containerName,
+			"networks":  networks,
+		}, "", "  ")
+		if err != nil {
+			return mcp.NewToolResultError("Failed to format network list"), nil
+		}
+		return mcp.NewToolResultText(string(jsonBytes)), nil
 	}
 }
 
@@ -216,18 +674,29 @@ func createDockerFindByIPHandler(pool *ssh.Pool) server.ToolHandlerFunc {
 
 		ip, _ := req.RequireString("ip")
 		target := req.GetString("target", "primary")
+		runtime := container.Runtime(req.GetString("runtime", ""))
 
-		if err := checkDockerAvailable(ctx, mgr, target); err != nil {
-			return mcp.NewToolResultError(err.Error()), nil
+		rt, err := containerRuntimeFor(ctx, mgr, target, runtime)
+		if err != nil {
+			return dockerFindByIPShell(ctx, mgr, target, ip)
 		}
 
-		cmd := fmt.Sprintf(`docker ps -q | xargs -I {} docker inspect --format '{{.Name}}|{{range $net, $conf := .NetworkSettings.Networks}}{{$net}}:{{$conf.IPAddress}},{{end}}' {} 2>/dev/null | grep %s`, shellQuote(ip))
-		output, err := mgr.Execute(ctx, cmd, target)
+		info, netName, err := rt.FindByIP(ctx, ip)
 		if err != nil {
 			return mcp.NewToolResultText(fmt.Sprintf("No container found with IP: %s", ip)), nil
 		}
 
-		return mcp.NewToolResultText(output), nil
+		jsonBytes, err := json.MarshalIndent(map[string]interface{}{
+			"runtime":   rt.Name(),
+			"container": info.Names,
+			"id":        info.ID,
+			"network":   netName,
+			"ip":        ip,
+		}, "", "  ")
+		if err != nil {
+			return mcp.NewToolResultError("Failed to format result"), nil
+		}
+		return mcp.NewToolResultText(string(jsonBytes)), nil
 	}
 }
 
@@ -239,18 +708,27 @@ func createDockerNetworksHandler(pool *ssh.Pool) server.ToolHandlerFunc {
 		}
 
 		target := req.GetString("target", "primary")
+		runtime := container.Runtime(req.GetString("runtime", ""))
 
-		if err := checkDockerAvailable(ctx, mgr, target); err != nil {
-			return mcp.NewToolResultError(err.Error()), nil
+		rt, err := containerRuntimeFor(ctx, mgr, target, runtime)
+		if err != nil {
+			return dockerNetworksShell(ctx, mgr, target)
 		}
 
-		cmd := "docker network ls --format '{{.Name}} ({{.Driver}})'"
-		output, err := mgr.Execute(ctx, cmd, target)
+		networks, err := rt.Networks(ctx)
 		if err != nil {
-			return mcp.NewToolResultError(err.Error()), nil
+			log.Printf("[Tool:docker_networks] %s error, falling back to shell: %v", rt.Name(), err)
+			return dockerNetworksShell(ctx, mgr, target)
 		}
 
-		return mcp.NewToolResultText(output), nil
+		jsonBytes, err := json.MarshalIndent(map[string]interface{}{
+			"runtime":  rt.Name(),
+			"networks": networks,
+		}, "", "  ")
+		if err != nil {
+			return mcp.NewToolResultError("Failed to format network list"), nil
+		}
+		return mcp.NewToolResultText(string(jsonBytes)), nil
 	}
 }
 
@@ -261,26 +739,28 @@ func createDockerCpFromHandler(pool *ssh.Pool) server.ToolHandlerFunc {
 			return mcp.NewToolResultError("No active session"), nil
 		}
 
-		container, _ := req.RequireString("container")
+		containerName, _ := req.RequireString("container")
 		containerPath, _ := req.RequireString("container_path")
 		hostPath, _ := req.RequireString("host_path")
 		target := req.GetString("target", "primary")
+		runtime := container.Runtime(req.GetString("runtime", ""))
 
-		if err := checkDockerAvailable(ctx, mgr, target); err != nil {
-			return mcp.NewToolResultError(err.Error()), nil
+		rt, err := containerRuntimeFor(ctx, mgr, target, runtime)
+		if err != nil {
+			return dockerCpFromShell(ctx, mgr, target, containerName, containerPath, hostPath)
 		}
 
-		cmd := fmt.Sprintf("docker cp %s:%s %s 2>&1", shellQuote(container), shellQuote(containerPath), shellQuote(hostPath))
-		output, err := mgr.Execute(ctx, cmd, target)
+		content, err := rt.CopyFrom(ctx, containerName, containerPath)
 		if err != nil {
-			return mcp.NewToolResultError(err.Error()), nil
+			log.Printf("[Tool:docker_cp_from] %s error, falling back to shell: %v", rt.Name(), err)
+			return dockerCpFromShell(ctx, mgr, target, containerName, containerPath, hostPath)
 		}
 
-		if containsString(output, "Error") || containsString(output, "No such") {
-			return mcp.NewToolResultError(output), nil
+		if err := mgr.WriteFile(ctx, hostPath, content, target); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
 		}
 
-		return mcp.NewToolResultText(fmt.Sprintf("Copied %s:%s to %s", container, containerPath, hostPath)), nil
+		return mcp.NewToolResultText(fmt.Sprintf("Copied %s:%s to %s", containerName, containerPath, hostPath)), nil
 	}
 }
 
@@ -292,28 +772,161 @@ func createDockerCpToHandler(pool *ssh.Pool) server.ToolHandlerFunc {
 		}
 
 		hostPath, _ := req.RequireString("host_path")
-		container, _ := req.RequireString("container")
+		containerName, _ := req.RequireString("container")
 		containerPath, _ := req.RequireString("container_path")
 		target := req.GetString("target", "primary")
+		runtime := container.Runtime(req.GetString("runtime", ""))
 
-		if err := checkDockerAvailable(ctx, mgr, target); err != nil {
-			return mcp.NewToolResultError(err.Error()), nil
+		rt, err := containerRuntimeFor(ctx, mgr, target, runtime)
+		if err != nil {
+			return dockerCpToShell(ctx, mgr, target, hostPath, containerName, containerPath)
 		}
 
-		cmd := fmt.Sprintf("docker cp %s %s:%s 2>&1", shellQuote(hostPath), shellQuote(container), shellQuote(containerPath))
-		output, err := mgr.Execute(ctx, cmd, target)
+		content, err := mgr.ReadFile(ctx, hostPath, target)
 		if err != nil {
 			return mcp.NewToolResultError(err.Error()), nil
 		}
 
-		if containsString(output, "Error") || containsString(output, "No such") {
-			return mcp.NewToolResultError(output), nil
+		if err := rt.CopyTo(ctx, containerName, containerPath, content); err != nil {
+			log.Printf("[Tool:docker_cp_to] %s error, falling back to shell: %v", rt.Name(), err)
+			return dockerCpToShell(ctx, mgr, target, hostPath, containerName, containerPath)
 		}
 
-		return mcp.NewToolResultText(fmt.Sprintf("Copied %s to %s:%s", hostPath, container, containerPath)), nil
+		return mcp.NewToolResultText(fmt.Sprintf("Copied %s to %s:%s", hostPath, containerName, containerPath)), nil
 	}
 }
 
+// --- shell fallbacks, used when no container runtime backend
+// (Docker/Podman Engine API, or containerd via nerdctl) could be resolved,
+// or when the resolved backend's call fails, as a last resort if a bare
+// `docker` CLI still works ---
+
+func dockerPsShell(ctx context.Context, mgr *ssh.Manager, target string, all bool) (*mcp.CallToolResult, error) {
+	if err := checkDockerAvailable(ctx, mgr, target); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	flag := ""
+	if all {
+		flag = "-a"
+	}
+
+	cmd := fmt.Sprintf("docker ps %s --format 'table {{.ID}}\t{{.Image}}\t{{.Status}}\t{{.Names}}'", flag)
+	output, err := mgr.Execute(ctx, cmd, target)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	return mcp.NewToolResultText(output), nil
+}
+
+func dockerLogsShell(ctx context.Context, mgr *ssh.Manager, target, containerName string, lines int) (*mcp.CallToolResult, error) {
+	if err := checkDockerAvailable(ctx, mgr, target); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	cmd := fmt.Sprintf("docker logs --tail %d %s 2>&1", lines, shellQuote(containerName))
+	output, err := mgr.Execute(ctx, cmd, target)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	return mcp.NewToolResultText(output), nil
+}
+
+func dockerOpShell(ctx context.Context, mgr *ssh.Manager, target, containerName, action string) (*mcp.CallToolResult, error) {
+	if err := checkDockerAvailable(ctx, mgr, target); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	cmd := fmt.Sprintf("docker %s %s 2>&1", shellQuote(action), shellQuote(containerName))
+	output, err := mgr.Execute(ctx, cmd, target)
+	if err != nil {
+		log.Printf("[Tool:docker_op] Error: %v", err)
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	return mcp.NewToolResultText(fmt.Sprintf("%s: %s\n%s", action, containerName, output)), nil
+}
+
+func dockerIPShell(ctx context.Context, mgr *ssh.Manager, target, containerName string) (*mcp.CallToolResult, error) {
+	if err := checkDockerAvailable(ctx, mgr, target); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	cmd := fmt.Sprintf("docker inspect --format '{{range $net, $conf := .NetworkSettings.Networks}}{{$net}}:{{$conf.IPAddress}}|{{end}}' %s 2>/dev/null", shellQuote(containerName))
+	output, err := mgr.Execute(ctx, cmd, target)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	return mcp.NewToolResultText(fmt.Sprintf("Container: %s\nNetworks: %s", containerName, output)), nil
+}
+
+func dockerFindByIPShell(ctx context.Context, mgr *ssh.Manager, target, ip string) (*mcp.CallToolResult, error) {
+	if err := checkDockerAvailable(ctx, mgr, target); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	cmd := fmt.Sprintf(`docker ps -q | xargs -I {} docker inspect --format '{{.Name}}|{{range $net, $conf := .NetworkSettings.Networks}}{{$net}}:{{$conf.IPAddress}},{{end}}' {} 2>/dev/null | grep %s`, shellQuote(ip))
+	output, err := mgr.Execute(ctx, cmd, target)
+	if err != nil {
+		return mcp.NewToolResultText(fmt.Sprintf("No container found with IP: %s", ip)), nil
+	}
+
+	return mcp.NewToolResultText(output), nil
+}
+
+func dockerNetworksShell(ctx context.Context, mgr *ssh.Manager, target string) (*mcp.CallToolResult, error) {
+	if err := checkDockerAvailable(ctx, mgr, target); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	cmd := "docker network ls --format '{{.Name}} ({{.Driver}})'"
+	output, err := mgr.Execute(ctx, cmd, target)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	return mcp.NewToolResultText(output), nil
+}
+
+func dockerCpFromShell(ctx context.Context, mgr *ssh.Manager, target, containerName, containerPath, hostPath string) (*mcp.CallToolResult, error) {
+	if err := checkDockerAvailable(ctx, mgr, target); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	cmd := fmt.Sprintf("docker cp %s:%s %s 2>&1", shellQuote(containerName), shellQuote(containerPath), shellQuote(hostPath))
+	output, err := mgr.Execute(ctx, cmd, target)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	if containsString(output, "Error") || containsString(output, "No such") {
+		return mcp.NewToolResultError(output), nil
+	}
+
+	return mcp.NewToolResultText(fmt.Sprintf("Copied %s:%s to %s", containerName, containerPath, hostPath)), nil
+}
+
+func dockerCpToShell(ctx context.Context, mgr *ssh.Manager, target, hostPath, containerName, containerPath string) (*mcp.CallToolResult, error) {
+	if err := checkDockerAvailable(ctx, mgr, target); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	cmd := fmt.Sprintf("docker cp %s %s:%s 2>&1", shellQuote(hostPath), shellQuote(containerName), shellQuote(containerPath))
+	output, err := mgr.Execute(ctx, cmd, target)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	if containsString(output, "Error") || containsString(output, "No such") {
+		return mcp.NewToolResultError(output), nil
+	}
+
+	return mcp.NewToolResultText(fmt.Sprintf("Copied %s to %s:%s", hostPath, containerName, containerPath)), nil
+}
+
 func checkDockerAvailable(ctx context.Context, mgr *ssh.Manager, target string) error {
 	output, err := mgr.Execute(ctx, "command -v docker >/dev/null 2>&1 && echo 'ok' || echo 'missing'", target)
 	if err != nil {
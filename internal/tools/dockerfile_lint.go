@@ -0,0 +1,321 @@
+// Package tools: dockerfile_lint.go implements the Dockerfile validator
+// used by ValidateContent — structural checks (known instruction names,
+// FROM present) plus a small hadolint-style rule set over a re-assembled
+// view of the file where line continuations have already been joined
+// back into logical instructions.
+package tools
+
+import (
+	"bufio"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+var dockerfileInstructions = map[string]bool{
+	"FROM": true, "RUN": true, "CMD": true, "LABEL": true,
+	"EXPOSE": true, "ENV": true, "ADD": true, "COPY": true,
+	"ENTRYPOINT": true, "VOLUME": true, "USER": true, "WORKDIR": true,
+	"ARG": true, "ONBUILD": true, "STOPSIGNAL": true, "HEALTHCHECK": true,
+	"SHELL": true, "MAINTAINER": true,
+}
+
+// dockerfileInstr is one logical instruction with its line-continuations
+// already joined back into a single argument string, and the source line
+// range it came from.
+type dockerfileInstr struct {
+	Instruction string // upper-cased, e.g. "FROM", "RUN"
+	Raw         string // the instruction keyword as written
+	Args        string // argument text, continuation lines joined with a space
+	StartLine   int
+	EndLine     int
+}
+
+// parseDockerfileInstructions reassembles line-continuations ("\" at
+// end of line) into logical instructions so the rules below can reason
+// about a RUN's full argument text instead of a fragment of it.
+func parseDockerfileInstructions(content string) []dockerfileInstr {
+	var instrs []dockerfileInstr
+	var cur *dockerfileInstr
+
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		trimmed := strings.TrimSpace(scanner.Text())
+
+		if cur != nil {
+			text := strings.TrimSpace(strings.TrimSuffix(trimmed, "\\"))
+			cur.Args = strings.TrimSpace(cur.Args + " " + text)
+			cur.EndLine = lineNum
+			if !strings.HasSuffix(trimmed, "\\") {
+				instrs = append(instrs, *cur)
+				cur = nil
+			}
+			continue
+		}
+
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+
+		fields := strings.Fields(trimmed)
+		if len(fields) == 0 {
+			continue
+		}
+
+		rest := strings.TrimSpace(strings.TrimPrefix(trimmed, fields[0]))
+		continues := strings.HasSuffix(rest, "\\")
+		rest = strings.TrimSpace(strings.TrimSuffix(rest, "\\"))
+
+		instr := dockerfileInstr{
+			Instruction: strings.ToUpper(fields[0]),
+			Raw:         fields[0],
+			Args:        rest,
+			StartLine:   lineNum,
+			EndLine:     lineNum,
+		}
+		if continues {
+			cur = &instr
+		} else {
+			instrs = append(instrs, instr)
+		}
+	}
+	if cur != nil {
+		instrs = append(instrs, *cur)
+	}
+	return instrs
+}
+
+// dockerfileStageAliases collects the "AS <name>" aliases declared by
+// FROM instructions, so rules can tell a multi-stage build's internal
+// stage references ("FROM builder") apart from external images.
+func dockerfileStageAliases(instrs []dockerfileInstr) map[string]bool {
+	aliases := make(map[string]bool)
+	for _, in := range instrs {
+		if in.Instruction != "FROM" {
+			continue
+		}
+		fields := strings.Fields(in.Args)
+		for i := 0; i+1 < len(fields); i++ {
+			if strings.EqualFold(fields[i], "AS") {
+				aliases[strings.ToLower(fields[i+1])] = true
+			}
+		}
+	}
+	return aliases
+}
+
+// DL3006: FROM without an explicit tag or digest isn't reproducible.
+func checkDL3006(instrs []dockerfileInstr) []LintFinding {
+	var findings []LintFinding
+	aliases := dockerfileStageAliases(instrs)
+	for _, in := range instrs {
+		if in.Instruction != "FROM" {
+			continue
+		}
+		fields := strings.Fields(in.Args)
+		if len(fields) == 0 {
+			continue
+		}
+		image := fields[0]
+		if strings.Contains(image, "@") {
+			continue // pinned by digest
+		}
+		if strings.EqualFold(image, "scratch") || aliases[strings.ToLower(image)] {
+			continue
+		}
+		if !strings.Contains(image, ":") {
+			findings = append(findings, LintFinding{
+				Rule: "DL3006", Severity: SeverityWarning, Line: in.StartLine,
+				Message: fmt.Sprintf("FROM %q has no explicit tag or digest — pin a version for reproducible builds", image),
+			})
+		}
+	}
+	return findings
+}
+
+// DL3007: pinning the "latest" tag is just as unreproducible as no tag.
+func checkDL3007(instrs []dockerfileInstr) []LintFinding {
+	var findings []LintFinding
+	for _, in := range instrs {
+		if in.Instruction != "FROM" {
+			continue
+		}
+		fields := strings.Fields(in.Args)
+		if len(fields) == 0 {
+			continue
+		}
+		if strings.HasSuffix(fields[0], ":latest") {
+			findings = append(findings, LintFinding{
+				Rule: "DL3007", Severity: SeverityWarning, Line: in.StartLine,
+				Message: fmt.Sprintf("FROM %q pins the 'latest' tag, which is not reproducible", fields[0]),
+			})
+		}
+	}
+	return findings
+}
+
+// DL3008/DL3009: apt-get install should skip recommended packages and
+// clean up the package list cache in the same layer it was populated in.
+func checkAptGetHygiene(instrs []dockerfileInstr) []LintFinding {
+	var findings []LintFinding
+	for _, in := range instrs {
+		if in.Instruction != "RUN" || !strings.Contains(in.Args, "apt-get install") {
+			continue
+		}
+		if !strings.Contains(in.Args, "--no-install-recommends") {
+			findings = append(findings, LintFinding{
+				Rule: "DL3008", Severity: SeverityWarning, Line: in.StartLine,
+				Message: "apt-get install without --no-install-recommends pulls in unnecessary packages",
+			})
+		}
+		hasUpdate := strings.Contains(in.Args, "apt-get update")
+		hasCleanup := strings.Contains(in.Args, "rm -rf /var/lib/apt/lists")
+		if !hasUpdate || !hasCleanup {
+			findings = append(findings, LintFinding{
+				Rule: "DL3009", Severity: SeverityWarning, Line: in.StartLine,
+				Message: "combine 'apt-get update', 'apt-get install', and 'rm -rf /var/lib/apt/lists/*' in the same RUN to avoid a stale or bloated layer",
+			})
+		}
+	}
+	return findings
+}
+
+var dockerfileArchiveExts = []string{".tar", ".tar.gz", ".tgz", ".tar.bz2", ".tar.xz", ".zip"}
+
+// DL3020: ADD's remote-fetch and auto-extract behavior is rarely what's
+// wanted for a local file — COPY is the explicit, predictable choice.
+func checkDL3020(instrs []dockerfileInstr) []LintFinding {
+	var findings []LintFinding
+	for _, in := range instrs {
+		if in.Instruction != "ADD" {
+			continue
+		}
+		fields := strings.Fields(in.Args)
+		if len(fields) == 0 {
+			continue
+		}
+		src := fields[0]
+		if strings.HasPrefix(src, "http://") || strings.HasPrefix(src, "https://") {
+			continue // ADD's one job a URL source can't do via COPY
+		}
+		isArchive := false
+		for _, ext := range dockerfileArchiveExts {
+			if strings.HasSuffix(src, ext) {
+				isArchive = true
+				break
+			}
+		}
+		if isArchive {
+			continue // auto-extraction is the other legitimate ADD use
+		}
+		findings = append(findings, LintFinding{
+			Rule: "DL3020", Severity: SeverityWarning, Line: in.StartLine,
+			Message: "use COPY instead of ADD for local files",
+		})
+	}
+	return findings
+}
+
+// DL3025: shell-form CMD/ENTRYPOINT runs through /bin/sh -c, which drops
+// signal handling the JSON array form preserves.
+func checkDL3025(instrs []dockerfileInstr) []LintFinding {
+	var findings []LintFinding
+	for _, in := range instrs {
+		if in.Instruction != "CMD" && in.Instruction != "ENTRYPOINT" {
+			continue
+		}
+		if !strings.HasPrefix(strings.TrimSpace(in.Args), "[") {
+			findings = append(findings, LintFinding{
+				Rule: "DL3025", Severity: SeverityWarning, Line: in.StartLine,
+				Message: fmt.Sprintf(`%s should use JSON array form (e.g. ["cmd", "arg"]) instead of shell form`, in.Instruction),
+			})
+		}
+	}
+	return findings
+}
+
+// DL4000: MAINTAINER has been deprecated in favor of LABEL since Docker 1.13.
+func checkDL4000(instrs []dockerfileInstr) []LintFinding {
+	var findings []LintFinding
+	for _, in := range instrs {
+		if in.Instruction == "MAINTAINER" {
+			findings = append(findings, LintFinding{
+				Rule: "DL4000", Severity: SeverityWarning, Line: in.StartLine,
+				Message: "MAINTAINER is deprecated — use a LABEL (e.g. org.opencontainers.image.authors) instead",
+			})
+		}
+	}
+	return findings
+}
+
+// DL3059: consecutive RUNs each add a layer that could usually be merged
+// with '&&' into one.
+func checkDL3059(instrs []dockerfileInstr) []LintFinding {
+	var findings []LintFinding
+	for i := 1; i < len(instrs); i++ {
+		if instrs[i].Instruction == "RUN" && instrs[i-1].Instruction == "RUN" {
+			findings = append(findings, LintFinding{
+				Rule: "DL3059", Severity: SeverityInfo, Line: instrs[i].StartLine,
+				Message: "multiple consecutive RUN instructions could be combined into one to reduce image layers",
+			})
+		}
+	}
+	return findings
+}
+
+var dockerfileRules = []func([]dockerfileInstr) []LintFinding{
+	checkDL3006,
+	checkDL3007,
+	checkAptGetHygiene,
+	checkDL3020,
+	checkDL3025,
+	checkDL4000,
+	checkDL3059,
+}
+
+// ValidateDockerfileWithRules runs structural validation (known
+// instruction names, FROM present) plus the hadolint-style rule set
+// above, skipping any rule ID listed in `disabled`.
+func ValidateDockerfileWithRules(content string, disabled []string) *ValidationResult {
+	r := &ValidationResult{FileType: "dockerfile"}
+	skip := make(map[string]bool, len(disabled))
+	for _, id := range disabled {
+		skip[strings.ToUpper(id)] = true
+	}
+
+	instrs := parseDockerfileInstructions(content)
+
+	hasFrom := false
+	for _, in := range instrs {
+		if in.Instruction == "FROM" {
+			hasFrom = true
+		}
+		if !dockerfileInstructions[in.Instruction] {
+			r.Errors = append(r.Errors, simpleError(in.StartLine, fmt.Sprintf("unknown instruction: %s", in.Raw)))
+		}
+	}
+	if !hasFrom && strings.TrimSpace(content) != "" {
+		r.Errors = append(r.Errors, ValidationError{Severity: SeverityError, Message: "missing FROM instruction"})
+	}
+
+	for _, rule := range dockerfileRules {
+		for _, f := range rule(instrs) {
+			if skip[f.Rule] {
+				continue
+			}
+			r.Findings = append(r.Findings, f)
+		}
+	}
+	sort.SliceStable(r.Findings, func(i, j int) bool { return r.Findings[i].Line < r.Findings[j].Line })
+
+	r.Valid = len(r.Errors) == 0
+	for _, f := range r.Findings {
+		if f.Severity == SeverityError {
+			r.Valid = false
+			break
+		}
+	}
+	return r
+}
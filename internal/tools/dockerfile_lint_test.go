@@ -0,0 +1,79 @@
+package tools
+
+import "testing"
+
+func findingRules(findings []LintFinding) map[string]bool {
+	out := make(map[string]bool, len(findings))
+	for _, f := range findings {
+		out[f.Rule] = true
+	}
+	return out
+}
+
+func TestValidateDockerfileCleanFile(t *testing.T) {
+	content := `FROM golang:1.21-bookworm AS builder
+RUN apt-get update && apt-get install --no-install-recommends -y git && rm -rf /var/lib/apt/lists/*
+COPY . .
+RUN go build -o /app
+
+FROM builder
+COPY --from=builder /app /app
+ENTRYPOINT ["/app"]
+`
+	r := ValidateDockerfileWithRules(content, nil)
+	if !r.Valid {
+		t.Fatalf("expected valid, got errors: %v", r.Errors)
+	}
+	if len(r.Findings) != 0 {
+		t.Fatalf("expected no lint findings, got: %v", r.Findings)
+	}
+}
+
+func TestValidateDockerfileFlagsRules(t *testing.T) {
+	content := `FROM ubuntu:latest
+MAINTAINER someone@example.com
+ADD ./app.tar.gz /app
+RUN apt-get install -y curl
+RUN echo hi
+CMD echo hello
+`
+	r := ValidateDockerfileWithRules(content, nil)
+	if !r.Valid {
+		t.Fatalf("expected valid (findings are warnings, not errors): %v", r.Errors)
+	}
+	rules := findingRules(r.Findings)
+	for _, want := range []string{"DL3007", "DL4000", "DL3008", "DL3009", "DL3025", "DL3059"} {
+		if !rules[want] {
+			t.Errorf("expected rule %s to fire, findings: %v", want, r.Findings)
+		}
+	}
+}
+
+func TestValidateDockerfileMissingTag(t *testing.T) {
+	r := ValidateDockerfileWithRules("FROM ubuntu\n", nil)
+	if !findingRules(r.Findings)["DL3006"] {
+		t.Errorf("expected DL3006 for untagged FROM, findings: %v", r.Findings)
+	}
+}
+
+func TestValidateDockerfileSkipsArchiveAdd(t *testing.T) {
+	content := "FROM scratch\nADD rootfs.tar.gz /\n"
+	r := ValidateDockerfileWithRules(content, nil)
+	if findingRules(r.Findings)["DL3020"] {
+		t.Errorf("ADD of a tarball should not trigger DL3020: %v", r.Findings)
+	}
+}
+
+func TestValidateDockerfileDisabledRules(t *testing.T) {
+	r := ValidateDockerfileWithRules("FROM ubuntu:latest\n", []string{"DL3007"})
+	if findingRules(r.Findings)["DL3007"] {
+		t.Errorf("DL3007 should have been suppressed, findings: %v", r.Findings)
+	}
+}
+
+func TestValidateDockerfileMissingFromStillErrors(t *testing.T) {
+	r := ValidateDockerfileWithRules("RUN echo hi\n", nil)
+	if r.Valid {
+		t.Fatal("expected invalid: missing FROM instruction")
+	}
+}
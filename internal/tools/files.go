@@ -8,6 +8,7 @@ import (
 	"path/filepath"
 	"strings"
 
+	"ssh-mcp/internal/plugin"
 	"ssh-mcp/internal/ssh"
 
 	"github.com/mark3labs/mcp-go/mcp"
@@ -81,6 +82,40 @@ Examples:
 		),
 		createEditHandler(pool),
 	)
+
+	// edit_transaction — all-or-nothing multi-file edit with rollback
+	s.AddTool(
+		mcp.NewTool("edit_transaction",
+			mcp.WithDescription(`Apply an ordered list of edit operations across one or more files (and optionally multiple targets) atomically. Every file touched is snapshotted before any operation runs; if any operation errors or any touched file fails server-side syntax validation afterward, every snapshot is restored and the whole transaction is reported as rolled back.
+
+Each entry in 'operations' takes the same fields as the edit tool: path, operation (default replace), target (default primary), plus whichever of old_text/new_text/pattern/replacement/content/line/start_line/end_line/global that operation needs.
+
+Set dry_run=true to run the whole transaction against /tmp copies and get back a diff per file, without touching the originals at all.`),
+			mcp.WithArray("operations", mcp.Required(), mcp.Description("Ordered list of edit operation objects, same shape as the edit tool's parameters plus 'path' and optional 'target'")),
+			mcp.WithBoolean("dry_run", mcp.Description("Run against /tmp copies and report diffs instead of touching the originals (default: false)")),
+		),
+		createEditTransactionHandler(pool),
+	)
+
+	// apply_patch — unified-diff based file editor
+	s.AddTool(
+		mcp.NewTool("apply_patch",
+			mcp.WithDescription(`Apply a standard unified diff (as produced by 'diff -u' or 'git diff') to a single remote file.
+
+Unlike 'edit', which requires reasoning about sed escaping one operation at a time, this takes a whole patch and locates each hunk by its context lines rather than trusting the line numbers in the diff — so the patch still applies after small drift in the file. Set 'fuzz' (0, 1, or 2; default 1) to control how many leading/trailing context lines a hunk is allowed to drop before it's considered unmatched.
+
+The patch is applied in-memory against the current file content, then run through the same syntax validation as the write tool before anything is committed. If any hunk can't be located, or validation fails after applying, nothing is written and the response reports exactly which hunk(s) failed along with their expected context.
+
+The patch must touch exactly one file (its own --- / +++ headers); split multi-file patches into one apply_patch call per file.`),
+			mcp.WithString("path", mcp.Required(), mcp.Description("File path to patch")),
+			mcp.WithString("patch", mcp.Required(), mcp.Description("Unified diff text (e.g. from 'diff -u' or 'git diff') for a single file")),
+			mcp.WithNumber("fuzz", mcp.Description("Context lines a hunk may drop from each end before it's unmatched: 0, 1, or 2 (default: 1)")),
+			mcp.WithBoolean("skip_validate", mcp.Description("Skip syntax validation before write (default: false)")),
+			mcp.WithString("target", mcp.Description("Connection alias (default: primary)")),
+		),
+		createApplyPatchHandler(pool),
+	)
+
 	// validate
 	s.AddTool(
 		mcp.NewTool("validate",
@@ -93,11 +128,20 @@ Supported formats:
   .xml, .svg, .xhtml       — XML well-formedness
   .ini, .cfg, .conf        — INI key=value structure
   .env                     — Dotenv KEY=VALUE format
-  Dockerfile               — Instruction validation
+  Dockerfile               — Instruction validation + hadolint-style rules (DL3006, DL3007, DL3008/9, DL3020, DL3025, DL4000, DL3059)
+  sshd_config              — OpenSSH server config keyword/value/Match grammar
+  ssh_config               — OpenSSH client config keyword/value/Host grammar
+  nginx.conf               — Brace/statement structure + server/location nesting
+  Caddyfile                — Brace structure, matcher (@name) syntax, import references
+  *.service, *.timer, ...  — systemd unit [Section] headers + required directives per unit type
+  crontab                  — 5-field schedule syntax (ranges, steps, lists, @keywords)
+
+JSON/YAML/TOML that parse cleanly are additionally checked against a matching schema, if one is registered (bundled schemas cover package.json, tsconfig.json, docker-compose.yml, GitHub Actions workflows; a YAML file can also pin its own via a "# yaml-language-server: $schema=..." comment).
 
 All validation runs on the MCP server using Go parsers. No python3, jq, or other tools needed on the remote host.`),
 			mcp.WithString("path", mcp.Required(), mcp.Description("File path to validate")),
-			mcp.WithString("type", mcp.Description("Force file type: json, yaml, toml, xml, ini, env, dockerfile (auto-detected from extension if omitted)")),
+			mcp.WithString("type", mcp.Description("Force file type: json, yaml, toml, xml, ini, env, dockerfile, sshd_config, ssh_config, nginx, caddyfile, systemd, crontab. Omit or pass \"auto\" to detect from the file's content and name (shebang/directive sniffing, then extension).")),
+			mcp.WithString("format", mcp.Description("Output format: text (default, human-readable), json (structured, for editor/CI tooling), or sarif (SARIF 2.1.0, for GitHub code scanning)")),
 			mcp.WithString("target", mcp.Description("Connection alias (default: primary)")),
 		),
 		createValidateHandler(pool),
@@ -113,17 +157,81 @@ All validation runs on the MCP server using Go parsers. No python3, jq, or other
 		createListDirHandler(pool),
 	)
 
+	// ssh_read_chunk
+	s.AddTool(
+		mcp.NewTool("ssh_read_chunk",
+			mcp.WithDescription("Read a bounded byte range of a remote file without loading the whole file into memory. Use to iterate through large files/logs in pieces."),
+			mcp.WithString("path", mcp.Required(), mcp.Description("File path to read")),
+			mcp.WithNumber("offset", mcp.Description("Byte offset to start reading from (default: 0)")),
+			mcp.WithNumber("length", mcp.Description("Number of bytes to read (default: 65536)")),
+			mcp.WithString("target", mcp.Description("Connection alias (default: primary)")),
+		),
+		createReadChunkHandler(pool),
+	)
+
+	// ssh_write_chunk
+	s.AddTool(
+		mcp.NewTool("ssh_write_chunk",
+			mcp.WithDescription(`Write a remote file in bounded chunks without buffering the whole payload in memory.
+
+Two modes:
+  offset-based — pass 'offset' to write this chunk at an exact byte position (no token needed).
+  resumable    — omit 'offset' on the first call to start a new upload; the response includes a
+                 'token' to pass on subsequent calls so each chunk appends where the last one left
+                 off, even if an earlier call had to be retried. Pass final=true on the last chunk
+                 to release the upload's resumable state.`),
+			mcp.WithString("path", mcp.Required(), mcp.Description("File path to write")),
+			mcp.WithString("content", mcp.Required(), mcp.Description("Chunk content to write")),
+			mcp.WithNumber("offset", mcp.Description("Exact byte offset to write at (offset-based mode)")),
+			mcp.WithString("token", mcp.Description("Resumable upload token from a previous ssh_write_chunk call")),
+			mcp.WithBoolean("final", mcp.Description("Set true on the last chunk of a resumable upload to release its token (default: false)")),
+			mcp.WithString("target", mcp.Description("Connection alias (default: primary)")),
+		),
+		createWriteChunkHandler(pool),
+	)
+
 	// sync
 	s.AddTool(
 		mcp.NewTool("sync",
-			mcp.WithDescription("Stream a file directly between two remote nodes"),
+			mcp.WithDescription("Stream a file directly between two remote nodes via chunked SFTP reads/writes, without buffering the whole file in memory"),
 			mcp.WithString("source_node", mcp.Required(), mcp.Description("Source connection alias")),
 			mcp.WithString("source_path", mcp.Required(), mcp.Description("Source file path")),
 			mcp.WithString("dest_node", mcp.Required(), mcp.Description("Destination connection alias")),
 			mcp.WithString("dest_path", mcp.Required(), mcp.Description("Destination file path")),
+			mcp.WithNumber("chunk_size", mcp.Description("Bytes read/written per chunk (default: 262144)")),
+			mcp.WithBoolean("resume", mcp.Description("Resume a partial transfer by seeking past bytes already written to the destination's temp file (default: false)")),
+			mcp.WithString("mode", mcp.Description("'full' to stream every byte, or 'delta' to transfer only the blocks that differ from the destination's current content (default: full)")),
 		),
 		createSyncHandler(pool),
 	)
+
+	// upload
+	s.AddTool(
+		mcp.NewTool("upload",
+			mcp.WithDescription("Upload a local file or directory to the remote host via SFTP. Glob metacharacters in local_path are expanded locally; set recursive=true to upload a directory tree."),
+			mcp.WithString("local_path", mcp.Required(), mcp.Description("Local path (or glob) to upload")),
+			mcp.WithString("remote_path", mcp.Required(), mcp.Description("Destination path on the remote host")),
+			mcp.WithBoolean("recursive", mcp.Description("Upload directories recursively (default: false)")),
+			mcp.WithBoolean("resume", mcp.Description("Resume a partial transfer by seeking past bytes already present at the destination (default: false)")),
+			mcp.WithBoolean("preserve", mcp.Description("Preserve source file modes and modification times (default: false)")),
+			mcp.WithString("target", mcp.Description("Connection alias (default: primary)")),
+		),
+		createUploadHandler(pool),
+	)
+
+	// download
+	s.AddTool(
+		mcp.NewTool("download",
+			mcp.WithDescription("Download a remote file or directory to the local filesystem via SFTP. Glob metacharacters in remote_path are expanded remotely; set recursive=true to download a directory tree."),
+			mcp.WithString("remote_path", mcp.Required(), mcp.Description("Remote path (or glob) to download")),
+			mcp.WithString("local_path", mcp.Required(), mcp.Description("Destination path on the local filesystem")),
+			mcp.WithBoolean("recursive", mcp.Description("Download directories recursively (default: false)")),
+			mcp.WithBoolean("resume", mcp.Description("Resume a partial transfer by seeking past bytes already present at the destination (default: false)")),
+			mcp.WithBoolean("preserve", mcp.Description("Preserve source file modes and modification times (default: false)")),
+			mcp.WithString("target", mcp.Description("Connection alias (default: primary)")),
+		),
+		createDownloadHandler(pool),
+	)
 }
 
 func createReadHandler(pool *ssh.Pool) server.ToolHandlerFunc {
@@ -162,7 +270,7 @@ func createWriteHandler(pool *ssh.Pool) server.ToolHandlerFunc {
 		if !skipValidate {
 			fileType := detectFileType(path)
 			if fileType != "" {
-				result := ValidateContent(content, fileType)
+				result := ValidateContent(path, content, fileType)
 				if result != nil && !result.Valid {
 					return mcp.NewToolResultError(fmt.Sprintf(
 						"Syntax validation failed — file NOT written.\n%s\n\nFix the errors above or set skip_validate=true to force write.",
@@ -190,6 +298,119 @@ func createWriteHandler(pool *ssh.Pool) server.ToolHandlerFunc {
 	}
 }
 
+// buildEditCommand builds the sed command for one of edit's built-in
+// operations. It's parameterized over getStr/getInt/getBool rather than
+// taking an mcp.CallToolRequest directly so createEditTransactionHandler can
+// reuse it against a single operation's params pulled out of the
+// "operations" array, instead of the top-level request.
+func buildEditCommand(path, operation string, getStr func(key, def string) string, getInt func(key string, def int) int, getBool func(key string, def bool) bool) (string, error) {
+	switch operation {
+	case "replace":
+		oldText := getStr("old_text", "")
+		newText := getStr("new_text", "")
+		if oldText == "" {
+			return "", fmt.Errorf("'old_text' is required for replace operation")
+		}
+		// Use sed with literal string replacement via escaped special chars
+		// We pipe through sed to handle special characters properly
+		globalFlag := ""
+		if getBool("global", false) {
+			globalFlag = "g"
+		}
+		return fmt.Sprintf("sed -i 's/%s/%s/%s' %s 2>&1",
+			sedEscapeLiteral(oldText), sedEscapeReplacement(newText), globalFlag, shellQuote(path)), nil
+
+	case "regex":
+		pattern := getStr("pattern", "")
+		replacement := getStr("replacement", "")
+		if pattern == "" {
+			return "", fmt.Errorf("'pattern' is required for regex operation")
+		}
+		globalFlag := "g" // regex defaults to global
+		if !getBool("global", true) {
+			globalFlag = ""
+		}
+		return fmt.Sprintf("sed -i -E 's/%s/%s/%s' %s 2>&1",
+			sedEscapePattern(pattern), sedEscapeReplacement(replacement), globalFlag, shellQuote(path)), nil
+
+	case "insert":
+		lineNum := getInt("line", 0)
+		content := getStr("content", "")
+		if lineNum <= 0 {
+			return "", fmt.Errorf("'line' (positive integer) is required for insert operation")
+		}
+		if content == "" {
+			return "", fmt.Errorf("'content' is required for insert operation")
+		}
+		return fmt.Sprintf("sed -i '%di\\%s' %s 2>&1",
+			lineNum, sedEscapeInsertText(content), shellQuote(path)), nil
+
+	case "append":
+		content := getStr("content", "")
+		pattern := getStr("pattern", "")
+		if content == "" {
+			return "", fmt.Errorf("'content' is required for append operation")
+		}
+		if pattern != "" {
+			// Append after line matching pattern
+			return fmt.Sprintf("sed -i '/%s/a\\%s' %s 2>&1",
+				sedEscapePattern(pattern), sedEscapeInsertText(content), shellQuote(path)), nil
+		}
+		// Append at end of file
+		return fmt.Sprintf("printf '\\n%%s' %s >> %s 2>&1",
+			shellQuote(content), shellQuote(path)), nil
+
+	case "prepend":
+		content := getStr("content", "")
+		pattern := getStr("pattern", "")
+		if content == "" {
+			return "", fmt.Errorf("'content' is required for prepend operation")
+		}
+		if pattern != "" {
+			// Insert before line matching pattern
+			return fmt.Sprintf("sed -i '/%s/i\\%s' %s 2>&1",
+				sedEscapePattern(pattern), sedEscapeInsertText(content), shellQuote(path)), nil
+		}
+		// Prepend at start of file
+		return fmt.Sprintf("sed -i '1i\\%s' %s 2>&1",
+			sedEscapeInsertText(content), shellQuote(path)), nil
+
+	case "delete":
+		pattern := getStr("pattern", "")
+		startLine := getInt("start_line", 0)
+		endLine := getInt("end_line", 0)
+
+		if pattern != "" {
+			// Delete lines matching pattern
+			return fmt.Sprintf("sed -i '/%s/d' %s 2>&1",
+				sedEscapePattern(pattern), shellQuote(path)), nil
+		}
+		if startLine > 0 && endLine > 0 {
+			// Delete line range
+			return fmt.Sprintf("sed -i '%d,%dd' %s 2>&1",
+				startLine, endLine, shellQuote(path)), nil
+		}
+		if startLine > 0 {
+			// Delete single line
+			return fmt.Sprintf("sed -i '%dd' %s 2>&1",
+				startLine, shellQuote(path)), nil
+		}
+		return "", fmt.Errorf("'pattern' or 'start_line' is required for delete operation")
+
+	case "replace_line":
+		pattern := getStr("pattern", "")
+		content := getStr("content", "")
+		if pattern == "" {
+			return "", fmt.Errorf("'pattern' is required for replace_line operation")
+		}
+		return fmt.Sprintf("sed -i -E 's/%s/%s/' %s 2>&1",
+			sedEscapePattern(pattern), sedEscapeReplacement(content), shellQuote(path)), nil
+
+	default:
+		return "", fmt.Errorf("unknown operation: '%s'. Supported: replace, regex, insert, append, prepend, delete, replace_line", operation)
+	}
+}
+
 func createEditHandler(pool *ssh.Pool) server.ToolHandlerFunc {
 	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		mgr := getManager(ctx, pool)
@@ -201,116 +422,31 @@ func createEditHandler(pool *ssh.Pool) server.ToolHandlerFunc {
 		operation := req.GetString("operation", "replace")
 		target := req.GetString("target", "primary")
 
-		// Build a sed command based on the operation type.
-		// We use sed for maximum compatibility with any file type on any remote system.
-		var cmd string
-
-		switch operation {
-		case "replace":
-			oldText := req.GetString("old_text", "")
-			newText := req.GetString("new_text", "")
-			if oldText == "" {
-				return mcp.NewToolResultError("'old_text' is required for replace operation"), nil
-			}
-			// Use sed with literal string replacement via escaped special chars
-			// We pipe through sed to handle special characters properly
-			globalFlag := ""
-			if req.GetBool("global", false) {
-				globalFlag = "g"
-			}
-			cmd = fmt.Sprintf("sed -i 's/%s/%s/%s' %s 2>&1",
-				sedEscapeLiteral(oldText), sedEscapeReplacement(newText), globalFlag, shellQuote(path))
-
-		case "regex":
-			pattern := req.GetString("pattern", "")
-			replacement := req.GetString("replacement", "")
-			if pattern == "" {
-				return mcp.NewToolResultError("'pattern' is required for regex operation"), nil
-			}
-			globalFlag := "g" // regex defaults to global
-			if !req.GetBool("global", true) {
-				globalFlag = ""
-			}
-			cmd = fmt.Sprintf("sed -i -E 's/%s/%s/%s' %s 2>&1",
-				sedEscapePattern(pattern), sedEscapeReplacement(replacement), globalFlag, shellQuote(path))
-
-		case "insert":
-			lineNum := req.GetInt("line", 0)
-			content := req.GetString("content", "")
-			if lineNum <= 0 {
-				return mcp.NewToolResultError("'line' (positive integer) is required for insert operation"), nil
-			}
-			if content == "" {
-				return mcp.NewToolResultError("'content' is required for insert operation"), nil
-			}
-			cmd = fmt.Sprintf("sed -i '%di\\%s' %s 2>&1",
-				lineNum, sedEscapeInsertText(content), shellQuote(path))
-
-		case "append":
-			content := req.GetString("content", "")
-			pattern := req.GetString("pattern", "")
-			if content == "" {
-				return mcp.NewToolResultError("'content' is required for append operation"), nil
-			}
-			if pattern != "" {
-				// Append after line matching pattern
-				cmd = fmt.Sprintf("sed -i '/%s/a\\%s' %s 2>&1",
-					sedEscapePattern(pattern), sedEscapeInsertText(content), shellQuote(path))
-			} else {
-				// Append at end of file
-				cmd = fmt.Sprintf("printf '\\n%%s' %s >> %s 2>&1",
-					shellQuote(content), shellQuote(path))
-			}
-
-		case "prepend":
-			content := req.GetString("content", "")
-			pattern := req.GetString("pattern", "")
-			if content == "" {
-				return mcp.NewToolResultError("'content' is required for prepend operation"), nil
-			}
-			if pattern != "" {
-				// Insert before line matching pattern
-				cmd = fmt.Sprintf("sed -i '/%s/i\\%s' %s 2>&1",
-					sedEscapePattern(pattern), sedEscapeInsertText(content), shellQuote(path))
-			} else {
-				// Prepend at start of file
-				cmd = fmt.Sprintf("sed -i '1i\\%s' %s 2>&1",
-					sedEscapeInsertText(content), shellQuote(path))
-			}
-
-		case "delete":
-			pattern := req.GetString("pattern", "")
-			startLine := req.GetInt("start_line", 0)
-			endLine := req.GetInt("end_line", 0)
-
-			if pattern != "" {
-				// Delete lines matching pattern
-				cmd = fmt.Sprintf("sed -i '/%s/d' %s 2>&1",
-					sedEscapePattern(pattern), shellQuote(path))
-			} else if startLine > 0 && endLine > 0 {
-				// Delete line range
-				cmd = fmt.Sprintf("sed -i '%d,%dd' %s 2>&1",
-					startLine, endLine, shellQuote(path))
-			} else if startLine > 0 {
-				// Delete single line
-				cmd = fmt.Sprintf("sed -i '%dd' %s 2>&1",
-					startLine, shellQuote(path))
-			} else {
-				return mcp.NewToolResultError("'pattern' or 'start_line' is required for delete operation"), nil
+		// A plugin-registered operation takes over the whole call: there's
+		// no sed command to build, and the plugin talks back to the remote
+		// file itself via HostOps.
+		if op := plugin.Default().EditOp(operation); op != nil {
+			params := make(map[string]string)
+			for k, v := range req.GetArguments() {
+				if k == "path" || k == "operation" || k == "target" {
+					continue
+				}
+				params[k] = fmt.Sprintf("%v", v)
 			}
 
-		case "replace_line":
-			pattern := req.GetString("pattern", "")
-			content := req.GetString("content", "")
-			if pattern == "" {
-				return mcp.NewToolResultError("'pattern' is required for replace_line operation"), nil
+			out, err := op.Apply(path, target, params, managerHostOps{ctx: ctx, mgr: mgr})
+			if err != nil {
+				log.Printf("[Tool:edit] plugin operation %q error: %v", operation, err)
+				return mcp.NewToolResultError(err.Error()), nil
 			}
-			cmd = fmt.Sprintf("sed -i -E 's/%s/%s/' %s 2>&1",
-				sedEscapePattern(pattern), sedEscapeReplacement(content), shellQuote(path))
+			return mcp.NewToolResultText(out), nil
+		}
 
-		default:
-			return mcp.NewToolResultError(fmt.Sprintf(
-				"Unknown operation: '%s'. Supported: replace, regex, insert, append, prepend, delete, replace_line", operation)), nil
+		// Build a sed command based on the operation type.
+		// We use sed for maximum compatibility with any file type on any remote system.
+		cmd, err := buildEditCommand(path, operation, req.GetString, req.GetInt, req.GetBool)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
 		}
 
 		log.Printf("[Tool:edit] %s on %s: %s", operation, path, cmd)
@@ -334,7 +470,7 @@ func createEditHandler(pool *ssh.Pool) server.ToolHandlerFunc {
 		if fileType != "" {
 			updated, readErr := mgr.ReadFile(ctx, path, target)
 			if readErr == nil {
-				result := ValidateContent(updated, fileType)
+				result := ValidateContent(path, updated, fileType)
 				if result != nil {
 					if result.Valid {
 						msg += fmt.Sprintf("\n✓ Syntax (%s): OK", fileType)
@@ -350,6 +486,348 @@ func createEditHandler(pool *ssh.Pool) server.ToolHandlerFunc {
 	}
 }
 
+// txnKey identifies one file touched by a transaction: the same path on two
+// different targets is a different file, so both must be part of the key.
+type txnKey struct {
+	target string
+	path   string
+}
+
+// txnOp is one parsed entry from edit_transaction's "operations" array.
+type txnOp struct {
+	target    string
+	path      string
+	operation string
+	params    map[string]interface{}
+}
+
+// parseTxnOps converts the raw "operations" argument into a []txnOp,
+// rejecting anything that isn't a list of objects with at least a path.
+func parseTxnOps(raw []interface{}) ([]txnOp, error) {
+	if len(raw) == 0 {
+		return nil, fmt.Errorf("'operations' must contain at least one operation")
+	}
+
+	ops := make([]txnOp, 0, len(raw))
+	for i, entry := range raw {
+		obj, ok := entry.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("operations[%d]: expected an object", i)
+		}
+
+		path, _ := obj["path"].(string)
+		if path == "" {
+			return nil, fmt.Errorf("operations[%d]: 'path' is required", i)
+		}
+
+		operation, _ := obj["operation"].(string)
+		if operation == "" {
+			operation = "replace"
+		}
+		target, _ := obj["target"].(string)
+		if target == "" {
+			target = "primary"
+		}
+
+		ops = append(ops, txnOp{target: target, path: path, operation: operation, params: obj})
+	}
+	return ops, nil
+}
+
+func paramString(params map[string]interface{}, key, def string) string {
+	if s, ok := params[key].(string); ok {
+		return s
+	}
+	return def
+}
+
+func paramInt(params map[string]interface{}, key string, def int) int {
+	switch n := params[key].(type) {
+	case float64:
+		return int(n)
+	case int:
+		return n
+	default:
+		return def
+	}
+}
+
+func paramBool(params map[string]interface{}, key string, def bool) bool {
+	if b, ok := params[key].(bool); ok {
+		return b
+	}
+	return def
+}
+
+func createEditTransactionHandler(pool *ssh.Pool) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		mgr := getManager(ctx, pool)
+		if mgr == nil {
+			return mcp.NewToolResultError("No active session"), nil
+		}
+
+		ops, err := parseTxnOps(getAnySlice(req, "operations"))
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		dryRun := req.GetBool("dry_run", false)
+
+		if dryRun {
+			return runEditTransactionDryRun(ctx, mgr, ops)
+		}
+		return runEditTransaction(ctx, mgr, ops)
+	}
+}
+
+// runEditTransaction snapshots every file the ops touch, applies each op in
+// order, re-validates every touched file, and restores every snapshot if
+// anything along the way failed.
+func runEditTransaction(ctx context.Context, mgr *ssh.Manager, ops []txnOp) (*mcp.CallToolResult, error) {
+	snapshots := make(map[txnKey]string)
+	var touchedOrder []txnKey
+
+	snapshot := func(key txnKey) error {
+		if _, ok := snapshots[key]; ok {
+			return nil
+		}
+		content, err := mgr.ReadFile(ctx, key.path, key.target)
+		if err != nil {
+			return fmt.Errorf("failed to snapshot %s on %s: %w", key.path, key.target, err)
+		}
+		snapshots[key] = content
+		touchedOrder = append(touchedOrder, key)
+		return nil
+	}
+
+	rollback := func() []string {
+		var reverted []string
+		for _, key := range touchedOrder {
+			if err := mgr.WriteFile(ctx, key.path, snapshots[key], key.target); err != nil {
+				log.Printf("[Tool:edit_transaction] failed to restore %s on %s: %v", key.path, key.target, err)
+				continue
+			}
+			reverted = append(reverted, fmt.Sprintf("%s:%s", key.target, key.path))
+		}
+		return reverted
+	}
+
+	for i, op := range ops {
+		key := txnKey{target: op.target, path: op.path}
+		if err := snapshot(key); err != nil {
+			reverted := rollback()
+			return mcp.NewToolResultError(fmt.Sprintf(
+				"Transaction aborted at step %d (%s on %s): %v\nReverted: %s",
+				i+1, op.operation, op.path, err, strings.Join(reverted, ", "))), nil
+		}
+
+		cmd, err := buildEditCommand(op.path, op.operation,
+			func(k, d string) string { return paramString(op.params, k, d) },
+			func(k string, d int) int { return paramInt(op.params, k, d) },
+			func(k string, d bool) bool { return paramBool(op.params, k, d) },
+		)
+		if err != nil {
+			reverted := rollback()
+			return mcp.NewToolResultError(fmt.Sprintf(
+				"Transaction aborted at step %d (%s on %s): %v\nReverted: %s",
+				i+1, op.operation, op.path, err, strings.Join(reverted, ", "))), nil
+		}
+
+		if _, err := mgr.Execute(ctx, cmd, op.target); err != nil {
+			reverted := rollback()
+			return mcp.NewToolResultError(fmt.Sprintf(
+				"Transaction aborted at step %d (%s on %s): %v\nReverted: %s",
+				i+1, op.operation, op.path, err, strings.Join(reverted, ", "))), nil
+		}
+	}
+
+	// Every op applied — now re-validate every touched file before
+	// committing to the transaction.
+	var brokenDetails []string
+	for _, key := range touchedOrder {
+		fileType := detectFileType(key.path)
+		if fileType == "" {
+			continue
+		}
+		updated, err := mgr.ReadFile(ctx, key.path, key.target)
+		if err != nil {
+			continue
+		}
+		result := ValidateContent(key.path, updated, fileType)
+		if result != nil && !result.Valid {
+			brokenDetails = append(brokenDetails, result.FormatResult(fmt.Sprintf("%s:%s", key.target, key.path)))
+		}
+	}
+
+	if len(brokenDetails) > 0 {
+		reverted := rollback()
+		return mcp.NewToolResultError(fmt.Sprintf(
+			"Transaction rolled back — %d file(s) failed post-edit validation:\n%s\nReverted: %s",
+			len(brokenDetails), strings.Join(brokenDetails, "\n"), strings.Join(reverted, ", "))), nil
+	}
+
+	var touched []string
+	for _, key := range touchedOrder {
+		touched = append(touched, fmt.Sprintf("%s:%s", key.target, key.path))
+	}
+	return mcp.NewToolResultText(fmt.Sprintf(
+		"Transaction committed — %d operation(s) applied across %d file(s): %s",
+		len(ops), len(touchedOrder), strings.Join(touched, ", "))), nil
+}
+
+// runEditTransactionDryRun applies the same operations against throwaway
+// /tmp copies of each touched file and reports a diff per file, without
+// ever writing to the real path.
+func runEditTransactionDryRun(ctx context.Context, mgr *ssh.Manager, ops []txnOp) (*mcp.CallToolResult, error) {
+	tmpPaths := make(map[txnKey]string)
+	var touchedOrder []txnKey
+
+	prepare := func(key txnKey) (string, error) {
+		if tmp, ok := tmpPaths[key]; ok {
+			return tmp, nil
+		}
+		content, err := mgr.ReadFile(ctx, key.path, key.target)
+		if err != nil {
+			return "", fmt.Errorf("failed to read %s on %s: %w", key.path, key.target, err)
+		}
+		tmp := fmt.Sprintf("/tmp/edit_txn_dryrun_%d_%s", len(touchedOrder), filepath.Base(key.path))
+		if err := mgr.WriteFile(ctx, tmp, content, key.target); err != nil {
+			return "", fmt.Errorf("failed to stage dry-run copy of %s on %s: %w", key.path, key.target, err)
+		}
+		tmpPaths[key] = tmp
+		touchedOrder = append(touchedOrder, key)
+		return tmp, nil
+	}
+
+	cleanup := func() {
+		for key, tmp := range tmpPaths {
+			if _, err := mgr.Execute(ctx, fmt.Sprintf("rm -f %s", shellQuote(tmp)), key.target); err != nil {
+				log.Printf("[Tool:edit_transaction] failed to clean up dry-run copy %s on %s: %v", tmp, key.target, err)
+			}
+		}
+	}
+	defer cleanup()
+
+	for i, op := range ops {
+		key := txnKey{target: op.target, path: op.path}
+		tmp, err := prepare(key)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Dry run failed at step %d (%s on %s): %v", i+1, op.operation, op.path, err)), nil
+		}
+
+		cmd, err := buildEditCommand(tmp, op.operation,
+			func(k, d string) string { return paramString(op.params, k, d) },
+			func(k string, d int) int { return paramInt(op.params, k, d) },
+			func(k string, d bool) bool { return paramBool(op.params, k, d) },
+		)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Dry run failed at step %d (%s on %s): %v", i+1, op.operation, op.path, err)), nil
+		}
+
+		if _, err := mgr.Execute(ctx, cmd, op.target); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Dry run failed at step %d (%s on %s): %v", i+1, op.operation, op.path, err)), nil
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("Dry run — %d operation(s) across %d file(s), originals untouched:\n\n", len(ops), len(touchedOrder)))
+	for _, key := range touchedOrder {
+		diffCmd := fmt.Sprintf("diff -u %s %s", shellQuote(key.path), shellQuote(tmpPaths[key]))
+		diff, err := mgr.Execute(ctx, diffCmd, key.target)
+		if err != nil && diff == "" {
+			diff = fmt.Sprintf("(failed to diff: %v)", err)
+		}
+		b.WriteString(fmt.Sprintf("--- %s:%s ---\n%s\n\n", key.target, key.path, diff))
+	}
+
+	return mcp.NewToolResultText(strings.TrimRight(b.String(), "\n")), nil
+}
+
+func createReadChunkHandler(pool *ssh.Pool) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		mgr := getManager(ctx, pool)
+		if mgr == nil {
+			return mcp.NewToolResultError("No active session"), nil
+		}
+
+		path, _ := req.RequireString("path")
+		offset := int64(req.GetInt("offset", 0))
+		length := int64(req.GetInt("length", 65536))
+		target := req.GetString("target", "primary")
+
+		chunk, err := mgr.ReadFileRange(path, offset, length, target)
+		if err != nil {
+			log.Printf("[Tool:ssh_read_chunk] Error: %v", err)
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		result := map[string]interface{}{
+			"content":     string(chunk),
+			"offset":      offset,
+			"bytes_read":  len(chunk),
+			"next_offset": offset + int64(len(chunk)),
+			"eof":         int64(len(chunk)) < length,
+		}
+		jsonBytes, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return mcp.NewToolResultError("Failed to format chunk result"), nil
+		}
+
+		return mcp.NewToolResultText(string(jsonBytes)), nil
+	}
+}
+
+func createWriteChunkHandler(pool *ssh.Pool) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		mgr := getManager(ctx, pool)
+		if mgr == nil {
+			return mcp.NewToolResultError("No active session"), nil
+		}
+
+		path, _ := req.RequireString("path")
+		content, _ := req.RequireString("content")
+		token := req.GetString("token", "")
+		final := req.GetBool("final", false)
+		target := req.GetString("target", "primary")
+
+		// Offset-based mode: exact byte position, no resumable state.
+		if token == "" && req.GetInt("offset", -1) >= 0 {
+			offset := int64(req.GetInt("offset", 0))
+			if err := mgr.WriteFileAt(path, offset, []byte(content), target); err != nil {
+				log.Printf("[Tool:ssh_write_chunk] Error: %v", err)
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			return mcp.NewToolResultText(fmt.Sprintf("Wrote %d bytes to %s at offset %d", len(content), path, offset)), nil
+		}
+
+		// Resumable mode: start a new upload if no token was passed in.
+		if token == "" {
+			var err error
+			token, err = mgr.BeginUpload(path, target)
+			if err != nil {
+				log.Printf("[Tool:ssh_write_chunk] Error: %v", err)
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+		}
+
+		nextOffset, err := mgr.WriteChunk(token, []byte(content), target)
+		if err != nil {
+			log.Printf("[Tool:ssh_write_chunk] Error: %v", err)
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		if final {
+			if err := mgr.EndUpload(token, target); err != nil {
+				log.Printf("[Tool:ssh_write_chunk] Error ending upload: %v", err)
+			}
+			return mcp.NewToolResultText(fmt.Sprintf("Wrote final chunk (%d bytes) to %s — upload complete, %d total bytes", len(content), path, nextOffset)), nil
+		}
+
+		return mcp.NewToolResultText(fmt.Sprintf(
+			"Wrote %d bytes to %s (token=%s, next_offset=%d). Pass this token on the next call to continue, or final=true to finish.",
+			len(content), path, token, nextOffset)), nil
+	}
+}
+
 func createListDirHandler(pool *ssh.Pool) server.ToolHandlerFunc {
 	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		mgr := getManager(ctx, pool)
@@ -386,17 +864,100 @@ func createSyncHandler(pool *ssh.Pool) server.ToolHandlerFunc {
 		sourcePath, _ := req.RequireString("source_path")
 		destNode, _ := req.RequireString("dest_node")
 		destPath, _ := req.RequireString("dest_path")
+		chunkSize := int64(req.GetInt("chunk_size", 256*1024))
+		if chunkSize <= 0 {
+			chunkSize = 256 * 1024
+		}
+		resume := req.GetBool("resume", false)
+		mode := req.GetString("mode", "full")
+
+		if mode == "delta" {
+			blocks := 0
+			onProgress := func(transferred, total int64) {
+				blocks++
+				log.Printf("[Tool:sync] delta %s:%s -> %s:%s: block %d, %d/%d bytes", sourceNode, sourcePath, destNode, destPath, blocks, transferred, total)
+			}
 
-		content, err := mgr.ReadFile(ctx, sourcePath, sourceNode)
+			transferred, err := mgr.SyncFileDelta(ctx, sourcePath, sourceNode, destPath, destNode, onProgress)
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Delta sync failed after %d bytes: %v", transferred, err)), nil
+			}
+
+			return mcp.NewToolResultText(fmt.Sprintf(
+				"Successfully delta-synced %d bytes from %s:%s to %s:%s in %d block(s)",
+				transferred, sourceNode, sourcePath, destNode, destPath, blocks)), nil
+		}
+
+		chunks := 0
+		onProgress := func(transferred, total int64) {
+			chunks++
+			log.Printf("[Tool:sync] %s:%s -> %s:%s: chunk %d, %d/%d bytes", sourceNode, sourcePath, destNode, destPath, chunks, transferred, total)
+		}
+
+		transferred, err := mgr.SyncFile(ctx, sourcePath, sourceNode, destPath, destNode, chunkSize, resume, onProgress)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to read from source: %v", err)), nil
+			return mcp.NewToolResultError(fmt.Sprintf("Sync failed after %d bytes: %v", transferred, err)), nil
+		}
+
+		return mcp.NewToolResultText(fmt.Sprintf(
+			"Successfully synced %d bytes from %s:%s to %s:%s in %d chunk(s) of up to %d bytes",
+			transferred, sourceNode, sourcePath, destNode, destPath, chunks, chunkSize)), nil
+	}
+}
+
+func createUploadHandler(pool *ssh.Pool) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		mgr := getManager(ctx, pool)
+		if mgr == nil {
+			return mcp.NewToolResultError("No active session"), nil
+		}
+
+		localPath, _ := req.RequireString("local_path")
+		remotePath, _ := req.RequireString("remote_path")
+		target := req.GetString("target", "primary")
+		preserve := req.GetBool("preserve", false)
+
+		opts := ssh.TransferOptions{
+			Recursive:     req.GetBool("recursive", false),
+			Resume:        req.GetBool("resume", false),
+			PreserveMode:  preserve,
+			PreserveTimes: preserve,
 		}
 
-		if err := mgr.WriteFile(ctx, destPath, content, destNode); err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to write to destination: %v", err)), nil
+		if err := mgr.Put(localPath, remotePath, target, opts); err != nil {
+			log.Printf("[Tool:upload] Error: %v", err)
+			return mcp.NewToolResultError(err.Error()), nil
 		}
 
-		return mcp.NewToolResultText(fmt.Sprintf("Successfully synced %d bytes from %s to %s", len(content), sourceNode, destNode)), nil
+		return mcp.NewToolResultText(fmt.Sprintf("Successfully uploaded %s to %s", localPath, remotePath)), nil
+	}
+}
+
+func createDownloadHandler(pool *ssh.Pool) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		mgr := getManager(ctx, pool)
+		if mgr == nil {
+			return mcp.NewToolResultError("No active session"), nil
+		}
+
+		remotePath, _ := req.RequireString("remote_path")
+		localPath, _ := req.RequireString("local_path")
+		target := req.GetString("target", "primary")
+		preserve := req.GetBool("preserve", false)
+
+		opts := ssh.TransferOptions{
+			Recursive:     req.GetBool("recursive", false),
+			Resume:        req.GetBool("resume", false),
+			PreserveMode:  preserve,
+			PreserveTimes: preserve,
+		}
+
+		if err := mgr.Get(remotePath, localPath, target, opts); err != nil {
+			log.Printf("[Tool:download] Error: %v", err)
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		return mcp.NewToolResultText(fmt.Sprintf("Successfully downloaded %s to %s", remotePath, localPath)), nil
 	}
 }
 
@@ -410,14 +971,9 @@ func createValidateHandler(pool *ssh.Pool) server.ToolHandlerFunc {
 		path, _ := req.RequireString("path")
 		forceType := req.GetString("type", "")
 		target := req.GetString("target", "primary")
-
-		fileType := forceType
-		if fileType == "" {
-			fileType = detectFileType(path)
-		}
-		if fileType == "" {
-			return mcp.NewToolResultError(fmt.Sprintf(
-				"Cannot detect file type for '%s'. Use the 'type' parameter to specify: json, yaml, toml, xml, ini, env, dockerfile", path)), nil
+		format := req.GetString("format", "text")
+		if format != "text" && format != "json" && format != "sarif" {
+			return mcp.NewToolResultError(fmt.Sprintf("'format' must be text, json, or sarif (got %q)", format)), nil
 		}
 
 		// Read file content via SFTP
@@ -427,14 +983,36 @@ func createValidateHandler(pool *ssh.Pool) server.ToolHandlerFunc {
 			return mcp.NewToolResultError(err.Error()), nil
 		}
 
-		// Validate server-side with Go parsers
-		result := ValidateContent(content, fileType)
+		// Validate server-side with Go parsers. An empty or "auto"
+		// forceType lets ValidateContent fall back to content-based
+		// detection (DetectFileType); an unrecognized one yields no result.
+		// A plugin validator gets the last word for file types the
+		// built-ins still don't know.
+		fileType := forceType
+		result := ValidateContent(path, content, fileType)
 		if result == nil {
-			return mcp.NewToolResultError(fmt.Sprintf("No server-side validator for type '%s'", fileType)), nil
+			if fileType == "" {
+				fileType = detectFileType(path)
+			}
+			if pr := plugin.Default().Validate(content, fileType); pr != nil {
+				result = &ValidationResult{Valid: pr.Valid, FileType: pr.FileType, Errors: pluginValidationErrors(pr.Errors)}
+			}
+		}
+		if result == nil {
+			return mcp.NewToolResultError(fmt.Sprintf(
+				"Cannot detect file type for '%s'. Use the 'type' parameter to specify: json, yaml, toml, xml, ini, env, dockerfile, sshd_config, ssh_config, nginx, caddyfile, systemd, crontab", path)), nil
 		}
+		fileType = result.FileType
 
 		log.Printf("[Tool:validate] %s %s: valid=%v", fileType, path, result.Valid)
-		return mcp.NewToolResultText(result.FormatResult(path)), nil
+		switch format {
+		case "json":
+			return mcp.NewToolResultText(result.FormatJSON()), nil
+		case "sarif":
+			return mcp.NewToolResultText(result.FormatSARIF(path)), nil
+		default:
+			return mcp.NewToolResultText(result.FormatResult(path)), nil
+		}
 	}
 }
 
@@ -445,6 +1023,13 @@ var fileTypePatterns = []struct {
 	pattern  string // matched against lowercase basename
 	fileType string
 }{
+	// Name-based patterns checked before the extension patterns; nginx.conf
+	// would otherwise be shadowed by the generic "*.conf" pattern below,
+	// since the first match wins.
+	{"nginx.conf", "nginx"},
+	{"caddyfile", "caddyfile"},
+	{"crontab", "crontab"},
+
 	// Extension-based patterns
 	{"*.json", "json"},
 	{"*.yaml", "yaml"},
@@ -460,13 +1045,30 @@ var fileTypePatterns = []struct {
 	{"*.cfg", "ini"},
 	{"*.conf", "ini"},
 	{"*.env", "env"},
-
-	// Name-based patterns (Dockerfile variants, dotenv)
+	{"*.cron", "crontab"},
+	{"*.service", "systemd"},
+	{"*.socket", "systemd"},
+	{"*.timer", "systemd"},
+	{"*.mount", "systemd"},
+	{"*.automount", "systemd"},
+	{"*.path", "systemd"},
+	{"*.target", "systemd"},
+	{"*.slice", "systemd"},
+	{"*.device", "systemd"},
+	{"*.swap", "systemd"},
+
+	// Name-based patterns (Dockerfile variants, dotenv, OpenSSH configs)
 	{"dockerfile*", "dockerfile"},
 	{".env*", "env"},
+	{"sshd_config", "sshd_config"},
+	{"ssh_config", "ssh_config"},
 }
 
 func detectFileType(path string) string {
+	if ft := plugin.Default().DetectFileType(path); ft != "" {
+		return ft
+	}
+
 	lower := strings.ToLower(path)
 
 	// Extract basename
@@ -485,4 +1087,23 @@ func detectFileType(path string) string {
 	return ""
 }
 
+// managerHostOps adapts an *ssh.Manager plus a fixed context into the
+// plugin.HostOps interface, so an EditOp plugin can read, write, and run
+// commands against the live SSH session during a single edit call without
+// ever holding a reference to the manager itself.
+type managerHostOps struct {
+	ctx context.Context
+	mgr *ssh.Manager
+}
+
+func (h managerHostOps) ReadFile(path, target string) (string, error) {
+	return h.mgr.ReadFile(h.ctx, path, target)
+}
+
+func (h managerHostOps) WriteFile(path, content, target string) error {
+	return h.mgr.WriteFile(h.ctx, path, content, target)
+}
 
+func (h managerHostOps) Execute(cmd, target string) (string, error) {
+	return h.mgr.Execute(h.ctx, cmd, target)
+}
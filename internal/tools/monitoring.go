@@ -2,8 +2,14 @@ package tools
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"log"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	"ssh-mcp/internal/ssh"
 
@@ -11,12 +17,22 @@ import (
 	"github.com/mark3labs/mcp-go/server"
 )
 
+// formatDesc is the shared description for the "format" parameter accepted
+// by every monitoring tool.
+const formatDesc = "Output format: text (default), json, prometheus, or influx"
+
+// followDesc/durationDesc are the shared descriptions for the streaming
+// parameters accepted by logs, journal_read, and dmesg_read.
+const followDesc = "Stream new lines as they appear instead of returning a single snapshot (default: false)"
+const durationDesc = "How long to stream for when follow is set, in seconds (default: 60, max: 600)"
+
 // registerMonitoringTools registers monitoring and diagnostics tools.
 func registerMonitoringTools(s *server.MCPServer, pool *ssh.Pool) {
 	// usage
 	s.AddTool(
 		mcp.NewTool("usage",
 			mcp.WithDescription("Get CPU/RAM/Disk usage summary"),
+			mcp.WithString("format", mcp.Description(formatDesc)),
 			mcp.WithString("target", mcp.Description("Connection alias (default: primary)")),
 		),
 		createUsageHandler(pool),
@@ -28,6 +44,7 @@ func registerMonitoringTools(s *server.MCPServer, pool *ssh.Pool) {
 			mcp.WithDescription("List top processes sorted by CPU or memory"),
 			mcp.WithString("sort_by", mcp.Description("Sort by 'cpu' or 'mem' (default: cpu)")),
 			mcp.WithNumber("limit", mcp.Description("Number of processes to show (default: 10)")),
+			mcp.WithString("format", mcp.Description(formatDesc)),
 			mcp.WithString("target", mcp.Description("Connection alias (default: primary)")),
 		),
 		createPsHandler(pool),
@@ -39,7 +56,10 @@ func registerMonitoringTools(s *server.MCPServer, pool *ssh.Pool) {
 			mcp.WithDescription("Read the tail of a log file"),
 			mcp.WithString("path", mcp.Required(), mcp.Description("Path to log file")),
 			mcp.WithNumber("lines", mcp.Description("Number of lines to read (default: 50, max: 500)")),
-			mcp.WithString("grep", mcp.Description("Optional filter pattern")),
+			mcp.WithString("grep", mcp.Description("Optional filter pattern (regex in follow mode)")),
+			mcp.WithBoolean("follow", mcp.Description(followDesc)),
+			mcp.WithNumber("duration_seconds", mcp.Description(durationDesc)),
+			mcp.WithString("format", mcp.Description(formatDesc)),
 			mcp.WithString("target", mcp.Description("Connection alias (default: primary)")),
 		),
 		createLogsHandler(pool),
@@ -49,10 +69,20 @@ func registerMonitoringTools(s *server.MCPServer, pool *ssh.Pool) {
 	s.AddTool(
 		mcp.NewTool("journal_read",
 			mcp.WithDescription("Read system logs (journalctl/syslog)"),
-			mcp.WithString("service", mcp.Description("Service name to filter (e.g., nginx, sshd)")),
+			mcp.WithString("service", mcp.Description("Service name to filter (e.g., nginx, sshd); alias for unit")),
+			mcp.WithString("unit", mcp.Description("Systemd unit to filter (-u); alias for service")),
+			mcp.WithString("identifier", mcp.Description("Syslog identifier to filter (-t), e.g. sshd, sudo")),
+			mcp.WithBoolean("boot", mcp.Description("Only show entries from the current boot (-b)")),
 			mcp.WithString("since", mcp.Description("Time filter (e.g., '1 hour ago')")),
 			mcp.WithNumber("lines", mcp.Description("Number of lines (default: 100, max: 500)")),
 			mcp.WithString("priority", mcp.Description("Log priority: emerg, alert, crit, err, warning, notice, info, debug")),
+			mcp.WithString("grep", mcp.Description("Optional pattern filter (journalctl -g outside follow mode, regex applied client-side in follow mode)")),
+			mcp.WithString("cursor", mcp.Description("Journal cursor to start reading from (--cursor), as returned by a previous call's \"cursor\" field")),
+			mcp.WithString("after_cursor", mcp.Description("Journal cursor to resume after (--after-cursor), excluding that entry itself")),
+			mcp.WithString("output", mcp.Description("Output mode: text (default), json, or json-pretty - json modes return parsed entries plus a resumable cursor")),
+			mcp.WithBoolean("follow", mcp.Description(followDesc)),
+			mcp.WithNumber("duration_seconds", mcp.Description(durationDesc)),
+			mcp.WithString("format", mcp.Description(formatDesc)),
 			mcp.WithString("target", mcp.Description("Connection alias (default: primary)")),
 		),
 		createJournalReadHandler(pool),
@@ -62,8 +92,11 @@ func registerMonitoringTools(s *server.MCPServer, pool *ssh.Pool) {
 	s.AddTool(
 		mcp.NewTool("dmesg_read",
 			mcp.WithDescription("Read kernel ring buffer (dmesg)"),
-			mcp.WithString("grep", mcp.Description("Optional pattern to filter messages")),
+			mcp.WithString("grep", mcp.Description("Optional pattern to filter messages (regex in follow mode)")),
 			mcp.WithNumber("lines", mcp.Description("Number of lines (default: 100)")),
+			mcp.WithBoolean("follow", mcp.Description(followDesc)),
+			mcp.WithNumber("duration_seconds", mcp.Description(durationDesc)),
+			mcp.WithString("format", mcp.Description(formatDesc)),
 			mcp.WithString("target", mcp.Description("Connection alias (default: primary)")),
 		),
 		createDmesgReadHandler(pool),
@@ -73,22 +106,209 @@ func registerMonitoringTools(s *server.MCPServer, pool *ssh.Pool) {
 	s.AddTool(
 		mcp.NewTool("diagnose_system",
 			mcp.WithDescription("One-click SRE health check: load, OOM, disk, failed services"),
+			mcp.WithString("format", mcp.Description(formatDesc)),
 			mcp.WithString("target", mcp.Description("Connection alias (default: primary)")),
 		),
 		createDiagnoseHandler(pool),
 	)
 
+	// diagnose_configure
+	s.AddTool(
+		mcp.NewTool("diagnose_configure",
+			mcp.WithDescription("Set per-target alert thresholds used by diagnose_system's structured output"),
+			mcp.WithNumber("load_ratio", mcp.Description("Load1/CPU ratio above which the load check fires (default: 2.0)")),
+			mcp.WithNumber("mem_pct", mcp.Description("Memory used %% above which the mem check fires (default: 90)")),
+			mcp.WithNumber("disk_pct", mcp.Description("Disk used %% above which the disk check fires (default: 90)")),
+			mcp.WithNumber("oom_window", mcp.Description("OOM event count above which the oom check fires (default: 0)")),
+			mcp.WithArray("failed_allowlist", mcp.Description("Service names to exclude from the failed_services check")),
+			mcp.WithString("severity", mcp.Description("Severity reported for any breach on this target: warn or crit (default: warn)")),
+			mcp.WithString("target", mcp.Description("Connection alias these thresholds apply to (default: primary)")),
+		),
+		createDiagnoseConfigureHandler(),
+	)
+
 	// list_services
 	s.AddTool(
 		mcp.NewTool("list_services",
 			mcp.WithDescription("List system services (systemd/OpenRC)"),
 			mcp.WithBoolean("failed_only", mcp.Description("Show only failed services")),
+			mcp.WithString("format", mcp.Description(formatDesc)),
 			mcp.WithString("target", mcp.Description("Connection alias (default: primary)")),
 		),
 		createListServicesHandler(pool),
 	)
 }
 
+// metricFormat is the structured-output mode shared by every monitoring
+// tool's "format" parameter.
+type metricFormat string
+
+const (
+	metricFormatText       metricFormat = "text"
+	metricFormatJSON       metricFormat = "json"
+	metricFormatPrometheus metricFormat = "prometheus"
+	metricFormatInflux     metricFormat = "influx"
+)
+
+// parseMetricFormat reads and validates the "format" parameter.
+func parseMetricFormat(req mcp.CallToolRequest) (metricFormat, error) {
+	f := metricFormat(req.GetString("format", string(metricFormatText)))
+	switch f {
+	case metricFormatText, metricFormatJSON, metricFormatPrometheus, metricFormatInflux:
+		return f, nil
+	default:
+		return "", fmt.Errorf("unknown format %q: must be text, json, prometheus, or influx", f)
+	}
+}
+
+// streamFollow runs cmd (already wrapped in "timeout <duration>" by the
+// caller) over mgr.RunStream, relaying matching lines to the MCP client as
+// "notifications/message" and returning the full captured text once the
+// command exits or the stream idles out. grepPattern, if non-empty, filters
+// lines server-side before they're forwarded or collected, matching
+// createDockerLogsFollowHandler's streaming convention in docker.go.
+func streamFollow(ctx context.Context, mgr *ssh.Manager, cmd, target, toolName, grepPattern string, durationSec int) (*mcp.CallToolResult, error) {
+	var re *regexp.Regexp
+	if grepPattern != "" {
+		var err error
+		re, err = regexp.Compile(grepPattern)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("invalid grep pattern: %v", err)), nil
+		}
+	}
+
+	const maxBytes = 1 << 20
+	idleTimeout := 30 * time.Second
+	maxDuration := time.Duration(durationSec)*time.Second + idleTimeout
+
+	log.Printf("[Tool:%s] Streaming: %s (target=%s)", toolName, cmd, target)
+
+	cctx, cancel := context.WithTimeout(ctx, maxDuration)
+	defer cancel()
+
+	srv := server.ServerFromContext(ctx)
+	filter := &lineFilterer{re: re}
+
+	output, _, truncated, err := streamCapped(cctx, mgr, cmd, target, maxBytes, idleTimeout,
+		func(data []byte) []byte { return filter.feed(data) },
+		func(chunk []byte) {
+			if srv == nil {
+				return
+			}
+			if notifyErr := srv.SendNotificationToClient(ctx, "notifications/message", map[string]any{
+				"tool": toolName,
+				"line": string(chunk),
+			}); notifyErr != nil {
+				log.Printf("[Tool:%s] Failed to send notification: %v", toolName, notifyErr)
+			}
+		},
+	)
+	if tail := filter.flush(); len(tail) > 0 {
+		output += string(tail)
+	}
+	if err != nil && !truncated {
+		log.Printf("[Tool:%s] Error: %v", toolName, err)
+	}
+	if truncated {
+		output += fmt.Sprintf("\n... [output truncated at %d bytes]", maxBytes)
+	}
+
+	log.Printf("[Tool:%s] Done (%d bytes output)", toolName, len(output))
+	return mcp.NewToolResultText(output), nil
+}
+
+// metricRecord is one structured record emitted by a monitoring tool, e.g.
+// one process row for `ps` or the single summary row for `usage`. String
+// values become Prometheus labels / Influx tags; numeric values become the
+// Prometheus metric value / Influx fields.
+type metricRecord map[string]interface{}
+
+// renderMetrics renders measurement (named records, e.g. "usage" or "ps")
+// scoped to host in the requested format. text/json are handled by callers
+// directly since their shape is tool-specific; renderMetrics only handles
+// the two wire metrics formats.
+func renderMetrics(format metricFormat, measurement, host string, records []metricRecord) (string, error) {
+	var b strings.Builder
+	for _, rec := range records {
+		tags := map[string]string{"host": host}
+		fields := map[string]float64{}
+		for k, v := range rec {
+			switch val := v.(type) {
+			case string:
+				tags[k] = val
+			case float64:
+				fields[k] = val
+			case int:
+				fields[k] = float64(val)
+			case int64:
+				fields[k] = float64(val)
+			}
+		}
+
+		switch format {
+		case metricFormatPrometheus:
+			labels := renderPrometheusLabels(tags)
+			for _, k := range sortedFloatKeys(fields) {
+				fmt.Fprintf(&b, "%s_%s{%s} %v\n", measurement, k, labels, fields[k])
+			}
+		case metricFormatInflux:
+			var tagStr strings.Builder
+			for _, k := range sortedStringKeys(tags) {
+				fmt.Fprintf(&tagStr, ",%s=%s", k, influxEscape(tags[k]))
+			}
+			fieldParts := make([]string, 0, len(fields))
+			for _, k := range sortedFloatKeys(fields) {
+				fieldParts = append(fieldParts, fmt.Sprintf("%s=%v", k, fields[k]))
+			}
+			if len(fieldParts) == 0 {
+				continue
+			}
+			fmt.Fprintf(&b, "%s%s %s %d\n", measurement, tagStr.String(), strings.Join(fieldParts, ","), time.Now().UnixNano())
+		default:
+			return "", fmt.Errorf("renderMetrics called with non-wire format %q", format)
+		}
+	}
+	return b.String(), nil
+}
+
+// renderPrometheusLabels renders a tag set as a sorted "k=\"v\",k2=\"v2\"" label body.
+func renderPrometheusLabels(tags map[string]string) string {
+	parts := make([]string, 0, len(tags))
+	for _, k := range sortedStringKeys(tags) {
+		parts = append(parts, fmt.Sprintf("%s=%q", k, tags[k]))
+	}
+	return strings.Join(parts, ",")
+}
+
+// influxEscape escapes commas, spaces, and equals signs in an Influx line
+// protocol tag value.
+func influxEscape(s string) string {
+	replacer := strings.NewReplacer(",", `\,`, " ", `\ `, "=", `\=`)
+	return replacer.Replace(s)
+}
+
+// sortedStringKeys returns m's keys sorted, so rendered label/tag order is
+// stable across calls.
+func sortedStringKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// sortedFloatKeys returns m's keys sorted, so rendered metric/field order is
+// stable across calls.
+func sortedFloatKeys(m map[string]float64) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
 func createUsageHandler(pool *ssh.Pool) server.ToolHandlerFunc {
 	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		mgr := getManager(ctx, pool)
@@ -96,9 +316,14 @@ func createUsageHandler(pool *ssh.Pool) server.ToolHandlerFunc {
 			return mcp.NewToolResultError("No active session"), nil
 		}
 
+		format, err := parseMetricFormat(req)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
 		target := req.GetString("target", "primary")
 
-		cmd := `
+		if format == metricFormatText {
+			cmd := `
 echo "=== LOAD AVERAGE ==="
 cat /proc/loadavg 2>/dev/null
 
@@ -109,14 +334,81 @@ free -h 2>/dev/null || cat /proc/meminfo 2>/dev/null | head -5
 echo ""
 echo "=== DISK ==="
 df -h / 2>/dev/null
+`
+			output, err := mgr.Execute(ctx, cmd, target)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			return mcp.NewToolResultText(output), nil
+		}
+
+		cmd := `
+cat /proc/loadavg 2>/dev/null
+echo "---"
+free -b 2>/dev/null | awk '/^Mem:/ {print $2, $3, $4}'
+echo "---"
+df -P / 2>/dev/null | awk 'NR==2 {gsub("%","",$5); print $5}'
 `
 		output, err := mgr.Execute(ctx, cmd, target)
 		if err != nil {
 			return mcp.NewToolResultError(err.Error()), nil
 		}
 
-		return mcp.NewToolResultText(output), nil
+		rec, parseErr := parseUsageOutput(output)
+		if parseErr != nil {
+			log.Printf("[Tool:usage] %v, returning raw output", parseErr)
+			return mcp.NewToolResultText(output), nil
+		}
+
+		return renderSingleRecord(format, "usage", target, rec)
+	}
+}
+
+// parseUsageOutput parses the "---"-separated loadavg/free/df section output
+// produced for structured usage requests into one record: load1/load5/
+// load15, mem_total/mem_used/mem_free in bytes, and disk_used_pct for /.
+func parseUsageOutput(output string) (metricRecord, error) {
+	sections := strings.Split(output, "---")
+	if len(sections) != 3 {
+		return nil, fmt.Errorf("failed to parse usage output: expected 3 sections, got %d", len(sections))
+	}
+
+	loadFields := strings.Fields(sections[0])
+	if len(loadFields) < 3 {
+		return nil, fmt.Errorf("failed to parse loadavg: %q", sections[0])
 	}
+	load1, err1 := strconv.ParseFloat(loadFields[0], 64)
+	load5, err2 := strconv.ParseFloat(loadFields[1], 64)
+	load15, err3 := strconv.ParseFloat(loadFields[2], 64)
+	if err1 != nil || err2 != nil || err3 != nil {
+		return nil, fmt.Errorf("failed to parse loadavg values: %q", sections[0])
+	}
+
+	memFields := strings.Fields(sections[1])
+	if len(memFields) < 3 {
+		return nil, fmt.Errorf("failed to parse memory: %q", sections[1])
+	}
+	memTotal, err1 := strconv.ParseFloat(memFields[0], 64)
+	memUsed, err2 := strconv.ParseFloat(memFields[1], 64)
+	memFree, err3 := strconv.ParseFloat(memFields[2], 64)
+	if err1 != nil || err2 != nil || err3 != nil {
+		return nil, fmt.Errorf("failed to parse memory values: %q", sections[1])
+	}
+
+	diskPct, err := strconv.ParseFloat(strings.TrimSpace(sections[2]), 64)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse disk usage: %q", sections[2])
+	}
+
+	return metricRecord{
+		"load1":         load1,
+		"load5":         load5,
+		"load15":        load15,
+		"mem_total":     memTotal,
+		"mem_used":      memUsed,
+		"mem_free":      memFree,
+		"disk_used_pct": diskPct,
+	}, nil
 }
 
 func createPsHandler(pool *ssh.Pool) server.ToolHandlerFunc {
@@ -126,6 +418,10 @@ func createPsHandler(pool *ssh.Pool) server.ToolHandlerFunc {
 			return mcp.NewToolResultError("No active session"), nil
 		}
 
+		format, err := parseMetricFormat(req)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
 		sortBy := req.GetString("sort_by", "cpu")
 		limit := req.GetInt("limit", 10)
 		target := req.GetString("target", "primary")
@@ -145,10 +441,57 @@ func createPsHandler(pool *ssh.Pool) server.ToolHandlerFunc {
 			return mcp.NewToolResultError(err.Error()), nil
 		}
 
-		return mcp.NewToolResultText(output), nil
+		if format == metricFormatText {
+			return mcp.NewToolResultText(output), nil
+		}
+
+		records, parseErr := parsePsOutput(output)
+		if parseErr != nil {
+			log.Printf("[Tool:ps] %v, returning raw output", parseErr)
+			return mcp.NewToolResultText(output), nil
+		}
+
+		return renderRecords(format, "ps", target, records)
 	}
 }
 
+// parsePsOutput parses "ps -eo pid,user,%cpu,%mem,comm" output (header plus
+// one row per process) into per-process records.
+func parsePsOutput(output string) ([]metricRecord, error) {
+	lines := strings.Split(strings.TrimRight(output, "\n"), "\n")
+	if len(lines) < 1 {
+		return nil, fmt.Errorf("empty ps output")
+	}
+
+	records := make([]metricRecord, 0, len(lines)-1)
+	for _, line := range lines[1:] {
+		fields := strings.Fields(line)
+		if len(fields) < 5 {
+			continue
+		}
+		pid, err := strconv.ParseInt(fields[0], 10, 64)
+		if err != nil {
+			continue
+		}
+		cpu, err := strconv.ParseFloat(fields[2], 64)
+		if err != nil {
+			continue
+		}
+		mem, err := strconv.ParseFloat(fields[3], 64)
+		if err != nil {
+			continue
+		}
+		records = append(records, metricRecord{
+			"pid":  pid,
+			"user": fields[1],
+			"cpu":  cpu,
+			"mem":  mem,
+			"comm": strings.Join(fields[4:], " "),
+		})
+	}
+	return records, nil
+}
+
 func createLogsHandler(pool *ssh.Pool) server.ToolHandlerFunc {
 	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		mgr := getManager(ctx, pool)
@@ -156,14 +499,28 @@ func createLogsHandler(pool *ssh.Pool) server.ToolHandlerFunc {
 			return mcp.NewToolResultError("No active session"), nil
 		}
 
+		format, err := parseMetricFormat(req)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
 		path, _ := req.RequireString("path")
 		lines := req.GetInt("lines", 50)
 		grep := req.GetString("grep", "")
+		follow := req.GetBool("follow", false)
+		duration := req.GetInt("duration_seconds", 60)
 		target := req.GetString("target", "primary")
 
 		if lines > 500 {
 			lines = 500
 		}
+		if duration > 600 {
+			duration = 600
+		}
+
+		if follow {
+			cmd := fmt.Sprintf("timeout %d tail -F -n %d %s 2>&1", duration, lines, shellQuote(path))
+			return streamFollow(ctx, mgr, cmd, target, "logs", grep, duration)
+		}
 
 		cmd := fmt.Sprintf("tail -n %d %s", lines, shellQuote(path))
 		if grep != "" {
@@ -175,7 +532,10 @@ func createLogsHandler(pool *ssh.Pool) server.ToolHandlerFunc {
 			return mcp.NewToolResultError(err.Error()), nil
 		}
 
-		return mcp.NewToolResultText(output), nil
+		if format == metricFormatText {
+			return mcp.NewToolResultText(output), nil
+		}
+		return renderLineCount(format, "logs", target, output)
 	}
 }
 
@@ -186,15 +546,34 @@ func createJournalReadHandler(pool *ssh.Pool) server.ToolHandlerFunc {
 			return mcp.NewToolResultError("No active session"), nil
 		}
 
+		format, err := parseMetricFormat(req)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
 		service := req.GetString("service", "")
+		unit := req.GetString("unit", service)
+		identifier := req.GetString("identifier", "")
+		boot := req.GetBool("boot", false)
 		since := req.GetString("since", "")
 		lines := req.GetInt("lines", 100)
 		priority := req.GetString("priority", "")
+		grep := req.GetString("grep", "")
+		cursor := req.GetString("cursor", "")
+		afterCursor := req.GetString("after_cursor", "")
+		output := req.GetString("output", "text")
+		follow := req.GetBool("follow", false)
+		duration := req.GetInt("duration_seconds", 60)
 		target := req.GetString("target", "primary")
 
+		if output != "text" && output != "json" && output != "json-pretty" {
+			return mcp.NewToolResultError(fmt.Sprintf("invalid output %q: must be text, json, or json-pretty", output)), nil
+		}
 		if lines > 500 {
 			lines = 500
 		}
+		if duration > 600 {
+			duration = 600
+		}
 
 		checkCmd := "command -v journalctl >/dev/null 2>&1 && echo 'systemd' || echo 'syslog'"
 		checkOutput, err := mgr.Execute(ctx, checkCmd, target)
@@ -202,11 +581,43 @@ func createJournalReadHandler(pool *ssh.Pool) server.ToolHandlerFunc {
 			return mcp.NewToolResultError(err.Error()), nil
 		}
 
+		if follow {
+			if !containsString(checkOutput, "systemd") {
+				return mcp.NewToolResultError("follow mode requires journalctl (systemd)"), nil
+			}
+			cmd := fmt.Sprintf("timeout %d journalctl --no-pager -f", duration)
+			if unit != "" {
+				cmd += fmt.Sprintf(" -u %s", shellQuote(unit))
+			}
+			if identifier != "" {
+				cmd += fmt.Sprintf(" -t %s", shellQuote(identifier))
+			}
+			if priority != "" {
+				cmd += fmt.Sprintf(" -p %s", shellQuote(priority))
+			}
+			cmd += " 2>&1"
+			return streamFollow(ctx, mgr, cmd, target, "journal_read", grep, duration)
+		}
+
+		if output != "text" && !containsString(checkOutput, "systemd") {
+			return mcp.NewToolResultError("json/json-pretty output requires journalctl (systemd)"), nil
+		}
+
 		var cmd string
 		if containsString(checkOutput, "systemd") {
-			cmd = "journalctl --no-pager"
-			if service != "" {
-				cmd += fmt.Sprintf(" -u %s", shellQuote(service))
+			if output == "text" {
+				cmd = "journalctl --no-pager"
+			} else {
+				cmd = fmt.Sprintf("journalctl --no-pager -o %s", output)
+			}
+			if unit != "" {
+				cmd += fmt.Sprintf(" -u %s", shellQuote(unit))
+			}
+			if identifier != "" {
+				cmd += fmt.Sprintf(" -t %s", shellQuote(identifier))
+			}
+			if boot {
+				cmd += " -b"
 			}
 			if since != "" {
 				cmd += fmt.Sprintf(" --since %s", shellQuote(since))
@@ -214,21 +625,74 @@ func createJournalReadHandler(pool *ssh.Pool) server.ToolHandlerFunc {
 			if priority != "" {
 				cmd += fmt.Sprintf(" -p %s", shellQuote(priority))
 			}
+			if grep != "" {
+				cmd += fmt.Sprintf(" -g %s", shellQuote(grep))
+			}
+			if cursor != "" {
+				cmd += fmt.Sprintf(" --cursor %s", shellQuote(cursor))
+			}
+			if afterCursor != "" {
+				cmd += fmt.Sprintf(" --after-cursor %s", shellQuote(afterCursor))
+			}
 			cmd += fmt.Sprintf(" -n %d 2>/dev/null", lines)
 		} else {
 			cmd = fmt.Sprintf("cat /var/log/syslog /var/log/messages 2>/dev/null | tail -n %d", lines)
-			if service != "" {
-				cmd += fmt.Sprintf(" | grep -i %s", shellQuote(service))
+			if unit != "" {
+				cmd += fmt.Sprintf(" | grep -i %s", shellQuote(unit))
 			}
 		}
 
-		output, err := mgr.Execute(ctx, cmd, target)
+		rawOutput, err := mgr.Execute(ctx, cmd, target)
 		if err != nil {
 			return mcp.NewToolResultError(err.Error()), nil
 		}
 
-		return mcp.NewToolResultText(output), nil
+		if output != "text" {
+			entries, lastCursor, parseErr := parseJournalEntries(rawOutput)
+			if parseErr != nil {
+				log.Printf("[Tool:journal_read] %v, returning raw output", parseErr)
+				return mcp.NewToolResultText(rawOutput), nil
+			}
+			jsonBytes, err := jsonMarshalIndent(map[string]interface{}{
+				"host":    target,
+				"cursor":  lastCursor,
+				"entries": entries,
+			})
+			if err != nil {
+				return mcp.NewToolResultError("Failed to format journal entries"), nil
+			}
+			return mcp.NewToolResultText(jsonBytes), nil
+		}
+
+		if format == metricFormatText {
+			return mcp.NewToolResultText(rawOutput), nil
+		}
+		return renderLineCount(format, "journal", target, rawOutput)
+	}
+}
+
+// parseJournalEntries decodes the concatenated JSON objects produced by
+// `journalctl -o json`/`-o json-pretty` (one object per entry, pretty or
+// not) into a slice of raw entry fields, and returns the last entry's
+// "__CURSOR" so the caller can resume with after_cursor.
+func parseJournalEntries(output string) ([]map[string]interface{}, string, error) {
+	entries := []map[string]interface{}{}
+	decoder := json.NewDecoder(strings.NewReader(output))
+	for decoder.More() {
+		var entry map[string]interface{}
+		if err := decoder.Decode(&entry); err != nil {
+			return nil, "", fmt.Errorf("failed to parse journal entry: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+
+	lastCursor := ""
+	if len(entries) > 0 {
+		if c, ok := entries[len(entries)-1]["__CURSOR"].(string); ok {
+			lastCursor = c
+		}
 	}
+	return entries, lastCursor, nil
 }
 
 func createDmesgReadHandler(pool *ssh.Pool) server.ToolHandlerFunc {
@@ -238,13 +702,27 @@ func createDmesgReadHandler(pool *ssh.Pool) server.ToolHandlerFunc {
 			return mcp.NewToolResultError("No active session"), nil
 		}
 
+		format, err := parseMetricFormat(req)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
 		grep := req.GetString("grep", "")
 		lines := req.GetInt("lines", 100)
+		follow := req.GetBool("follow", false)
+		duration := req.GetInt("duration_seconds", 60)
 		target := req.GetString("target", "primary")
 
 		if lines > 500 {
 			lines = 500
 		}
+		if duration > 600 {
+			duration = 600
+		}
+
+		if follow {
+			cmd := fmt.Sprintf("timeout %d dmesg -w --time-format iso 2>&1 || timeout %d dmesg -w 2>&1", duration, duration)
+			return streamFollow(ctx, mgr, cmd, target, "dmesg_read", grep, duration)
+		}
 
 		cmd := "dmesg --time-format iso 2>/dev/null || dmesg 2>/dev/null"
 		if grep != "" {
@@ -257,8 +735,40 @@ func createDmesgReadHandler(pool *ssh.Pool) server.ToolHandlerFunc {
 			return mcp.NewToolResultError(err.Error()), nil
 		}
 
-		return mcp.NewToolResultText(output), nil
+		if format == metricFormatText {
+			return mcp.NewToolResultText(output), nil
+		}
+		return renderLineCount(format, "dmesg", target, output)
+	}
+}
+
+// renderLineCount renders a simple line-count metric for the freeform log
+// tools (logs/journal_read/dmesg_read), where the text itself has no stable
+// machine-parsable schema but a count is still a useful scrape target.
+func renderLineCount(format metricFormat, measurement, host, output string) (*mcp.CallToolResult, error) {
+	trimmed := strings.TrimSpace(output)
+	count := 0
+	if trimmed != "" {
+		count = len(strings.Split(trimmed, "\n"))
+	}
+
+	if format == metricFormatJSON {
+		jsonBytes, err := jsonMarshalIndent(map[string]interface{}{
+			"host":  host,
+			"lines": count,
+			"text":  output,
+		})
+		if err != nil {
+			return mcp.NewToolResultError("Failed to format output"), nil
+		}
+		return mcp.NewToolResultText(jsonBytes), nil
+	}
+
+	rendered, err := renderMetrics(format, measurement, host, []metricRecord{{"lines": int64(count)}})
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
 	}
+	return mcp.NewToolResultText(rendered), nil
 }
 
 func createDiagnoseHandler(pool *ssh.Pool) server.ToolHandlerFunc {
@@ -268,9 +778,14 @@ func createDiagnoseHandler(pool *ssh.Pool) server.ToolHandlerFunc {
 			return mcp.NewToolResultError("No active session"), nil
 		}
 
+		format, err := parseMetricFormat(req)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
 		target := req.GetString("target", "primary")
 
-		cmd := `
+		if format == metricFormatText {
+			cmd := `
 echo "=== SYSTEM HEALTH DIAGNOSTIC ==="
 echo ""
 
@@ -320,6 +835,22 @@ fi
 
 echo ""
 echo "=== END DIAGNOSTIC ==="
+`
+			output, err := mgr.Execute(ctx, cmd, target)
+			if err != nil {
+				log.Printf("[Tool:diagnose] Error: %v", err)
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			return mcp.NewToolResultText(output), nil
+		}
+
+		cmd := `
+echo "LOAD1=$(awk '{print $1}' /proc/loadavg 2>/dev/null)"
+echo "CPUS=$(nproc 2>/dev/null || echo 1)"
+echo "MEM_PCT=$(free 2>/dev/null | awk '/^Mem:/ {printf "%.0f", $3/$2*100}')"
+echo "OOM_COUNT=$(dmesg 2>/dev/null | grep -ci 'out of memory')"
+echo "DISK_MAX_PCT=$(df -P 2>/dev/null | awk 'NR>1 {gsub("%","",$5); if ($5+0>max) max=$5+0} END {print max+0}')"
+echo "FAILED_LIST=$(systemctl --failed --no-legend --no-pager 2>/dev/null | awk '{print $1}' | paste -sd, -)"
 `
 		output, err := mgr.Execute(ctx, cmd, target)
 		if err != nil {
@@ -327,7 +858,178 @@ echo "=== END DIAGNOSTIC ==="
 			return mcp.NewToolResultError(err.Error()), nil
 		}
 
-		return mcp.NewToolResultText(output), nil
+		checks, overall, parseErr := parseDiagnoseOutput(output, defaultDiagnoseRuleStore.get(target))
+		if parseErr != nil {
+			log.Printf("[Tool:diagnose] %v, returning raw output", parseErr)
+			return mcp.NewToolResultText(output), nil
+		}
+
+		if format == metricFormatJSON {
+			jsonBytes, err := jsonMarshalIndent(map[string]interface{}{
+				"host":    target,
+				"overall": overall,
+				"checks":  checks,
+			})
+			if err != nil {
+				return mcp.NewToolResultError("Failed to format health report"), nil
+			}
+			return mcp.NewToolResultText(jsonBytes), nil
+		}
+
+		records := make([]metricRecord, 0, len(checks))
+		for _, check := range checks {
+			breached := float64(0)
+			if check.Severity != "" {
+				breached = 1
+			}
+			records = append(records, metricRecord{
+				"check":  check.Name,
+				"status": breached,
+			})
+		}
+		rendered, err := renderMetrics(format, "diagnose", target, records)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		return mcp.NewToolResultText(rendered), nil
+	}
+}
+
+// diagnoseCheck is one rule evaluation result for the structured
+// diagnose_system verdict. Severity is empty when the check passed.
+type diagnoseCheck struct {
+	Name      string  `json:"name"`
+	Value     float64 `json:"value"`
+	Threshold float64 `json:"threshold"`
+	Severity  string  `json:"severity,omitempty"`
+	Message   string  `json:"message"`
+}
+
+// parseDiagnoseOutput parses the KEY=VALUE lines produced for structured
+// diagnose_system requests, evaluates them against thresholds (from
+// diagnose_configure, or defaultDiagnoseThresholds if the target has no
+// stored rules), and returns one diagnoseCheck per rule plus the overall
+// "ok"/"warn"/"crit" verdict.
+func parseDiagnoseOutput(output string, thresholds diagnoseThresholds) ([]diagnoseCheck, string, error) {
+	values := map[string]string{}
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		parts := strings.SplitN(line, "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		values[parts[0]] = parts[1]
+	}
+
+	required := []string{"LOAD1", "CPUS", "MEM_PCT", "OOM_COUNT", "DISK_MAX_PCT", "FAILED_LIST"}
+	for _, key := range required {
+		if _, ok := values[key]; !ok {
+			return nil, "", fmt.Errorf("failed to parse diagnose output: missing %s", key)
+		}
+	}
+
+	load1, err1 := strconv.ParseFloat(values["LOAD1"], 64)
+	cpus, err2 := strconv.ParseFloat(values["CPUS"], 64)
+	memPct, err3 := strconv.ParseFloat(values["MEM_PCT"], 64)
+	oomCount, err4 := strconv.Atoi(values["OOM_COUNT"])
+	diskMaxPct, err5 := strconv.ParseFloat(values["DISK_MAX_PCT"], 64)
+	if err1 != nil || err2 != nil || err3 != nil || err4 != nil || err5 != nil {
+		return nil, "", fmt.Errorf("failed to parse diagnose output values: %q", output)
+	}
+
+	var failed []string
+	for _, name := range strings.Split(values["FAILED_LIST"], ",") {
+		name = strings.TrimSpace(name)
+		if name == "" || sliceContains(thresholds.FailedAllowlist, name) {
+			continue
+		}
+		failed = append(failed, name)
+	}
+
+	loadThreshold := cpus * thresholds.LoadRatio
+	checks := []diagnoseCheck{
+		{Name: "load", Value: load1, Threshold: loadThreshold,
+			Message: fmt.Sprintf("load1=%.2f threshold=%.2f (cpus=%.0f x ratio=%.2f)", load1, loadThreshold, cpus, thresholds.LoadRatio)},
+		{Name: "mem", Value: memPct, Threshold: thresholds.MemPct,
+			Message: fmt.Sprintf("mem_used_pct=%.0f threshold=%.0f", memPct, thresholds.MemPct)},
+		{Name: "disk", Value: diskMaxPct, Threshold: thresholds.DiskPct,
+			Message: fmt.Sprintf("max_disk_used_pct=%.0f threshold=%.0f", diskMaxPct, thresholds.DiskPct)},
+		{Name: "oom", Value: float64(oomCount), Threshold: float64(thresholds.OOMWindow),
+			Message: fmt.Sprintf("oom_count=%d threshold=%d", oomCount, thresholds.OOMWindow)},
+		{Name: "failed_services", Value: float64(len(failed)), Threshold: 0,
+			Message: fmt.Sprintf("failed=%v allowlist=%v", failed, thresholds.FailedAllowlist)},
+	}
+
+	overall := "ok"
+	for i, breached := range []bool{
+		load1 > loadThreshold,
+		memPct > thresholds.MemPct,
+		diskMaxPct > thresholds.DiskPct,
+		oomCount > thresholds.OOMWindow,
+		len(failed) > 0,
+	} {
+		if !breached {
+			continue
+		}
+		checks[i].Severity = string(thresholds.Severity)
+		if thresholds.Severity == severityCrit {
+			overall = "crit"
+		} else if overall != "crit" {
+			overall = "warn"
+		}
+	}
+
+	return checks, overall, nil
+}
+
+// sliceContains reports whether list contains s exactly (containsString is
+// reserved for substring checks elsewhere in this package).
+func sliceContains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// createDiagnoseConfigureHandler stores per-target alert thresholds that
+// createDiagnoseHandler's structured output modes evaluate against. Unlike
+// the SSH connect handlers, this one needs no *ssh.Pool: it only touches
+// defaultDiagnoseRuleStore.
+func createDiagnoseConfigureHandler() server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		target := req.GetString("target", "primary")
+
+		severity := diagnoseSeverity(req.GetString("severity", string(severityWarn)))
+		if severity != severityWarn && severity != severityCrit {
+			return mcp.NewToolResultError(fmt.Sprintf("invalid severity %q: must be warn or crit", severity)), nil
+		}
+
+		thresholds := diagnoseThresholds{
+			LoadRatio:       req.GetFloat("load_ratio", defaultDiagnoseThresholds.LoadRatio),
+			MemPct:          req.GetFloat("mem_pct", defaultDiagnoseThresholds.MemPct),
+			DiskPct:         req.GetFloat("disk_pct", defaultDiagnoseThresholds.DiskPct),
+			OOMWindow:       req.GetInt("oom_window", defaultDiagnoseThresholds.OOMWindow),
+			FailedAllowlist: req.GetStringSlice("failed_allowlist", nil),
+			Severity:        severity,
+		}
+
+		if err := defaultDiagnoseRuleStore.set(target, thresholds); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		jsonBytes, err := jsonMarshalIndent(map[string]interface{}{
+			"target":     target,
+			"thresholds": thresholds,
+		})
+		if err != nil {
+			return mcp.NewToolResultError("Failed to format configured thresholds"), nil
+		}
+		return mcp.NewToolResultText(jsonBytes), nil
 	}
 }
 
@@ -338,14 +1040,32 @@ func createListServicesHandler(pool *ssh.Pool) server.ToolHandlerFunc {
 			return mcp.NewToolResultError("No active session"), nil
 		}
 
+		format, err := parseMetricFormat(req)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
 		failedOnly := req.GetBool("failed_only", false)
 		target := req.GetString("target", "primary")
 
+		if format == metricFormatText {
+			var cmd string
+			if failedOnly {
+				cmd = "systemctl --failed --no-pager 2>/dev/null || rc-status --crashed 2>/dev/null"
+			} else {
+				cmd = "systemctl list-units --type=service --no-pager 2>/dev/null | head -50 || rc-status 2>/dev/null"
+			}
+			output, err := mgr.Execute(ctx, cmd, target)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			return mcp.NewToolResultText(output), nil
+		}
+
 		var cmd string
 		if failedOnly {
-			cmd = "systemctl --failed --no-pager 2>/dev/null || rc-status --crashed 2>/dev/null"
+			cmd = "systemctl --failed --no-legend --no-pager 2>/dev/null || rc-status --crashed 2>/dev/null"
 		} else {
-			cmd = "systemctl list-units --type=service --no-pager 2>/dev/null | head -50 || rc-status 2>/dev/null"
+			cmd = "systemctl list-units --type=service --no-legend --no-pager 2>/dev/null | head -50 || rc-status 2>/dev/null"
 		}
 
 		output, err := mgr.Execute(ctx, cmd, target)
@@ -353,6 +1073,84 @@ func createListServicesHandler(pool *ssh.Pool) server.ToolHandlerFunc {
 			return mcp.NewToolResultError(err.Error()), nil
 		}
 
-		return mcp.NewToolResultText(output), nil
+		records, parseErr := parseServiceListOutput(output)
+		if parseErr != nil {
+			log.Printf("[Tool:list_services] %v, returning raw output", parseErr)
+			return mcp.NewToolResultText(output), nil
+		}
+
+		return renderRecords(format, "service", target, records)
+	}
+}
+
+// parseServiceListOutput parses "systemctl list-units/--failed
+// --no-legend" rows (UNIT LOAD ACTIVE SUB DESCRIPTION) into per-service
+// records.
+func parseServiceListOutput(output string) ([]metricRecord, error) {
+	var records []metricRecord
+	for _, line := range strings.Split(strings.TrimRight(output, "\n"), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) < 4 {
+			continue
+		}
+		desc := ""
+		if len(fields) > 4 {
+			desc = strings.Join(fields[4:], " ")
+		}
+		records = append(records, metricRecord{
+			"name":        fields[0],
+			"load":        fields[1],
+			"active":      fields[2],
+			"sub":         fields[3],
+			"description": desc,
+		})
+	}
+	return records, nil
+}
+
+// renderSingleRecord renders one record (e.g. usage's single summary row)
+// as json/prometheus/influx.
+func renderSingleRecord(format metricFormat, measurement, host string, rec metricRecord) (*mcp.CallToolResult, error) {
+	if format == metricFormatJSON {
+		out := map[string]interface{}{"host": host}
+		for k, v := range rec {
+			out[k] = v
+		}
+		jsonBytes, err := jsonMarshalIndent(out)
+		if err != nil {
+			return mcp.NewToolResultError("Failed to format output"), nil
+		}
+		return mcp.NewToolResultText(jsonBytes), nil
+	}
+
+	rendered, err := renderMetrics(format, measurement, host, []metricRecord{rec})
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	return mcp.NewToolResultText(rendered), nil
+}
+
+// renderRecords renders an array of records (e.g. ps's per-process rows) as
+// json/prometheus/influx.
+func renderRecords(format metricFormat, measurement, host string, records []metricRecord) (*mcp.CallToolResult, error) {
+	if format == metricFormatJSON {
+		jsonBytes, err := jsonMarshalIndent(map[string]interface{}{
+			"host":    host,
+			"records": records,
+		})
+		if err != nil {
+			return mcp.NewToolResultError("Failed to format output"), nil
+		}
+		return mcp.NewToolResultText(jsonBytes), nil
+	}
+
+	rendered, err := renderMetrics(format, measurement, host, records)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
 	}
+	return mcp.NewToolResultText(rendered), nil
 }
@@ -0,0 +1,285 @@
+// Package tools: patch.go implements apply_patch, a unified-diff based
+// alternative to the sed-oriented edit tool. Hunks are located by their
+// context lines with configurable fuzz rather than trusting the line
+// numbers in the patch, so a patch generated against a slightly different
+// revision of the file still applies cleanly.
+package tools
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"ssh-mcp/internal/ssh"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/sourcegraph/go-diff/diff"
+)
+
+// maxPatchFuzz bounds the 'fuzz' parameter: the number of leading/trailing
+// context lines a hunk is allowed to drop from matching before giving up.
+const maxPatchFuzz = 2
+
+// patchLine is one line from a hunk body, tagged with its diff marker.
+type patchLine struct {
+	kind byte // ' ' (context), '+' (added), '-' (removed)
+	text string
+}
+
+// parseHunkLines splits a Hunk's raw body into tagged lines, dropping the
+// "\ No newline at end of file" marker lines diff emits.
+func parseHunkLines(body []byte) []patchLine {
+	var lines []patchLine
+	for _, raw := range strings.Split(strings.TrimSuffix(string(body), "\n"), "\n") {
+		if raw == "" {
+			continue
+		}
+		if raw[0] == '\\' {
+			continue
+		}
+		lines = append(lines, patchLine{kind: raw[0], text: raw[1:]})
+	}
+	return lines
+}
+
+// trimContext drops up to `fuzz` context-only lines from each end of a
+// hunk's line list, mirroring how `patch`'s fuzz factor relaxes matching.
+// It refuses to trim a '+'/'-' line, so it only ever shrinks how much
+// surrounding context must agree.
+func trimContext(lines []patchLine, fuzz int) []patchLine {
+	start, end := 0, len(lines)
+	for i := 0; i < fuzz && start < end && lines[start].kind == ' '; i++ {
+		start++
+	}
+	for i := 0; i < fuzz && end > start && lines[end-1].kind == ' '; i++ {
+		end--
+	}
+	return lines[start:end]
+}
+
+// preAndPost splits a hunk's lines into the pre-image (context + removed,
+// i.e. what must already be in the file) and the post-image (context +
+// added, i.e. what should replace it).
+func preAndPost(lines []patchLine) (pre, post []string) {
+	for _, l := range lines {
+		switch l.kind {
+		case ' ':
+			pre = append(pre, l.text)
+			post = append(post, l.text)
+		case '-':
+			pre = append(pre, l.text)
+		case '+':
+			post = append(post, l.text)
+		}
+	}
+	return pre, post
+}
+
+// findSequence looks for `pattern` as a contiguous run inside `lines`,
+// preferring the occurrence nearest to `hint`. Returns -1 if not found.
+// An empty pattern matches at `hint` itself (a hunk that is pure additions
+// with no surviving context after trimming).
+func findSequence(lines []string, pattern []string, hint int) int {
+	if len(pattern) == 0 {
+		if hint < 0 {
+			hint = 0
+		}
+		if hint > len(lines) {
+			hint = len(lines)
+		}
+		return hint
+	}
+
+	best := -1
+	bestDist := -1
+	for i := 0; i+len(pattern) <= len(lines); i++ {
+		if !sliceEqual(lines[i:i+len(pattern)], pattern) {
+			continue
+		}
+		dist := i - hint
+		if dist < 0 {
+			dist = -dist
+		}
+		if best == -1 || dist < bestDist {
+			best, bestDist = i, dist
+		}
+	}
+	return best
+}
+
+func sliceEqual(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// hunkFailure describes a hunk that could not be located even at the
+// maximum allowed fuzz, for reporting back to the caller.
+type hunkFailure struct {
+	index   int
+	header  string
+	context []string
+}
+
+func (f hunkFailure) String() string {
+	return fmt.Sprintf("hunk #%d %s\ncontext:\n  %s",
+		f.index+1, f.header, strings.Join(f.context, "\n  "))
+}
+
+// applyHunks applies each hunk of a single file's diff against `lines` in
+// order, trying fuzz 0..maxFuzz for each before giving up on it. It returns
+// the patched lines, or the list of hunks that could not be located.
+func applyHunks(lines []string, hunks []*diff.Hunk, maxFuzz int) ([]string, []hunkFailure) {
+	var failures []hunkFailure
+	offset := 0
+
+	for i, h := range hunks {
+		all := parseHunkLines(h.Body)
+
+		added, removed := 0, 0
+		for _, l := range all {
+			switch l.kind {
+			case '+':
+				added++
+			case '-':
+				removed++
+			}
+		}
+
+		hint := int(h.OrigStartLine) - 1 + offset
+		applied := false
+
+		for fuzz := 0; fuzz <= maxFuzz; fuzz++ {
+			trimmed := trimContext(all, fuzz)
+			pre, post := preAndPost(trimmed)
+
+			idx := findSequence(lines, pre, hint)
+			if idx < 0 {
+				continue
+			}
+
+			next := make([]string, 0, len(lines)-len(pre)+len(post))
+			next = append(next, lines[:idx]...)
+			next = append(next, post...)
+			next = append(next, lines[idx+len(pre):]...)
+			lines = next
+			offset += added - removed
+			applied = true
+			break
+		}
+
+		if !applied {
+			pre, _ := preAndPost(all)
+			failures = append(failures, hunkFailure{
+				index:   i,
+				header:  fmt.Sprintf("@@ -%d,%d +%d,%d @@", h.OrigStartLine, h.OrigLines, h.NewStartLine, h.NewLines),
+				context: pre,
+			})
+		}
+	}
+
+	return lines, failures
+}
+
+func splitLines(content string) (lines []string, trailingNewline bool) {
+	if content == "" {
+		return nil, false
+	}
+	trailingNewline = strings.HasSuffix(content, "\n")
+	trimmed := strings.TrimSuffix(content, "\n")
+	return strings.Split(trimmed, "\n"), trailingNewline
+}
+
+func joinLines(lines []string, trailingNewline bool) string {
+	out := strings.Join(lines, "\n")
+	if trailingNewline && out != "" {
+		out += "\n"
+	}
+	return out
+}
+
+func createApplyPatchHandler(pool *ssh.Pool) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		mgr := getManager(ctx, pool)
+		if mgr == nil {
+			return mcp.NewToolResultError("No active session"), nil
+		}
+
+		path, _ := req.RequireString("path")
+		patchText, _ := req.RequireString("patch")
+		skipValidate := req.GetBool("skip_validate", false)
+		target := req.GetString("target", "primary")
+		fuzz := req.GetInt("fuzz", 1)
+		if fuzz < 0 || fuzz > maxPatchFuzz {
+			return mcp.NewToolResultError(fmt.Sprintf("'fuzz' must be between 0 and %d", maxPatchFuzz)), nil
+		}
+
+		fileDiffs, err := diff.ParseMultiFileDiff([]byte(patchText))
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to parse unified diff: %v", err)), nil
+		}
+		if len(fileDiffs) != 1 {
+			return mcp.NewToolResultError(fmt.Sprintf(
+				"apply_patch takes a single-file unified diff (got %d file(s) in 'patch'); split multi-file patches into one call per file", len(fileDiffs))), nil
+		}
+		fileDiff := fileDiffs[0]
+		if len(fileDiff.Hunks) == 0 {
+			return mcp.NewToolResultError("patch contains no hunks"), nil
+		}
+
+		content, err := mgr.ReadFile(ctx, path, target)
+		if err != nil {
+			log.Printf("[Tool:apply_patch] Error reading %s: %v", path, err)
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		lines, trailingNewline := splitLines(content)
+		patched, failures := applyHunks(lines, fileDiff.Hunks, fuzz)
+		if len(failures) > 0 {
+			var b strings.Builder
+			b.WriteString(fmt.Sprintf("Patch NOT applied — %d of %d hunk(s) could not be located at fuzz<=%d:\n\n",
+				len(failures), len(fileDiff.Hunks), fuzz))
+			for _, f := range failures {
+				b.WriteString(f.String())
+				b.WriteString("\n\n")
+			}
+			return mcp.NewToolResultError(strings.TrimRight(b.String(), "\n")), nil
+		}
+
+		newContent := joinLines(patched, trailingNewline)
+
+		if !skipValidate {
+			fileType := detectFileType(path)
+			if fileType != "" {
+				result := ValidateContent(path, newContent, fileType)
+				if result != nil && !result.Valid {
+					return mcp.NewToolResultError(fmt.Sprintf(
+						"Syntax validation failed — file NOT written.\n%s\n\nFix the patch or set skip_validate=true to force write.",
+						result.FormatResult(path))), nil
+				}
+			}
+		}
+
+		if err := mgr.WriteFile(ctx, path, newContent, target); err != nil {
+			log.Printf("[Tool:apply_patch] Error writing %s: %v", path, err)
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		msg := fmt.Sprintf("Successfully applied %d hunk(s) to %s", len(fileDiff.Hunks), path)
+		if !skipValidate {
+			fileType := detectFileType(path)
+			if fileType != "" {
+				msg += fmt.Sprintf("\n✓ Syntax (%s): OK", fileType)
+			}
+		}
+		return mcp.NewToolResultText(msg), nil
+	}
+}
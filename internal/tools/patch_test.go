@@ -0,0 +1,111 @@
+package tools
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/sourcegraph/go-diff/diff"
+)
+
+func mustParseHunks(t *testing.T, patch string) []*diff.Hunk {
+	t.Helper()
+	fds, err := diff.ParseMultiFileDiff([]byte(patch))
+	if err != nil {
+		t.Fatalf("ParseMultiFileDiff: %v", err)
+	}
+	if len(fds) != 1 {
+		t.Fatalf("expected 1 file diff, got %d", len(fds))
+	}
+	return fds[0].Hunks
+}
+
+func TestApplyHunksExactMatch(t *testing.T) {
+	patch := strings.Join([]string{
+		"--- a/file.txt",
+		"+++ b/file.txt",
+		"@@ -1,3 +1,3 @@",
+		" one",
+		"-two",
+		"+TWO",
+		" three",
+		"",
+	}, "\n")
+
+	lines := []string{"one", "two", "three"}
+	patched, failures := applyHunks(lines, mustParseHunks(t, patch), 1)
+	if len(failures) != 0 {
+		t.Fatalf("unexpected failures: %v", failures)
+	}
+	want := []string{"one", "TWO", "three"}
+	if !sliceEqual(patched, want) {
+		t.Errorf("applyHunks() = %v, want %v", patched, want)
+	}
+}
+
+func TestApplyHunksSurvivesDrift(t *testing.T) {
+	// Hunk line numbers claim the change is at line 1, but the real file
+	// has two unrelated lines prepended — context-based location should
+	// still find it.
+	patch := strings.Join([]string{
+		"--- a/file.txt",
+		"+++ b/file.txt",
+		"@@ -1,3 +1,3 @@",
+		" one",
+		"-two",
+		"+TWO",
+		" three",
+		"",
+	}, "\n")
+
+	lines := []string{"zero-a", "zero-b", "one", "two", "three"}
+	patched, failures := applyHunks(lines, mustParseHunks(t, patch), 1)
+	if len(failures) != 0 {
+		t.Fatalf("unexpected failures: %v", failures)
+	}
+	want := []string{"zero-a", "zero-b", "one", "TWO", "three"}
+	if !sliceEqual(patched, want) {
+		t.Errorf("applyHunks() = %v, want %v", patched, want)
+	}
+}
+
+func TestApplyHunksFailsWhenContextMissing(t *testing.T) {
+	patch := strings.Join([]string{
+		"--- a/file.txt",
+		"+++ b/file.txt",
+		"@@ -1,3 +1,3 @@",
+		" one",
+		"-two",
+		"+TWO",
+		" three",
+		"",
+	}, "\n")
+
+	lines := []string{"one", "completely different", "three"}
+	_, failures := applyHunks(lines, mustParseHunks(t, patch), 1)
+	if len(failures) != 1 {
+		t.Fatalf("expected 1 failure, got %d: %v", len(failures), failures)
+	}
+}
+
+func TestTrimContext(t *testing.T) {
+	lines := []string{"a", "b", "c"}
+	pl := []patchLine{
+		{kind: ' ', text: lines[0]},
+		{kind: '-', text: lines[1]},
+		{kind: '+', text: "B"},
+		{kind: ' ', text: lines[2]},
+	}
+
+	trimmed := trimContext(pl, 0)
+	if len(trimmed) != len(pl) {
+		t.Errorf("fuzz 0 should not trim, got %d lines", len(trimmed))
+	}
+
+	trimmed = trimContext(pl, 1)
+	if len(trimmed) != len(pl)-2 {
+		t.Errorf("fuzz 1 should trim one line off each end, got %d lines", len(trimmed))
+	}
+	if trimmed[0].kind == ' ' {
+		t.Errorf("leading context line should have been trimmed")
+	}
+}
@@ -2,6 +2,9 @@
 package tools
 
 import (
+	"log"
+
+	"ssh-mcp/internal/plugin"
 	"ssh-mcp/internal/ssh"
 
 	"github.com/mark3labs/mcp-go/server"
@@ -9,12 +12,22 @@ import (
 
 // RegisterAll registers all MCP tools.
 func RegisterAll(s *server.MCPServer, pool *ssh.Pool) {
+	if _, err := plugin.RegisterAll(""); err != nil {
+		log.Printf("[Plugin] failed to discover plugins: %v", err)
+	}
+
 	registerCoreTools(s, pool)
 	registerFileTools(s, pool)
 	registerMonitoringTools(s, pool)
 	registerDockerTools(s, pool)
+	registerContainerAliasTools(s, pool)
+	registerComposeTools(s, pool)
 	registerNetworkTools(s, pool)
 	registerDBTools(s, pool)
 	registerVoIPTools(s, pool)
+	registerTunnelTools(s, pool)
+	registerForwardTools(s, pool)
+	registerShellTools(s, pool)
+	registerShellAliasTools(s, pool)
 }
 
@@ -0,0 +1,246 @@
+// Package tools: schema.go adds optional JSON Schema (draft-07/2020-12)
+// validation on top of the plain syntax checks in validate.go. A document
+// that parses cleanly as JSON/YAML/TOML can additionally be checked
+// against a schema selected by filename glob or by its own
+// "# yaml-language-server: $schema=..." directive, via the bundled
+// defaults below or whatever RegisterSchema adds at startup.
+package tools
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/santhosh-tekuri/jsonschema/v6"
+	"gopkg.in/yaml.v3"
+
+	"github.com/BurntSushi/toml"
+)
+
+// Schema validates an already-decoded JSON-compatible value (the result
+// of jsonschema.UnmarshalJSON, or a YAML/TOML document round-tripped
+// through JSON) and reports failures as JSON-Pointer-tagged messages.
+type Schema interface {
+	ValidateValue(v interface{}) []string
+}
+
+// compiledSchema adapts a *jsonschema.Schema to Schema. The draft
+// (07 vs 2020-12) is selected automatically from the schema's own
+// "$schema" keyword.
+type compiledSchema struct {
+	sch *jsonschema.Schema
+}
+
+func (c *compiledSchema) ValidateValue(v interface{}) []string {
+	err := c.sch.Validate(v)
+	if err == nil {
+		return nil
+	}
+	ve, ok := err.(*jsonschema.ValidationError)
+	if !ok {
+		return []string{err.Error()}
+	}
+	return flattenSchemaErrors(ve.BasicOutput())
+}
+
+// flattenSchemaErrors walks a BasicOutput tree (already flat by
+// construction) and renders each leaf as "<json-pointer>: <message>".
+func flattenSchemaErrors(u *jsonschema.OutputUnit) []string {
+	var out []string
+	if u.Error != nil {
+		loc := u.InstanceLocation
+		if loc == "" {
+			loc = "/"
+		}
+		out = append(out, fmt.Sprintf("%s: %s", loc, u.Error.String()))
+	}
+	for i := range u.Errors {
+		out = append(out, flattenSchemaErrors(&u.Errors[i])...)
+	}
+	return out
+}
+
+// schemaEntry is one registered schema, matched either by a glob
+// against the file's path (see Lookup) or by
+// exact match against an inline "$schema" directive value.
+type schemaEntry struct {
+	key    string
+	schema Schema
+}
+
+// SchemaRegistry holds every schema ValidateContent can check parsed
+// JSON/YAML/TOML structures against: bundled defaults plus whatever
+// RegisterSchema adds at startup.
+type SchemaRegistry struct {
+	mu      sync.RWMutex
+	entries []schemaEntry
+	seq     int
+}
+
+var defaultSchemaRegistry = newSchemaRegistryWithBundledSchemas()
+
+// RegisterSchema adds a schema (raw JSON Schema document bytes) to the
+// default registry, matched against files whose path's basename, whole
+// path, or trailing part after a '/' matches `glob` (filepath.Match, the
+// same matcher detectFileType uses), or whose
+// "# yaml-language-server: $schema=<glob>" directive equals `glob`
+// exactly.
+func RegisterSchema(glob string, schemaJSON []byte) error {
+	return defaultSchemaRegistry.Register(glob, schemaJSON)
+}
+
+func newSchemaRegistry() *SchemaRegistry {
+	return &SchemaRegistry{}
+}
+
+func (r *SchemaRegistry) Register(key string, schemaJSON []byte) error {
+	doc, err := jsonschema.UnmarshalJSON(strings.NewReader(string(schemaJSON)))
+	if err != nil {
+		return fmt.Errorf("schema %q: invalid JSON Schema: %w", key, err)
+	}
+
+	r.mu.Lock()
+	r.seq++
+	url := fmt.Sprintf("mem://schema/%d", r.seq)
+	r.mu.Unlock()
+
+	compiler := jsonschema.NewCompiler()
+	if err := compiler.AddResource(url, doc); err != nil {
+		return fmt.Errorf("schema %q: %w", key, err)
+	}
+	sch, err := compiler.Compile(url)
+	if err != nil {
+		return fmt.Errorf("schema %q: %w", key, err)
+	}
+
+	r.mu.Lock()
+	r.entries = append(r.entries, schemaEntry{key: key, schema: &compiledSchema{sch: sch}})
+	r.mu.Unlock()
+	return nil
+}
+
+// Lookup finds the schema registered for `path`, preferring an exact
+// match against an inline schema directive (e.g. extracted from a YAML
+// "$schema" comment) over a glob match against the basename, the whole
+// path, or any trailing part of the path after a '/'. Entries registered
+// later take precedence over earlier ones with a matching key.
+func (r *SchemaRegistry) Lookup(path, inlineSchemaRef string) Schema {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if inlineSchemaRef != "" {
+		for i := len(r.entries) - 1; i >= 0; i-- {
+			if e := r.entries[i]; e.key == inlineSchemaRef {
+				return e.schema
+			}
+		}
+	}
+
+	base := filepath.Base(path)
+	for i := len(r.entries) - 1; i >= 0; i-- {
+		e := r.entries[i]
+		if matched, _ := filepath.Match(e.key, base); matched {
+			return e.schema
+		}
+		if matched, _ := filepath.Match(e.key, path); matched {
+			return e.schema
+		}
+		for j := 0; j < len(path); j++ {
+			if path[j] != '/' {
+				continue
+			}
+			if matched, _ := filepath.Match(e.key, path[j+1:]); matched {
+				return e.schema
+			}
+		}
+	}
+	return nil
+}
+
+// extractYAMLSchemaDirective pulls the value out of a
+// "# yaml-language-server: $schema=<ref>" comment, the convention most
+// YAML editors use to pin a document to a schema. Returns "" if absent.
+func extractYAMLSchemaDirective(content string) string {
+	for _, line := range strings.Split(content, "\n") {
+		line := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(line), "#"))
+		if !strings.HasPrefix(line, "yaml-language-server:") {
+			continue
+		}
+		for _, field := range strings.Fields(strings.TrimPrefix(line, "yaml-language-server:")) {
+			if ref, ok := strings.CutPrefix(field, "$schema="); ok {
+				return ref
+			}
+		}
+	}
+	return ""
+}
+
+// checkSchema looks up a schema for `path`/`content` and, if one
+// matches, decodes content into a JSON-compatible value and merges any
+// schema violations into r.Errors. No-op if no schema matches.
+func checkSchema(path, content, fileType string, r *ValidationResult) {
+	var inlineRef string
+	if fileType == "yaml" {
+		inlineRef = extractYAMLSchemaDirective(content)
+	}
+
+	sch := defaultSchemaRegistry.Lookup(path, inlineRef)
+	if sch == nil {
+		return
+	}
+
+	v, err := decodeForSchema(content, fileType)
+	if err != nil {
+		r.Errors = append(r.Errors, ValidationError{
+			RuleID: "schema", Severity: SeverityError,
+			Message: fmt.Sprintf("schema: failed to decode for schema check: %v", err),
+		})
+		r.Valid = false
+		return
+	}
+
+	for _, e := range sch.ValidateValue(v) {
+		r.Errors = append(r.Errors, ValidationError{RuleID: "schema", Severity: SeverityError, Message: "schema: " + e})
+		r.Valid = false
+	}
+}
+
+// decodeForSchema produces the JSON-compatible value a Schema expects.
+// YAML and TOML are marshaled back to JSON after decoding so anchors,
+// aliases, and Go-native numeric types are normalized the same way a
+// plain JSON document would be — only the first document is checked for
+// multi-document YAML.
+func decodeForSchema(content, fileType string) (interface{}, error) {
+	switch fileType {
+	case "json":
+		return jsonschema.UnmarshalJSON(strings.NewReader(content))
+
+	case "yaml":
+		var raw interface{}
+		dec := yaml.NewDecoder(strings.NewReader(content))
+		if err := dec.Decode(&raw); err != nil {
+			return nil, err
+		}
+		return roundTripThroughJSON(raw)
+
+	case "toml":
+		var raw interface{}
+		if _, err := toml.Decode(content, &raw); err != nil {
+			return nil, err
+		}
+		return roundTripThroughJSON(raw)
+
+	default:
+		return nil, fmt.Errorf("unsupported schema file type: %s", fileType)
+	}
+}
+
+func roundTripThroughJSON(v interface{}) (interface{}, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return jsonschema.UnmarshalJSON(strings.NewReader(string(b)))
+}
@@ -0,0 +1,126 @@
+package tools
+
+// bundledSchemas are the schemas RegisterSchema-style validation ships
+// with out of the box: small, hand-trimmed JSON Schemas for a handful of
+// config formats common on the boxes this server manages — not full
+// copies of the upstream schemas from schemastore.org, just enough to
+// catch the mistakes that matter (missing required keys, wrong types).
+// RegisterSchema can add or override entries at startup.
+var bundledSchemas = []struct {
+	key  string
+	data string
+}{
+	{
+		key: "docker-compose.yml",
+		data: `{
+			"$schema": "http://json-schema.org/draft-07/schema#",
+			"type": "object",
+			"required": ["services"],
+			"properties": {
+				"version": {"type": "string"},
+				"services": {
+					"type": "object",
+					"minProperties": 1,
+					"additionalProperties": {
+						"type": "object",
+						"properties": {
+							"image": {"type": "string"},
+							"build": {"type": ["string", "object"]},
+							"ports": {"type": "array"},
+							"environment": {"type": ["array", "object"]},
+							"volumes": {"type": "array"},
+							"depends_on": {"type": ["array", "object"]}
+						}
+					}
+				}
+			}
+		}`,
+	},
+	{
+		key: "package.json",
+		data: `{
+			"$schema": "http://json-schema.org/draft-07/schema#",
+			"type": "object",
+			"required": ["name", "version"],
+			"properties": {
+				"name": {"type": "string", "pattern": "^(@[a-z0-9-~][a-z0-9-._~]*/)?[a-z0-9-~][a-z0-9-._~]*$"},
+				"version": {"type": "string"},
+				"scripts": {"type": "object", "additionalProperties": {"type": "string"}},
+				"dependencies": {"type": "object", "additionalProperties": {"type": "string"}},
+				"devDependencies": {"type": "object", "additionalProperties": {"type": "string"}}
+			}
+		}`,
+	},
+	{
+		key: "tsconfig.json",
+		data: `{
+			"$schema": "http://json-schema.org/draft-07/schema#",
+			"type": "object",
+			"properties": {
+				"compilerOptions": {"type": "object"},
+				"include": {"type": "array", "items": {"type": "string"}},
+				"exclude": {"type": "array", "items": {"type": "string"}},
+				"extends": {"type": ["string", "array"]}
+			}
+		}`,
+	},
+	{
+		key: "*.github/workflows/*.yml",
+		data: `{
+			"$schema": "http://json-schema.org/draft-07/schema#",
+			"type": "object",
+			"required": ["on", "jobs"],
+			"properties": {
+				"name": {"type": "string"},
+				"on": {},
+				"jobs": {
+					"type": "object",
+					"minProperties": 1,
+					"additionalProperties": {
+						"type": "object",
+						"required": ["runs-on"],
+						"properties": {
+							"runs-on": {},
+							"steps": {"type": "array"}
+						}
+					}
+				}
+			}
+		}`,
+	},
+	{
+		key: "kubernetes-manifest.yml",
+		data: `{
+			"$schema": "http://json-schema.org/draft-07/schema#",
+			"type": "object",
+			"required": ["apiVersion", "kind", "metadata"],
+			"properties": {
+				"apiVersion": {"type": "string"},
+				"kind": {"type": "string"},
+				"metadata": {
+					"type": "object",
+					"required": ["name"],
+					"properties": {
+						"name": {"type": "string"},
+						"namespace": {"type": "string"}
+					}
+				},
+				"spec": {"type": "object"}
+			}
+		}`,
+	},
+}
+
+// newSchemaRegistryWithBundledSchemas builds the default registry and
+// registers every bundled schema into it. A schema that fails to compile
+// is a bug in this file, not in caller input, so it panics at init time
+// rather than surfacing as a validation error later.
+func newSchemaRegistryWithBundledSchemas() *SchemaRegistry {
+	r := newSchemaRegistry()
+	for _, b := range bundledSchemas {
+		if err := r.Register(b.key, []byte(b.data)); err != nil {
+			panic("tools: invalid bundled schema " + b.key + ": " + err.Error())
+		}
+	}
+	return r
+}
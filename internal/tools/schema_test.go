@@ -0,0 +1,86 @@
+package tools
+
+import "testing"
+
+func TestCheckSchemaPackageJSONMissingRequired(t *testing.T) {
+	content := `{"scripts": {"build": "tsc"}}`
+	r := validateJSON(content)
+	if !r.Valid {
+		t.Fatalf("expected plain JSON to parse: %v", r.Errors)
+	}
+	checkSchema("package.json", content, "json", r)
+	if r.Valid {
+		t.Fatalf("expected schema violation for missing name/version")
+	}
+}
+
+func TestCheckSchemaPackageJSONValid(t *testing.T) {
+	content := `{"name": "my-pkg", "version": "1.0.0"}`
+	r := validateJSON(content)
+	checkSchema("package.json", content, "json", r)
+	if !r.Valid {
+		t.Fatalf("expected valid package.json, got errors: %v", r.Errors)
+	}
+}
+
+func TestCheckSchemaNoMatchIsNoop(t *testing.T) {
+	content := `{"anything": "goes"}`
+	r := validateJSON(content)
+	checkSchema("unrelated-file.json", content, "json", r)
+	if !r.Valid {
+		t.Fatalf("expected no schema to match and leave result valid, got: %v", r.Errors)
+	}
+}
+
+func TestCheckSchemaDockerComposeYAML(t *testing.T) {
+	content := "services:\n  web:\n    image: nginx\n"
+	r := validateYAML(content)
+	if !r.Valid {
+		t.Fatalf("expected plain YAML to parse: %v", r.Errors)
+	}
+	checkSchema("docker-compose.yml", content, "yaml", r)
+	if !r.Valid {
+		t.Fatalf("expected valid docker-compose.yml, got errors: %v", r.Errors)
+	}
+}
+
+func TestCheckSchemaDockerComposeMissingServices(t *testing.T) {
+	content := "version: \"3\"\n"
+	r := validateYAML(content)
+	checkSchema("docker-compose.yml", content, "yaml", r)
+	if r.Valid {
+		t.Fatalf("expected schema violation for missing services key")
+	}
+}
+
+func TestExtractYAMLSchemaDirective(t *testing.T) {
+	content := "# yaml-language-server: $schema=package.json\nname: x\n"
+	if got := extractYAMLSchemaDirective(content); got != "package.json" {
+		t.Fatalf("got %q, want %q", got, "package.json")
+	}
+	if got := extractYAMLSchemaDirective("name: x\n"); got != "" {
+		t.Fatalf("got %q, want empty", got)
+	}
+}
+
+func TestSchemaRegistryRegisterAndLookup(t *testing.T) {
+	r := newSchemaRegistry()
+	err := r.Register("my-schema.json", []byte(`{"type": "object", "required": ["id"]}`))
+	if err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	sch := r.Lookup("whatever.json", "my-schema.json")
+	if sch == nil {
+		t.Fatalf("expected schema to be found by inline ref")
+	}
+	if errs := sch.ValidateValue(map[string]interface{}{}); len(errs) == 0 {
+		t.Fatalf("expected validation errors for missing required field")
+	}
+}
+
+func TestSchemaRegistryRegisterInvalidSchema(t *testing.T) {
+	r := newSchemaRegistry()
+	if err := r.Register("bad.json", []byte(`not json`)); err == nil {
+		t.Fatalf("expected error registering invalid schema JSON")
+	}
+}
@@ -0,0 +1,365 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"ssh-mcp/internal/ssh"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	cssh "golang.org/x/crypto/ssh"
+)
+
+const (
+	defaultShellReadTimeout   = 2 * time.Second
+	defaultShellStreamTimeout = 30 * time.Second
+)
+
+// registerShellTools registers interactive PTY shell session tools.
+func registerShellTools(s *server.MCPServer, pool *ssh.Pool) {
+	// ssh_shell_open
+	s.AddTool(
+		mcp.NewTool("ssh_shell_open",
+			mcp.WithDescription("Start an interactive PTY shell session for driving terminal UIs, sudo prompts, and other interactive commands step by step. Returns a session_id for ssh_shell_write/ssh_shell_read."),
+			mcp.WithString("target", mcp.Description("Connection alias (default: primary)")),
+			mcp.WithNumber("cols", mcp.Description("Terminal width in columns (default: 80)")),
+			mcp.WithNumber("rows", mcp.Description("Terminal height in rows (default: 24)")),
+			mcp.WithString("term", mcp.Description("TERM value to request (default: xterm)")),
+		),
+		createShellOpenHandler(pool),
+	)
+
+	// ssh_shell_write
+	s.AddTool(
+		mcp.NewTool("ssh_shell_write",
+			mcp.WithDescription("Write data to an open shell session's stdin, e.g. a command followed by \\n or a response to an interactive prompt."),
+			mcp.WithString("session_id", mcp.Required(), mcp.Description("Shell session ID from ssh_shell_open")),
+			mcp.WithString("data", mcp.Required(), mcp.Description("Data to write, e.g. \"ls -la\\n\"")),
+		),
+		createShellWriteHandler(pool),
+	)
+
+	// ssh_shell_read
+	s.AddTool(
+		mcp.NewTool("ssh_shell_read",
+			mcp.WithDescription("Read output accumulated on a shell session since the last read, waiting briefly for new output if none is buffered yet."),
+			mcp.WithString("session_id", mcp.Required(), mcp.Description("Shell session ID from ssh_shell_open")),
+			mcp.WithNumber("max_bytes", mcp.Description("Maximum bytes to return (default: 65536)")),
+			mcp.WithNumber("timeout", mcp.Description("Seconds to wait for output before returning empty (default: 2)")),
+		),
+		createShellReadHandler(pool),
+	)
+
+	// ssh_shell_resize
+	s.AddTool(
+		mcp.NewTool("ssh_shell_resize",
+			mcp.WithDescription("Resize an open shell session's PTY window"),
+			mcp.WithString("session_id", mcp.Required(), mcp.Description("Shell session ID from ssh_shell_open")),
+			mcp.WithNumber("cols", mcp.Required(), mcp.Description("Terminal width in columns")),
+			mcp.WithNumber("rows", mcp.Required(), mcp.Description("Terminal height in rows")),
+		),
+		createShellResizeHandler(pool),
+	)
+
+	// ssh_shell_close
+	s.AddTool(
+		mcp.NewTool("ssh_shell_close",
+			mcp.WithDescription("Close an open shell session"),
+			mcp.WithString("session_id", mcp.Required(), mcp.Description("Shell session ID from ssh_shell_open")),
+		),
+		createShellCloseHandler(pool),
+	)
+
+	// ssh_shell_list
+	s.AddTool(
+		mcp.NewTool("ssh_shell_list",
+			mcp.WithDescription("List open shell sessions"),
+		),
+		createShellListHandler(pool),
+	)
+
+	// ssh_shell_stream
+	s.AddTool(
+		mcp.NewTool("ssh_shell_stream",
+			mcp.WithDescription(`Write data to a shell session, then stream its output as it arrives via MCP notifications instead of a single blocking read. Useful for driving a long-running interactive step (e.g. "sudo apt upgrade") and watching it progress.
+
+Each chunk of output is sent as a "notifications/message" notification with {"session_id", "data"} in its data field; the tool result contains the full output collected during the stream.`),
+			mcp.WithString("session_id", mcp.Required(), mcp.Description("Shell session ID from ssh_shell_open")),
+			mcp.WithString("data", mcp.Description("Data to write before streaming begins, e.g. \"sudo apt upgrade -y\\n\"")),
+			mcp.WithNumber("duration", mcp.Description("Maximum seconds to stream before returning (default: 30)")),
+		),
+		createShellStreamHandler(pool),
+	)
+}
+
+// registerShellAliasTools registers shell_open/shell_send/shell_recv/
+// shell_resize/shell_close — thin aliases over the same Manager shell
+// session machinery ssh_shell_* already uses, under the shorter naming an
+// agent driving an interactive program (vim, mysql, a python REPL) would
+// expect, with terminal modes (echo, icrnl) exposed for tools like `top`
+// that misrender under the defaults.
+func registerShellAliasTools(s *server.MCPServer, pool *ssh.Pool) {
+	// shell_open
+	s.AddTool(
+		mcp.NewTool("shell_open",
+			mcp.WithDescription("Start an interactive PTY shell session for driving terminal UIs, sudo prompts, and other interactive commands step by step. Returns a session_id for shell_send/shell_recv."),
+			mcp.WithString("target", mcp.Description("Connection alias (default: primary)")),
+			mcp.WithNumber("cols", mcp.Description("Terminal width in columns (default: 80)")),
+			mcp.WithNumber("rows", mcp.Description("Terminal height in rows (default: 24)")),
+			mcp.WithString("term", mcp.Description("TERM value to request (default: xterm)")),
+			mcp.WithBoolean("echo", mcp.Description("Enable local echo of typed input (default: true)")),
+			mcp.WithBoolean("icrnl", mcp.Description("Translate CR to NL on input, as a normal tty does (default: true)")),
+		),
+		createShellAliasOpenHandler(pool),
+	)
+
+	// shell_send
+	s.AddTool(
+		mcp.NewTool("shell_send",
+			mcp.WithDescription("Send data to an open shell session's stdin, e.g. a command followed by \\n or a response to an interactive prompt."),
+			mcp.WithString("session_id", mcp.Required(), mcp.Description("Shell session ID from shell_open")),
+			mcp.WithString("data", mcp.Required(), mcp.Description("Data to write, e.g. \"ls -la\\n\"")),
+		),
+		createShellWriteHandler(pool),
+	)
+
+	// shell_recv
+	s.AddTool(
+		mcp.NewTool("shell_recv",
+			mcp.WithDescription("Receive output accumulated on a shell session since the last read, waiting briefly for new output if none is buffered yet."),
+			mcp.WithString("session_id", mcp.Required(), mcp.Description("Shell session ID from shell_open")),
+			mcp.WithNumber("max_bytes", mcp.Description("Maximum bytes to return (default: 65536)")),
+			mcp.WithNumber("timeout", mcp.Description("Seconds to wait for output before returning empty (default: 2)")),
+		),
+		createShellReadHandler(pool),
+	)
+
+	// shell_resize
+	s.AddTool(
+		mcp.NewTool("shell_resize",
+			mcp.WithDescription("Resize an open shell session's PTY window"),
+			mcp.WithString("session_id", mcp.Required(), mcp.Description("Shell session ID from shell_open")),
+			mcp.WithNumber("cols", mcp.Required(), mcp.Description("Terminal width in columns")),
+			mcp.WithNumber("rows", mcp.Required(), mcp.Description("Terminal height in rows")),
+		),
+		createShellResizeHandler(pool),
+	)
+
+	// shell_close
+	s.AddTool(
+		mcp.NewTool("shell_close",
+			mcp.WithDescription("Close an open shell session"),
+			mcp.WithString("session_id", mcp.Required(), mcp.Description("Shell session ID from shell_open")),
+		),
+		createShellCloseHandler(pool),
+	)
+}
+
+func createShellAliasOpenHandler(pool *ssh.Pool) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		mgr := getManager(ctx, pool)
+		if mgr == nil {
+			return mcp.NewToolResultError("No active session"), nil
+		}
+
+		target := req.GetString("target", "primary")
+		modes := cssh.TerminalModes{}
+		if !req.GetBool("echo", true) {
+			modes[cssh.ECHO] = 0
+		}
+		if !req.GetBool("icrnl", true) {
+			modes[cssh.ICRNL] = 0
+		}
+
+		opts := ssh.PtyOpts{
+			Cols:  req.GetInt("cols", 80),
+			Rows:  req.GetInt("rows", 24),
+			Term:  req.GetString("term", ""),
+			Modes: modes,
+		}
+
+		sessionID, err := mgr.OpenShell(ctx, target, opts)
+		if err != nil {
+			log.Printf("[Tool:shell_open] Error: %v", err)
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		return mcp.NewToolResultText(fmt.Sprintf("Opened shell session %s", sessionID)), nil
+	}
+}
+
+func createShellOpenHandler(pool *ssh.Pool) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		mgr := getManager(ctx, pool)
+		if mgr == nil {
+			return mcp.NewToolResultError("No active session"), nil
+		}
+
+		target := req.GetString("target", "primary")
+		opts := ssh.PtyOpts{
+			Cols: req.GetInt("cols", 80),
+			Rows: req.GetInt("rows", 24),
+			Term: req.GetString("term", ""),
+		}
+
+		sessionID, err := mgr.OpenShell(ctx, target, opts)
+		if err != nil {
+			log.Printf("[Tool:ssh_shell_open] Error: %v", err)
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		return mcp.NewToolResultText(fmt.Sprintf("Opened shell session %s", sessionID)), nil
+	}
+}
+
+func createShellWriteHandler(pool *ssh.Pool) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		mgr := getManager(ctx, pool)
+		if mgr == nil {
+			return mcp.NewToolResultError("No active session"), nil
+		}
+
+		sessionID, _ := req.RequireString("session_id")
+		data, _ := req.RequireString("data")
+
+		if err := mgr.WriteStdin(sessionID, []byte(data)); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		return mcp.NewToolResultText("OK"), nil
+	}
+}
+
+func createShellReadHandler(pool *ssh.Pool) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		mgr := getManager(ctx, pool)
+		if mgr == nil {
+			return mcp.NewToolResultError("No active session"), nil
+		}
+
+		sessionID, _ := req.RequireString("session_id")
+		maxBytes := req.GetInt("max_bytes", 65536)
+		timeout := time.Duration(req.GetInt("timeout", int(defaultShellReadTimeout/time.Second))) * time.Second
+
+		data, ok, err := mgr.ReadStdout(sessionID, maxBytes, timeout)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		result := map[string]interface{}{
+			"data": string(data),
+			"eof":  !ok,
+		}
+		jsonBytes, _ := json.MarshalIndent(result, "", "  ")
+		return mcp.NewToolResultText(string(jsonBytes)), nil
+	}
+}
+
+func createShellResizeHandler(pool *ssh.Pool) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		mgr := getManager(ctx, pool)
+		if mgr == nil {
+			return mcp.NewToolResultError("No active session"), nil
+		}
+
+		sessionID, _ := req.RequireString("session_id")
+		cols := req.GetInt("cols", 80)
+		rows := req.GetInt("rows", 24)
+
+		if err := mgr.Resize(sessionID, cols, rows); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		return mcp.NewToolResultText("OK"), nil
+	}
+}
+
+func createShellCloseHandler(pool *ssh.Pool) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		mgr := getManager(ctx, pool)
+		if mgr == nil {
+			return mcp.NewToolResultError("No active session"), nil
+		}
+
+		sessionID, _ := req.RequireString("session_id")
+
+		if err := mgr.CloseShell(sessionID); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		return mcp.NewToolResultText(fmt.Sprintf("Closed shell session %s", sessionID)), nil
+	}
+}
+
+func createShellListHandler(pool *ssh.Pool) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		mgr := getManager(ctx, pool)
+		if mgr == nil {
+			return mcp.NewToolResultError("No active session"), nil
+		}
+
+		shells := mgr.ListShells()
+		jsonBytes, err := json.MarshalIndent(shells, "", "  ")
+		if err != nil {
+			return mcp.NewToolResultError("Failed to format shell list"), nil
+		}
+
+		return mcp.NewToolResultText(string(jsonBytes)), nil
+	}
+}
+
+// createShellStreamHandler writes an optional command to the session, then
+// relays its output as MCP notifications until the remote shell exits or
+// duration elapses; a poll window with no new output does not end the
+// stream. The full output
+// collected is also returned as the tool result, for clients that don't
+// surface notifications.
+func createShellStreamHandler(pool *ssh.Pool) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		mgr := getManager(ctx, pool)
+		if mgr == nil {
+			return mcp.NewToolResultError("No active session"), nil
+		}
+
+		sessionID, _ := req.RequireString("session_id")
+		data := req.GetString("data", "")
+		duration := time.Duration(req.GetInt("duration", int(defaultShellStreamTimeout/time.Second))) * time.Second
+
+		if data != "" {
+			if err := mgr.WriteStdin(sessionID, []byte(data)); err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+		}
+
+		srv := server.ServerFromContext(ctx)
+		deadline := time.Now().Add(duration)
+		var collected []byte
+
+		for time.Now().Before(deadline) {
+			chunk, ok, err := mgr.ReadStdout(sessionID, 65536, 500*time.Millisecond)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			if len(chunk) > 0 {
+				collected = append(collected, chunk...)
+				if srv != nil {
+					if notifyErr := srv.SendNotificationToClient(ctx, "notifications/message", map[string]any{
+						"session_id": sessionID,
+						"data":       string(chunk),
+					}); notifyErr != nil {
+						log.Printf("[Tool:ssh_shell_stream] Failed to send notification: %v", notifyErr)
+					}
+				}
+			}
+			if !ok {
+				break // remote shell exited
+			}
+		}
+
+		return mcp.NewToolResultText(string(collected)), nil
+	}
+}
@@ -0,0 +1,247 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"ssh-mcp/internal/ssh"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// registerTunnelTools registers port-forwarding and SOCKS5 proxy tools.
+func registerTunnelTools(s *server.MCPServer, pool *ssh.Pool) {
+	// ssh_tunnel_open
+	s.AddTool(
+		mcp.NewTool("ssh_tunnel_open",
+			mcp.WithDescription(`Open a port-forward or SOCKS5 proxy over an existing SSH connection.
+
+Modes (set via 'mode' parameter):
+  local  — ssh -L: listen locally, forward to a remote address. Requires 'listen_addr' and 'remote_addr'.
+  remote — ssh -R: ask the remote host to listen, forward back to a local address. Requires 'listen_addr' and 'remote_addr'.
+  socks5 — ssh -D: run a local SOCKS5 proxy that dials out through the remote host. Requires 'listen_addr'.`),
+			mcp.WithString("mode", mcp.Required(), mcp.Description("Tunnel mode: local, remote, socks5")),
+			mcp.WithString("listen_addr", mcp.Required(), mcp.Description("Address to listen on, e.g. 127.0.0.1:8080")),
+			mcp.WithString("remote_addr", mcp.Description("Destination address, e.g. 10.0.0.5:5432 (required for local/remote modes)")),
+			mcp.WithString("alias", mcp.Description("Connection alias (default: primary)")),
+		),
+		createTunnelOpenHandler(pool),
+	)
+
+	// ssh_tunnel_list
+	s.AddTool(
+		mcp.NewTool("ssh_tunnel_list",
+			mcp.WithDescription("List open tunnels with listen/remote addresses and byte counters"),
+			mcp.WithString("alias", mcp.Description("Connection alias (default: primary)")),
+		),
+		createTunnelListHandler(pool),
+	)
+
+	// ssh_tunnel_close
+	s.AddTool(
+		mcp.NewTool("ssh_tunnel_close",
+			mcp.WithDescription("Close an open tunnel by ID"),
+			mcp.WithString("tunnel_id", mcp.Required(), mcp.Description("Tunnel ID returned by ssh_tunnel_open")),
+			mcp.WithString("alias", mcp.Description("Connection alias (default: primary)")),
+		),
+		createTunnelCloseHandler(pool),
+	)
+}
+
+// registerForwardTools registers dedicated forward_local/forward_remote/
+// forward_list/forward_close tools. These are thin aliases over the same
+// Manager tunnel tracking ssh_tunnel_open/list/close already use — agents
+// that expect an explicit tool per forward direction (rather than a mode
+// enum) get one without a second, competing forward-tracking subsystem.
+func registerForwardTools(s *server.MCPServer, pool *ssh.Pool) {
+	// forward_local
+	s.AddTool(
+		mcp.NewTool("forward_local",
+			mcp.WithDescription("Open a local port-forward (ssh -L): listen on listen_addr and forward accepted connections to remote_addr through the SSH connection. Equivalent to ssh_tunnel_open with mode=local."),
+			mcp.WithString("listen_addr", mcp.Required(), mcp.Description("Address to listen on, e.g. 127.0.0.1:8080")),
+			mcp.WithString("remote_addr", mcp.Required(), mcp.Description("Destination address reachable from the remote host, e.g. 10.0.0.5:5432")),
+			mcp.WithString("alias", mcp.Description("Connection alias (default: primary)")),
+		),
+		createForwardLocalHandler(pool),
+	)
+
+	// forward_remote
+	s.AddTool(
+		mcp.NewTool("forward_remote",
+			mcp.WithDescription("Open a remote port-forward (ssh -R): ask the remote host to listen on listen_addr and forward accepted connections back to local_addr on this host. Equivalent to ssh_tunnel_open with mode=remote."),
+			mcp.WithString("listen_addr", mcp.Required(), mcp.Description("Address for the remote host to listen on, e.g. 0.0.0.0:9000")),
+			mcp.WithString("local_addr", mcp.Required(), mcp.Description("Local address to forward accepted connections to, e.g. 127.0.0.1:3000")),
+			mcp.WithString("alias", mcp.Description("Connection alias (default: primary)")),
+		),
+		createForwardRemoteHandler(pool),
+	)
+
+	// forward_list
+	s.AddTool(
+		mcp.NewTool("forward_list",
+			mcp.WithDescription("List open port-forwards and SOCKS5 proxies with listen/remote addresses and byte counters. Equivalent to ssh_tunnel_list."),
+			mcp.WithString("alias", mcp.Description("Connection alias (default: primary)")),
+		),
+		createForwardListHandler(pool),
+	)
+
+	// forward_close
+	s.AddTool(
+		mcp.NewTool("forward_close",
+			mcp.WithDescription("Close an open port-forward by ID. Equivalent to ssh_tunnel_close."),
+			mcp.WithString("forward_id", mcp.Required(), mcp.Description("Tunnel ID returned by forward_local/forward_remote")),
+			mcp.WithString("alias", mcp.Description("Connection alias (default: primary)")),
+		),
+		createForwardCloseHandler(pool),
+	)
+}
+
+func createForwardLocalHandler(pool *ssh.Pool) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		mgr := getManager(ctx, pool)
+		if mgr == nil {
+			return mcp.NewToolResultError("No active session"), nil
+		}
+
+		listenAddr, _ := req.RequireString("listen_addr")
+		remoteAddr, _ := req.RequireString("remote_addr")
+		alias := req.GetString("alias", "primary")
+
+		tunnelID, err := mgr.OpenLocalForward(alias, listenAddr, remoteAddr)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		return mcp.NewToolResultText(fmt.Sprintf("Opened local forward %s: %s -> %s", tunnelID, listenAddr, remoteAddr)), nil
+	}
+}
+
+func createForwardRemoteHandler(pool *ssh.Pool) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		mgr := getManager(ctx, pool)
+		if mgr == nil {
+			return mcp.NewToolResultError("No active session"), nil
+		}
+
+		listenAddr, _ := req.RequireString("listen_addr")
+		localAddr, _ := req.RequireString("local_addr")
+		alias := req.GetString("alias", "primary")
+
+		tunnelID, err := mgr.OpenRemoteForward(alias, listenAddr, localAddr)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		return mcp.NewToolResultText(fmt.Sprintf("Opened remote forward %s: %s <- %s", tunnelID, listenAddr, localAddr)), nil
+	}
+}
+
+func createForwardListHandler(pool *ssh.Pool) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		mgr := getManager(ctx, pool)
+		if mgr == nil {
+			return mcp.NewToolResultError("No active session"), nil
+		}
+
+		tunnels := mgr.ListTunnels()
+		jsonBytes, err := json.MarshalIndent(tunnels, "", "  ")
+		if err != nil {
+			return mcp.NewToolResultError("Failed to format forward list"), nil
+		}
+
+		return mcp.NewToolResultText(string(jsonBytes)), nil
+	}
+}
+
+func createForwardCloseHandler(pool *ssh.Pool) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		mgr := getManager(ctx, pool)
+		if mgr == nil {
+			return mcp.NewToolResultError("No active session"), nil
+		}
+
+		forwardID, _ := req.RequireString("forward_id")
+
+		if err := mgr.CloseTunnel(forwardID); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		return mcp.NewToolResultText(fmt.Sprintf("Closed forward %s", forwardID)), nil
+	}
+}
+
+func createTunnelOpenHandler(pool *ssh.Pool) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		mgr := getManager(ctx, pool)
+		if mgr == nil {
+			return mcp.NewToolResultError("No active session"), nil
+		}
+
+		mode, _ := req.RequireString("mode")
+		listenAddr, _ := req.RequireString("listen_addr")
+		remoteAddr := req.GetString("remote_addr", "")
+		alias := req.GetString("alias", "primary")
+
+		var tunnelID string
+		var err error
+
+		switch mode {
+		case "local":
+			if remoteAddr == "" {
+				return mcp.NewToolResultError("'remote_addr' is required for local mode"), nil
+			}
+			tunnelID, err = mgr.OpenLocalForward(alias, listenAddr, remoteAddr)
+		case "remote":
+			if remoteAddr == "" {
+				return mcp.NewToolResultError("'remote_addr' is required for remote mode"), nil
+			}
+			tunnelID, err = mgr.OpenRemoteForward(alias, listenAddr, remoteAddr)
+		case "socks5":
+			tunnelID, err = mgr.OpenSOCKS5(alias, listenAddr)
+		default:
+			return mcp.NewToolResultError(fmt.Sprintf("Unknown mode: '%s'. Supported: local, remote, socks5", mode)), nil
+		}
+
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		return mcp.NewToolResultText(fmt.Sprintf("Opened %s tunnel %s: %s", mode, tunnelID, listenAddr)), nil
+	}
+}
+
+func createTunnelListHandler(pool *ssh.Pool) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		mgr := getManager(ctx, pool)
+		if mgr == nil {
+			return mcp.NewToolResultError("No active session"), nil
+		}
+
+		tunnels := mgr.ListTunnels()
+		jsonBytes, err := json.MarshalIndent(tunnels, "", "  ")
+		if err != nil {
+			return mcp.NewToolResultError("Failed to format tunnel list"), nil
+		}
+
+		return mcp.NewToolResultText(string(jsonBytes)), nil
+	}
+}
+
+func createTunnelCloseHandler(pool *ssh.Pool) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		mgr := getManager(ctx, pool)
+		if mgr == nil {
+			return mcp.NewToolResultError("No active session"), nil
+		}
+
+		tunnelID, _ := req.RequireString("tunnel_id")
+
+		if err := mgr.CloseTunnel(tunnelID); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		return mcp.NewToolResultText(fmt.Sprintf("Closed tunnel %s", tunnelID)), nil
+	}
+}
@@ -1,9 +1,24 @@
 package tools
 
 import (
+	"encoding/json"
 	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
 )
 
+// getAnySlice returns a mixed-type array argument by key (e.g. db_query's
+// "params"), or nil if absent - there's no typed GetXSlice for this on
+// mcp.CallToolRequest since the element type varies per call.
+func getAnySlice(req mcp.CallToolRequest, key string) []interface{} {
+	if val, ok := req.GetArguments()[key]; ok {
+		if slice, ok := val.([]interface{}); ok {
+			return slice
+		}
+	}
+	return nil
+}
+
 // shellQuote quotes a string for safe shell use.
 func shellQuote(s string) string {
 	if s == "" {
@@ -19,6 +34,16 @@ func containsString(s, substr string) bool {
 	return strings.Contains(s, substr)
 }
 
+// jsonMarshalIndent marshals v as indented JSON, for tools that return a
+// structured result as their text output.
+func jsonMarshalIndent(v interface{}) (string, error) {
+	b, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
 // trimOutput trims whitespace from output.
 func trimOutput(s string) string {
 	return strings.TrimSpace(s)
@@ -9,54 +9,327 @@ import (
 	"encoding/xml"
 	"fmt"
 	"io"
+	"regexp"
+	"strconv"
 	"strings"
 
 	"github.com/BurntSushi/toml"
 	"gopkg.in/yaml.v3"
 )
 
-// ValidationResult holds the outcome of a syntax check.
+// Severity classifies a LintFinding. Only SeverityError affects a
+// ValidationResult's Valid field — warnings and info findings are
+// surfaced but never block a write.
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+	SeverityInfo    Severity = "info"
+)
+
+// LintFinding is one semantic lint hit, identified by a stable rule ID
+// (hadolint-style, e.g. "DL3006") so callers can suppress individual rules.
+type LintFinding struct {
+	Rule     string
+	Severity Severity
+	Line     int
+	Message  string
+}
+
+// ValidationError is one hard parse failure, carrying whatever position
+// data the underlying parser makes available. Column, Offset, RuleID, and
+// Snippet are best-effort: a parser that only reports a message (no
+// position) still produces a ValidationError, just with those fields
+// zero/empty.
+type ValidationError struct {
+	Line     int      `json:"line,omitempty"`
+	Column   int      `json:"column,omitempty"`
+	Offset   int      `json:"offset,omitempty"`
+	RuleID   string   `json:"rule_id,omitempty"`
+	Severity Severity `json:"severity"`
+	Message  string   `json:"message"`
+	Snippet  string   `json:"snippet,omitempty"`
+}
+
+// String renders a ValidationError the way FormatResult has always shown
+// errors: "line N: message", falling back to the bare message when no
+// line number is known.
+func (e ValidationError) String() string {
+	if e.Line > 0 {
+		return fmt.Sprintf("line %d: %s", e.Line, e.Message)
+	}
+	return e.Message
+}
+
+// ValidationResult holds the outcome of a syntax check. Errors holds
+// hard parse failures; Findings holds non-fatal semantic lint hits
+// (currently only populated by the Dockerfile linter).
 type ValidationResult struct {
-	Valid    bool
-	FileType string
-	Errors   []string
+	Valid    bool              `json:"valid"`
+	FileType string            `json:"file_type"`
+	Errors   []ValidationError `json:"errors,omitempty"`
+	Findings []LintFinding     `json:"findings,omitempty"`
 }
 
 // FormatResult returns a human-readable summary.
 func (v *ValidationResult) FormatResult(path string) string {
+	var b strings.Builder
 	if v.Valid {
-		return fmt.Sprintf("✓ Valid %s — %s", strings.ToUpper(v.FileType), path)
+		b.WriteString(fmt.Sprintf("✓ Valid %s — %s\n", strings.ToUpper(v.FileType), path))
+	} else {
+		b.WriteString(fmt.Sprintf("✗ INVALID %s — %s\n", strings.ToUpper(v.FileType), path))
+		for _, e := range v.Errors {
+			b.WriteString("  " + e.String() + "\n")
+		}
+	}
+	for _, f := range v.Findings {
+		b.WriteString(fmt.Sprintf("  [%s %s] line %d: %s\n", strings.ToUpper(string(f.Severity)), f.Rule, f.Line, f.Message))
 	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// FormatJSON renders the result as a stable JSON document for MCP clients
+// and editor integrations that want structured data rather than the
+// human-readable summary FormatResult produces.
+func (v *ValidationResult) FormatJSON() string {
+	b, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return "{}"
+	}
+	return string(b)
+}
+
+// --- SARIF export ---
+//
+// A minimal subset of the SARIF 2.1.0 object model — just enough to carry
+// one run with one result per error/finding — so a ValidationResult can be
+// dropped straight into GitHub code scanning or any other SARIF consumer.
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine,omitempty"`
+	StartColumn int `json:"startColumn,omitempty"`
+}
+
+// sarifLevel maps our Severity to the three levels SARIF results support.
+func sarifLevel(sev Severity) string {
+	switch sev {
+	case SeverityWarning:
+		return "warning"
+	case SeverityInfo:
+		return "note"
+	default:
+		return "error"
+	}
+}
+
+// sarifRegionFor returns nil (omitted) when no line is known, rather than
+// reporting a misleading startLine of 0.
+func sarifRegionFor(line, col int) *sarifRegion {
+	if line <= 0 {
+		return nil
+	}
+	return &sarifRegion{StartLine: line, StartColumn: col}
+}
+
+// FormatSARIF renders the result as a SARIF 2.1.0 log with a single run,
+// one result per hard error and per lint finding.
+func (v *ValidationResult) FormatSARIF(path string) string {
+	run := sarifRun{Tool: sarifTool{Driver: sarifDriver{Name: "ssh-mcp-validate", Version: "1.0.0"}}}
 
-	var b strings.Builder
-	b.WriteString(fmt.Sprintf("✗ INVALID %s — %s\n", strings.ToUpper(v.FileType), path))
 	for _, e := range v.Errors {
-		b.WriteString("  " + e + "\n")
+		ruleID := e.RuleID
+		if ruleID == "" {
+			ruleID = v.FileType + "-syntax"
+		}
+		run.Results = append(run.Results, sarifResult{
+			RuleID:  ruleID,
+			Level:   sarifLevel(e.Severity),
+			Message: sarifMessage{Text: e.Message},
+			Locations: []sarifLocation{{PhysicalLocation: sarifPhysicalLocation{
+				ArtifactLocation: sarifArtifactLocation{URI: path},
+				Region:           sarifRegionFor(e.Line, e.Column),
+			}}},
+		})
 	}
-	return strings.TrimRight(b.String(), "\n")
+
+	for _, f := range v.Findings {
+		run.Results = append(run.Results, sarifResult{
+			RuleID:  f.Rule,
+			Level:   sarifLevel(f.Severity),
+			Message: sarifMessage{Text: f.Message},
+			Locations: []sarifLocation{{PhysicalLocation: sarifPhysicalLocation{
+				ArtifactLocation: sarifArtifactLocation{URI: path},
+				Region:           sarifRegionFor(f.Line, 0),
+			}}},
+		})
+	}
+
+	log := sarifLog{
+		Schema:  "https://raw.githubusercontent.com/oasis-tcs/sarif-spec/master/Schemata/sarif-schema-2.1.0.json",
+		Version: "2.1.0",
+		Runs:    []sarifRun{run},
+	}
+	b, err := json.MarshalIndent(log, "", "  ")
+	if err != nil {
+		return "{}"
+	}
+	return string(b)
+}
+
+// simpleError builds a ValidationError carrying only a message, for the
+// validators (INI, ENV, sshd_config/ssh_config) that already track their
+// own line numbers inline rather than recovering them from a native
+// parser error.
+func simpleError(line int, message string) ValidationError {
+	return ValidationError{Line: line, Severity: SeverityError, Message: message}
+}
+
+// offsetToLineCol converts a 0-based byte offset into content into a
+// 1-based line and column, the way most Go parsers' native error types
+// report position (when they report one at all).
+func offsetToLineCol(content string, offset int) (line, col int) {
+	if offset < 0 {
+		offset = 0
+	}
+	if offset > len(content) {
+		offset = len(content)
+	}
+	line = 1
+	lineStart := 0
+	for i := 0; i < offset; i++ {
+		if content[i] == '\n' {
+			line++
+			lineStart = i + 1
+		}
+	}
+	return line, offset - lineStart + 1
+}
+
+// pluginValidationErrors adapts the plain string errors an out-of-process
+// plugin reports (plugin.ValidationResult carries no position data across
+// the RPC boundary) into ValidationErrors.
+func pluginValidationErrors(errs []string) []ValidationError {
+	if errs == nil {
+		return nil
+	}
+	out := make([]ValidationError, len(errs))
+	for i, e := range errs {
+		out[i] = ValidationError{Severity: SeverityError, Message: e}
+	}
+	return out
+}
+
+// lineSnippet returns the (trimmed) source line at 1-based `line`, for
+// ValidationError.Snippet. Returns "" for an out-of-range or unknown line.
+func lineSnippet(content string, line int) string {
+	if line <= 0 {
+		return ""
+	}
+	lines := strings.Split(content, "\n")
+	if line > len(lines) {
+		return ""
+	}
+	return strings.TrimSpace(lines[line-1])
 }
 
-// ValidateContent validates raw file content server-side based on the detected file type.
-// Returns nil if the file type is not recognized (no validation possible).
-func ValidateContent(content, fileType string) *ValidationResult {
+// ValidateContent validates raw file content server-side based on the
+// detected file type. `path` is used to look up a schema (by glob or by
+// the document's own schema directive) for the schema-aware types
+// (json/yaml/toml); it's also fed to DetectFileType alongside `content`
+// when `fileType` is "" or "auto", so a path without a recognizable
+// extension can still be validated. Returns nil if the file type is not
+// recognized (no validation possible).
+func ValidateContent(path, content, fileType string) *ValidationResult {
+	if fileType == "" || fileType == "auto" {
+		fileType = DetectFileType(path, content)
+		if fileType == "" {
+			return nil
+		}
+	}
+
+	var r *ValidationResult
 	switch fileType {
 	case "json":
-		return validateJSON(content)
+		r = validateJSON(content)
 	case "yaml":
-		return validateYAML(content)
+		r = validateYAML(content)
 	case "toml":
-		return validateTOML(content)
+		r = validateTOML(content)
 	case "xml":
-		return validateXML(content)
+		r = validateXML(content)
 	case "ini":
-		return validateINI(content)
+		r = validateINI(content)
 	case "env":
-		return validateENV(content)
+		r = validateENV(content)
 	case "dockerfile":
-		return validateDockerfile(content)
+		r = validateDockerfile(content)
+	case "sshd_config":
+		r = validateSSHDConfig(content)
+	case "ssh_config":
+		r = validateSSHConfig(content)
+	case "nginx":
+		r = validateNginx(content)
+	case "caddyfile":
+		r = validateCaddyfile(content)
+	case "systemd":
+		r = validateSystemdUnit(content)
+	case "crontab":
+		r = validateCrontab(content)
 	default:
 		return nil
 	}
+
+	// Only worth checking against a schema once the document itself
+	// parses cleanly — a schema error on top of a syntax error is noise.
+	if r != nil && r.Valid {
+		checkSchema(path, content, fileType, r)
+	}
+	return r
 }
 
 // --- JSON ---
@@ -65,13 +338,34 @@ func validateJSON(content string) *ValidationResult {
 	r := &ValidationResult{FileType: "json"}
 	var v interface{}
 	if err := json.Unmarshal([]byte(content), &v); err != nil {
-		r.Errors = append(r.Errors, err.Error())
+		r.Errors = append(r.Errors, jsonValidationError(content, err))
 		return r
 	}
 	r.Valid = true
 	return r
 }
 
+// jsonValidationError recovers position data from encoding/json's own
+// error types. json.SyntaxError and json.UnmarshalTypeError both report a
+// byte Offset; anything else (e.g. io.ErrUnexpectedEOF for a truncated
+// document) only has a message.
+func jsonValidationError(content string, err error) ValidationError {
+	var offset int64
+	switch e := err.(type) {
+	case *json.SyntaxError:
+		offset = e.Offset
+	case *json.UnmarshalTypeError:
+		offset = e.Offset
+	default:
+		return ValidationError{Severity: SeverityError, Message: err.Error()}
+	}
+	line, col := offsetToLineCol(content, int(offset))
+	return ValidationError{
+		Line: line, Column: col, Offset: int(offset),
+		Severity: SeverityError, Message: err.Error(), Snippet: lineSnippet(content, line),
+	}
+}
+
 // --- YAML ---
 
 func validateYAML(content string) *ValidationResult {
@@ -85,7 +379,7 @@ func validateYAML(content string) *ValidationResult {
 			break
 		}
 		if err != nil {
-			r.Errors = append(r.Errors, err.Error())
+			r.Errors = append(r.Errors, yamlValidationError(content, err))
 			return r
 		}
 	}
@@ -93,19 +387,42 @@ func validateYAML(content string) *ValidationResult {
 	return r
 }
 
+// yamlLineRef matches the "yaml: line N: ..." format gopkg.in/yaml.v3
+// uses for syntax errors, letting us recover a line number it doesn't
+// expose through any field.
+var yamlLineRef = regexp.MustCompile(`^yaml: line (\d+): (.*)$`)
+
+func yamlValidationError(content string, err error) ValidationError {
+	if m := yamlLineRef.FindStringSubmatch(err.Error()); m != nil {
+		line, _ := strconv.Atoi(m[1])
+		return ValidationError{Line: line, Severity: SeverityError, Message: m[2], Snippet: lineSnippet(content, line)}
+	}
+	return ValidationError{Severity: SeverityError, Message: err.Error()}
+}
+
 // --- TOML ---
 
 func validateTOML(content string) *ValidationResult {
 	r := &ValidationResult{FileType: "toml"}
 	var v interface{}
 	if _, err := toml.Decode(content, &v); err != nil {
-		r.Errors = append(r.Errors, err.Error())
+		r.Errors = append(r.Errors, tomlValidationError(content, err))
 		return r
 	}
 	r.Valid = true
 	return r
 }
 
+func tomlValidationError(content string, err error) ValidationError {
+	if pe, ok := err.(toml.ParseError); ok {
+		return ValidationError{
+			Line: pe.Position.Line, Column: pe.Position.Col, Offset: pe.Position.Start,
+			Severity: SeverityError, Message: pe.Message, Snippet: lineSnippet(content, pe.Position.Line),
+		}
+	}
+	return ValidationError{Severity: SeverityError, Message: err.Error()}
+}
+
 // --- XML ---
 
 func validateXML(content string) *ValidationResult {
@@ -117,7 +434,7 @@ func validateXML(content string) *ValidationResult {
 			break
 		}
 		if err != nil {
-			r.Errors = append(r.Errors, err.Error())
+			r.Errors = append(r.Errors, xmlValidationError(content, err))
 			return r
 		}
 	}
@@ -125,6 +442,13 @@ func validateXML(content string) *ValidationResult {
 	return r
 }
 
+func xmlValidationError(content string, err error) ValidationError {
+	if se, ok := err.(*xml.SyntaxError); ok {
+		return ValidationError{Line: se.Line, Severity: SeverityError, Message: se.Msg, Snippet: lineSnippet(content, se.Line)}
+	}
+	return ValidationError{Severity: SeverityError, Message: err.Error()}
+}
+
 // --- INI / .conf / .cfg ---
 // Simple validator: checks section headers [section] and key=value pairs.
 // Allows comments (# and ;) and blank lines.
@@ -145,7 +469,7 @@ func validateINI(content string) *ValidationResult {
 		// Section header
 		if strings.HasPrefix(line, "[") {
 			if !strings.HasSuffix(line, "]") {
-				r.Errors = append(r.Errors, fmt.Sprintf("line %d: unclosed section header: %s", lineNum, line))
+				r.Errors = append(r.Errors, simpleError(lineNum, fmt.Sprintf("unclosed section header: %s", line)))
 			}
 			continue
 		}
@@ -155,7 +479,7 @@ func validateINI(content string) *ValidationResult {
 			continue
 		}
 
-		r.Errors = append(r.Errors, fmt.Sprintf("line %d: invalid syntax: %s", lineNum, line))
+		r.Errors = append(r.Errors, simpleError(lineNum, fmt.Sprintf("invalid syntax: %s", line)))
 	}
 
 	r.Valid = len(r.Errors) == 0
@@ -181,7 +505,7 @@ func validateENV(content string) *ValidationResult {
 		// Must contain = and key must start with letter/underscore
 		eqIdx := strings.Index(line, "=")
 		if eqIdx <= 0 {
-			r.Errors = append(r.Errors, fmt.Sprintf("line %d: missing KEY=VALUE format: %s", lineNum, line))
+			r.Errors = append(r.Errors, simpleError(lineNum, fmt.Sprintf("missing KEY=VALUE format: %s", line)))
 			continue
 		}
 
@@ -192,13 +516,13 @@ func validateENV(content string) *ValidationResult {
 		key = strings.TrimSpace(key)
 
 		if key == "" {
-			r.Errors = append(r.Errors, fmt.Sprintf("line %d: empty key", lineNum))
+			r.Errors = append(r.Errors, simpleError(lineNum, "empty key"))
 			continue
 		}
 
 		firstChar := key[0]
 		if !((firstChar >= 'A' && firstChar <= 'Z') || (firstChar >= 'a' && firstChar <= 'z') || firstChar == '_') {
-			r.Errors = append(r.Errors, fmt.Sprintf("line %d: key must start with letter or underscore: %s", lineNum, key))
+			r.Errors = append(r.Errors, simpleError(lineNum, fmt.Sprintf("key must start with letter or underscore: %s", key)))
 		}
 	}
 
@@ -207,76 +531,279 @@ func validateENV(content string) *ValidationResult {
 }
 
 // --- Dockerfile ---
-// Validates that each non-comment, non-continuation line starts with a known instruction.
+// Structural + rule-based (hadolint-style) validation lives in
+// dockerfile_lint.go. validateDockerfile is the ValidateContent entry
+// point; ValidateDockerfileWithRules is exported so callers can suppress
+// individual rule IDs.
 
-var dockerfileInstructions = map[string]bool{
-	"FROM": true, "RUN": true, "CMD": true, "LABEL": true,
-	"EXPOSE": true, "ENV": true, "ADD": true, "COPY": true,
-	"ENTRYPOINT": true, "VOLUME": true, "USER": true, "WORKDIR": true,
-	"ARG": true, "ONBUILD": true, "STOPSIGNAL": true, "HEALTHCHECK": true,
-	"SHELL": true, "MAINTAINER": true,
+func validateDockerfile(content string) *ValidationResult {
+	return ValidateDockerfileWithRules(content, nil)
 }
 
-func validateDockerfile(content string) *ValidationResult {
-	r := &ValidationResult{FileType: "dockerfile"}
+// --- sshd_config / ssh_config ---
+// Parses the OpenSSH keyword grammar rather than treating the file as
+// generic INI: keywords are case-insensitive and take space- (or
+// comma-) separated values, not key=value pairs, and "Match"/"Host" lines
+// open a block that scopes every following line until the next block
+// header or EOF.
+
+// sshKeywordSpec describes how a single OpenSSH config keyword's value is
+// shaped, for the keywords this validator knows about. A zero value means
+// "known keyword, free-form argument" (paths, numbers, single tokens we
+// don't enumerate).
+type sshKeywordSpec struct {
+	multiValue bool     // accepts a comma- or space-separated list of values
+	enum       []string // if non-nil, each value (or the single argument) must be one of these, case-insensitively
+}
+
+func (s sshKeywordSpec) checkValue(value string) (ok bool, got string) {
+	if s.enum == nil {
+		return true, ""
+	}
+	values := []string{value}
+	if s.multiValue {
+		values = splitSSHList(value)
+	}
+	for _, v := range values {
+		if !containsFold(s.enum, v) {
+			return false, v
+		}
+	}
+	return true, ""
+}
+
+func splitSSHList(value string) []string {
+	fields := strings.FieldsFunc(value, func(r rune) bool { return r == ',' || r == ' ' || r == '\t' })
+	out := make([]string, 0, len(fields))
+	for _, f := range fields {
+		if f != "" {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+func containsFold(list []string, v string) bool {
+	for _, c := range list {
+		if strings.EqualFold(c, v) {
+			return true
+		}
+	}
+	return false
+}
+
+var yesNo = []string{"yes", "no"}
+
+// sshdKeywords covers the server-side (sshd_config) directives this
+// validator enforces. Keys are lowercased keyword names.
+var sshdKeywords = map[string]sshKeywordSpec{
+	"port":                            {multiValue: true},
+	"listenaddress":                   {multiValue: true},
+	"hostkey":                         {multiValue: true},
+	"protocol":                        {enum: []string{"1", "2"}},
+	"addressfamily":                   {enum: []string{"any", "inet", "inet6"}},
+	"permitrootlogin":                 {enum: []string{"yes", "no", "without-password", "forced-commands-only", "prohibit-password"}},
+	"passwordauthentication":          {enum: yesNo},
+	"pubkeyauthentication":            {enum: yesNo},
+	"challengeresponseauthentication": {enum: yesNo},
+	"kbdinteractiveauthentication":    {enum: yesNo},
+	"permitemptypasswords":            {enum: yesNo},
+	"hostbasedauthentication":         {enum: yesNo},
+	"gssapiauthentication":            {enum: yesNo},
+	"x11forwarding":                   {enum: yesNo},
+	"allowagentforwarding":            {enum: yesNo},
+	"allowtcpforwarding":              {enum: []string{"yes", "no", "local", "remote"}},
+	"allowstreamlocalforwarding":      {enum: []string{"yes", "no", "local", "remote"}},
+	"gatewayports":                    {enum: []string{"yes", "no", "clientspecified"}},
+	"compression":                     {enum: []string{"yes", "no", "delayed"}},
+	"usepam":                          {enum: yesNo},
+	"usedns":                          {enum: yesNo},
+	"printmotd":                       {enum: yesNo},
+	"printlastlog":                    {enum: yesNo},
+	"strictmodes":                     {enum: yesNo},
+	"ignorerhosts":                    {enum: yesNo},
+	"permituserenvironment":           {enum: yesNo},
+	"permittty":                       {enum: yesNo},
+	"tcpkeepalive":                    {enum: yesNo},
+	"disableforwarding":               {enum: yesNo},
+	"allowusers":                      {multiValue: true},
+	"denyusers":                       {multiValue: true},
+	"allowgroups":                     {multiValue: true},
+	"denygroups":                      {multiValue: true},
+	"ciphers":                         {multiValue: true},
+	"macs":                            {multiValue: true},
+	"kexalgorithms":                   {multiValue: true},
+	"authorizedkeysfile":              {multiValue: true},
+	"subsystem":                       {},
+	"loglevel":                        {enum: []string{"QUIET", "FATAL", "ERROR", "INFO", "VERBOSE", "DEBUG", "DEBUG1", "DEBUG2", "DEBUG3"}},
+	"syslogfacility": {enum: []string{
+		"DAEMON", "USER", "AUTH", "LOCAL0", "LOCAL1", "LOCAL2", "LOCAL3", "LOCAL4", "LOCAL5", "LOCAL6", "LOCAL7",
+	}},
+	"maxauthtries":          {},
+	"maxsessions":           {},
+	"maxstartups":           {},
+	"clientaliveinterval":   {},
+	"clientalivecountmax":   {},
+	"logintracegraceperiod": {},
+	"banner":                {},
+	"pidfile":               {},
+	"chrootdirectory":       {},
+	"forcecommand":          {},
+	"permitopen":            {multiValue: true},
+	"permitlisten":          {multiValue: true},
+}
+
+// sshdGlobalOnly are directives OpenSSH rejects inside a Match block
+// because they configure the listening process itself, not a connection.
+var sshdGlobalOnly = map[string]bool{
+	"port": true, "listenaddress": true, "hostkey": true, "protocol": true,
+	"addressfamily": true, "pidfile": true, "syslogfacility": true,
+	"usepam": true, "maxstartups": true,
+}
+
+// sshKeywords covers the client-side (ssh_config) directives this
+// validator enforces.
+var sshKeywords = map[string]sshKeywordSpec{
+	"hostname":                 {},
+	"user":                     {},
+	"port":                     {},
+	"identityfile":             {multiValue: true},
+	"identitiesonly":           {enum: yesNo},
+	"forwardagent":             {enum: yesNo},
+	"forwardx11":               {enum: yesNo},
+	"forwardx11trusted":        {enum: yesNo},
+	"stricthostkeychecking":    {enum: []string{"yes", "no", "ask", "accept-new", "off"}},
+	"userknownhostsfile":       {multiValue: true},
+	"globalknownhostsfile":     {multiValue: true},
+	"proxycommand":             {},
+	"proxyjump":                {},
+	"ciphers":                  {multiValue: true},
+	"macs":                     {multiValue: true},
+	"kexalgorithms":            {multiValue: true},
+	"hostkeyalgorithms":        {multiValue: true},
+	"compression":              {enum: yesNo},
+	"connecttimeout":           {},
+	"serveraliveinterval":      {},
+	"serveralivecountmax":      {},
+	"batchmode":                {enum: yesNo},
+	"addkeystoagent":           {enum: []string{"yes", "no", "ask", "confirm"}},
+	"pubkeyauthentication":     {enum: yesNo},
+	"passwordauthentication":   {enum: yesNo},
+	"gssapiauthentication":     {enum: yesNo},
+	"checkhostip":              {enum: yesNo},
+	"visualhostkey":            {enum: yesNo},
+	"sendenv":                  {multiValue: true},
+	"setenv":                   {multiValue: true},
+	"requesttty":               {enum: []string{"no", "yes", "force", "auto"}},
+	"remotecommand":            {},
+	"localforward":             {},
+	"remoteforward":            {},
+	"dynamicforward":           {},
+	"escapechar":               {},
+	"controlmaster":            {enum: []string{"yes", "no", "ask", "auto", "autoask"}},
+	"controlpath":              {},
+	"controlpersist":           {},
+	"preferredauthentications": {multiValue: true},
+	"loglevel": {enum: []string{
+		"QUIET", "FATAL", "ERROR", "INFO", "VERBOSE", "DEBUG", "DEBUG1", "DEBUG2", "DEBUG3",
+	}},
+}
+
+// sshConfigSpec parameterizes validateOpenSSHConfig over the two dialects:
+// sshd_config (block keyword "Match") and ssh_config (block keywords
+// "Host" and "Match").
+type sshConfigSpec struct {
+	fileType      string
+	keywords      map[string]sshKeywordSpec
+	blockKeywords map[string]bool
+	globalOnly    map[string]bool // directives invalid once inside a block; nil if none
+}
+
+var sshdConfigSpec = sshConfigSpec{
+	fileType:      "sshd_config",
+	keywords:      sshdKeywords,
+	blockKeywords: map[string]bool{"match": true},
+	globalOnly:    sshdGlobalOnly,
+}
+
+var sshConfigSpecClient = sshConfigSpec{
+	fileType:      "ssh_config",
+	keywords:      sshKeywords,
+	blockKeywords: map[string]bool{"host": true, "match": true},
+}
+
+func validateSSHDConfig(content string) *ValidationResult {
+	return validateOpenSSHConfig(content, sshdConfigSpec)
+}
+
+func validateSSHConfig(content string) *ValidationResult {
+	return validateOpenSSHConfig(content, sshConfigSpecClient)
+}
+
+func validateOpenSSHConfig(content string, spec sshConfigSpec) *ValidationResult {
+	r := &ValidationResult{FileType: spec.fileType}
 	scanner := bufio.NewScanner(strings.NewReader(content))
 	lineNum := 0
-	continuation := false
+	inBlock := false
 
 	for scanner.Scan() {
 		lineNum++
-		line := scanner.Text()
-		trimmed := strings.TrimSpace(line)
-
-		// Handle line continuation from previous line
-		if continuation {
-			continuation = strings.HasSuffix(trimmed, "\\")
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
 			continue
 		}
 
-		// Skip blank lines and comments
-		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+		keyword, value := splitSSHDirective(line)
+		if keyword == "" {
+			r.Errors = append(r.Errors, simpleError(lineNum, fmt.Sprintf("missing argument: %s", line)))
 			continue
 		}
+		lower := strings.ToLower(keyword)
 
-		// Check if line continues
-		continuation = strings.HasSuffix(trimmed, "\\")
-
-		// Extract instruction (first word)
-		parts := strings.Fields(trimmed)
-		if len(parts) == 0 {
+		if spec.blockKeywords[lower] {
+			if value == "" {
+				r.Errors = append(r.Errors, simpleError(lineNum, fmt.Sprintf("%s requires a pattern/criteria argument", keyword)))
+			}
+			inBlock = true
 			continue
 		}
 
-		instruction := strings.ToUpper(parts[0])
-		// Handle parser directives (# syntax=..., # escape=...)
-		if strings.HasPrefix(instruction, "#") {
+		kwSpec, known := spec.keywords[lower]
+		if !known {
+			r.Errors = append(r.Errors, simpleError(lineNum, fmt.Sprintf("unknown keyword: %s", keyword)))
 			continue
 		}
-
-		if !dockerfileInstructions[instruction] {
-			r.Errors = append(r.Errors, fmt.Sprintf("line %d: unknown instruction: %s", lineNum, parts[0]))
+		if value == "" {
+			r.Errors = append(r.Errors, simpleError(lineNum, fmt.Sprintf("%s requires a value", keyword)))
+			continue
 		}
-	}
-
-	// Check that FROM is present
-	hasFrom := false
-	scanner2 := bufio.NewScanner(strings.NewReader(content))
-	for scanner2.Scan() {
-		line := strings.TrimSpace(scanner2.Text())
-		if line == "" || strings.HasPrefix(line, "#") {
+		if inBlock && spec.globalOnly[lower] {
+			r.Errors = append(r.Errors, simpleError(lineNum, fmt.Sprintf("%s may not appear inside a Match block", keyword)))
 			continue
 		}
-		parts := strings.Fields(line)
-		if len(parts) > 0 && strings.ToUpper(parts[0]) == "FROM" {
-			hasFrom = true
-			break
+		if ok, got := kwSpec.checkValue(value); !ok {
+			r.Errors = append(r.Errors, simpleError(lineNum, fmt.Sprintf("%s: invalid value %q", keyword, got)))
 		}
 	}
-	if !hasFrom && strings.TrimSpace(content) != "" {
-		r.Errors = append(r.Errors, "missing FROM instruction")
-	}
 
 	r.Valid = len(r.Errors) == 0
 	return r
 }
+
+// splitSSHDirective splits a config line into its keyword and the rest of
+// the line as the value. OpenSSH accepts either whitespace or a single '='
+// (with optional surrounding whitespace) between the two.
+func splitSSHDirective(line string) (keyword, value string) {
+	i := 0
+	for i < len(line) && !isSSHSeparator(line[i]) {
+		i++
+	}
+	keyword = line[:i]
+	rest := strings.TrimLeft(line[i:], " \t")
+	rest = strings.TrimPrefix(rest, "=")
+	return keyword, strings.TrimSpace(rest)
+}
+
+func isSSHSeparator(b byte) bool {
+	return b == ' ' || b == '\t' || b == '='
+}
@@ -0,0 +1,404 @@
+// Package tools: validate_configs.go adds syntax validators for the
+// config formats remote-admin users most often hand-edit over SSH but
+// which aren't plain INI/YAML: nginx, Caddyfile, systemd units, and
+// crontab. None of these attempt full parity with their real parsers —
+// each checks the structural rules worth catching before a write, the
+// same trade-off the other validators in this package make.
+package tools
+
+import (
+	"bufio"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// --- nginx ---
+//
+// validateNginx tokenizes the config respecting quoted strings and '#'
+// comments, and checks brace balance, that every directive ends in ';'
+// (block openers end in '{' instead), and that 'server'/'location'
+// blocks only appear inside a valid parent context.
+
+func validateNginx(content string) *ValidationResult {
+	r := &ValidationResult{FileType: "nginx"}
+	var stack []string
+	var buf strings.Builder
+	line := 1
+	inSingle, inDouble := false, false
+
+	src := content
+	for i := 0; i < len(src); i++ {
+		c := src[i]
+		switch {
+		case c == '\n':
+			line++
+			buf.WriteByte(c)
+		case inSingle:
+			buf.WriteByte(c)
+			if c == '\'' {
+				inSingle = false
+			}
+		case inDouble:
+			buf.WriteByte(c)
+			if c == '"' {
+				inDouble = false
+			}
+		case c == '\'':
+			inSingle = true
+			buf.WriteByte(c)
+		case c == '"':
+			inDouble = true
+			buf.WriteByte(c)
+		case c == '#':
+			for i < len(src) && src[i] != '\n' {
+				i++
+			}
+			i--
+		case c == '{':
+			header := strings.TrimSpace(buf.String())
+			blockType := nginxBlockType(header)
+			if blockType == "server" && !nginxInContext(stack, "http") {
+				r.Errors = append(r.Errors, simpleError(line, "'server' block must be inside an 'http' block"))
+			}
+			if blockType == "location" && !nginxInContext(stack, "server", "location") {
+				r.Errors = append(r.Errors, simpleError(line, "'location' block must be inside a 'server' or 'location' block"))
+			}
+			stack = append(stack, blockType)
+			buf.Reset()
+		case c == '}':
+			if trimmed := strings.TrimSpace(buf.String()); trimmed != "" {
+				r.Errors = append(r.Errors, simpleError(line, fmt.Sprintf("directive not terminated with ';': %s", trimmed)))
+			}
+			buf.Reset()
+			if len(stack) == 0 {
+				r.Errors = append(r.Errors, simpleError(line, "unexpected '}' with no matching '{'"))
+			} else {
+				stack = stack[:len(stack)-1]
+			}
+		case c == ';':
+			buf.Reset()
+		default:
+			buf.WriteByte(c)
+		}
+	}
+
+	if trimmed := strings.TrimSpace(buf.String()); trimmed != "" {
+		r.Errors = append(r.Errors, simpleError(line, fmt.Sprintf("directive not terminated with ';': %s", trimmed)))
+	}
+	if len(stack) > 0 {
+		r.Errors = append(r.Errors, simpleError(line, fmt.Sprintf("missing closing '}' for %d block(s): %s", len(stack), strings.Join(stack, ", "))))
+	}
+
+	r.Valid = len(r.Errors) == 0
+	return r
+}
+
+func nginxBlockType(header string) string {
+	fields := strings.Fields(header)
+	if len(fields) == 0 {
+		return ""
+	}
+	return strings.ToLower(fields[0])
+}
+
+func nginxInContext(stack []string, allowed ...string) bool {
+	if len(stack) == 0 {
+		return false
+	}
+	top := stack[len(stack)-1]
+	for _, a := range allowed {
+		if top == a {
+			return true
+		}
+	}
+	return false
+}
+
+// --- Caddyfile ---
+//
+// validateCaddyfile checks brace balance, that top-level block headers
+// are either a site address list or a "(name)" snippet definition, that
+// every "@token" matcher name is well-formed, and that "import" lines
+// carry an argument.
+
+var (
+	caddyMatcherTokenRe  = regexp.MustCompile(`@[A-Za-z0-9_.~-]*`)
+	caddyValidMatcherRe  = regexp.MustCompile(`^@[A-Za-z0-9_.~-]+$`)
+	caddySnippetHeaderRe = regexp.MustCompile(`^\([A-Za-z0-9_.~-]+\)$`)
+)
+
+func validateCaddyfile(content string) *ValidationResult {
+	r := &ValidationResult{FileType: "caddyfile"}
+	depth := 0
+	lineNum := 0
+
+	for _, raw := range strings.Split(content, "\n") {
+		lineNum++
+		line := raw
+		if idx := strings.Index(line, "#"); idx >= 0 {
+			line = line[:idx]
+		}
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		for _, tok := range caddyMatcherTokenRe.FindAllString(line, -1) {
+			if !caddyValidMatcherRe.MatchString(tok) {
+				r.Errors = append(r.Errors, simpleError(lineNum, fmt.Sprintf("invalid matcher syntax: %s", tok)))
+			}
+		}
+
+		if fields := strings.Fields(line); strings.EqualFold(fields[0], "import") && len(fields) < 2 {
+			r.Errors = append(r.Errors, simpleError(lineNum, "import requires a snippet name or file path argument"))
+		}
+
+		if depth == 0 && strings.HasSuffix(line, "{") {
+			header := strings.TrimSpace(strings.TrimSuffix(line, "{"))
+			switch {
+			case header == "":
+				r.Errors = append(r.Errors, simpleError(lineNum, "empty site-block header"))
+			case strings.HasPrefix(header, "(") && !caddySnippetHeaderRe.MatchString(header):
+				r.Errors = append(r.Errors, simpleError(lineNum, fmt.Sprintf("malformed snippet header: %s", header)))
+			}
+		}
+
+		depth += strings.Count(line, "{") - strings.Count(line, "}")
+		if depth < 0 {
+			r.Errors = append(r.Errors, simpleError(lineNum, "unexpected '}' with no matching '{'"))
+			depth = 0
+		}
+	}
+
+	if depth > 0 {
+		r.Errors = append(r.Errors, simpleError(lineNum, fmt.Sprintf("missing %d closing '}'", depth)))
+	}
+
+	r.Valid = len(r.Errors) == 0
+	return r
+}
+
+// --- systemd unit ---
+//
+// validateSystemdUnit checks that every "[Section]" header is a known
+// unit-file section, that every directive appears inside a section and
+// is shaped Key=Value, and that a couple of directives required for the
+// unit to actually do anything are present: ExecStart for a [Service]
+// whose Type is simple/exec/forking (the default Type, if unspecified,
+// is simple), and an On*-schedule directive for a [Timer].
+
+var systemdKnownSections = map[string]bool{
+	"Unit": true, "Service": true, "Socket": true, "Timer": true, "Install": true,
+	"Mount": true, "Automount": true, "Path": true, "Swap": true, "Slice": true, "Scope": true,
+}
+
+var systemdTimerTriggers = map[string]bool{
+	"OnCalendar": true, "OnBootSec": true, "OnStartupSec": true,
+	"OnUnitActiveSec": true, "OnUnitInactiveSec": true, "OnActiveSec": true,
+}
+
+func validateSystemdUnit(content string) *ValidationResult {
+	r := &ValidationResult{FileType: "systemd"}
+
+	section := ""
+	haveService, haveTimer := false, false
+	serviceType := "simple"
+	haveExecStart := false
+	haveTimerTrigger := false
+
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") {
+			if !strings.HasSuffix(line, "]") {
+				r.Errors = append(r.Errors, simpleError(lineNum, fmt.Sprintf("unclosed section header: %s", line)))
+				continue
+			}
+			name := line[1 : len(line)-1]
+			if !systemdKnownSections[name] {
+				r.Errors = append(r.Errors, simpleError(lineNum, fmt.Sprintf("unknown section: [%s]", name)))
+			}
+			section = name
+			haveService = haveService || name == "Service"
+			haveTimer = haveTimer || name == "Timer"
+			continue
+		}
+
+		if section == "" {
+			r.Errors = append(r.Errors, simpleError(lineNum, fmt.Sprintf("directive outside any [Section]: %s", line)))
+			continue
+		}
+
+		eq := strings.Index(line, "=")
+		if eq <= 0 {
+			r.Errors = append(r.Errors, simpleError(lineNum, fmt.Sprintf("expected Key=Value directive: %s", line)))
+			continue
+		}
+		key := strings.TrimSpace(line[:eq])
+		value := strings.TrimSpace(line[eq+1:])
+
+		switch {
+		case section == "Service" && key == "Type":
+			serviceType = strings.ToLower(value)
+		case section == "Service" && key == "ExecStart":
+			haveExecStart = true
+		case section == "Timer" && systemdTimerTriggers[key]:
+			haveTimerTrigger = true
+		}
+	}
+
+	if haveService && !haveExecStart {
+		switch serviceType {
+		case "simple", "exec", "forking":
+			r.Errors = append(r.Errors, ValidationError{
+				Severity: SeverityError,
+				Message:  fmt.Sprintf("[Service] with Type=%s requires ExecStart", serviceType),
+			})
+		}
+	}
+	if haveTimer && !haveTimerTrigger {
+		r.Errors = append(r.Errors, ValidationError{
+			Severity: SeverityError,
+			Message:  "[Timer] requires at least one OnCalendar/OnBootSec/On*Sec directive",
+		})
+	}
+
+	r.Valid = len(r.Errors) == 0
+	return r
+}
+
+// --- crontab ---
+//
+// validateCrontab parses each non-comment, non-assignment line as a
+// 5-field schedule (minute hour day-of-month month day-of-week) followed
+// by a command, or a "@keyword command" shorthand. A 6th leading field
+// (the system-crontab username, as in /etc/crontab or /etc/cron.d/*) is
+// accepted the same way a 5-field user crontab's command is — validation
+// only inspects the 5 schedule fields, so it can't tell the two forms
+// apart, but it never needs to.
+
+var cronEnvRe = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*\s*=`)
+
+var cronMacros = map[string]bool{
+	"reboot": true, "yearly": true, "annually": true, "monthly": true,
+	"weekly": true, "daily": true, "midnight": true, "hourly": true,
+}
+
+var cronMonthNames = map[string]int{
+	"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+	"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+}
+
+var cronDowNames = map[string]int{
+	"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+}
+
+type cronFieldSpec struct {
+	name  string
+	min   int
+	max   int
+	names map[string]int
+}
+
+var cronFieldSpecs = []cronFieldSpec{
+	{"minute", 0, 59, nil},
+	{"hour", 0, 23, nil},
+	{"day of month", 1, 31, nil},
+	{"month", 1, 12, cronMonthNames},
+	{"day of week", 0, 7, cronDowNames},
+}
+
+func validateCrontab(content string) *ValidationResult {
+	r := &ValidationResult{FileType: "crontab"}
+
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || cronEnvRe.MatchString(line) {
+			continue
+		}
+
+		if strings.HasPrefix(line, "@") {
+			fields := strings.Fields(line)
+			macro := strings.ToLower(strings.TrimPrefix(fields[0], "@"))
+			if !cronMacros[macro] {
+				r.Errors = append(r.Errors, simpleError(lineNum, fmt.Sprintf("unknown schedule macro: @%s", macro)))
+				continue
+			}
+			if len(fields) < 2 {
+				r.Errors = append(r.Errors, simpleError(lineNum, "missing command after schedule macro"))
+			}
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) < 6 {
+			r.Errors = append(r.Errors, simpleError(lineNum, "expected a 5-field schedule followed by a command"))
+			continue
+		}
+
+		for _, msg := range checkCronSchedule(fields[:5]) {
+			r.Errors = append(r.Errors, simpleError(lineNum, msg))
+		}
+	}
+
+	r.Valid = len(r.Errors) == 0
+	return r
+}
+
+func checkCronSchedule(fields []string) []string {
+	var problems []string
+	for i, spec := range cronFieldSpecs {
+		if !validCronField(fields[i], spec) {
+			problems = append(problems, fmt.Sprintf("invalid %s field: %q", spec.name, fields[i]))
+		}
+	}
+	return problems
+}
+
+func validCronField(field string, spec cronFieldSpec) bool {
+	for _, part := range strings.Split(field, ",") {
+		base := part
+		if idx := strings.Index(part, "/"); idx >= 0 {
+			base = part[:idx]
+			step, err := strconv.Atoi(part[idx+1:])
+			if err != nil || step <= 0 {
+				return false
+			}
+		}
+		if base == "" || !validCronValue(base, spec) {
+			return false
+		}
+	}
+	return true
+}
+
+func validCronValue(s string, spec cronFieldSpec) bool {
+	if s == "*" {
+		return true
+	}
+	if lo, hi, ok := strings.Cut(s, "-"); ok {
+		a, errA := cronFieldNumber(lo, spec.names)
+		b, errB := cronFieldNumber(hi, spec.names)
+		return errA == nil && errB == nil && a >= spec.min && a <= spec.max && b >= spec.min && b <= spec.max
+	}
+	n, err := cronFieldNumber(s, spec.names)
+	return err == nil && n >= spec.min && n <= spec.max
+}
+
+func cronFieldNumber(s string, names map[string]int) (int, error) {
+	if n, ok := names[strings.ToLower(s)]; ok {
+		return n, nil
+	}
+	return strconv.Atoi(s)
+}
@@ -0,0 +1,157 @@
+package tools
+
+import "testing"
+
+func TestValidateNginxValid(t *testing.T) {
+	content := `
+http {
+    server {
+        listen 80;
+        location / {
+            proxy_pass http://backend;
+        }
+    }
+}
+`
+	r := validateNginx(content)
+	if !r.Valid {
+		t.Fatalf("expected valid nginx config, got errors: %v", r.Errors)
+	}
+}
+
+func TestValidateNginxServerOutsideHttp(t *testing.T) {
+	content := `
+server {
+    listen 80;
+}
+`
+	r := validateNginx(content)
+	if r.Valid {
+		t.Fatalf("expected invalid: server outside http")
+	}
+}
+
+func TestValidateNginxUnterminatedDirective(t *testing.T) {
+	content := `
+http {
+    server {
+        listen 80
+    }
+}
+`
+	r := validateNginx(content)
+	if r.Valid {
+		t.Fatalf("expected invalid: missing ';'")
+	}
+}
+
+func TestValidateNginxUnbalancedBraces(t *testing.T) {
+	r := validateNginx("http {\n  server {\n    listen 80;\n  }\n")
+	if r.Valid {
+		t.Fatalf("expected invalid: unbalanced braces")
+	}
+}
+
+func TestValidateCaddyfileValid(t *testing.T) {
+	content := `
+example.com {
+    reverse_proxy localhost:8080
+    @static path *.css *.js
+    import snippets/common
+}
+`
+	r := validateCaddyfile(content)
+	if !r.Valid {
+		t.Fatalf("expected valid Caddyfile, got errors: %v", r.Errors)
+	}
+}
+
+func TestValidateCaddyfileBadMatcher(t *testing.T) {
+	r := validateCaddyfile("example.com {\n    @ path /foo\n}\n")
+	if r.Valid {
+		t.Fatalf("expected invalid: bad matcher syntax")
+	}
+}
+
+func TestValidateCaddyfileImportMissingArg(t *testing.T) {
+	r := validateCaddyfile("example.com {\n    import\n}\n")
+	if r.Valid {
+		t.Fatalf("expected invalid: import with no argument")
+	}
+}
+
+func TestValidateSystemdServiceValid(t *testing.T) {
+	content := `
+[Unit]
+Description=example
+
+[Service]
+Type=simple
+ExecStart=/usr/bin/example
+
+[Install]
+WantedBy=multi-user.target
+`
+	r := validateSystemdUnit(content)
+	if !r.Valid {
+		t.Fatalf("expected valid unit, got errors: %v", r.Errors)
+	}
+}
+
+func TestValidateSystemdServiceMissingExecStart(t *testing.T) {
+	content := "[Service]\nType=forking\n"
+	r := validateSystemdUnit(content)
+	if r.Valid {
+		t.Fatalf("expected invalid: missing ExecStart")
+	}
+}
+
+func TestValidateSystemdTimerMissingTrigger(t *testing.T) {
+	content := "[Timer]\nUnit=example.service\n"
+	r := validateSystemdUnit(content)
+	if r.Valid {
+		t.Fatalf("expected invalid: timer with no schedule directive")
+	}
+}
+
+func TestValidateSystemdUnknownSection(t *testing.T) {
+	content := "[Bogus]\nFoo=bar\n"
+	r := validateSystemdUnit(content)
+	if r.Valid {
+		t.Fatalf("expected invalid: unknown section")
+	}
+}
+
+func TestValidateCrontabValid(t *testing.T) {
+	content := `# comment
+MAILTO=root
+*/15 * * * * /usr/bin/true
+0 2 * * mon /usr/bin/backup
+@reboot /usr/bin/startup
+`
+	r := validateCrontab(content)
+	if !r.Valid {
+		t.Fatalf("expected valid crontab, got errors: %v", r.Errors)
+	}
+}
+
+func TestValidateCrontabBadMinute(t *testing.T) {
+	r := validateCrontab("99 * * * * /usr/bin/true\n")
+	if r.Valid {
+		t.Fatalf("expected invalid: minute out of range")
+	}
+}
+
+func TestValidateCrontabUnknownMacro(t *testing.T) {
+	r := validateCrontab("@fortnightly /usr/bin/true\n")
+	if r.Valid {
+		t.Fatalf("expected invalid: unknown schedule macro")
+	}
+}
+
+func TestValidateCrontabTooFewFields(t *testing.T) {
+	r := validateCrontab("* * * * *\n")
+	if r.Valid {
+		t.Fatalf("expected invalid: missing command")
+	}
+}
@@ -0,0 +1,67 @@
+package tools
+
+import "testing"
+
+func TestValidateSSHDConfigValid(t *testing.T) {
+	content := `
+# basic server config
+Port 22
+ListenAddress 0.0.0.0
+PermitRootLogin no
+PasswordAuthentication yes
+Ciphers aes256-gcm@openssh.com,chacha20-poly1305@openssh.com
+AllowUsers deploy admin
+
+Match User deploy
+	X11Forwarding no
+	AllowTcpForwarding no
+`
+	r := validateSSHDConfig(content)
+	if !r.Valid {
+		t.Fatalf("expected valid, got errors: %v", r.Errors)
+	}
+}
+
+func TestValidateSSHDConfigUnknownKeyword(t *testing.T) {
+	r := validateSSHDConfig("PermitRotLogin no\n")
+	if r.Valid {
+		t.Fatal("expected invalid for misspelled keyword")
+	}
+}
+
+func TestValidateSSHDConfigBadEnumValue(t *testing.T) {
+	r := validateSSHDConfig("PermitRootLogin maybe\n")
+	if r.Valid {
+		t.Fatal("expected invalid for out-of-range enum value")
+	}
+}
+
+func TestValidateSSHDConfigGlobalOnlyInsideMatch(t *testing.T) {
+	content := "Match User deploy\n\tPort 2222\n"
+	r := validateSSHDConfig(content)
+	if r.Valid {
+		t.Fatal("expected invalid: Port is not allowed inside a Match block")
+	}
+}
+
+func TestValidateSSHConfigValid(t *testing.T) {
+	content := `
+Host bastion
+	HostName bastion.example.com
+	User ops
+	Port 22
+	IdentityFile ~/.ssh/id_ed25519
+	StrictHostKeyChecking accept-new
+`
+	r := validateSSHConfig(content)
+	if !r.Valid {
+		t.Fatalf("expected valid, got errors: %v", r.Errors)
+	}
+}
+
+func TestValidateSSHConfigUnknownKeyword(t *testing.T) {
+	r := validateSSHConfig("HostNme bastion.example.com\n")
+	if r.Valid {
+		t.Fatal("expected invalid for misspelled keyword")
+	}
+}
@@ -0,0 +1,80 @@
+package tools
+
+import "testing"
+
+func TestValidateJSONSyntaxErrorHasPosition(t *testing.T) {
+	r := validateJSON("{\n  \"a\": ,\n}")
+	if r.Valid {
+		t.Fatalf("expected invalid JSON")
+	}
+	if len(r.Errors) != 1 {
+		t.Fatalf("expected 1 error, got %d", len(r.Errors))
+	}
+	e := r.Errors[0]
+	if e.Line != 2 {
+		t.Fatalf("expected error on line 2, got line %d", e.Line)
+	}
+	if e.Snippet == "" {
+		t.Fatalf("expected a snippet")
+	}
+}
+
+func TestValidateYAMLSyntaxErrorHasPosition(t *testing.T) {
+	r := validateYAML("a: 1\nb: [1, 2\n")
+	if r.Valid {
+		t.Fatalf("expected invalid YAML")
+	}
+	if r.Errors[0].Line == 0 {
+		t.Fatalf("expected a recovered line number, got 0")
+	}
+}
+
+func TestValidateTOMLSyntaxErrorHasPosition(t *testing.T) {
+	r := validateTOML("a = 1\nb = [1, 2\n")
+	if r.Valid {
+		t.Fatalf("expected invalid TOML")
+	}
+	if r.Errors[0].Line == 0 {
+		t.Fatalf("expected a recovered line number, got 0")
+	}
+}
+
+func TestValidateXMLSyntaxErrorHasLine(t *testing.T) {
+	r := validateXML("<a>\n<b></a>\n")
+	if r.Valid {
+		t.Fatalf("expected invalid XML")
+	}
+	if r.Errors[0].Line == 0 {
+		t.Fatalf("expected a line number, got 0")
+	}
+}
+
+func TestValidationErrorStringFallsBackWithoutLine(t *testing.T) {
+	e := ValidationError{Message: "boom"}
+	if got := e.String(); got != "boom" {
+		t.Fatalf("got %q, want %q", got, "boom")
+	}
+	e.Line = 3
+	if got := e.String(); got != "line 3: boom" {
+		t.Fatalf("got %q, want %q", got, "line 3: boom")
+	}
+}
+
+func TestFormatJSONRoundTrips(t *testing.T) {
+	r := validateINI("not valid\n")
+	out := r.FormatJSON()
+	if out == "{}" || out == "" {
+		t.Fatalf("expected non-empty JSON output")
+	}
+}
+
+func TestFormatSARIFHasResultPerError(t *testing.T) {
+	r := validateENV("=bad\nFOO=ok\n")
+	if r.Valid {
+		t.Fatalf("expected invalid env file")
+	}
+	out := r.FormatSARIF("app.env")
+	if out == "{}" {
+		t.Fatalf("expected a populated SARIF log")
+	}
+}
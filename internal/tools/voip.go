@@ -4,9 +4,14 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
+	"log"
+	"sort"
 	"strings"
 	"time"
 
+	"ssh-mcp/internal/hep"
+	"ssh-mcp/internal/sip"
 	"ssh-mcp/internal/ssh"
 
 	"github.com/mark3labs/mcp-go/mcp"
@@ -15,13 +20,18 @@ import (
 
 // VoIP constants
 const (
-	SIPUDPPort    = 5060
-	SIPTCPPort    = 5060
-	SIPTLSPort    = 5061
-	RTPPortRange  = "50000-60000"
+	SIPUDPPort       = 5060
+	SIPTCPPort       = 5060
+	SIPTLSPort       = 5061
+	RTPPortRange     = "50000-60000"
 	DefaultPCAPLimit = 5 * 1024 * 1024
 )
 
+// voipContainerKeywords matches container names/images likely to carry SIP
+// signaling, shared by voip_discover_containers and voip_correlate_calls'
+// auto-discovery of legs to correlate.
+var voipContainerKeywords = []string{"gw", "media", "fs", "sbc", "sw", "freeswitch", "asterisk", "kamailio", "opensips", "rtpengine"}
+
 // registerVoIPTools registers VoIP troubleshooting tools.
 func registerVoIPTools(s *server.MCPServer, pool *ssh.Pool) {
 	// voip_discover_containers
@@ -145,6 +155,84 @@ func registerVoIPTools(s *server.MCPServer, pool *ssh.Pool) {
 		),
 		createNetworkDiagnosticsHandler(pool),
 	)
+
+	// sip_analyze_pcap
+	s.AddTool(
+		mcp.NewTool("sip_analyze_pcap",
+			mcp.WithDescription("Parse a PCAP file on the remote host with the native sip analyzer and return a compact summary: stats, registrations, and failing calls"),
+			mcp.WithString("path", mcp.Required(), mcp.Description("Path to the PCAP file on the remote host")),
+			mcp.WithString("call_id_filter", mcp.Description("Only include this Call-ID")),
+			mcp.WithString("since", mcp.Description("RFC3339 timestamp; exclude calls that started before it")),
+			mcp.WithString("until", mcp.Description("RFC3339 timestamp; exclude calls that started after it")),
+			mcp.WithString("target", mcp.Description("Connection alias (default: primary)")),
+		),
+		createSIPAnalyzePCAPHandler(pool),
+	)
+
+	// sip_tail_sip
+	s.AddTool(
+		mcp.NewTool("sip_tail_sip",
+			mcp.WithDescription("Run tcpdump on the remote host filtered to SIP traffic and stream parsed SIP messages back as MCP \"notifications/message\" notifications as they're captured"),
+			mcp.WithString("interface", mcp.Description("Network interface to capture on (default: any)")),
+			mcp.WithNumber("duration", mcp.Description("Seconds to capture before returning (default: 30)")),
+			mcp.WithString("target", mcp.Description("Connection alias (default: primary)")),
+		),
+		createSIPTailHandler(pool),
+	)
+
+	// voip_sip_stream
+	s.AddTool(
+		mcp.NewTool("voip_sip_stream",
+			mcp.WithDescription(`Run tcpdump inside a container and stream decoded SIP messages and RTP packets back to the MCP client live, instead of writing a PCAP file and requiring a second tool to parse it. Cancel the request to stop the capture early.
+
+Each SIP message is sent as a "notifications/message" notification with {"tool": "voip_sip_stream", "kind": "sip", "message": <sip.Message>}; RTP is sent the same way with kind "rtp" and an "rtp" field, but an RTP frame is dropped instead of queued when a previous RTP notification to the client is still in flight, so a slow client never stalls SIP signaling. The tool result contains every SIP message collected once the capture ends.`),
+			mcp.WithString("container", mcp.Required(), mcp.Description("Container name")),
+			mcp.WithNumber("duration", mcp.Description("Capture duration in seconds (default: 30)")),
+			mcp.WithString("interface", mcp.Description("Network interface (default: any)")),
+			mcp.WithString("target", mcp.Description("Connection alias (default: primary)")),
+		),
+		createSIPStreamHandler(pool),
+	)
+
+	// voip_hep_export
+	s.AddTool(
+		mcp.NewTool("voip_hep_export",
+			mcp.WithDescription("Re-emit every SIP message in a captured PCAP as a HEP v3 packet to a Homer/HEPIC collector, so teams already running a capture agent can ingest it without re-capturing at the SBC"),
+			mcp.WithString("container", mcp.Required(), mcp.Description("Container name")),
+			mcp.WithString("pcap_file", mcp.Required(), mcp.Description("Path to PCAP file in container")),
+			mcp.WithString("collector", mcp.Required(), mcp.Description("Collector address as host:port")),
+			mcp.WithString("network", mcp.Description("Transport to the collector: udp (default) or tcp")),
+			mcp.WithNumber("capture_id", mcp.Description("HEP capture agent ID reported to the collector (default: 0)")),
+			mcp.WithString("auth_key", mcp.Description("HEP auth key, if the collector requires one")),
+			mcp.WithString("call_id", mcp.Description("Only export messages for this Call-ID")),
+			mcp.WithString("target", mcp.Description("Connection alias (default: primary)")),
+		),
+		createHEPExportHandler(pool),
+	)
+
+	// voip_rtp_quality
+	s.AddTool(
+		mcp.NewTool("voip_rtp_quality",
+			mcp.WithDescription("Compute per-SSRC RTP quality stats (loss %, jitter, estimated MOS) from an existing PCAP or a short live capture, flagging streams with loss > 5% or jitter > 30ms. Codec is reported when the stream's endpoint matches a call's SDP offer."),
+			mcp.WithString("container", mcp.Required(), mcp.Description("Container name")),
+			mcp.WithString("pcap_file", mcp.Description("Path to an existing PCAP file in the container; omit to run a live capture instead")),
+			mcp.WithNumber("duration", mcp.Description("Live capture duration in seconds, used only when pcap_file is omitted (default: 10)")),
+			mcp.WithString("port_range", mcp.Description("RTP port range for a live capture (default: 50000-60000)")),
+			mcp.WithString("call_id", mcp.Description("Only report the stream(s) whose SDP-matched Call-ID equals this")),
+			mcp.WithString("target", mcp.Description("Connection alias (default: primary)")),
+		),
+		createRTPQualityHandler(pool),
+	)
+
+	// voip_correlate_calls
+	s.AddTool(
+		mcp.NewTool("voip_correlate_calls",
+			mcp.WithDescription(`Stitch SIP dialogs captured on different containers/hosts into "super-calls" for B2BUA/SBC scenarios where each leg is a separate Call-ID. Legs are matched by, in order: exact Call-ID, matching From/To tags, X-CID header, P-Charging-Vector header, SDP "o=" session-id, and RTP SSRC continuity across legs' media streams. Each super-call reports its legs (container, Call-ID, final status), per-leg INVITE->100->180->200->ACK->BYE timing, and flags legs with signaling but no matched RTP flow (one-way audio).`),
+			mcp.WithArray("legs", mcp.Description("List of {container, pcap_file, target} objects to correlate. Omit to auto-discover: every connected target alias is scanned for VoIP containers with a captured /tmp/voip_sip_*.pcap")),
+			mcp.WithString("target", mcp.Description("Connection alias to use when 'legs' entries omit their own target (default: primary)")),
+		),
+		createCorrelateCallsHandler(pool),
+	)
 }
 
 func createVoIPDiscoverHandler(pool *ssh.Pool) server.ToolHandlerFunc {
@@ -160,11 +248,8 @@ func createVoIPDiscoverHandler(pool *ssh.Pool) server.ToolHandlerFunc {
 			return mcp.NewToolResultError(err.Error()), nil
 		}
 
-		// Default VoIP keywords
-		defaultKeywords := []string{"gw", "media", "fs", "sbc", "sw", "freeswitch", "asterisk", "kamailio", "opensips", "rtpengine"}
-
 		// Build grep pattern
-		pattern := strings.Join(defaultKeywords, "\\|")
+		pattern := strings.Join(voipContainerKeywords, "\\|")
 		cmd := fmt.Sprintf(`docker ps --format '{{.Names}}|{{.Image}}' | grep -iE '%s' 2>/dev/null || echo ''`, pattern)
 
 		output, err := mgr.Execute(ctx, cmd, target)
@@ -234,11 +319,11 @@ func createSIPCaptureHandler(pool *ssh.Pool) server.ToolHandlerFunc {
 		mgr.Execute(ctx, cmd, target) // Ignore timeout error
 
 		result := map[string]interface{}{
-			"container":  container,
-			"pcap_file":  pcapPath,
-			"duration":   duration,
-			"filter":     bpfFilter,
-			"message":    fmt.Sprintf("SIP capture completed. Use voip_call_flow to analyze %s", pcapPath),
+			"container": container,
+			"pcap_file": pcapPath,
+			"duration":  duration,
+			"filter":    bpfFilter,
+			"message":   fmt.Sprintf("SIP capture completed. Use voip_call_flow to analyze %s", pcapPath),
 		}
 
 		jsonBytes, _ := json.MarshalIndent(result, "", "  ")
@@ -264,34 +349,85 @@ func createCallFlowHandler(pool *ssh.Pool) server.ToolHandlerFunc {
 			return mcp.NewToolResultError(err.Error()), nil
 		}
 
-		// Use tshark for PCAP analysis (most reliable)
-		var filter string
+		reader, err := openContainerPCAP(ctx, mgr, container, pcapFile, target)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		defer reader.Close()
+
+		// callID is applied via ParseOptions rather than interpolated into a
+		// shell filter string, so a Call-ID containing shell metacharacters
+		// can't escape into the docker exec command.
+		opts := sip.ParseOptions{SkipSDP: summaryOnly}
 		if callID != "" {
-			filter = fmt.Sprintf("-Y 'sip.Call-ID == \"%s\"'", callID)
-		} else if phoneNumber != "" {
-			filter = fmt.Sprintf("-Y 'sip contains \"%s\"'", phoneNumber)
+			opts.CallIDAllowlist = []string{callID}
+		}
+
+		result, err := sip.ParsePCAPStream(ctx, reader, opts)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to parse pcap: %v", err)), nil
+		}
+
+		messages := result.Messages
+		if phoneNumber != "" {
+			messages = filterMessagesByPhoneNumber(messages, phoneNumber)
 		}
 
-		var cmd string
+		var jsonBytes []byte
 		if summaryOnly {
-			// Get summary: method, response codes, call-ids
-			cmd = fmt.Sprintf(`docker exec %s sh -c 'if command -v tshark >/dev/null 2>&1; then tshark -r %s -T fields -e frame.time -e ip.src -e ip.dst -e sip.Method -e sip.Status-Code -e sip.Call-ID %s 2>/dev/null | head -100; else sngrep -I %s -q 2>/dev/null | head -50 || echo "No analysis tool available"; fi'`,
-				shellQuote(container), pcapFile, filter, pcapFile)
+			jsonBytes, err = json.MarshalIndent(summarizeCallFlow(messages), "", "  ")
 		} else {
-			// Get detailed call flow
-			cmd = fmt.Sprintf(`docker exec %s sh -c 'if command -v tshark >/dev/null 2>&1; then tshark -r %s -V -Y sip %s 2>/dev/null | head -500; else cat %s 2>/dev/null | strings | grep -E "^(INVITE|REGISTER|BYE|ACK|CANCEL|SIP/2.0)" | head -100 || echo "No analysis tool available"; fi'`,
-				shellQuote(container), pcapFile, filter, pcapFile)
+			jsonBytes, err = json.MarshalIndent(messages, "", "  ")
 		}
-
-		output, err := mgr.Execute(ctx, cmd, target)
 		if err != nil {
-			return mcp.NewToolResultError(err.Error()), nil
+			return mcp.NewToolResultError("failed to format call flow"), nil
 		}
 
-		return mcp.NewToolResultText(output), nil
+		return mcp.NewToolResultText(string(jsonBytes)), nil
 	}
 }
 
+// callFlowSummary is the trimmed per-message view returned by
+// createCallFlowHandler when summary_only is set: one line's worth of
+// fields, enough to skim a call without the full message/SDP bodies.
+type callFlowSummary struct {
+	Time       string `json:"time"`
+	SrcIP      string `json:"src_ip"`
+	DstIP      string `json:"dst_ip"`
+	Method     string `json:"method,omitempty"`
+	StatusCode int    `json:"status_code,omitempty"`
+	CallID     string `json:"call_id"`
+}
+
+func summarizeCallFlow(messages []sip.Message) []callFlowSummary {
+	summaries := make([]callFlowSummary, 0, len(messages))
+	for _, m := range messages {
+		summaries = append(summaries, callFlowSummary{
+			Time:       m.Time,
+			SrcIP:      m.SrcIP,
+			DstIP:      m.DstIP,
+			Method:     m.Method,
+			StatusCode: m.StatusCode,
+			CallID:     m.CallID,
+		})
+	}
+	return summaries
+}
+
+// filterMessagesByPhoneNumber keeps messages whose From/To user or URI
+// contains phoneNumber, mirroring the old `sip contains "<number>"` tshark
+// filter but applied in Go against already-parsed fields.
+func filterMessagesByPhoneNumber(messages []sip.Message, phoneNumber string) []sip.Message {
+	filtered := make([]sip.Message, 0, len(messages))
+	for _, m := range messages {
+		if strings.Contains(m.FromUser, phoneNumber) || strings.Contains(m.ToUser, phoneNumber) ||
+			strings.Contains(m.FromURI, phoneNumber) || strings.Contains(m.ToURI, phoneNumber) {
+			filtered = append(filtered, m)
+		}
+	}
+	return filtered
+}
+
 func createRegistrationsHandler(pool *ssh.Pool) server.ToolHandlerFunc {
 	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		mgr := getManager(ctx, pool)
@@ -307,16 +443,23 @@ func createRegistrationsHandler(pool *ssh.Pool) server.ToolHandlerFunc {
 			return mcp.NewToolResultError(err.Error()), nil
 		}
 
-		// Extract REGISTER dialogs using tshark or strings
-		cmd := fmt.Sprintf(`docker exec %s sh -c 'if command -v tshark >/dev/null 2>&1; then tshark -r %s -Y "sip.Method == REGISTER or (sip.CSeq.method == REGISTER and sip.Status-Code)" -T fields -e frame.time -e sip.from.user -e sip.to.user -e sip.contact.uri -e sip.Status-Code -E header=y 2>/dev/null; else cat %s 2>/dev/null | strings | grep -E "(REGISTER|200 OK|401|403)" | head -50; fi'`,
-			shellQuote(container), pcapFile, pcapFile)
-
-		output, err := mgr.Execute(ctx, cmd, target)
+		reader, err := openContainerPCAP(ctx, mgr, container, pcapFile, target)
 		if err != nil {
 			return mcp.NewToolResultError(err.Error()), nil
 		}
+		defer reader.Close()
+
+		result, err := sip.ParsePCAPStream(ctx, reader, sip.ParseOptions{SkipSDP: true})
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to parse pcap: %v", err)), nil
+		}
+
+		jsonBytes, err := json.MarshalIndent(result.Registrations, "", "  ")
+		if err != nil {
+			return mcp.NewToolResultError("failed to format registrations"), nil
+		}
 
-		return mcp.NewToolResultText(output), nil
+		return mcp.NewToolResultText(string(jsonBytes)), nil
 	}
 }
 
@@ -335,29 +478,23 @@ func createCallStatsHandler(pool *ssh.Pool) server.ToolHandlerFunc {
 			return mcp.NewToolResultError(err.Error()), nil
 		}
 
-		// Aggregate stats using tshark
-		cmd := fmt.Sprintf(`docker exec %s sh -c '
-if command -v tshark >/dev/null 2>&1; then
-  echo "=== SIP STATISTICS ==="
-  echo ""
-  echo "--- Request Methods ---"
-  tshark -r %s -Y sip.Method -T fields -e sip.Method 2>/dev/null | sort | uniq -c | sort -rn
-  echo ""
-  echo "--- Response Codes ---"
-  tshark -r %s -Y sip.Status-Code -T fields -e sip.Status-Code 2>/dev/null | sort | uniq -c | sort -rn
-  echo ""
-  echo "--- Unique Call-IDs ---"
-  tshark -r %s -Y sip -T fields -e sip.Call-ID 2>/dev/null | sort -u | wc -l | xargs echo "Total calls:"
-else
-  cat %s 2>/dev/null | strings | grep -oE "^(INVITE|REGISTER|BYE|ACK|CANCEL|OPTIONS|SIP/2.0 [0-9]+)" | sort | uniq -c | sort -rn
-fi'`, shellQuote(container), pcapFile, pcapFile, pcapFile, pcapFile)
-
-		output, err := mgr.Execute(ctx, cmd, target)
+		reader, err := openContainerPCAP(ctx, mgr, container, pcapFile, target)
 		if err != nil {
 			return mcp.NewToolResultError(err.Error()), nil
 		}
+		defer reader.Close()
 
-		return mcp.NewToolResultText(output), nil
+		result, err := sip.ParsePCAPStream(ctx, reader, sip.ParseOptions{SkipSDP: true})
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to parse pcap: %v", err)), nil
+		}
+
+		jsonBytes, err := json.MarshalIndent(result.Stats, "", "  ")
+		if err != nil {
+			return mcp.NewToolResultError("failed to format stats"), nil
+		}
+
+		return mcp.NewToolResultText(string(jsonBytes)), nil
 	}
 }
 
@@ -377,24 +514,49 @@ func createExtractSDPHandler(pool *ssh.Pool) server.ToolHandlerFunc {
 			return mcp.NewToolResultError(err.Error()), nil
 		}
 
-		var filter string
+		reader, err := openContainerPCAP(ctx, mgr, container, pcapFile, target)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		defer reader.Close()
+
+		opts := sip.ParseOptions{}
 		if callID != "" {
-			filter = fmt.Sprintf("-Y 'sip.Call-ID == \"%s\" and sdp'", callID)
-		} else {
-			filter = "-Y 'sdp'"
+			opts.CallIDAllowlist = []string{callID}
 		}
 
-		// Extract SDP with tshark
-		cmd := fmt.Sprintf(`docker exec %s sh -c 'if command -v tshark >/dev/null 2>&1; then tshark -r %s %s -T fields -e sdp.connection_info -e sdp.media -e sdp.media.port -e sdp.media.format -E header=y 2>/dev/null | head -50; else cat %s 2>/dev/null | strings | grep -E "^(c=|m=|a=rtpmap)" | head -50; fi'`,
-			shellQuote(container), pcapFile, filter, pcapFile)
+		result, err := sip.ParsePCAPStream(ctx, reader, opts)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to parse pcap: %v", err)), nil
+		}
 
-		output, err := mgr.Execute(ctx, cmd, target)
+		sdps := extractSDPEntries(result.Messages)
+
+		jsonBytes, err := json.MarshalIndent(sdps, "", "  ")
 		if err != nil {
-			return mcp.NewToolResultError(err.Error()), nil
+			return mcp.NewToolResultError("failed to format sdp"), nil
 		}
 
-		return mcp.NewToolResultText(output), nil
+		return mcp.NewToolResultText(string(jsonBytes)), nil
+	}
+}
+
+// sdpEntry pairs a parsed SDP body with the SIP message it was carried in.
+type sdpEntry struct {
+	Time   string  `json:"time"`
+	CallID string  `json:"call_id"`
+	SDP    sip.SDP `json:"sdp"`
+}
+
+func extractSDPEntries(messages []sip.Message) []sdpEntry {
+	entries := make([]sdpEntry, 0)
+	for _, m := range messages {
+		if !m.HasSDP || m.SDP == nil {
+			continue
+		}
+		entries = append(entries, sdpEntry{Time: m.Time, CallID: m.CallID, SDP: *m.SDP})
 	}
+	return entries
 }
 
 func createPacketCheckHandler(pool *ssh.Pool) server.ToolHandlerFunc {
@@ -458,11 +620,11 @@ func createNetworkCaptureHandler(pool *ssh.Pool) server.ToolHandlerFunc {
 		mgr.Execute(ctx, cmd, target) // Ignore timeout
 
 		result := map[string]interface{}{
-			"container":  container,
-			"pcap_file":  pcapPath,
-			"duration":   duration,
-			"interface":  iface,
-			"message":    fmt.Sprintf("Network capture complete. Analyze with voip_call_flow or copy with docker_cp_from"),
+			"container": container,
+			"pcap_file": pcapPath,
+			"duration":  duration,
+			"interface": iface,
+			"message":   fmt.Sprintf("Network capture complete. Analyze with voip_call_flow or copy with docker_cp_from"),
 		}
 
 		jsonBytes, _ := json.MarshalIndent(result, "", "  ")
@@ -593,3 +755,816 @@ func buildSIPFilter(port int, protocol string) string {
 
 	return "udp port 5060 or tcp port 5060 or tcp port 5061"
 }
+
+func createSIPAnalyzePCAPHandler(pool *ssh.Pool) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		mgr := getManager(ctx, pool)
+		if mgr == nil {
+			return mcp.NewToolResultError("No active session"), nil
+		}
+
+		path, _ := req.RequireString("path")
+		target := req.GetString("target", "primary")
+		callIDFilter := req.GetString("call_id_filter", "")
+
+		since, err := parseOptionalRFC3339(req.GetString("since", ""))
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("invalid since: %v", err)), nil
+		}
+		until, err := parseOptionalRFC3339(req.GetString("until", ""))
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("invalid until: %v", err)), nil
+		}
+
+		reader, err := openRemotePCAP(ctx, mgr, path, target)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		defer reader.Close()
+
+		opts := sip.ParseOptions{}
+		if callIDFilter != "" {
+			opts.CallIDAllowlist = []string{callIDFilter}
+		}
+
+		result, err := sip.ParsePCAPStream(ctx, reader, opts)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to parse pcap: %v", err)), nil
+		}
+
+		calls := result.Calls
+		if !since.IsZero() || !until.IsZero() {
+			calls = filterCallsByWindow(calls, since, until)
+		}
+
+		summary := map[string]interface{}{
+			"stats":         result.Stats,
+			"registrations": result.Registrations,
+			"total_calls":   len(calls),
+			"failing_calls": failingCalls(calls),
+		}
+
+		jsonBytes, err := json.MarshalIndent(summary, "", "  ")
+		if err != nil {
+			return mcp.NewToolResultError("failed to format summary"), nil
+		}
+
+		return mcp.NewToolResultText(string(jsonBytes)), nil
+	}
+}
+
+// openContainerPCAP streams a PCAP file out of a running container over the
+// existing SSH exec channel. `docker exec ... cat` is binary-safe (unlike
+// shelling a parser like tshark out through it), so the native sip parser
+// can read it directly without staging a copy on the host first.
+func openContainerPCAP(ctx context.Context, mgr *ssh.Manager, container, path, target string) (io.ReadCloser, error) {
+	cmd := fmt.Sprintf("docker exec %s cat %s 2>/dev/null", shellQuote(container), shellQuote(path))
+	return mgr.StreamCommand(ctx, cmd, target)
+}
+
+// openRemotePCAP opens path on target for streaming, preferring SFTP
+// (mgr.OpenRead) and falling back to a dd pipeline over the SSH session's
+// exec channel (which is binary-safe, so no base64 encoding is needed) when
+// the remote host has no SFTP subsystem.
+func openRemotePCAP(ctx context.Context, mgr *ssh.Manager, path, target string) (io.ReadCloser, error) {
+	reader, err := mgr.OpenRead(ctx, path, target, nil)
+	if err == nil {
+		return reader, nil
+	}
+
+	cmd := fmt.Sprintf("dd if=%s bs=1M 2>/dev/null", shellQuote(path))
+	return mgr.StreamCommand(ctx, cmd, target)
+}
+
+// parseOptionalRFC3339 parses an RFC3339 timestamp, returning the zero
+// time for an empty string.
+func parseOptionalRFC3339(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(time.RFC3339, s)
+}
+
+// filterCallsByWindow keeps calls whose StartTime falls within [since, until],
+// treating a zero bound as unbounded.
+func filterCallsByWindow(calls []sip.Call, since, until time.Time) []sip.Call {
+	filtered := make([]sip.Call, 0, len(calls))
+	for _, call := range calls {
+		startTime, err := time.Parse(time.RFC3339, call.StartTime)
+		if err != nil {
+			continue
+		}
+		if !since.IsZero() && startTime.Before(since) {
+			continue
+		}
+		if !until.IsZero() && startTime.After(until) {
+			continue
+		}
+		filtered = append(filtered, call)
+	}
+	return filtered
+}
+
+// failingCalls returns the calls whose final status is "failed"; calls
+// whose status is "unknown" are not included.
+func failingCalls(calls []sip.Call) []sip.Call {
+	var failing []sip.Call
+	for _, call := range calls {
+		if call.FinalStatus == "failed" {
+			failing = append(failing, call)
+		}
+	}
+	return failing
+}
+
+func createSIPTailHandler(pool *ssh.Pool) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		mgr := getManager(ctx, pool)
+		if mgr == nil {
+			return mcp.NewToolResultError("No active session"), nil
+		}
+
+		iface := req.GetString("interface", "any")
+		duration := req.GetInt("duration", 30)
+		target := req.GetString("target", "primary")
+
+		captureCtx, cancel := context.WithTimeout(ctx, time.Duration(duration)*time.Second)
+		defer cancel()
+
+		cmd := fmt.Sprintf("tcpdump -i %s -U -w - 'port 5060' 2>/dev/null", shellQuote(iface))
+		reader, err := mgr.StreamCommand(captureCtx, cmd, target)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		defer reader.Close()
+
+		srv := server.ServerFromContext(ctx)
+		var messages []sip.Message
+
+		err = sip.WalkPCAP(captureCtx, reader, func(msg sip.Message) error {
+			messages = append(messages, msg)
+			if srv != nil {
+				if notifyErr := srv.SendNotificationToClient(ctx, "notifications/message", map[string]any{
+					"tool":    "sip_tail_sip",
+					"message": msg,
+				}); notifyErr != nil {
+					log.Printf("[Tool:sip_tail_sip] Failed to send notification: %v", notifyErr)
+				}
+			}
+			return nil
+		})
+		if err != nil && captureCtx.Err() == nil {
+			return mcp.NewToolResultError(fmt.Sprintf("capture failed: %v", err)), nil
+		}
+
+		jsonBytes, err := json.MarshalIndent(messages, "", "  ")
+		if err != nil {
+			return mcp.NewToolResultError("failed to format messages"), nil
+		}
+
+		return mcp.NewToolResultText(string(jsonBytes)), nil
+	}
+}
+
+// RTP quality thresholds used to flag a stream in voip_rtp_quality, per the
+// tool's documented "loss > 5% or jitter > 30 ms" criteria.
+const (
+	rtpQualityLossWarnPercent = 5.0
+	rtpQualityJitterWarnMs    = 30.0
+)
+
+// rtpQualityStream is sip.MediaStream plus the call-leg correlation and
+// threshold flag voip_rtp_quality reports on top of the raw stats.
+type rtpQualityStream struct {
+	sip.MediaStream
+	CallID     string `json:"call_id,omitempty"`
+	Flagged    bool   `json:"flagged"`
+	FlagReason string `json:"flag_reason,omitempty"`
+}
+
+func newRTPQualityStream(ms sip.MediaStream, callID string) rtpQualityStream {
+	s := rtpQualityStream{MediaStream: ms, CallID: callID}
+
+	var reasons []string
+	if ms.LossPercent > rtpQualityLossWarnPercent {
+		reasons = append(reasons, fmt.Sprintf("loss %.1f%% > %.0f%%", ms.LossPercent, rtpQualityLossWarnPercent))
+	}
+	if ms.JitterMs > rtpQualityJitterWarnMs {
+		reasons = append(reasons, fmt.Sprintf("jitter %.1fms > %.0fms", ms.JitterMs, rtpQualityJitterWarnMs))
+	}
+	if len(reasons) > 0 {
+		s.Flagged = true
+		s.FlagReason = strings.Join(reasons, "; ")
+	}
+	return s
+}
+
+func createRTPQualityHandler(pool *ssh.Pool) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		mgr := getManager(ctx, pool)
+		if mgr == nil {
+			return mcp.NewToolResultError("No active session"), nil
+		}
+
+		container, _ := req.RequireString("container")
+		pcapFile := req.GetString("pcap_file", "")
+		duration := req.GetInt("duration", 10)
+		portRange := req.GetString("port_range", RTPPortRange)
+		callIDFilter := req.GetString("call_id", "")
+		target := req.GetString("target", "primary")
+
+		if err := checkDockerAvailable(ctx, mgr, target); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		var reader io.ReadCloser
+		var err error
+		if pcapFile != "" {
+			reader, err = openContainerPCAP(ctx, mgr, container, pcapFile, target)
+		} else {
+			reader, err = openContainerLiveRTPCapture(ctx, mgr, container, duration, portRange, target)
+		}
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		defer reader.Close()
+
+		result, err := sip.ParsePCAPStream(ctx, reader, sip.ParseOptions{})
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to parse capture: %v", err)), nil
+		}
+
+		streams := make([]rtpQualityStream, 0)
+		for _, call := range result.Calls {
+			if callIDFilter != "" && call.CallID != callIDFilter {
+				continue
+			}
+			for _, ms := range call.MediaStreams {
+				streams = append(streams, newRTPQualityStream(ms, call.CallID))
+			}
+		}
+		if callIDFilter == "" {
+			for _, ms := range result.MediaStreams {
+				streams = append(streams, newRTPQualityStream(ms, ""))
+			}
+		}
+
+		jsonBytes, err := json.MarshalIndent(streams, "", "  ")
+		if err != nil {
+			return mcp.NewToolResultError("failed to format rtp quality"), nil
+		}
+
+		return mcp.NewToolResultText(string(jsonBytes)), nil
+	}
+}
+
+// openContainerLiveRTPCapture runs a bounded-duration tcpdump inside
+// container over portRange and streams the resulting pcap bytes back,
+// mirroring openContainerPCAP's binary-safe docker exec pipe but for a
+// fresh capture instead of a file already on disk.
+func openContainerLiveRTPCapture(ctx context.Context, mgr *ssh.Manager, container string, duration int, portRange, target string) (io.ReadCloser, error) {
+	ports := strings.Split(portRange, "-")
+	startPort, endPort := "50000", "60000"
+	if len(ports) == 2 {
+		startPort, endPort = ports[0], ports[1]
+	}
+
+	cmd := fmt.Sprintf("docker exec %s timeout %ds tcpdump -i any -U -w - 'udp portrange %s-%s' 2>/dev/null",
+		shellQuote(container), duration, startPort, endPort)
+	return mgr.StreamCommand(ctx, cmd, target)
+}
+
+func createSIPStreamHandler(pool *ssh.Pool) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		mgr := getManager(ctx, pool)
+		if mgr == nil {
+			return mcp.NewToolResultError("No active session"), nil
+		}
+
+		container, _ := req.RequireString("container")
+		duration := req.GetInt("duration", 30)
+		iface := req.GetString("interface", "any")
+		target := req.GetString("target", "primary")
+
+		if err := checkDockerAvailable(ctx, mgr, target); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		// streamCtx bounds the capture to duration and is also canceled if the
+		// client cancels the underlying MCP request, letting the operator stop
+		// early instead of waiting out the full duration.
+		streamCtx, cancel := context.WithTimeout(ctx, time.Duration(duration)*time.Second)
+		defer cancel()
+
+		cmd := fmt.Sprintf("docker exec %s tcpdump -i %s -U -w - 'port 5060 or udp portrange %s' 2>/dev/null",
+			shellQuote(container), shellQuote(iface), RTPPortRange)
+		reader, err := mgr.StreamCommand(streamCtx, cmd, target)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		defer reader.Close()
+
+		srv := server.ServerFromContext(ctx)
+		var messages []sip.Message
+		// rtpInFlight admits at most one outstanding RTP notification; a
+		// decode-loop iteration that can't acquire it drops that RTP frame
+		// rather than block on a slow client. SIP signaling is never gated by
+		// this and is always sent.
+		rtpInFlight := make(chan struct{}, 1)
+
+		opts := sip.ParseOptions{MaxBytes: DefaultPCAPLimit}
+		err = sip.WalkPCAPStream(streamCtx, reader, opts, func(ev sip.StreamEvent) error {
+			if srv == nil {
+				if ev.Kind == "sip" {
+					messages = append(messages, *ev.SIP)
+				}
+				return nil
+			}
+
+			switch ev.Kind {
+			case "sip":
+				messages = append(messages, *ev.SIP)
+				if notifyErr := srv.SendNotificationToClient(ctx, "notifications/message", map[string]any{
+					"tool":    "voip_sip_stream",
+					"kind":    "sip",
+					"message": ev.SIP,
+				}); notifyErr != nil {
+					log.Printf("[Tool:voip_sip_stream] Failed to send notification: %v", notifyErr)
+				}
+
+			case "rtp":
+				select {
+				case rtpInFlight <- struct{}{}:
+				default:
+					return nil
+				}
+				go func(pkt *sip.RTPPacket) {
+					defer func() { <-rtpInFlight }()
+					if notifyErr := srv.SendNotificationToClient(ctx, "notifications/message", map[string]any{
+						"tool": "voip_sip_stream",
+						"kind": "rtp",
+						"rtp":  pkt,
+					}); notifyErr != nil {
+						log.Printf("[Tool:voip_sip_stream] Failed to send notification: %v", notifyErr)
+					}
+				}(ev.RTP)
+			}
+			return nil
+		})
+		if err != nil && streamCtx.Err() == nil {
+			return mcp.NewToolResultError(fmt.Sprintf("capture failed: %v", err)), nil
+		}
+
+		jsonBytes, err := json.MarshalIndent(messages, "", "  ")
+		if err != nil {
+			return mcp.NewToolResultError("failed to format messages"), nil
+		}
+
+		return mcp.NewToolResultText(string(jsonBytes)), nil
+	}
+}
+
+func createHEPExportHandler(pool *ssh.Pool) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		mgr := getManager(ctx, pool)
+		if mgr == nil {
+			return mcp.NewToolResultError("No active session"), nil
+		}
+
+		container, _ := req.RequireString("container")
+		pcapFile, _ := req.RequireString("pcap_file")
+		collector, _ := req.RequireString("collector")
+		network := req.GetString("network", "udp")
+		captureID := req.GetInt("capture_id", 0)
+		authKey := req.GetString("auth_key", "")
+		callID := req.GetString("call_id", "")
+		target := req.GetString("target", "primary")
+
+		if network != "udp" && network != "tcp" {
+			return mcp.NewToolResultError(fmt.Sprintf("'network' must be udp or tcp (got %q)", network)), nil
+		}
+
+		if err := checkDockerAvailable(ctx, mgr, target); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		reader, err := openContainerPCAP(ctx, mgr, container, pcapFile, target)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		defer reader.Close()
+
+		opts := sip.ParseOptions{SkipSDP: true}
+		if callID != "" {
+			opts.CallIDAllowlist = []string{callID}
+		}
+
+		result, err := sip.ParsePCAPStream(ctx, reader, opts)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to parse pcap: %v", err)), nil
+		}
+
+		sent := 0
+		for _, msg := range result.Messages {
+			frame, err := hep.Encode(hep.Packet{
+				SrcIP:     msg.SrcIP,
+				SrcPort:   msg.SrcPort,
+				DstIP:     msg.DstIP,
+				DstPort:   msg.DstPort,
+				Transport: msg.Transport,
+				Timestamp: msg.Timestamp,
+				CaptureID: uint32(captureID),
+				AuthKey:   authKey,
+				Payload:   msg.RawPayload,
+			})
+			if err != nil {
+				log.Printf("[Tool:voip_hep_export] skipping call-id=%s: %v", msg.CallID, err)
+				continue
+			}
+			if err := hep.Send(network, collector, frame); err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("failed to send HEP packet %d/%d to %s: %v", sent+1, len(result.Messages), collector, err)), nil
+			}
+			sent++
+		}
+
+		summary := map[string]interface{}{
+			"collector":      collector,
+			"network":        network,
+			"messages_total": len(result.Messages),
+			"messages_sent":  sent,
+		}
+
+		jsonBytes, err := json.MarshalIndent(summary, "", "  ")
+		if err != nil {
+			return mcp.NewToolResultError("failed to format summary"), nil
+		}
+
+		return mcp.NewToolResultText(string(jsonBytes)), nil
+	}
+}
+
+// correlateLeg is one parsed entry from voip_correlate_calls' "legs"
+// argument: where to find a capture to pull dialogs from.
+type correlateLeg struct {
+	container string
+	pcapFile  string
+	target    string
+}
+
+// parseCorrelateLegs converts the raw "legs" argument into a []correlateLeg,
+// mirroring parseTxnOps' handling of edit_transaction's "operations" array.
+func parseCorrelateLegs(raw []interface{}, defaultTarget string) ([]correlateLeg, error) {
+	legs := make([]correlateLeg, 0, len(raw))
+	for i, entry := range raw {
+		obj, ok := entry.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("legs[%d]: expected an object", i)
+		}
+		container, _ := obj["container"].(string)
+		if container == "" {
+			return nil, fmt.Errorf("legs[%d]: 'container' is required", i)
+		}
+		pcapFile, _ := obj["pcap_file"].(string)
+		if pcapFile == "" {
+			return nil, fmt.Errorf("legs[%d]: 'pcap_file' is required", i)
+		}
+		target, _ := obj["target"].(string)
+		if target == "" {
+			target = defaultTarget
+		}
+		legs = append(legs, correlateLeg{container: container, pcapFile: pcapFile, target: target})
+	}
+	return legs, nil
+}
+
+// discoverCorrelateLegs scans every target alias connected on mgr for VoIP
+// containers (by the same keyword match as voip_discover_containers) that
+// have a capture left behind by voip_sip_capture, used when voip_correlate_calls
+// is called without an explicit "legs" list.
+func discoverCorrelateLegs(ctx context.Context, mgr *ssh.Manager) []correlateLeg {
+	pattern := strings.Join(voipContainerKeywords, "\\|")
+	var legs []correlateLeg
+
+	for _, target := range mgr.ListConnections() {
+		if err := checkDockerAvailable(ctx, mgr, target); err != nil {
+			continue
+		}
+
+		cmd := fmt.Sprintf(`docker ps --format '{{.Names}}' | grep -iE '%s' 2>/dev/null || echo ''`, pattern)
+		output, err := mgr.Execute(ctx, cmd, target)
+		if err != nil {
+			continue
+		}
+
+		for _, container := range strings.Split(output, "\n") {
+			container = strings.TrimSpace(container)
+			if container == "" {
+				continue
+			}
+			findCmd := fmt.Sprintf("docker exec %s sh -c 'ls -t /tmp/voip_sip_*.pcap 2>/dev/null | head -1'", shellQuote(container))
+			pcapFile, err := mgr.Execute(ctx, findCmd, target)
+			if err != nil {
+				continue
+			}
+			pcapFile = strings.TrimSpace(pcapFile)
+			if pcapFile == "" {
+				continue
+			}
+			legs = append(legs, correlateLeg{container: container, pcapFile: pcapFile, target: target})
+		}
+	}
+
+	return legs
+}
+
+// correlateDialog is one SIP dialog (a Call, keyed by Call-ID) pulled from a
+// leg's capture, plus the correlation signals voip_correlate_calls matches
+// legs on.
+type correlateDialog struct {
+	leg         correlateLeg
+	call        sip.Call
+	fromTag     string
+	toTag       string
+	xcid        string
+	pcv         string
+	sdpSessions map[string]bool
+	ssrcs       map[uint32]bool
+}
+
+// correlatedLeg is the per-leg view of a super-call in voip_correlate_calls'
+// result.
+type correlatedLeg struct {
+	Container   string             `json:"container"`
+	Target      string             `json:"target"`
+	CallID      string             `json:"call_id"`
+	FromUser    string             `json:"from_user,omitempty"`
+	ToUser      string             `json:"to_user,omitempty"`
+	FinalStatus string             `json:"final_status"`
+	TimingMs    map[string]float64 `json:"timing_ms,omitempty"`
+	OneWayAudio bool               `json:"one_way_audio"`
+}
+
+// superCall is a group of dialogs across legs that voip_correlate_calls
+// believes are the same end-to-end call.
+type superCall struct {
+	Legs         []correlatedLeg `json:"legs"`
+	CorrelatedBy []string        `json:"correlated_by,omitempty"`
+}
+
+// callDialogs extracts correlateDialogs from a leg's parsed capture: one per
+// non-REGISTER Call-ID, carrying the signals used to match it against
+// dialogs from other legs.
+func callDialogs(leg correlateLeg, result *sip.ParseResult) []correlateDialog {
+	dialogs := make([]correlateDialog, 0, len(result.Calls))
+	for _, call := range result.Calls {
+		d := correlateDialog{
+			leg:         leg,
+			call:        call,
+			sdpSessions: make(map[string]bool),
+			ssrcs:       make(map[uint32]bool),
+		}
+		for _, msg := range call.Messages {
+			if d.fromTag == "" && msg.FromTag != "" {
+				d.fromTag = msg.FromTag
+			}
+			if d.toTag == "" && msg.ToTag != "" {
+				d.toTag = msg.ToTag
+			}
+			if d.xcid == "" && msg.XCID != "" {
+				d.xcid = msg.XCID
+			}
+			if d.pcv == "" && msg.PChargingVector != "" {
+				d.pcv = msg.PChargingVector
+			}
+			if msg.HasSDP && msg.SDP != nil && msg.SDP.SessionID != "" {
+				d.sdpSessions[msg.SDP.SessionID] = true
+			}
+		}
+		for _, ms := range call.MediaStreams {
+			d.ssrcs[ms.SSRC] = true
+		}
+		dialogs = append(dialogs, d)
+	}
+	return dialogs
+}
+
+// correlationSignal reports how a and b match, or "" if they don't. Checked
+// in order of how trustworthy the signal is: an exact Call-ID match beats an
+// RTP SSRC coincidence.
+func correlationSignal(a, b correlateDialog) string {
+	if a.call.CallID != "" && a.call.CallID == b.call.CallID {
+		return "call_id"
+	}
+	if a.fromTag != "" && a.toTag != "" && a.fromTag == b.fromTag && a.toTag == b.toTag {
+		return "dialog_tags"
+	}
+	if a.xcid != "" && a.xcid == b.xcid {
+		return "x_cid"
+	}
+	if a.pcv != "" && a.pcv == b.pcv {
+		return "p_charging_vector"
+	}
+	for id := range a.sdpSessions {
+		if b.sdpSessions[id] {
+			return "sdp_session_id"
+		}
+	}
+	for ssrc := range a.ssrcs {
+		if b.ssrcs[ssrc] {
+			return "rtp_ssrc"
+		}
+	}
+	return ""
+}
+
+// correlateDSU is a union-find over dialog indices, grouping dialogs across
+// legs into super-calls.
+type correlateDSU struct {
+	parent []int
+}
+
+func newCorrelateDSU(n int) *correlateDSU {
+	d := &correlateDSU{parent: make([]int, n)}
+	for i := range d.parent {
+		d.parent[i] = i
+	}
+	return d
+}
+
+func (d *correlateDSU) find(i int) int {
+	for d.parent[i] != i {
+		d.parent[i] = d.parent[d.parent[i]]
+		i = d.parent[i]
+	}
+	return i
+}
+
+func (d *correlateDSU) union(i, j int) {
+	ri, rj := d.find(i), d.find(j)
+	if ri != rj {
+		d.parent[ri] = rj
+	}
+}
+
+// dialogTimingMs computes INVITE-relative timing for a dialog's standard
+// events, in milliseconds, skipping events the dialog never reached.
+func dialogTimingMs(call sip.Call) map[string]float64 {
+	var inviteAt time.Time
+	events := make(map[string]time.Time)
+
+	for _, m := range call.Messages {
+		if m.Type == "request" && m.Method == "INVITE" && inviteAt.IsZero() {
+			inviteAt = m.Timestamp
+			events["invite"] = m.Timestamp
+			continue
+		}
+		if m.Type == "response" && m.CSeqMethod == "INVITE" {
+			switch {
+			case m.StatusCode == 100:
+				if _, ok := events["100"]; !ok {
+					events["100"] = m.Timestamp
+				}
+			case m.StatusCode == 180 || m.StatusCode == 183:
+				if _, ok := events["180"]; !ok {
+					events["180"] = m.Timestamp
+				}
+			case m.StatusCode >= 200 && m.StatusCode < 300:
+				if _, ok := events["200"]; !ok {
+					events["200"] = m.Timestamp
+				}
+			}
+			continue
+		}
+		if m.Type == "request" && m.Method == "ACK" {
+			if _, ok := events["ack"]; !ok {
+				events["ack"] = m.Timestamp
+			}
+		}
+		if m.Type == "request" && m.Method == "BYE" {
+			if _, ok := events["bye"]; !ok {
+				events["bye"] = m.Timestamp
+			}
+		}
+	}
+
+	if inviteAt.IsZero() {
+		return nil
+	}
+	timing := make(map[string]float64, len(events))
+	for name, ts := range events {
+		timing[name] = float64(ts.Sub(inviteAt).Microseconds()) / 1000.0
+	}
+	return timing
+}
+
+func createCorrelateCallsHandler(pool *ssh.Pool) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		mgr := getManager(ctx, pool)
+		if mgr == nil {
+			return mcp.NewToolResultError("No active session"), nil
+		}
+
+		defaultTarget := req.GetString("target", "primary")
+
+		legs, err := parseCorrelateLegs(getAnySlice(req, "legs"), defaultTarget)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		if len(legs) == 0 {
+			legs = discoverCorrelateLegs(ctx, mgr)
+		}
+		if len(legs) == 0 {
+			return mcp.NewToolResultText("No legs given and none discovered: no connected target had a VoIP container with a /tmp/voip_sip_*.pcap capture"), nil
+		}
+
+		var dialogs []correlateDialog
+		for _, leg := range legs {
+			if err := checkDockerAvailable(ctx, mgr, leg.target); err != nil {
+				log.Printf("[Tool:voip_correlate_calls] skipping %s/%s: %v", leg.target, leg.container, err)
+				continue
+			}
+			reader, err := openContainerPCAP(ctx, mgr, leg.container, leg.pcapFile, leg.target)
+			if err != nil {
+				log.Printf("[Tool:voip_correlate_calls] skipping %s/%s: %v", leg.target, leg.container, err)
+				continue
+			}
+			result, err := sip.ParsePCAPStream(ctx, reader, sip.ParseOptions{})
+			reader.Close()
+			if err != nil {
+				log.Printf("[Tool:voip_correlate_calls] failed to parse %s on %s/%s: %v", leg.pcapFile, leg.target, leg.container, err)
+				continue
+			}
+			dialogs = append(dialogs, callDialogs(leg, result)...)
+		}
+
+		dsu := newCorrelateDSU(len(dialogs))
+		signalsByGroup := make(map[int]map[string]bool)
+		for i := 0; i < len(dialogs); i++ {
+			for j := i + 1; j < len(dialogs); j++ {
+				if dialogs[i].leg == dialogs[j].leg {
+					continue // a dialog only needs correlating against other legs
+				}
+				if signal := correlationSignal(dialogs[i], dialogs[j]); signal != "" {
+					dsu.union(i, j)
+					root := dsu.find(i)
+					if signalsByGroup[root] == nil {
+						signalsByGroup[root] = make(map[string]bool)
+					}
+					signalsByGroup[root][signal] = true
+				}
+			}
+		}
+
+		groups := make(map[int][]int)
+		for i := range dialogs {
+			root := dsu.find(i)
+			groups[root] = append(groups[root], i)
+		}
+
+		superCalls := make([]superCall, 0, len(groups))
+		for root, members := range groups {
+			sc := superCall{Legs: make([]correlatedLeg, 0, len(members))}
+			for signal := range signalsByGroup[root] {
+				sc.CorrelatedBy = append(sc.CorrelatedBy, signal)
+			}
+			sort.Strings(sc.CorrelatedBy)
+
+			for _, idx := range members {
+				d := dialogs[idx]
+				sc.Legs = append(sc.Legs, correlatedLeg{
+					Container:   d.leg.container,
+					Target:      d.leg.target,
+					CallID:      d.call.CallID,
+					FromUser:    d.call.FromUser,
+					ToUser:      d.call.ToUser,
+					FinalStatus: d.call.FinalStatus,
+					TimingMs:    dialogTimingMs(d.call),
+					OneWayAudio: d.call.HasSDP && len(d.call.MediaStreams) == 0,
+				})
+			}
+			sort.Slice(sc.Legs, func(i, j int) bool {
+				return sc.Legs[i].Container < sc.Legs[j].Container
+			})
+			superCalls = append(superCalls, sc)
+		}
+		sort.Slice(superCalls, func(i, j int) bool {
+			if len(superCalls[i].Legs) == 0 || len(superCalls[j].Legs) == 0 {
+				return len(superCalls[i].Legs) > len(superCalls[j].Legs)
+			}
+			return superCalls[i].Legs[0].CallID < superCalls[j].Legs[0].CallID
+		})
+
+		result := map[string]interface{}{
+			"legs_analyzed": len(legs),
+			"dialogs_found": len(dialogs),
+			"super_calls":   superCalls,
+		}
+
+		jsonBytes, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return mcp.NewToolResultError("failed to format correlation result"), nil
+		}
+
+		return mcp.NewToolResultText(string(jsonBytes)), nil
+	}
+}